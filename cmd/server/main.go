@@ -8,11 +8,14 @@ import (
 	"time"
 
 	aleoUtil "github.com/venture23-aleo/aleo-oracle-notarization-backend/internal/aleoutil"
+	"github.com/venture23-aleo/aleo-oracle-notarization-backend/internal/api/handler"
 	configs "github.com/venture23-aleo/aleo-oracle-notarization-backend/internal/config"
-	"github.com/venture23-aleo/aleo-oracle-notarization-backend/internal/logger"
 	"github.com/venture23-aleo/aleo-oracle-notarization-backend/internal/metrics"
 	"github.com/venture23-aleo/aleo-oracle-notarization-backend/internal/server"
-	"github.com/venture23-aleo/aleo-oracle-notarization-backend/internal/sgx"
+	data_extraction "github.com/venture23-aleo/aleo-oracle-notarization-backend/internal/services/dataextraction"
+	"github.com/venture23-aleo/aleo-oracle-notarization-backend/internal/services/scheduler"
+	"github.com/venture23-aleo/aleo-oracle-notarization-backend/pkg/logger"
+	"github.com/venture23-aleo/aleo-oracle-notarization-backend/pkg/sgx"
 )
 
 func main() {
@@ -24,7 +27,12 @@ func main() {
 	}
 	logger.InitLogger(appConfig.LogLevel)
 
-	// 2. Enforce SGX startup check
+	// 2. Initialize the SGX quote provider and enforce SGX startup check
+	allowSimulatedSGX := os.Getenv(sgx.AllowSimulatedSGXEnv) == "true"
+	if err := sgx.InitQuoteProvider(appConfig.SGXConfig.QuoteProvider, allowSimulatedSGX); err != nil {
+		logger.Fatal("Failed to initialize SGX quote provider: %v", err)
+	}
+
 	if err := sgx.EnforceSGXStartup(); err != nil {
 		logger.Fatal("Failed to enforce SGX startup check: %v", err)
 	}
@@ -35,6 +43,17 @@ func main() {
 	if err := configs.ValidateConfigs(); err != nil {
 		logger.Fatal("Configuration validation failed: %v", err)
 	}
+	if err := data_extraction.ValidateExchangeAdapters(configs.GetExchangesConfigs()); err != nil {
+		logger.Fatal("Configuration validation failed: %v", err)
+	}
+	if err := data_extraction.CheckPriceGuardStateFile(); err != nil {
+		logger.Fatal("Price guard state file check failed: %v", err)
+	}
+	if schedulerConfig := configs.GetAttestationSchedulerConfig(); schedulerConfig.Enabled {
+		if err := scheduler.CheckStateFile(schedulerConfig); err != nil {
+			logger.Fatal("Attestation scheduler state file check failed: %v", err)
+		}
+	}
 
 	// 4. Initialize Aleo context
 	if err := aleoUtil.InitAleoContext(); err != nil {
@@ -46,6 +65,20 @@ func main() {
 	systemMetricsCollector.Start()
 	defer systemMetricsCollector.Stop()
 
+	// Start the price sampler behind the TWAP price feeds
+	if samplerConfig := configs.GetPriceSamplerConfig(); samplerConfig.Enabled {
+		priceSampler := data_extraction.NewPriceSampler(samplerConfig)
+		priceSampler.Start()
+		defer priceSampler.Stop()
+	}
+
+	// Start the attestation scheduler publishing the deviation and heartbeat attestations
+	if schedulerConfig := configs.GetAttestationSchedulerConfig(); schedulerConfig.Enabled {
+		attestationScheduler := scheduler.NewScheduler(schedulerConfig, handler.GenerateSingleAttestation)
+		attestationScheduler.Start()
+		defer attestationScheduler.Stop()
+	}
+
 	// 6. Create HTTP server
 	notarizationServer, metricsServer := server.NewServer()
 
@@ -53,8 +86,11 @@ func main() {
 	notarizationServer.SetKeepAlivesEnabled(false)
 	metricsServer.SetKeepAlivesEnabled(false)
 
+	// Create the admin server, nil when the admin listener is disabled
+	adminServer := server.NewAdminServer()
+
 	// Create a channel to listen for server errors
-	serverErr := make(chan error, 2)
+	serverErr := make(chan error, 3)
 
 	// 7. Start notarization server
 	go func() {
@@ -68,6 +104,14 @@ func main() {
 		serverErr <- metricsServer.ListenAndServe()
 	}()
 
+	// Start admin server
+	if adminServer != nil {
+		go func() {
+			logger.Info("Admin server started", "address", adminServer.Addr)
+			serverErr <- adminServer.ListenAndServe()
+		}()
+	}
+
 	// 9. Listen for shutdown signals
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, os.Interrupt, syscall.SIGTERM)
@@ -91,6 +135,11 @@ func main() {
 	if err := metricsServer.Shutdown(ctx); err != nil {
 		logger.Error("Metrics server shutdown error", "error", err)
 	}
+	if adminServer != nil {
+		if err := adminServer.Shutdown(ctx); err != nil {
+			logger.Error("Admin server shutdown error", "error", err)
+		}
+	}
 
 	// 12. Shutdown Aleo context
 	if err := aleoUtil.ShutdownAleoContext(); err != nil {
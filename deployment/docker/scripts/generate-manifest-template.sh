@@ -33,6 +33,8 @@ mounts = [
   { uri = "file:static_hosts", path = "/etc/hosts" },
   { uri = "file:/etc/sgx_default_qcnl.conf", path = "/etc/sgx_default_qcnl.conf" },
   { uri = "file:rootCAs/", path = "/rootCAs/" },
+  { type = "encrypted", uri = "file:sealed/mrenclave/", path = "/sealed/mrenclave/", key_name = "_sgx_mrenclave" },
+  { type = "encrypted", uri = "file:sealed/mrsigner/", path = "/sealed/mrsigner/", key_name = "_sgx_mrsigner" },
 ]
 
 [sgx]
@@ -37,7 +37,9 @@ mounts = [
   { uri = "file:/etc/resolv.conf", path = "/etc/resolv.conf" },
   { uri = "file:/etc/hosts", path = "/etc/hosts" },
   { uri = "file:/etc/sgx_default_qcnl.conf", path = "/etc/sgx_default_qcnl.conf" },
-  { uri = "file:${INPUTS_DIR}/rootCAs/", path = "/rootCAs/" }
+  { uri = "file:${INPUTS_DIR}/rootCAs/", path = "/rootCAs/" },
+  { type = "encrypted", uri = "file:${ENCLAVE_ARTIFACTS_DIR}/sealed/mrenclave/", path = "/sealed/mrenclave/", key_name = "_sgx_mrenclave" },
+  { type = "encrypted", uri = "file:${ENCLAVE_ARTIFACTS_DIR}/sealed/mrsigner/", path = "/sealed/mrsigner/", key_name = "_sgx_mrsigner" }
 ]
 
 [sgx]
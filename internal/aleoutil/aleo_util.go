@@ -3,9 +3,11 @@ package aleo
 import (
 	"fmt"
 	"sync"
+	"time"
 
-	appErrors "github.com/venture23-aleo/aleo-oracle-notarization-backend/internal/errors"
-	"github.com/venture23-aleo/aleo-oracle-notarization-backend/internal/logger"
+	configs "github.com/venture23-aleo/aleo-oracle-notarization-backend/internal/config"
+	appErrors "github.com/venture23-aleo/aleo-oracle-notarization-backend/pkg/errors"
+	"github.com/venture23-aleo/aleo-oracle-notarization-backend/pkg/logger"
 	aleoUtils "github.com/venture23-aleo/aleo-utils-go"
 )
 
@@ -14,7 +16,13 @@ type AleoPublicContext interface {
 	HashMessageToString(message []byte) (string, error) // Hash a message and return a string.
 	FormatMessage(message []byte, chunkSize int) ([]byte, error) // Format a message.
 	GetPublicKey() string                // Get the Aleo public key.
-	Sign(message []byte) (string, error) // Sign a message.
+	GetSealingPolicy() string            // Get the signing key sealing policy.
+	GetKeyFingerprint() string           // Get the signing key fingerprint.
+	GetPreviousPublicKey() (string, time.Time) // Get the previous Aleo public key during a rotation overlap window.
+	GetPlannedPublicKey() (string, time.Time) // Get the planned Aleo public key before its rotation takes effect.
+	Sign(message []byte) (string, string, error) // Sign a message and return the signature with the signing address.
+	SignWithRotation(message []byte) (*RotationSignatures, error) // Sign a message with the current key and, during a rotation overlap window, the previous key.
+	RotateKey(effectiveAt time.Time, overlap time.Duration, attest HandoverAttestor) (*KeyHandover, error) // Rotate the signing key.
 }
 
 type AleoContext struct {
@@ -22,6 +30,15 @@ type AleoContext struct {
 	session    aleoUtils.Session // The Aleo session.
 	privateKey []byte            // The Aleo private key.
 	PublicKey  string            // The Aleo public key.
+	SealingPolicy string         // The signing key sealing policy.
+	sealedKeyPath string         // The sealed key file path, empty when the key is not sealed.
+	previousPrivateKey []byte    // The previous Aleo private key, kept during a rotation overlap window.
+	previousPublicKey  string    // The previous Aleo public key.
+	previousValidUntil time.Time // The end of the rotation overlap window.
+	plannedPrivateKey  []byte    // The planned Aleo private key, signing from the planned effective time.
+	plannedPublicKey   string    // The planned Aleo public key.
+	plannedEffectiveAt time.Time // The time the planned key takes over.
+	plannedOverlapUntil time.Time // The end of the overlap window of the planned rotation.
 	Close      func()            // The Aleo close function.
 }
 
@@ -47,26 +64,61 @@ func (a *AleoContext) HashMessageToString(message []byte) (string, error) {
 
 // GetPublicKey returns the Aleo public key.
 func (a *AleoContext) GetPublicKey() string {
-	return a.PublicKey
+	a.sessionLock.RLock()
+	defer a.sessionLock.RUnlock()
+	current, _, _ := a.activeKeys(time.Now())
+	return current.address
 }
 
-// Sign signs a message.
-func (a *AleoContext) Sign(message []byte) (string, error) {
+// GetSealingPolicy returns the signing key sealing policy.
+func (a *AleoContext) GetSealingPolicy() string {
+	return a.SealingPolicy
+}
+
+// GetKeyFingerprint returns the signing key fingerprint.
+func (a *AleoContext) GetKeyFingerprint() string {
+	return KeyFingerprint(a.GetPublicKey())
+}
+
+// Sign signs a message and returns the signature together with the address of the key that produced it.
+func (a *AleoContext) Sign(message []byte) (string, string, error) {
 	if IsAleoContextInitialized() {
 		a.sessionLock.Lock()
 		defer a.sessionLock.Unlock()
-		return a.session.Sign(a.privateKey, message)
+		current, _, _ := a.activeKeys(time.Now())
+		signature, err := a.session.Sign(current.privateKey, message)
+		if err != nil {
+			return "", "", err
+		}
+		return signature, current.address, nil
 	}
-	return "", appErrors.ErrAleoContext.WithDetails("Aleo context is not initialized")
+	return "", "", appErrors.ErrAleoContext.WithDetails("Aleo context is not initialized")
 }
 
 // String returns a string representation of the Aleo context.
 func (a *AleoContext) String() string {
-	return fmt.Sprintf("AleoContext{Session: <hidden>, PublicKey: %s}", a.PublicKey)
+	return fmt.Sprintf("AleoContext{Session: <hidden>, PublicKey: %s, SealingPolicy: %s}", a.GetPublicKey(), a.SealingPolicy)
 }
 
 // newAleoContext creates a new Aleo context with a session and a private key.
+// When sealing is enabled the private key is loaded from, or generated and sealed to, the Gramine encrypted mount.
 func newAleoContext() (*AleoContext, error) {
+	signingKeyConfig := configs.GetAppConfig().SigningKeyConfig
+
+	sealingPolicy := SealingPolicyNone
+	var sealedKeyPath string
+	if signingKeyConfig.Sealed {
+		path, err := GetSealedKeyPath(signingKeyConfig.SealingPolicy)
+		if err != nil {
+			return nil, err
+		}
+		if err := checkSealingEnvironment(path); err != nil {
+			return nil, err
+		}
+		sealingPolicy = signingKeyConfig.SealingPolicy
+		sealedKeyPath = path
+	}
+
 	// Create a new wrapper.
 	wrapper, closeFn, err := aleoUtils.NewWrapper()
 	if err != nil {
@@ -79,19 +131,34 @@ func newAleoContext() (*AleoContext, error) {
 		return nil, err
 	}
 
-	// Generate a new private key.
-	privKey, address, err := s.NewPrivateKey()
-	if err != nil {
-		return nil, err
-	}
-
-	return &AleoContext{
+	aleoCtx := &AleoContext{
 		sessionLock: sync.RWMutex{},
 		session:    s,
-		privateKey: privKey,
-		PublicKey:  address,
+		SealingPolicy: sealingPolicy,
+		sealedKeyPath: sealedKeyPath,
 		Close:      closeFn,
-	}, nil
+	}
+
+	if sealedKeyPath != "" {
+		// Load the sealed private key or generate and seal a new one.
+		sealedKey, err := loadOrCreateSealedKey(s, sealedKeyPath)
+		if err != nil {
+			closeFn()
+			return nil, err
+		}
+		aleoCtx.restoreSealedKey(sealedKey)
+	} else {
+		// Generate a new private key.
+		privKey, address, err := s.NewPrivateKey()
+		if err != nil {
+			closeFn()
+			return nil, err
+		}
+		aleoCtx.privateKey = privKey
+		aleoCtx.PublicKey = address
+	}
+
+	return aleoCtx, nil
 }
 
 // AleoContextManager manages the singleton Aleo context.
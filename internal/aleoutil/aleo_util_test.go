@@ -9,7 +9,7 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
-	"github.com/venture23-aleo/aleo-oracle-notarization-backend/internal/logger"
+	"github.com/venture23-aleo/aleo-oracle-notarization-backend/pkg/logger"
 )
 
 // TestMain initializes the logger for all tests in this package
@@ -60,13 +60,14 @@ func TestGenerateSignature(t *testing.T) {
 	}
 
 	for _, testCase := range testCases {
-		signature, signErr := aleoCtx.Sign(testCase.message)
+		signature, address, signErr := aleoCtx.Sign(testCase.message)
 		if testCase.expectedError {
 			assert.NotNil(t, signErr)
 			assert.Empty(t, signature)
 		} else {
 			assert.Nil(t, signErr)
 			assert.NotEmpty(t, signature)
+			assert.Equal(t, aleoCtx.GetPublicKey(), address)
 		}
 	}
 
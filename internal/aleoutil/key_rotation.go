@@ -0,0 +1,266 @@
+package aleo
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/venture23-aleo/aleo-oracle-notarization-backend/pkg/logger"
+)
+
+// HandoverMessageChunkSize is the number of Aleo struct chunks the handover message is formatted into.
+const HandoverMessageChunkSize = 1
+
+// KeyHandover is the record of a signer key rotation, signed by the previous key.
+type KeyHandover struct {
+	// Address of the key being rotated out.
+	OldAddress string `json:"oldAddress"`
+
+	// Address of the key being rotated in.
+	NewAddress string `json:"newAddress"`
+
+	// Unix timestamp from which the new key signs attestations.
+	EffectiveAt int64 `json:"effectiveAt"`
+
+	// Unix timestamp until which the old key keeps signing attestations alongside the new key.
+	OverlapUntil int64 `json:"overlapUntil"`
+
+	// Plain text handover message:
+	// "aleo-oracle-key-handover:<old address>:<new address>:<effective at>:<overlap until>".
+	Message string `json:"message"`
+
+	// Aleo-encoded handover message.
+	FormattedMessage string `json:"formattedMessage"`
+
+	// Poseidon8 hash of the Aleo-encoded handover message.
+	MessageHash string `json:"messageHash"`
+
+	// Schnorr signature of the message hash by the old key.
+	Signature string `json:"signature"`
+}
+
+// FormatHandoverMessage formats the plain text handover message. The end of the overlap window is part of the
+// signed message, so it cannot be altered without breaking the signature.
+func FormatHandoverMessage(oldAddress, newAddress string, effectiveAt, overlapUntil int64) string {
+	return fmt.Sprintf("aleo-oracle-key-handover:%s:%s:%d:%d", oldAddress, newAddress, effectiveAt, overlapUntil)
+}
+
+// HandoverAttestor attests a signed handover before the rotation takes effect. An error aborts the rotation.
+type HandoverAttestor func(handover *KeyHandover) error
+
+// ErrKeyRotationPlanned is returned when a key rotation is requested while another one has not taken effect yet.
+var ErrKeyRotationPlanned = errors.New("a key rotation is already planned")
+
+// signingKey is an Aleo private key with its address.
+type signingKey struct {
+	privateKey []byte
+	address    string
+}
+
+// activeKeys returns the current key and, during a rotation overlap window, the previous key and the end of the
+// window at a time. A planned key becomes the current key at its effective time, and the key it replaces then
+// becomes the previous key until the end of the overlap window. The caller holds sessionLock.
+func (a *AleoContext) activeKeys(now time.Time) (current signingKey, previous signingKey, previousValidUntil time.Time) {
+	current = signingKey{privateKey: a.privateKey, address: a.PublicKey}
+	previous = signingKey{privateKey: a.previousPrivateKey, address: a.previousPublicKey}
+	previousValidUntil = a.previousValidUntil
+
+	if a.plannedPublicKey != "" && !now.Before(a.plannedEffectiveAt) {
+		previous, previousValidUntil = current, a.plannedOverlapUntil
+		current = signingKey{privateKey: a.plannedPrivateKey, address: a.plannedPublicKey}
+	}
+
+	if previous.address == "" || !now.Before(previousValidUntil) {
+		return current, signingKey{}, time.Time{}
+	}
+	return current, previous, previousValidUntil
+}
+
+// promotePlannedKey makes a planned key that has taken effect the current key. The caller holds sessionLock for writing.
+func (a *AleoContext) promotePlannedKey(now time.Time) {
+	if a.plannedPublicKey == "" || now.Before(a.plannedEffectiveAt) {
+		return
+	}
+
+	a.previousPrivateKey = a.privateKey
+	a.previousPublicKey = a.PublicKey
+	a.previousValidUntil = a.plannedOverlapUntil
+	a.privateKey = a.plannedPrivateKey
+	a.PublicKey = a.plannedPublicKey
+	a.plannedPrivateKey = nil
+	a.plannedPublicKey = ""
+	a.plannedEffectiveAt = time.Time{}
+	a.plannedOverlapUntil = time.Time{}
+}
+
+// restoreSealedKey restores the current, previous and planned keys from the sealed file content.
+func (a *AleoContext) restoreSealedKey(sealedKey *sealedSigningKey) {
+	a.privateKey = sealedKey.PrivateKey
+	a.PublicKey = sealedKey.Address
+
+	if sealedKey.Previous != nil {
+		a.previousPrivateKey = sealedKey.Previous.PrivateKey
+		a.previousPublicKey = sealedKey.Previous.Address
+		a.previousValidUntil = time.Unix(sealedKey.PreviousValidUntil, 0)
+	}
+
+	if sealedKey.Planned != nil {
+		a.plannedPrivateKey = sealedKey.Planned.PrivateKey
+		a.plannedPublicKey = sealedKey.Planned.Address
+		a.plannedEffectiveAt = time.Unix(sealedKey.PlannedEffectiveAt, 0)
+		a.plannedOverlapUntil = time.Unix(sealedKey.PlannedOverlapUntil, 0)
+	}
+}
+
+// GetPreviousPublicKey returns the previous Aleo public key and the end of the overlap window.
+// It returns an empty key once the overlap window has closed.
+func (a *AleoContext) GetPreviousPublicKey() (string, time.Time) {
+	a.sessionLock.RLock()
+	defer a.sessionLock.RUnlock()
+
+	_, previous, validUntil := a.activeKeys(time.Now())
+	return previous.address, validUntil
+}
+
+// GetPlannedPublicKey returns the planned Aleo public key and the time it takes over.
+// It returns an empty key when no rotation is planned or once the planned rotation has taken effect.
+func (a *AleoContext) GetPlannedPublicKey() (string, time.Time) {
+	a.sessionLock.RLock()
+	defer a.sessionLock.RUnlock()
+
+	if a.plannedPublicKey == "" || !time.Now().Before(a.plannedEffectiveAt) {
+		return "", time.Time{}
+	}
+	return a.plannedPublicKey, a.plannedEffectiveAt
+}
+
+// RotationSignatures is a message signed by the current key and, during a rotation overlap window, by the previous key.
+type RotationSignatures struct {
+	Signature         string // Signature by the current key.
+	Address           string // Address of the current key.
+	PreviousSignature string // Signature by the previous key, empty outside a rotation overlap window.
+	PreviousAddress   string // Address of the previous key, empty outside a rotation overlap window.
+}
+
+// SignWithRotation signs a message with the current key and, during the rotation overlap window, with the
+// previous key. Both signatures are produced under one lock, so a concurrent rotation cannot pair a signature
+// with the address of another key.
+func (a *AleoContext) SignWithRotation(message []byte) (*RotationSignatures, error) {
+	a.sessionLock.Lock()
+	defer a.sessionLock.Unlock()
+
+	current, previous, _ := a.activeKeys(time.Now())
+
+	signature, err := a.session.Sign(current.privateKey, message)
+	if err != nil {
+		return nil, err
+	}
+	signatures := &RotationSignatures{Signature: signature, Address: current.address}
+
+	if previous.address == "" {
+		return signatures, nil
+	}
+
+	previousSignature, err := a.session.Sign(previous.privateKey, message)
+	if err != nil {
+		return nil, err
+	}
+	signatures.PreviousSignature = previousSignature
+	signatures.PreviousAddress = previous.address
+
+	return signatures, nil
+}
+
+// RotateKey generates a new signing key and signs a handover message with the current key.
+//
+// The new key takes over at effectiveAt, which may be in the future to plan the rotation, and the current key
+// keeps signing alongside it until the overlap window closes. Only one rotation can be planned at a time.
+//
+// The signed handover is passed to attest, when not nil, before anything is committed: an
+// attestation failure leaves the current key in place. When the key is sealed, the planned key is
+// sealed with the current key before the rotation is committed, so a failed write leaves the current
+// key in place and a planned rotation survives restarts.
+func (a *AleoContext) RotateKey(effectiveAt time.Time, overlap time.Duration, attest HandoverAttestor) (*KeyHandover, error) {
+	a.sessionLock.Lock()
+	defer a.sessionLock.Unlock()
+
+	now := time.Now()
+	a.promotePlannedKey(now)
+	if a.plannedPublicKey != "" {
+		return nil, fmt.Errorf("%w: %s takes over at %s", ErrKeyRotationPlanned, a.plannedPublicKey, a.plannedEffectiveAt.UTC().Format(time.RFC3339))
+	}
+
+	newPrivKey, newAddress, err := a.session.NewPrivateKey()
+	if err != nil {
+		return nil, fmt.Errorf("generating new key: %w", err)
+	}
+
+	// The handover message carries Unix seconds, so the rotation takes effect at the signed second.
+	effectiveAt = time.Unix(effectiveAt.Unix(), 0)
+	overlapUntil := effectiveAt.Add(overlap)
+
+	message := FormatHandoverMessage(a.PublicKey, newAddress, effectiveAt.Unix(), overlapUntil.Unix())
+
+	formattedMessage, err := a.session.FormatMessage([]byte(message), HandoverMessageChunkSize)
+	if err != nil {
+		return nil, fmt.Errorf("formatting handover message: %w", err)
+	}
+
+	hashedMessage, err := a.session.HashMessage(formattedMessage)
+	if err != nil {
+		return nil, fmt.Errorf("hashing handover message: %w", err)
+	}
+
+	messageHash, err := a.session.HashMessageToString(formattedMessage)
+	if err != nil {
+		return nil, fmt.Errorf("hashing handover message: %w", err)
+	}
+
+	signature, err := a.session.Sign(a.privateKey, hashedMessage)
+	if err != nil {
+		return nil, fmt.Errorf("signing handover message: %w", err)
+	}
+
+	handover := &KeyHandover{
+		OldAddress:       a.PublicKey,
+		NewAddress:       newAddress,
+		EffectiveAt:      effectiveAt.Unix(),
+		OverlapUntil:     overlapUntil.Unix(),
+		Message:          message,
+		FormattedMessage: string(formattedMessage),
+		MessageHash:      messageHash,
+		Signature:        signature,
+	}
+
+	if attest != nil {
+		if err := attest(handover); err != nil {
+			return nil, fmt.Errorf("attesting handover: %w", err)
+		}
+	}
+
+	if a.sealedKeyPath != "" {
+		sealedKey := &sealedSigningKey{
+			PrivateKey:          a.privateKey,
+			Address:             a.PublicKey,
+			Planned:             &sealedSigningKey{PrivateKey: newPrivKey, Address: newAddress},
+			PlannedEffectiveAt:  effectiveAt.Unix(),
+			PlannedOverlapUntil: overlapUntil.Unix(),
+		}
+		if _, previous, validUntil := a.activeKeys(now); previous.address != "" {
+			sealedKey.Previous = &sealedSigningKey{PrivateKey: previous.privateKey, Address: previous.address}
+			sealedKey.PreviousValidUntil = validUntil.Unix()
+		}
+		if err := writeSealedKey(a.sealedKeyPath, sealedKey); err != nil {
+			return nil, err
+		}
+	}
+
+	a.plannedPrivateKey = newPrivKey
+	a.plannedPublicKey = newAddress
+	a.plannedEffectiveAt = effectiveAt
+	a.plannedOverlapUntil = overlapUntil
+
+	logger.Info("Aleo signing key rotation planned", "oldAddress", handover.OldAddress, "newAddress", handover.NewAddress, "effectiveAt", effectiveAt, "overlapUntil", overlapUntil)
+
+	return handover, nil
+}
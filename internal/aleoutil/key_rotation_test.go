@@ -0,0 +1,185 @@
+package aleo
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFormatHandoverMessage(t *testing.T) {
+	message := FormatHandoverMessage("aleo1old", "aleo1new", 1754278324, 1754364724)
+	assert.Equal(t, "aleo-oracle-key-handover:aleo1old:aleo1new:1754278324:1754364724", message)
+}
+
+func TestAleoContext_RotateKey(t *testing.T) {
+	aleoCtx, err := newAleoContext()
+	require.NoError(t, err)
+	t.Cleanup(aleoCtx.Close)
+
+	oldAddress := aleoCtx.GetPublicKey()
+
+	// No previous key before the first rotation.
+	previousKey, _ := aleoCtx.GetPreviousPublicKey()
+	assert.Empty(t, previousKey)
+
+	effectiveAt := time.Now()
+	handover, err := aleoCtx.RotateKey(effectiveAt, time.Hour, nil)
+	require.NoError(t, err)
+
+	assert.Equal(t, oldAddress, handover.OldAddress)
+	assert.Equal(t, aleoCtx.GetPublicKey(), handover.NewAddress)
+	assert.NotEqual(t, handover.OldAddress, handover.NewAddress)
+	assert.Equal(t, effectiveAt.Unix(), handover.EffectiveAt)
+	assert.Equal(t, effectiveAt.Add(time.Hour).Unix(), handover.OverlapUntil)
+	assert.Equal(t, FormatHandoverMessage(handover.OldAddress, handover.NewAddress, handover.EffectiveAt, handover.OverlapUntil), handover.Message)
+	assert.NotEmpty(t, handover.FormattedMessage)
+	assert.NotEmpty(t, handover.MessageHash)
+	assert.NotEmpty(t, handover.Signature)
+
+	// The old key keeps signing during the overlap window.
+	previousKey, validUntil := aleoCtx.GetPreviousPublicKey()
+	assert.Equal(t, oldAddress, previousKey)
+	assert.Equal(t, effectiveAt.Add(time.Hour).Unix(), validUntil.Unix())
+
+	signatures, err := aleoCtx.SignWithRotation([]byte("message longer than sixteen bytes"))
+	require.NoError(t, err)
+	assert.NotEmpty(t, signatures.Signature)
+	assert.Equal(t, handover.NewAddress, signatures.Address)
+	assert.NotEmpty(t, signatures.PreviousSignature)
+	assert.Equal(t, oldAddress, signatures.PreviousAddress)
+}
+
+func TestAleoContext_RotateKey_AttestationFailed(t *testing.T) {
+	aleoCtx, err := newAleoContext()
+	require.NoError(t, err)
+	t.Cleanup(aleoCtx.Close)
+
+	aleoCtx.sealedKeyPath = filepath.Join(t.TempDir(), "aleo_signing_key.json")
+	oldAddress := aleoCtx.GetPublicKey()
+
+	var attested *KeyHandover
+	_, err = aleoCtx.RotateKey(time.Now(), time.Hour, func(handover *KeyHandover) error {
+		attested = handover
+		return errors.New("quote failed")
+	})
+	require.ErrorContains(t, err, "quote failed")
+
+	// The handover was signed, but the rotation did not take effect.
+	require.NotNil(t, attested)
+	assert.NotEmpty(t, attested.Signature)
+	assert.Equal(t, oldAddress, aleoCtx.GetPublicKey())
+	previousKey, _ := aleoCtx.GetPreviousPublicKey()
+	assert.Empty(t, previousKey)
+	assert.NoFileExists(t, aleoCtx.sealedKeyPath)
+}
+
+func TestAleoContext_RotateKey_OverlapExpired(t *testing.T) {
+	aleoCtx, err := newAleoContext()
+	require.NoError(t, err)
+	t.Cleanup(aleoCtx.Close)
+
+	_, err = aleoCtx.RotateKey(time.Now().Add(-2*time.Hour), time.Hour, nil)
+	require.NoError(t, err)
+
+	previousKey, _ := aleoCtx.GetPreviousPublicKey()
+	assert.Empty(t, previousKey)
+
+	signatures, err := aleoCtx.SignWithRotation([]byte("message longer than sixteen bytes"))
+	require.NoError(t, err)
+	assert.NotEmpty(t, signatures.Signature)
+	assert.Empty(t, signatures.PreviousSignature)
+	assert.Empty(t, signatures.PreviousAddress)
+}
+
+func TestAleoContext_RotateKey_Planned(t *testing.T) {
+	aleoCtx, err := newAleoContext()
+	require.NoError(t, err)
+	t.Cleanup(aleoCtx.Close)
+
+	oldAddress := aleoCtx.GetPublicKey()
+	effectiveAt := time.Now().Add(time.Hour)
+	handover, err := aleoCtx.RotateKey(effectiveAt, time.Hour, nil)
+	require.NoError(t, err)
+	assert.Equal(t, effectiveAt.Unix(), handover.EffectiveAt)
+	assert.Equal(t, effectiveAt.Add(time.Hour).Unix(), handover.OverlapUntil)
+
+	// The old key keeps signing alone until the rotation takes effect.
+	assert.Equal(t, oldAddress, aleoCtx.GetPublicKey())
+	previousKey, _ := aleoCtx.GetPreviousPublicKey()
+	assert.Empty(t, previousKey)
+	plannedKey, plannedAt := aleoCtx.GetPlannedPublicKey()
+	assert.Equal(t, handover.NewAddress, plannedKey)
+	assert.Equal(t, handover.EffectiveAt, plannedAt.Unix())
+
+	signatures, err := aleoCtx.SignWithRotation([]byte("message longer than sixteen bytes"))
+	require.NoError(t, err)
+	assert.Equal(t, oldAddress, signatures.Address)
+	assert.Empty(t, signatures.PreviousAddress)
+
+	// A second rotation cannot be planned before the first one takes effect.
+	_, err = aleoCtx.RotateKey(time.Now(), time.Hour, nil)
+	require.ErrorIs(t, err, ErrKeyRotationPlanned)
+
+	// At the effective time the new key takes over and the old key signs until the end of the overlap window.
+	current, previous, validUntil := aleoCtx.activeKeys(effectiveAt.Add(time.Minute))
+	assert.Equal(t, handover.NewAddress, current.address)
+	assert.Equal(t, oldAddress, previous.address)
+	assert.Equal(t, handover.OverlapUntil, validUntil.Unix())
+
+	current, previous, _ = aleoCtx.activeKeys(effectiveAt.Add(2 * time.Hour))
+	assert.Equal(t, handover.NewAddress, current.address)
+	assert.Empty(t, previous.address)
+}
+
+func TestAleoContext_RotateKey_Sealed(t *testing.T) {
+	aleoCtx, err := newAleoContext()
+	require.NoError(t, err)
+	t.Cleanup(aleoCtx.Close)
+
+	aleoCtx.sealedKeyPath = filepath.Join(t.TempDir(), "aleo_signing_key.json")
+
+	handover, err := aleoCtx.RotateKey(time.Now().Add(time.Hour), time.Hour, nil)
+	require.NoError(t, err)
+
+	// The sealed file holds the current key and the planned key with its effective time and overlap window.
+	sealedKey, err := loadOrCreateSealedKey(aleoCtx.session, aleoCtx.sealedKeyPath)
+	require.NoError(t, err)
+	assert.Equal(t, handover.OldAddress, sealedKey.Address)
+	assert.Nil(t, sealedKey.Previous)
+	require.NotNil(t, sealedKey.Planned)
+	assert.Equal(t, handover.NewAddress, sealedKey.Planned.Address)
+	assert.Equal(t, handover.EffectiveAt, sealedKey.PlannedEffectiveAt)
+	assert.Equal(t, handover.OverlapUntil, sealedKey.PlannedOverlapUntil)
+
+	// A restart restores the planned rotation.
+	restored := &AleoContext{}
+	restored.restoreSealedKey(sealedKey)
+	plannedKey, plannedAt := restored.GetPlannedPublicKey()
+	assert.Equal(t, handover.NewAddress, plannedKey)
+	assert.Equal(t, handover.EffectiveAt, plannedAt.Unix())
+
+	effectiveAt := time.Unix(handover.EffectiveAt, 0)
+	current, previous, validUntil := restored.activeKeys(effectiveAt)
+	assert.Equal(t, handover.NewAddress, current.address)
+	assert.Equal(t, handover.OldAddress, previous.address)
+	assert.Equal(t, handover.OverlapUntil, validUntil.Unix())
+
+	// Once the planned rotation took effect, the next rotation seals the old key as the previous key.
+	aleoCtx.plannedEffectiveAt = time.Now().Add(-time.Minute)
+	next, err := aleoCtx.RotateKey(time.Now(), time.Hour, nil)
+	require.NoError(t, err)
+	assert.Equal(t, handover.NewAddress, next.OldAddress)
+
+	sealedKey, err = loadOrCreateSealedKey(aleoCtx.session, aleoCtx.sealedKeyPath)
+	require.NoError(t, err)
+	assert.Equal(t, handover.NewAddress, sealedKey.Address)
+	require.NotNil(t, sealedKey.Previous)
+	assert.Equal(t, handover.OldAddress, sealedKey.Previous.Address)
+	assert.Equal(t, handover.OverlapUntil, sealedKey.PreviousValidUntil)
+	require.NotNil(t, sealedKey.Planned)
+	assert.Equal(t, next.NewAddress, sealedKey.Planned.Address)
+}
@@ -0,0 +1,139 @@
+package aleo
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/venture23-aleo/aleo-oracle-notarization-backend/pkg/logger"
+	aleoUtils "github.com/venture23-aleo/aleo-utils-go"
+)
+
+// Sealing policies for the Aleo signing key.
+const (
+	// SealingPolicyNone means the signing key is ephemeral and regenerated on every start.
+	SealingPolicyNone = "none"
+
+	// SealingPolicyMREnclave seals the signing key to the enclave measurement. The key is lost on any code change.
+	SealingPolicyMREnclave = "mrenclave"
+
+	// SealingPolicyMRSigner seals the signing key to the enclave signer. The key survives upgrades signed by the same key.
+	SealingPolicyMRSigner = "mrsigner"
+)
+
+// sealedKeyPaths maps each sealing policy to the key file inside the matching Gramine encrypted mount.
+// The mounts are declared in the manifest with key_name "_sgx_mrenclave" and "_sgx_mrsigner".
+var sealedKeyPaths = map[string]string{
+	SealingPolicyMREnclave: "/sealed/mrenclave/aleo_signing_key.json",
+	SealingPolicyMRSigner:  "/sealed/mrsigner/aleo_signing_key.json",
+}
+
+// attestationDevicePath is Gramine's attestation pseudo-filesystem, only present inside an SGX enclave.
+var attestationDevicePath = "/dev/attestation"
+
+// checkSealingEnvironment refuses to seal the signing key outside a Gramine SGX enclave. Without
+// /dev/attestation and the encrypted mount holding path, the key would be written to the host in plaintext.
+func checkSealingEnvironment(path string) error {
+	if _, err := os.Stat(attestationDevicePath); err != nil {
+		return fmt.Errorf("sealed signing key requires an SGX enclave: %s is not available: %w", attestationDevicePath, err)
+	}
+
+	mountDir := filepath.Dir(path)
+	info, err := os.Stat(mountDir)
+	if err != nil {
+		return fmt.Errorf("sealed signing key requires the encrypted mount %s: %w", mountDir, err)
+	}
+	if !info.IsDir() {
+		return fmt.Errorf("sealed signing key requires the encrypted mount %s: not a directory", mountDir)
+	}
+
+	return nil
+}
+
+// sealedSigningKey is the sealed file content. The address is stored alongside the private key
+// because it cannot be derived from an existing key through the Aleo session.
+//
+// After a key rotation the previous key is kept until the end of the overlap window. A planned key is kept
+// with its effective time and the end of its overlap window, and takes over from the key at its effective time.
+type sealedSigningKey struct {
+	PrivateKey          []byte            `json:"privateKey"`
+	Address             string            `json:"address"`
+	Previous            *sealedSigningKey `json:"previous,omitempty"`
+	PreviousValidUntil  int64             `json:"previousValidUntil,omitempty"`
+	Planned             *sealedSigningKey `json:"planned,omitempty"`
+	PlannedEffectiveAt  int64             `json:"plannedEffectiveAt,omitempty"`
+	PlannedOverlapUntil int64             `json:"plannedOverlapUntil,omitempty"`
+}
+
+// GetSealedKeyPath returns the sealed key file path for the given sealing policy.
+func GetSealedKeyPath(policy string) (string, error) {
+	path, exists := sealedKeyPaths[policy]
+	if !exists {
+		return "", fmt.Errorf("unknown sealing policy %q", policy)
+	}
+	return path, nil
+}
+
+// loadOrCreateSealedKey loads the signing key from the sealed file at path.
+//
+// If the file does not exist a fresh key is generated and sealed. Any other error, including a
+// file that can no longer be unsealed, is returned so that an existing key is never overwritten.
+func loadOrCreateSealedKey(s aleoUtils.Session, path string) (*sealedSigningKey, error) {
+	data, err := os.ReadFile(path)
+	if err == nil {
+		var sealedKey sealedSigningKey
+		if err := json.Unmarshal(data, &sealedKey); err != nil {
+			return nil, fmt.Errorf("parsing sealed signing key %s: %w", path, err)
+		}
+		if len(sealedKey.PrivateKey) == 0 || sealedKey.Address == "" {
+			return nil, fmt.Errorf("sealed signing key %s is incomplete", path)
+		}
+		logger.Info("Loaded sealed Aleo signing key", "path", path, "address", sealedKey.Address)
+		return &sealedKey, nil
+	}
+
+	if !errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("reading sealed signing key %s: %w", path, err)
+	}
+
+	privKey, address, err := s.NewPrivateKey()
+	if err != nil {
+		return nil, err
+	}
+
+	sealedKey := &sealedSigningKey{PrivateKey: privKey, Address: address}
+	if err := writeSealedKey(path, sealedKey); err != nil {
+		return nil, err
+	}
+
+	logger.Info("Generated and sealed new Aleo signing key", "path", path, "address", address)
+	return sealedKey, nil
+}
+
+// writeSealedKey writes the signing key to the sealed file at path.
+func writeSealedKey(path string, sealedKey *sealedSigningKey) error {
+	data, err := json.Marshal(sealedKey)
+	if err != nil {
+		return fmt.Errorf("encoding sealed signing key: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return fmt.Errorf("creating sealed key directory: %w", err)
+	}
+
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		return fmt.Errorf("writing sealed signing key %s: %w", path, err)
+	}
+
+	return nil
+}
+
+// KeyFingerprint returns the hex encoded SHA-256 hash of the Aleo address.
+func KeyFingerprint(address string) string {
+	hash := sha256.Sum256([]byte(address))
+	return hex.EncodeToString(hash[:])
+}
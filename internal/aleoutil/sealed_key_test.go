@@ -0,0 +1,95 @@
+package aleo
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	aleoUtils "github.com/venture23-aleo/aleo-utils-go"
+)
+
+func newTestSession(t *testing.T) aleoUtils.Session {
+	wrapper, closeFn, err := aleoUtils.NewWrapper()
+	require.NoError(t, err)
+	t.Cleanup(closeFn)
+
+	session, err := wrapper.NewSession()
+	require.NoError(t, err)
+	return session
+}
+
+func TestGetSealedKeyPath(t *testing.T) {
+	path, err := GetSealedKeyPath(SealingPolicyMREnclave)
+	require.NoError(t, err)
+	assert.Equal(t, "/sealed/mrenclave/aleo_signing_key.json", path)
+
+	path, err = GetSealedKeyPath(SealingPolicyMRSigner)
+	require.NoError(t, err)
+	assert.Equal(t, "/sealed/mrsigner/aleo_signing_key.json", path)
+
+	_, err = GetSealedKeyPath("invalid")
+	assert.Error(t, err)
+}
+
+func TestCheckSealingEnvironment(t *testing.T) {
+	originalAttestationDevicePath := attestationDevicePath
+	t.Cleanup(func() { attestationDevicePath = originalAttestationDevicePath })
+
+	mountDir := t.TempDir()
+	path := filepath.Join(mountDir, "aleo_signing_key.json")
+
+	// Outside an enclave there is no attestation device.
+	attestationDevicePath = filepath.Join(t.TempDir(), "missing")
+	assert.Error(t, checkSealingEnvironment(path))
+
+	// Inside an enclave the encrypted mount must be present.
+	attestationDevicePath = t.TempDir()
+	assert.NoError(t, checkSealingEnvironment(path))
+	assert.Error(t, checkSealingEnvironment(filepath.Join(mountDir, "missing", "aleo_signing_key.json")))
+}
+
+func TestLoadOrCreateSealedKey(t *testing.T) {
+	session := newTestSession(t)
+	path := filepath.Join(t.TempDir(), "sealed", "aleo_signing_key.json")
+
+	// The first load generates and seals a fresh key.
+	sealedKey, err := loadOrCreateSealedKey(session, path)
+	require.NoError(t, err)
+	assert.NotEmpty(t, sealedKey.PrivateKey)
+	assert.NotEmpty(t, sealedKey.Address)
+	assert.Nil(t, sealedKey.Previous)
+	assert.FileExists(t, path)
+
+	// Subsequent loads return the same key.
+	reloaded, err := loadOrCreateSealedKey(session, path)
+	require.NoError(t, err)
+	assert.Equal(t, sealedKey.PrivateKey, reloaded.PrivateKey)
+	assert.Equal(t, sealedKey.Address, reloaded.Address)
+
+	// The reloaded key still signs.
+	signature, err := session.Sign(reloaded.PrivateKey, []byte("sealed key message"))
+	require.NoError(t, err)
+	assert.NotEmpty(t, signature)
+}
+
+func TestLoadOrCreateSealedKey_Corrupted(t *testing.T) {
+	session := newTestSession(t)
+	path := filepath.Join(t.TempDir(), "aleo_signing_key.json")
+	require.NoError(t, os.WriteFile(path, []byte("not json"), 0600))
+
+	_, err := loadOrCreateSealedKey(session, path)
+	assert.Error(t, err)
+
+	// A corrupted key must never be overwritten.
+	data, readErr := os.ReadFile(path)
+	require.NoError(t, readErr)
+	assert.Equal(t, "not json", string(data))
+}
+
+func TestKeyFingerprint(t *testing.T) {
+	fingerprint := KeyFingerprint("aleo1fcmp6c3pgjqke2pe8hzspr3l5j0ejssj7c48jc56pk089s4jhqgqxlf7y5")
+	assert.Len(t, fingerprint, 64)
+	assert.Equal(t, fingerprint, KeyFingerprint("aleo1fcmp6c3pgjqke2pe8hzspr3l5j0ejssj7c48jc56pk089s4jhqgqxlf7y5"))
+}
@@ -14,13 +14,13 @@ import (
 
 	"github.com/venture23-aleo/aleo-oracle-notarization-backend/internal/common"
 	"github.com/venture23-aleo/aleo-oracle-notarization-backend/internal/constants"
-	appErrors "github.com/venture23-aleo/aleo-oracle-notarization-backend/internal/errors"
 	httpUtil "github.com/venture23-aleo/aleo-oracle-notarization-backend/internal/httputil"
-	"github.com/venture23-aleo/aleo-oracle-notarization-backend/internal/logger"
 	"github.com/venture23-aleo/aleo-oracle-notarization-backend/internal/metrics"
 	"github.com/venture23-aleo/aleo-oracle-notarization-backend/internal/services/attestation"
 	data_extraction "github.com/venture23-aleo/aleo-oracle-notarization-backend/internal/services/dataextraction"
-	"github.com/venture23-aleo/aleo-oracle-notarization-backend/internal/sgx"
+	appErrors "github.com/venture23-aleo/aleo-oracle-notarization-backend/pkg/errors"
+	"github.com/venture23-aleo/aleo-oracle-notarization-backend/pkg/logger"
+	"github.com/venture23-aleo/aleo-oracle-notarization-backend/pkg/sgx"
 )
 
 func decodeOneOrMany[T any](raw []byte) ([]T, *appErrors.AppError) {
@@ -162,15 +162,64 @@ func processSingleTokenAttestation(ctx context.Context, w http.ResponseWriter, a
 		return status
 	}
 
-	// Get timestamp from roughtime server
-	timestamp, err := common.GetTimestampFromRoughtime()
+	if attestationRequestWithDebug.DebugRequest {
+		timestamp, _, extractDataResult, err := extractAttestationData(ctx, attestationRequest)
+		if err != nil {
+			httpUtil.WriteJsonError(w, http.StatusInternalServerError, err)
+			return status
+		}
+
+		attestationRequest.MaskUnacceptedHeaders()
+
+		reqLogger.Debug("Returning debug response")
+
+		// Create the attestation response.
+		response := &attestation.DebugAttestationResponse{
+			ReportType:           constants.SGXReportType,
+			AttestationRequest:   attestationRequest,
+			AttestationTimestamp: timestamp,
+			ResponseBody:         extractDataResult.ResponseBody,
+			ExtractedData:        extractDataResult.AttestationData,
+			ResponseStatusCode:   extractDataResult.StatusCode,
+		}
+
+		reqLogger.Debug("Debug attestation report generated")
+		httpUtil.WriteJsonSuccess(w, http.StatusOK, response)
+		return "success"
+	}
+
+	response, err := GenerateSingleAttestation(ctx, attestationRequest)
 	if err != nil {
-		reqLogger.Error("Failed to get timestamp from roughtime server", "error", err)
-		metrics.RecordError("timestamp_fetch_failed", "attestation_handler")
 		httpUtil.WriteJsonError(w, http.StatusInternalServerError, err)
 		return status
 	}
 
+	// Write the JSON success response.
+	httpUtil.WriteJsonSuccess(w, http.StatusOK, response)
+	return "success"
+}
+
+// extractAttestationData gets the attestation timestamp from the roughtime servers and fetches the data of a
+// normalized attestation request.
+func extractAttestationData(ctx context.Context, attestationRequest attestation.AttestationRequest) (int64, *common.RoughtimeProof, data_extraction.ExtractDataResult, *appErrors.AppError) {
+	reqLogger := logger.FromContext(ctx)
+
+	// Derive the roughtime nonces from the request.
+	requestDigest, err := attestation.GetRoughtimeRequestDigest(attestationRequest)
+	if err != nil {
+		reqLogger.Error("Failed to compute roughtime request digest", "error", err)
+		metrics.RecordError("request_digest_failed", "attestation_handler")
+		return 0, nil, data_extraction.ExtractDataResult{}, err
+	}
+
+	// Get timestamp from roughtime servers
+	timestamp, roughtimeProof, err := common.GetTimestampFromRoughtime(requestDigest)
+	if err != nil {
+		reqLogger.Error("Failed to get timestamp from roughtime server", "error", err)
+		metrics.RecordError("timestamp_fetch_failed", "attestation_handler")
+		return 0, nil, data_extraction.ExtractDataResult{}, err
+	}
+
 	reqLogger.Debug("Fetching data from target URL", "url", attestationRequest.Url, "timestamp", timestamp)
 
 	// Fetch the data from the attestation request.
@@ -183,50 +232,43 @@ func processSingleTokenAttestation(ctx context.Context, w http.ResponseWriter, a
 		reqLogger.Error("Failed to extract data from target URL", "error", err)
 		metrics.RecordError("data_extraction_failed", "attestation_handler")
 		metrics.RecordDataExtraction(attestationRequest.ResponseFormat, "failed", extractDuration)
-		httpUtil.WriteJsonError(w, http.StatusInternalServerError, err)
-		return status
+		return 0, nil, data_extraction.ExtractDataResult{}, err
 	}
 
 	metrics.RecordDataExtraction(attestationRequest.ResponseFormat, "success", extractDuration)
 
-	attestationRequest.MaskUnacceptedHeaders()
-
-	if attestationRequestWithDebug.DebugRequest {
-		reqLogger.Debug("Returning debug response")
+	return timestamp, roughtimeProof, extractDataResult, nil
+}
 
-		// Create the attestation response.
-		response := &attestation.DebugAttestationResponse{
-			ReportType:           constants.SGXReportType,
-			AttestationRequest:   attestationRequest,
-			AttestationTimestamp: timestamp,
-			ResponseBody:         extractDataResult.ResponseBody,
-			ExtractedData:        extractDataResult.AttestationData,
-			ResponseStatusCode:   extractDataResult.StatusCode,
-		}
+// GenerateSingleAttestation generates the attestation report of a normalized and validated attestation request,
+// with its SGX quote and oracle signature. It is shared by the notarization route and the attestation scheduler.
+func GenerateSingleAttestation(ctx context.Context, attestationRequest attestation.AttestationRequest) (*attestation.AttestationResponse, *appErrors.AppError) {
+	reqLogger := logger.FromContext(ctx)
 
-		reqLogger.Debug("Debug attestation report generated")
-		httpUtil.WriteJsonSuccess(w, http.StatusOK, response)
-		return "success"
+	timestamp, roughtimeProof, extractDataResult, err := extractAttestationData(ctx, attestationRequest)
+	if err != nil {
+		return nil, err
 	}
 
-	// aleoBlockHeight, err := common.GetAleoCurrentBlockHeight()
-	// if err != nil {
-	// 	reqLogger.Error("Failed to get Aleo block height", "error", err)
-	// 	metrics.RecordError("aleo_block_height_fetch_failed", "attestation_handler")
-	// 	httpUtil.WriteJsonError(w, http.StatusInternalServerError, err)
-	// 	return status
-	// }
+	attestationRequest.MaskUnacceptedHeaders()
+
+	// Get the latest Aleo block height, 0 when block heights are disabled.
+	aleoBlockHeight, err := common.GetAleoCurrentBlockHeight()
+	if err != nil {
+		reqLogger.Error("Failed to get Aleo block height", "error", err)
+		metrics.RecordError("aleo_block_height_fetch_failed", "attestation_handler")
+		return nil, err
+	}
 
 	// Prepare the oracle data before the quote.
 	reqLogger.Debug("Preparing data for quote generation")
-	quotePrepData, err := attestation.PrepareDataForQuoteGeneration(extractDataResult.StatusCode, extractDataResult.AttestationData, uint64(timestamp), attestationRequest)
+	quotePrepData, err := attestation.PrepareDataForQuoteGeneration(extractDataResult.StatusCode, extractDataResult.AttestationData, uint64(timestamp), uint64(aleoBlockHeight), attestationRequest)
 
 	// Check if the error is not nil.
 	if err != nil {
 		reqLogger.Error("Failed to prepare data for quote generation", "error", err)
 		metrics.RecordError("quote_prep_failed", "attestation_handler")
-		httpUtil.WriteJsonError(w, http.StatusInternalServerError, err)
-		return status
+		return nil, err
 	}
 
 	reqLogger.Debug("Quote preparation successful")
@@ -241,8 +283,7 @@ func processSingleTokenAttestation(ctx context.Context, w http.ResponseWriter, a
 		reqLogger.Error("Failed to generate SGX quote", "error", err)
 		metrics.RecordSgxQuoteGeneration("failed", quoteDuration)
 		metrics.RecordError("quote_generation_failed", "attestation_handler")
-		httpUtil.WriteJsonError(w, http.StatusInternalServerError, err)
-		return status
+		return nil, err
 	}
 
 	// metrics.RecordSgxQuoteGeneration("success", quoteDuration)
@@ -255,8 +296,7 @@ func processSingleTokenAttestation(ctx context.Context, w http.ResponseWriter, a
 	if err != nil {
 		reqLogger.Error("Failed to build complete oracle data", "error", err)
 		metrics.RecordError("oracle_data_build_failed", "attestation_handler")
-		httpUtil.WriteJsonError(w, http.StatusInternalServerError, err)
-		return status
+		return nil, err
 	}
 
 	reqLogger.Debug("Oracle data built successfully")
@@ -266,6 +306,7 @@ func processSingleTokenAttestation(ctx context.Context, w http.ResponseWriter, a
 		ReportType:           "sgx",
 		AttestationRequest:   attestationRequest,
 		AttestationTimestamp: timestamp,
+		AleoBlockHeight:      aleoBlockHeight,
 		ResponseBody:         extractDataResult.ResponseBody,
 		AttestationData:      extractDataResult.AttestationData,
 		ResponseStatusCode:   extractDataResult.StatusCode,
@@ -278,17 +319,20 @@ func processSingleTokenAttestation(ctx context.Context, w http.ResponseWriter, a
 				ResponseBody: extractDataResult.ResponseBody,
 				ResponseStatusCode: extractDataResult.StatusCode,
 				AttestationTimestamp: timestamp,
+				AleoBlockHeight: aleoBlockHeight,
 				RequestHash: oracleData.RequestHash,
 			},
 		},
+		RoughtimeProof:       roughtimeProof,
 	}
 
+	// Record the attested price feed value for the price guard.
+	extractDataResult.RecordAttestation()
+
 	// Log successful completion
 	reqLogger.Debug("Attestation report generated successfully")
 
-	// Write the JSON success response.
-	httpUtil.WriteJsonSuccess(w, http.StatusOK, response)
-	return "success"
+	return response, nil
 }
 
 // processMultipleTokensAttestation handles multiple tokens attestation request
@@ -297,30 +341,13 @@ func processMultipleTokensAttestation(ctx context.Context, w http.ResponseWriter
 
 	reqLogger.Debug("Processing multiple tokens attestation", "count", len(attestationRequests))
 
-	// Get timestamp from roughtime server
-	timestamp, err := common.GetTimestampFromRoughtime()
-	if err != nil {
-		reqLogger.Error("Failed to get timestamp from roughtime server", "error", err)
-		metrics.RecordError("timestamp_fetch_failed", "attestation_handler")
-		httpUtil.WriteJsonError(w, http.StatusInternalServerError, err)
-		return status
-	}
-
-	// aleoBlockHeight, err := common.GetAleoCurrentBlockHeight()
-	// if err != nil {
-	// 	reqLogger.Error("Failed to get Aleo block height", "error", err)
-	// 	metrics.RecordError("aleo_block_height_fetch_failed", "attestation_handler")
-	// 	httpUtil.WriteJsonError(w, http.StatusInternalServerError, err)
-	// 	return status
-	// }
-
 	normalizedAttestationRequests := make([]attestation.AttestationRequest, len(attestationRequests))
 
 	// Normalize and validate all attestation requests
 	for i, attestationRequest := range attestationRequests {
 		normalizedAttestationRequest := attestationRequest.AttestationRequest.Normalize()
 
-		if err := attestationRequest.Validate(); err != nil {
+		if err := normalizedAttestationRequest.Validate(); err != nil {
 			reqLogger.Error("Attestation request validation failed", "index", i, "error", err)
 			metrics.RecordError("validation_failed", "attestation_handler")
 			httpUtil.WriteJsonError(w, http.StatusBadRequest, err)
@@ -329,10 +356,38 @@ func processMultipleTokensAttestation(ctx context.Context, w http.ResponseWriter
 		normalizedAttestationRequests[i] = normalizedAttestationRequest
 	}
 
+	// Derive the roughtime nonces from the requests.
+	requestDigest, err := attestation.GetRoughtimeRequestDigest(normalizedAttestationRequests...)
+	if err != nil {
+		reqLogger.Error("Failed to compute roughtime request digest", "error", err)
+		metrics.RecordError("request_digest_failed", "attestation_handler")
+		httpUtil.WriteJsonError(w, http.StatusInternalServerError, err)
+		return status
+	}
+
+	// Get timestamp from roughtime servers
+	timestamp, roughtimeProof, err := common.GetTimestampFromRoughtime(requestDigest)
+	if err != nil {
+		reqLogger.Error("Failed to get timestamp from roughtime server", "error", err)
+		metrics.RecordError("timestamp_fetch_failed", "attestation_handler")
+		httpUtil.WriteJsonError(w, http.StatusInternalServerError, err)
+		return status
+	}
+
+	// Get the latest Aleo block height, 0 when block heights are disabled.
+	aleoBlockHeight, err := common.GetAleoCurrentBlockHeight()
+	if err != nil {
+		reqLogger.Error("Failed to get Aleo block height", "error", err)
+		metrics.RecordError("aleo_block_height_fetch_failed", "attestation_handler")
+		httpUtil.WriteJsonError(w, http.StatusInternalServerError, err)
+		return status
+	}
+
 	// Process all attestation requests in parallel
 	type processResult struct {
 		index           int
 		attestationResult attestation.AttestationResultForEachToken
+		extractDataResult data_extraction.ExtractDataResult
 		err             *appErrors.AppError
 		extractDuration float64
 	}
@@ -372,7 +427,7 @@ func processMultipleTokensAttestation(ctx context.Context, w http.ResponseWriter
 			// Prepare the oracle data before the quote.
 			reqLogger.Debug("Preparing data for quote generation", "index", idx)
 
-			userDataChunk, encodedPositions, err := attestation.PrepareOracleUserDataChunk(extractDataResult.StatusCode, extractDataResult.AttestationData, uint64(timestamp), req)
+			userDataChunk, encodedPositions, err := attestation.PrepareOracleUserDataChunk(extractDataResult.StatusCode, extractDataResult.AttestationData, uint64(timestamp), uint64(aleoBlockHeight), req)
 
 			if err != nil {
 				reqLogger.Error("Failed to prepare data for quote generation", "index", idx, "error", err)
@@ -403,6 +458,7 @@ func processMultipleTokensAttestation(ctx context.Context, w http.ResponseWriter
 				index:          idx,
 				err:            nil,
 				extractDuration: extractDuration,
+				extractDataResult: extractDataResult,
 				attestationResult: attestation.AttestationResultForEachToken{
 					Index: idx,
 					UserDataChunk: userDataChunk,
@@ -411,6 +467,7 @@ func processMultipleTokensAttestation(ctx context.Context, w http.ResponseWriter
 					ResponseBody: extractDataResult.ResponseBody,
 					ResponseStatusCode: extractDataResult.StatusCode,
 					AttestationTimestamp: timestamp,
+					AleoBlockHeight: aleoBlockHeight,
 					RequestHash: requestHash,
 				},
 				}
@@ -508,7 +565,7 @@ func processMultipleTokensAttestation(ctx context.Context, w http.ResponseWriter
 
 	// Prepare the oracle data after the quote.
 	reqLogger.Debug("Building complete oracle data")
-	signature, publicKey, err := attestation.PrepareOracleSignature(oracleReport)
+	signatures, err := attestation.PrepareOracleSignature(oracleReport)
 
 	if err != nil {
 		reqLogger.Error("Failed to build complete oracle data", "error", err)
@@ -523,14 +580,23 @@ func processMultipleTokensAttestation(ctx context.Context, w http.ResponseWriter
 	response := &attestation.AttestationResponseForMultipleTokens{
 		ReportType:           "sgx",
 		AttestationTimestamp: timestamp,
+		AleoBlockHeight:      aleoBlockHeight,
 		AttestationReport:    base64.StdEncoding.EncodeToString(quote),
 		OracleData: attestation.OracleData{
-			Signature: signature,
-			Report:    string(oracleReport),
-			Address:   publicKey,
-			UserData:  string(mergedUserData),
+			Signature:         signatures.Signature,
+			Report:            string(oracleReport),
+			Address:           signatures.Address,
+			UserData:          string(mergedUserData),
+			PreviousSignature: signatures.PreviousSignature,
+			PreviousAddress:   signatures.PreviousAddress,
 		},
 		AttestationResults: attestationResults,
+		RoughtimeProof:     roughtimeProof,
+	}
+
+	// Record the attested price feed values for the price guard.
+	for _, result := range results {
+		result.extractDataResult.RecordAttestation()
 	}
 
 	// Log successful completion
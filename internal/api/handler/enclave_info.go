@@ -5,8 +5,9 @@ import (
 
 	aleoUtil "github.com/venture23-aleo/aleo-oracle-notarization-backend/internal/aleoutil"
 	httpUtil "github.com/venture23-aleo/aleo-oracle-notarization-backend/internal/httputil"
-	"github.com/venture23-aleo/aleo-oracle-notarization-backend/internal/logger"
 	enclaveInfo "github.com/venture23-aleo/aleo-oracle-notarization-backend/internal/services/enclaveinfo"
+	"github.com/venture23-aleo/aleo-oracle-notarization-backend/pkg/logger"
+	"github.com/venture23-aleo/aleo-oracle-notarization-backend/pkg/sgx"
 )
 
 // GetEnclaveInfo handles the request to get the enclave info.
@@ -30,11 +31,38 @@ func GetEnclaveInfo(w http.ResponseWriter, req *http.Request) {
 		return
 	}
 
+	// Get the quote binding the signer public key to the enclave
+	signerPubKey := aleoContext.GetPublicKey()
+	signerBinding, bindingErr := enclaveInfo.GetSignerKeyBinding(signerPubKey)
+	if bindingErr != nil {
+		reqLogger.Error("Failed to get signer key binding", "error", bindingErr)
+		httpUtil.WriteJsonError(w, http.StatusInternalServerError, bindingErr)
+		return
+	}
+
+	// Get the signing key info, including the previous signer public key during a key rotation overlap window and
+	// the planned signer public key before a planned key rotation takes effect
+	signingKeyInfo := enclaveInfo.SigningKeyInfo{
+		SealingPolicy: aleoContext.GetSealingPolicy(),
+		Fingerprint:   aleoUtil.KeyFingerprint(signerPubKey),
+	}
+	if previousPubKey, validUntil := aleoContext.GetPreviousPublicKey(); previousPubKey != "" {
+		signingKeyInfo.PreviousPubKey = previousPubKey
+		signingKeyInfo.PreviousValidUntil = validUntil.Unix()
+	}
+	if plannedPubKey, effectiveAt := aleoContext.GetPlannedPublicKey(); plannedPubKey != "" {
+		signingKeyInfo.PlannedPubKey = plannedPubKey
+		signingKeyInfo.PlannedEffectiveAt = effectiveAt.Unix()
+	}
+
 	// Create the instance info response
 	enclaveInfoResponse := enclaveInfo.EnclaveInfoResponse{
-		ReportType:   "sgx",
-		Info:         sgxEnclaveInfo,
-		SignerPubKey: aleoContext.GetPublicKey(),
+		ReportType:    "sgx",
+		QuoteProvider: sgx.GetQuoteProviderName(),
+		Info:          sgxEnclaveInfo,
+		SignerPubKey:  signerPubKey,
+		SigningKey:    signingKeyInfo,
+		SignerBinding: signerBinding,
 	}
 
 	httpUtil.WriteJsonSuccess(w, http.StatusOK, enclaveInfoResponse)
@@ -5,6 +5,7 @@ import (
 	"time"
 
 	httpUtil "github.com/venture23-aleo/aleo-oracle-notarization-backend/internal/httputil"
+	data_extraction "github.com/venture23-aleo/aleo-oracle-notarization-backend/internal/services/dataextraction"
 )
 
 // HealthResponse is the response for the health check.
@@ -19,3 +20,10 @@ func GetHealthCheck(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Cache-Control", "no-store")
 	httpUtil.WriteJsonSuccess(w, http.StatusOK, HealthResponse{Status: "healthy", Timestamp: time.Now().Format(time.RFC3339)})
 }
+
+// GetExchangeHealth handles the request to get the health and circuit state of the exchange symbols.
+func GetExchangeHealth(w http.ResponseWriter, r *http.Request) {
+	// Write the JSON success response.
+	w.Header().Set("Cache-Control", "no-store")
+	httpUtil.WriteJsonSuccess(w, http.StatusOK, data_extraction.GetExchangeHealthReport())
+}
@@ -0,0 +1,75 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/venture23-aleo/aleo-oracle-notarization-backend/internal/constants"
+	httpUtil "github.com/venture23-aleo/aleo-oracle-notarization-backend/internal/httputil"
+	keyRotation "github.com/venture23-aleo/aleo-oracle-notarization-backend/internal/services/keyrotation"
+	appErrors "github.com/venture23-aleo/aleo-oracle-notarization-backend/pkg/errors"
+	"github.com/venture23-aleo/aleo-oracle-notarization-backend/pkg/logger"
+)
+
+// RotateSignerKey handles the admin request to rotate the Aleo signing key.
+//
+// The body is optional. Its effectiveAt field plans the rotation at a future Unix timestamp, and the rotation
+// takes effect immediately without it.
+func RotateSignerKey(w http.ResponseWriter, req *http.Request) {
+	reqLogger := logger.FromContext(req.Context())
+
+	// Limit the request body size.
+	req.Body = http.MaxBytesReader(w, req.Body, constants.MaxRequestBodySize)
+
+	bodyBytes, readErr := io.ReadAll(req.Body)
+	if readErr != nil {
+		reqLogger.Error("Failed to read request body", "error", readErr)
+		httpUtil.WriteJsonError(w, http.StatusBadRequest, appErrors.ErrReadingRequestBody)
+		return
+	}
+
+	var rotateRequest keyRotation.RotateSignerKeyRequest
+	if len(bytes.TrimSpace(bodyBytes)) > 0 {
+		if decodeErr := json.Unmarshal(bodyBytes, &rotateRequest); decodeErr != nil {
+			reqLogger.Error("Failed to decode rotate key request", "error", decodeErr)
+			httpUtil.WriteJsonError(w, http.StatusBadRequest, appErrors.ErrDecodingRequestBody)
+			return
+		}
+	}
+
+	var effectiveAt time.Time
+	if rotateRequest.EffectiveAt != 0 {
+		effectiveAt = time.Unix(rotateRequest.EffectiveAt, 0)
+	}
+
+	handover, err := keyRotation.RotateSignerKey(effectiveAt)
+	if err != nil {
+		reqLogger.Error("Failed to rotate signer key", "error", err)
+		switch err.Code {
+		case appErrors.ErrInvalidRotationTime.Code:
+			httpUtil.WriteJsonError(w, http.StatusBadRequest, err)
+		case appErrors.ErrKeyRotationPlanned.Code:
+			httpUtil.WriteJsonError(w, http.StatusConflict, err)
+		default:
+			httpUtil.WriteJsonError(w, http.StatusInternalServerError, err)
+		}
+		return
+	}
+
+	reqLogger.Info("Signer key rotated", "oldAddress", handover.OldAddress, "newAddress", handover.NewAddress, "effectiveAt", handover.EffectiveAt)
+	httpUtil.WriteJsonSuccess(w, http.StatusOK, handover)
+}
+
+// GetKeyHandover handles the request to get the latest key handover record.
+func GetKeyHandover(w http.ResponseWriter, req *http.Request) {
+	handover, err := keyRotation.GetLatestKeyHandover()
+	if err != nil {
+		httpUtil.WriteJsonError(w, http.StatusNotFound, err)
+		return
+	}
+
+	httpUtil.WriteJsonSuccess(w, http.StatusOK, handover)
+}
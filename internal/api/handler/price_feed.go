@@ -0,0 +1,55 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	encoding "github.com/venture23-aleo/aleo-oracle-encoding"
+	configs "github.com/venture23-aleo/aleo-oracle-notarization-backend/internal/config"
+	httpUtil "github.com/venture23-aleo/aleo-oracle-notarization-backend/internal/httputil"
+	"github.com/venture23-aleo/aleo-oracle-notarization-backend/internal/metrics"
+	data_extraction "github.com/venture23-aleo/aleo-oracle-notarization-backend/internal/services/dataextraction"
+	appErrors "github.com/venture23-aleo/aleo-oracle-notarization-backend/pkg/errors"
+	"github.com/venture23-aleo/aleo-oracle-notarization-backend/pkg/logger"
+)
+
+// GetPricePreview handles the request to preview the price feed of a token with its aggregation breakdown.
+// Nothing is attested nor signed.
+func GetPricePreview(w http.ResponseWriter, req *http.Request) {
+	// Get logger from context (request ID automatically included by middleware)
+	reqLogger := logger.FromContext(req.Context())
+
+	token := strings.ToUpper(req.PathValue("token"))
+	tokenConfig, exists := configs.GetTokenConfig(token)
+	if !exists {
+		reqLogger.Error("Price preview for unsupported token", "token", token)
+		metrics.RecordError("token_not_supported", "price_preview_handler")
+		httpUtil.WriteJsonError(w, http.StatusNotFound, appErrors.ErrTokenNotSupported)
+		return
+	}
+
+	// Use the precision of the query string, the default precision of the token otherwise
+	precision := tokenConfig.DefaultPrecision
+	if precisionStr := req.URL.Query().Get("precision"); precisionStr != "" {
+		parsedPrecision, err := strconv.ParseUint(precisionStr, 10, 32)
+		if err != nil || parsedPrecision == 0 || parsedPrecision > encoding.ENCODING_OPTION_FLOAT_MAX_PRECISION {
+			reqLogger.Error("Invalid price preview precision", "precision", precisionStr)
+			metrics.RecordError("invalid_precision", "price_preview_handler")
+			httpUtil.WriteJsonError(w, http.StatusBadRequest, appErrors.ErrInvalidEncodingPrecision)
+			return
+		}
+		precision = uint(parsedPrecision)
+	}
+
+	result, err := data_extraction.NewPriceFeedClient().PreviewPriceFeed(req.Context(), token, precision)
+	if err != nil {
+		metrics.RecordError("price_preview_failed", "price_preview_handler")
+		httpUtil.WriteJsonError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	// Write the JSON success response.
+	w.Header().Set("Cache-Control", "no-store")
+	httpUtil.WriteJsonSuccess(w, http.StatusOK, result)
+}
@@ -10,12 +10,12 @@ import (
 
 	encoding "github.com/venture23-aleo/aleo-oracle-encoding"
 	"github.com/venture23-aleo/aleo-oracle-notarization-backend/internal/common"
-	appErrors "github.com/venture23-aleo/aleo-oracle-notarization-backend/internal/errors"
 	httpUtil "github.com/venture23-aleo/aleo-oracle-notarization-backend/internal/httputil"
-	"github.com/venture23-aleo/aleo-oracle-notarization-backend/internal/logger"
 	"github.com/venture23-aleo/aleo-oracle-notarization-backend/internal/metrics"
 	attestation "github.com/venture23-aleo/aleo-oracle-notarization-backend/internal/services/attestation"
-	"github.com/venture23-aleo/aleo-oracle-notarization-backend/internal/sgx"
+	appErrors "github.com/venture23-aleo/aleo-oracle-notarization-backend/pkg/errors"
+	"github.com/venture23-aleo/aleo-oracle-notarization-backend/pkg/logger"
+	"github.com/venture23-aleo/aleo-oracle-notarization-backend/pkg/sgx"
 )
 
 var (
@@ -96,8 +96,17 @@ func GenerateAttestedRandom(w http.ResponseWriter, req *http.Request) {
 
 	reqLogger.Debug("Generated random number", "number", randomNumber.String())
 
+	// Derive the roughtime nonces from the request.
+	requestDigest, appError := attestation.GetRoughtimeRequestDigest(attestationRequest)
+	if appError != nil {
+		reqLogger.Error("Failed to compute roughtime request digest", "error", appError)
+		metrics.RecordError("request_digest_failed", "random_handler")
+		httpUtil.WriteJsonError(w, http.StatusInternalServerError, appError)
+		return
+	}
+
 	// Get the timestamp
-	timestamp, appError := common.GetTimestampFromRoughtime()
+	timestamp, roughtimeProof, appError := common.GetTimestampFromRoughtime(requestDigest)
 	if appError != nil {
 		reqLogger.Error("Failed to get timestamp from roughtime server", "error", appError)
 		metrics.RecordError("timestamp_fetch_failed", "random_handler")
@@ -108,16 +117,17 @@ func GenerateAttestedRandom(w http.ResponseWriter, req *http.Request) {
 	statusCode := 200
 	attestationData := randomNumber.String()
 
-	// aleoBlockHeight, blockHeightError := common.GetAleoCurrentBlockHeight()
-	// if blockHeightError != nil {
-	// 	reqLogger.Error("Failed to get Aleo block height", "error", err)
-	// 	metrics.RecordError("aleo_block_height_fetch_failed", "random_handler")
-	// 	httpUtil.WriteJsonError(w, http.StatusInternalServerError, blockHeightError)
-	// 	return
-	// }
+	// Get the latest Aleo block height, 0 when block heights are disabled.
+	aleoBlockHeight, appError := common.GetAleoCurrentBlockHeight()
+	if appError != nil {
+		reqLogger.Error("Failed to get Aleo block height", "error", appError)
+		metrics.RecordError("aleo_block_height_fetch_failed", "random_handler")
+		httpUtil.WriteJsonError(w, http.StatusInternalServerError, appError)
+		return
+	}
 
 	reqLogger.Debug("Preparing data for quote generation")
-	quotePrepData, appError := attestation.PrepareDataForQuoteGeneration(statusCode, attestationData, uint64(timestamp), attestationRequest)
+	quotePrepData, appError := attestation.PrepareDataForQuoteGeneration(statusCode, attestationData, uint64(timestamp), uint64(aleoBlockHeight), attestationRequest)
 
 	// Check if the error is not nil.
 	if appError != nil {
@@ -159,11 +169,13 @@ func GenerateAttestedRandom(w http.ResponseWriter, req *http.Request) {
 		ReportType:           "sgx",
 		AttestationRequest:   attestationRequest,
 		AttestationTimestamp: timestamp,
+		AleoBlockHeight:      aleoBlockHeight,
 		AttestationReport:    base64.StdEncoding.EncodeToString(quote),
 		OracleData:           *oracleData,
 		ResponseBody:         randomNumber.String(),
 		AttestationData:      randomNumber.String(),
 		ResponseStatusCode:   statusCode,
+		RoughtimeProof:       roughtimeProof,
 	}
 
 	reqLogger.Debug("Successfully generated attested random response")
@@ -0,0 +1,35 @@
+package handler
+
+import (
+	"net/http"
+	"strings"
+
+	httpUtil "github.com/venture23-aleo/aleo-oracle-notarization-backend/internal/httputil"
+	"github.com/venture23-aleo/aleo-oracle-notarization-backend/internal/services/scheduler"
+)
+
+// GetLatestScheduledAttestations handles the request to get the latest scheduled attestation of every token.
+func GetLatestScheduledAttestations(w http.ResponseWriter, req *http.Request) {
+	latest, err := scheduler.GetLatestScheduledAttestations()
+	if err != nil {
+		httpUtil.WriteJsonError(w, http.StatusNotFound, err)
+		return
+	}
+
+	w.Header().Set("Cache-Control", "no-store")
+	httpUtil.WriteJsonSuccess(w, http.StatusOK, latest)
+}
+
+// GetLatestScheduledAttestation handles the request to get the latest scheduled attestation of a token.
+func GetLatestScheduledAttestation(w http.ResponseWriter, req *http.Request) {
+	token := strings.ToUpper(req.PathValue("token"))
+
+	scheduled, err := scheduler.GetLatestScheduledAttestation(token)
+	if err != nil {
+		httpUtil.WriteJsonError(w, http.StatusNotFound, err)
+		return
+	}
+
+	w.Header().Set("Cache-Control", "no-store")
+	httpUtil.WriteJsonSuccess(w, http.StatusOK, scheduled)
+}
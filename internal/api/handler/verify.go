@@ -0,0 +1,174 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	aleoUtil "github.com/venture23-aleo/aleo-oracle-notarization-backend/internal/aleoutil"
+	configs "github.com/venture23-aleo/aleo-oracle-notarization-backend/internal/config"
+	"github.com/venture23-aleo/aleo-oracle-notarization-backend/internal/constants"
+	httpUtil "github.com/venture23-aleo/aleo-oracle-notarization-backend/internal/httputil"
+	appErrors "github.com/venture23-aleo/aleo-oracle-notarization-backend/pkg/errors"
+	"github.com/venture23-aleo/aleo-oracle-notarization-backend/pkg/logger"
+	"github.com/venture23-aleo/aleo-oracle-notarization-backend/pkg/sgx"
+	"github.com/venture23-aleo/aleo-oracle-notarization-backend/pkg/verifier"
+)
+
+// VerifyAttestation handles the request to verify an attestation response.
+//
+// The body is the response of the notarization endpoint, either for a single request or for multiple
+// price feed requests. Verification failures are reported per check with a 200 status.
+func VerifyAttestation(w http.ResponseWriter, req *http.Request) {
+	reqLogger := logger.FromContext(req.Context())
+
+	// Limit the request body size.
+	req.Body = http.MaxBytesReader(w, req.Body, constants.MaxVerifyRequestBodySize)
+
+	bodyBytes, err := io.ReadAll(req.Body)
+	if err != nil {
+		reqLogger.Error("Failed to read request body", "error", err)
+		httpUtil.WriteJsonError(w, http.StatusBadRequest, appErrors.ErrDecodingRequestBody)
+		return
+	}
+
+	var response verifier.AttestationResponse
+	if err := json.Unmarshal(bodyBytes, &response); err != nil {
+		reqLogger.Error("Failed to decode attestation response", "error", err)
+		httpUtil.WriteJsonError(w, http.StatusBadRequest, appErrors.ErrDecodingRequestBody)
+		return
+	}
+
+	// Trust the current signer and the previous signer during a key rotation overlap window.
+	aleoContext, ctxErr := aleoUtil.GetAleoContext()
+	if ctxErr != nil {
+		reqLogger.Error("Failed to get Aleo context", "error", ctxErr)
+		httpUtil.WriteJsonError(w, http.StatusInternalServerError, ctxErr)
+		return
+	}
+
+	opts := verifier.Options{TrustedAddresses: []string{aleoContext.GetPublicKey()}}
+	if previousPublicKey, _ := aleoContext.GetPreviousPublicKey(); previousPublicKey != "" {
+		opts.TrustedAddresses = append(opts.TrustedAddresses, previousPublicKey)
+	}
+
+	// Trust the configured roughtime servers.
+	roughtimeConfig := configs.GetRoughtimeConfig()
+	opts.RoughtimeQuorum = roughtimeConfig.Quorum
+	opts.RoughtimeServers = make(map[string][]byte, len(roughtimeConfig.ServerConfigs))
+	for _, serverConfig := range roughtimeConfig.ServerConfigs {
+		if serverConfig.Server != nil {
+			opts.RoughtimeServers[serverConfig.Server.Name] = serverConfig.Server.PublicKey
+		}
+	}
+
+	// Price feed attestations encode the token ID in the user data.
+	tokenRegistry := configs.GetTokenRegistry()
+	opts.PriceFeedTokenIDs = make(map[string]int, len(tokenRegistry))
+	for symbol, tokenConfig := range tokenRegistry {
+		opts.PriceFeedTokenIDs[symbol] = tokenConfig.TokenID
+	}
+
+	collateral, collateralErr := getVerifyCollateral()
+	if collateralErr != nil {
+		reqLogger.Error("Failed to load collateral", "error", collateralErr)
+		httpUtil.WriteJsonError(w, http.StatusInternalServerError, collateralErr)
+		return
+	}
+	opts.Collateral = collateral
+	// The collateral on disk is the current collateral, so check it at the current time.
+	opts.VerificationTime = time.Now()
+
+	// A multi-token response has no top-level attestation request.
+	var report *verifier.Report
+	var verifyErr *appErrors.AppError
+	if response.AttestationRequest.Url == "" && len(response.AttestationResults) > 0 {
+		report, verifyErr = verifier.VerifyAttestationResponseForMultipleTokens(&verifier.AttestationResponseForMultipleTokens{
+			ReportType:           response.ReportType,
+			AttestationTimestamp: response.AttestationTimestamp,
+			AttestationReport:    response.AttestationReport,
+			OracleData:           response.OracleData,
+			AttestationResults:   response.AttestationResults,
+			RoughtimeProof:       response.RoughtimeProof,
+		}, opts)
+	} else {
+		report, verifyErr = verifier.VerifyAttestationResponse(&response, opts)
+	}
+
+	if verifyErr != nil {
+		reqLogger.Error("Failed to verify attestation response", "error", verifyErr)
+		httpUtil.WriteJsonError(w, http.StatusInternalServerError, verifyErr)
+		return
+	}
+
+	reqLogger.Debug("Attestation response verified", "valid", report.Valid, "status", report.Status)
+	httpUtil.WriteJsonSuccess(w, http.StatusOK, report)
+}
+
+// Collateral cache for /verify, reloaded only when the collateral changes.
+var (
+	verifyCollateralMu  sync.Mutex           // Guards the cached collateral.
+	verifyCollateral    *verifier.Collateral // Cached collateral.
+	verifyCollateralKey string               // Source the cached collateral was loaded from.
+)
+
+// getVerifyCollateral returns the collateral for /verify: the collateral directory from the configuration,
+// or the simulated collateral when the simulated quote provider is active. It returns nil when neither is
+// available, in which case the quote signature and TCB checks are skipped.
+//
+// The parsed collateral is cached until the collateral files change on disk or the TCB info expires.
+func getVerifyCollateral() (*verifier.Collateral, *appErrors.AppError) {
+	var key string
+	var load func() (*verifier.Collateral, *appErrors.AppError)
+	if collateralDir := configs.GetAppConfig().SGXConfig.CollateralDir; collateralDir != "" {
+		key = collateralCacheKey(collateralDir)
+		load = func() (*verifier.Collateral, *appErrors.AppError) { return verifier.LoadCollateral(collateralDir) }
+	} else if sgx.GetQuoteProviderName() == sgx.QuoteProviderSimulated {
+		key = sgx.QuoteProviderSimulated
+		load = sgx.GetSimulatedCollateral
+	} else {
+		return nil, nil
+	}
+
+	verifyCollateralMu.Lock()
+	defer verifyCollateralMu.Unlock()
+
+	if verifyCollateral != nil && verifyCollateralKey == key && time.Now().Before(verifyCollateral.TCBInfo.NextUpdate) {
+		return verifyCollateral, nil
+	}
+
+	collateral, err := load()
+	if err != nil {
+		return nil, err
+	}
+
+	verifyCollateral = collateral
+	verifyCollateralKey = key
+	return collateral, nil
+}
+
+// collateralCacheKey returns the collateral directory with the size and modification time of each
+// collateral file, so that refreshed collateral is reloaded.
+func collateralCacheKey(dir string) string {
+	var key strings.Builder
+	key.WriteString(dir)
+	for _, name := range []string{
+		sgx.CollateralRootCAFile,
+		sgx.CollateralRootCACRLFile,
+		sgx.CollateralPCKCRLFile,
+		sgx.CollateralTCBInfoFile,
+		sgx.CollateralQEIdentityFile,
+		sgx.CollateralTCBSigningChainFile,
+	} {
+		if info, err := os.Stat(filepath.Join(dir, name)); err == nil {
+			fmt.Fprintf(&key, "|%s:%d:%d", name, info.Size(), info.ModTime().UnixNano())
+		}
+	}
+	return key.String()
+}
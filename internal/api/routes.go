@@ -19,9 +19,25 @@ func RegisterRoutes(mux *http.ServeMux) {
 	// Register the health check route.
 	mux.HandleFunc("GET /health", handler.GetHealthCheck)
 
+	// Register the exchange health route.
+	mux.HandleFunc("GET /health/exchanges", handler.GetExchangeHealth)
+
+	// Register the price preview route.
+	mux.HandleFunc("GET /price/{token}", handler.GetPricePreview)
+
 	// Register the notarization route.
 	mux.HandleFunc("POST /notarize", handler.GenerateAttestationReport)
 
+	// Register the scheduled attestation routes.
+	mux.HandleFunc("GET /attestations/latest", handler.GetLatestScheduledAttestations)
+	mux.HandleFunc("GET /attestations/latest/{token}", handler.GetLatestScheduledAttestation)
+
+	// Register the key handover route.
+	mux.HandleFunc("GET /key-handover", handler.GetKeyHandover)
+
+	// Register the attestation verification route.
+	mux.HandleFunc("POST /verify", handler.VerifyAttestation)
+
 	// Register the random number route.
 	mux.HandleFunc("GET /random", handler.GenerateAttestedRandom)
 
@@ -32,3 +48,10 @@ func RegisterRoutes(mux *http.ServeMux) {
 func RegisterMetricsRoute(mux *http.ServeMux) {
 	mux.Handle("GET /metrics", promhttp.Handler())
 }
+
+// RegisterAdminRoutes registers the admin routes. They are served on the loopback admin listener only, behind the
+// admin bearer token.
+func RegisterAdminRoutes(mux *http.ServeMux) {
+	// Register the signer key rotation route.
+	mux.HandleFunc("POST /admin/rotate-key", handler.RotateSignerKey)
+}
@@ -0,0 +1,161 @@
+package common
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+
+	configs "github.com/venture23-aleo/aleo-oracle-notarization-backend/internal/config"
+	appErrors "github.com/venture23-aleo/aleo-oracle-notarization-backend/pkg/errors"
+	"github.com/venture23-aleo/aleo-oracle-notarization-backend/pkg/logger"
+)
+
+// aleoBlockHeightPath is the REST route of the latest block height, relative to the network base URL of a node.
+const aleoBlockHeightPath = "/block/height/latest"
+
+// maxAleoBlockHeightResponseSize is the maximum size of a block height response. The response is a single number.
+const maxAleoBlockHeightResponseSize = 64
+
+// aleoBlockHeightSample is the latest block height reported by a single Aleo node endpoint.
+type aleoBlockHeightSample struct {
+	Endpoint string // The endpoint base URL.
+	Height   int64  // The latest block height.
+}
+
+// GetAleoCurrentBlockHeight queries the configured Aleo node endpoints concurrently and returns the latest
+// block height agreed on by a quorum of them. It returns 0 when block heights are disabled.
+func GetAleoCurrentBlockHeight() (int64, *appErrors.AppError) {
+	aleoNodeConfig := configs.GetAleoNodeConfig()
+	if !aleoNodeConfig.Enabled {
+		return 0, nil
+	}
+
+	samples := queryAleoNodeEndpoints(aleoNodeConfig)
+	if len(samples) < aleoNodeConfig.Quorum {
+		logger.Error("Not enough Aleo node endpoints responded", "responded", len(samples), "endpoints", len(aleoNodeConfig.Endpoints), "quorum", aleoNodeConfig.Quorum)
+		return 0, appErrors.ErrFetchingAleoBlockHeight.WithDetails(fmt.Sprintf("%d of %d endpoints responded, quorum is %d", len(samples), len(aleoNodeConfig.Endpoints), aleoNodeConfig.Quorum))
+	}
+
+	height, agreeing, err := selectAleoBlockHeightQuorum(samples, aleoNodeConfig.Quorum, aleoNodeConfig.MaxHeightDifference)
+	if err != nil {
+		logger.Error("Aleo node endpoints disagree", "error", err)
+		return 0, err
+	}
+
+	logger.Debug("Aleo block height quorum reached", "height", height, "endpoints", strings.Join(agreeing, ","))
+
+	return height, nil
+}
+
+// queryAleoNodeEndpoints queries all configured Aleo node endpoints concurrently and returns the samples
+// of the endpoints that responded.
+func queryAleoNodeEndpoints(aleoNodeConfig configs.AleoNodeConfig) []aleoBlockHeightSample {
+	responses := make([]*aleoBlockHeightSample, len(aleoNodeConfig.Endpoints))
+
+	client := &http.Client{Timeout: aleoNodeConfig.Timeout}
+
+	var wg sync.WaitGroup
+	for i, endpoint := range aleoNodeConfig.Endpoints {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			height, err := fetchAleoBlockHeight(client, endpoint)
+			if err != nil {
+				logger.Warn("Aleo block height query failed", "endpoint", endpoint, "error", err)
+				return
+			}
+
+			responses[i] = &aleoBlockHeightSample{Endpoint: endpoint, Height: height}
+		}()
+	}
+	wg.Wait()
+
+	samples := make([]aleoBlockHeightSample, 0, len(responses))
+	for _, sample := range responses {
+		if sample != nil {
+			samples = append(samples, *sample)
+		}
+	}
+	return samples
+}
+
+// fetchAleoBlockHeight fetches the latest block height from a single Aleo node endpoint.
+func fetchAleoBlockHeight(client *http.Client, endpoint string) (int64, error) {
+	req, err := http.NewRequest(http.MethodGet, strings.TrimSuffix(endpoint, "/")+aleoBlockHeightPath, nil)
+	if err != nil {
+		return 0, err
+	}
+
+	req.Header.Add("Accept", "application/json")
+
+	res, err := client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return 0, fmt.Errorf("unexpected status code %d", res.StatusCode)
+	}
+
+	body, err := io.ReadAll(io.LimitReader(res.Body, maxAleoBlockHeightResponseSize))
+	if err != nil {
+		return 0, err
+	}
+
+	var blockHeight int64
+	if err := json.Unmarshal(body, &blockHeight); err != nil {
+		return 0, err
+	}
+
+	if blockHeight <= 0 {
+		return 0, fmt.Errorf("invalid block height %d", blockHeight)
+	}
+
+	return blockHeight, nil
+}
+
+// selectAleoBlockHeightQuorum finds the largest group of endpoints whose block heights are at most
+// maxHeightDifference apart and returns the lowest height of the group, with the agreeing endpoints.
+//
+// Endpoints that are a few blocks behind still agree, and the lowest height is one every agreeing endpoint
+// has reached. When groups are equally large, the group with the highest heights wins. The group must have
+// at least quorum endpoints, otherwise ErrAleoBlockHeightDisagreement is returned.
+func selectAleoBlockHeightQuorum(samples []aleoBlockHeightSample, quorum int, maxHeightDifference int64) (int64, []string, *appErrors.AppError) {
+	sorted := append([]aleoBlockHeightSample{}, samples...)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].Height < sorted[j].Height
+	})
+
+	// Slide a window over the sorted heights, keeping the largest window within the allowed difference.
+	bestStart, bestEnd := 0, 0
+	start := 0
+	for end := range sorted {
+		for sorted[end].Height-sorted[start].Height > maxHeightDifference {
+			start++
+		}
+		if end+1-start >= bestEnd-bestStart {
+			bestStart, bestEnd = start, end+1
+		}
+	}
+
+	if bestEnd-bestStart < quorum {
+		reported := make([]string, 0, len(samples))
+		for _, sample := range samples {
+			reported = append(reported, fmt.Sprintf("%s: %d", sample.Endpoint, sample.Height))
+		}
+		return 0, nil, appErrors.ErrAleoBlockHeightDisagreement.WithDetails(fmt.Sprintf("at most %d endpoints agree, quorum is %d (%s)", bestEnd-bestStart, quorum, strings.Join(reported, "; ")))
+	}
+
+	agreeing := make([]string, 0, bestEnd-bestStart)
+	for _, sample := range sorted[bestStart:bestEnd] {
+		agreeing = append(agreeing, sample.Endpoint)
+	}
+
+	return sorted[bestStart].Height, agreeing, nil
+}
@@ -0,0 +1,125 @@
+package common
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	configs "github.com/venture23-aleo/aleo-oracle-notarization-backend/internal/config"
+	appErrors "github.com/venture23-aleo/aleo-oracle-notarization-backend/pkg/errors"
+)
+
+func TestSelectAleoBlockHeightQuorum(t *testing.T) {
+	sample := func(endpoint string, height int64) aleoBlockHeightSample {
+		return aleoBlockHeightSample{Endpoint: endpoint, Height: height}
+	}
+
+	tests := []struct {
+		name                string
+		samples             []aleoBlockHeightSample
+		quorum              int
+		maxHeightDifference int64
+		expectedHeight      int64
+		expectedAgreeing    []string
+		expectedError       *appErrors.AppError
+	}{
+		{
+			name:             "single endpoint",
+			samples:          []aleoBlockHeightSample{sample("a", 100)},
+			quorum:           1,
+			expectedHeight:   100,
+			expectedAgreeing: []string{"a"},
+		},
+		{
+			name:                "endpoints behind by a block agree on the lowest height",
+			samples:             []aleoBlockHeightSample{sample("a", 101), sample("b", 100), sample("c", 102)},
+			quorum:              3,
+			maxHeightDifference: 2,
+			expectedHeight:      100,
+			expectedAgreeing:    []string{"a", "b", "c"},
+		},
+		{
+			name:                "lagging endpoint is outvoted",
+			samples:             []aleoBlockHeightSample{sample("a", 100), sample("b", 50), sample("c", 101)},
+			quorum:              2,
+			maxHeightDifference: 2,
+			expectedHeight:      100,
+			expectedAgreeing:    []string{"a", "c"},
+		},
+		{
+			name:                "equally large groups prefer the highest heights",
+			samples:             []aleoBlockHeightSample{sample("a", 50), sample("b", 100)},
+			quorum:              1,
+			maxHeightDifference: 2,
+			expectedHeight:      100,
+			expectedAgreeing:    []string{"b"},
+		},
+		{
+			name:                "endpoints disagree",
+			samples:             []aleoBlockHeightSample{sample("a", 100), sample("b", 103)},
+			quorum:              2,
+			maxHeightDifference: 2,
+			expectedError:       appErrors.ErrAleoBlockHeightDisagreement,
+		},
+		{
+			name:                "no samples",
+			quorum:              1,
+			maxHeightDifference: 2,
+			expectedError:       appErrors.ErrAleoBlockHeightDisagreement,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			height, agreeing, err := selectAleoBlockHeightQuorum(tt.samples, tt.quorum, tt.maxHeightDifference)
+			if tt.expectedError != nil {
+				require.NotNil(t, err)
+				assert.Equal(t, tt.expectedError.Code, err.Code)
+				return
+			}
+			require.Nil(t, err)
+			assert.Equal(t, tt.expectedHeight, height)
+			assert.ElementsMatch(t, tt.expectedAgreeing, agreeing)
+		})
+	}
+}
+
+func TestQueryAleoNodeEndpoints(t *testing.T) {
+	newNode := func(status int, body string) *httptest.Server {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path != "/mainnet"+aleoBlockHeightPath {
+				http.NotFound(w, r)
+				return
+			}
+			w.WriteHeader(status)
+			fmt.Fprint(w, body)
+		}))
+		t.Cleanup(server.Close)
+		return server
+	}
+
+	healthy := newNode(http.StatusOK, "9876543")
+	trailingSlash := newNode(http.StatusOK, "9876544")
+	failing := newNode(http.StatusServiceUnavailable, "9876543")
+	malformed := newNode(http.StatusOK, `{"height":9876543}`)
+
+	samples := queryAleoNodeEndpoints(configs.AleoNodeConfig{
+		Endpoints: []string{
+			healthy.URL + "/mainnet",
+			trailingSlash.URL + "/mainnet/",
+			failing.URL + "/mainnet",
+			malformed.URL + "/mainnet",
+			healthy.URL + "/testnet",
+		},
+		Timeout: time.Second,
+	})
+
+	assert.ElementsMatch(t, []aleoBlockHeightSample{
+		{Endpoint: healthy.URL + "/mainnet", Height: 9876543},
+		{Endpoint: trailingSlash.URL + "/mainnet/", Height: 9876544},
+	}, samples)
+}
@@ -3,63 +3,87 @@ package common
 import (
 	"crypto/rand"
 	"encoding/hex"
-	"encoding/json"
-	"io"
 	"math/big"
-	"net/http"
 	"net/url"
 	"strings"
+	"time"
 
-	"github.com/cloudflare/roughtime/client"
 	configs "github.com/venture23-aleo/aleo-oracle-notarization-backend/internal/config"
 	"github.com/venture23-aleo/aleo-oracle-notarization-backend/internal/constants"
-	appErrors "github.com/venture23-aleo/aleo-oracle-notarization-backend/internal/errors"
-	"github.com/venture23-aleo/aleo-oracle-notarization-backend/internal/logger"
+	appErrors "github.com/venture23-aleo/aleo-oracle-notarization-backend/pkg/errors"
+	"github.com/venture23-aleo/aleo-oracle-notarization-backend/pkg/logger"
 )
 
-// IsPriceFeedURL checks if the URL is a price feed URL.
+// PriceFeedURL is a parsed price feed URL.
+type PriceFeedURL struct {
+	Token       string              // Upper case token.
+	TokenConfig configs.TokenConfig // Registry entry of the token.
+	TWAPWindow  time.Duration       // TWAP window, zero for the spot price.
+}
+
+// ParsePriceFeedURL parses a price feed URL like "price_feed: btc" or "price_feed: btc twap=15m" and resolves
+// its token through the token registry. It returns false for URLs of tokens not in the registry and for
+// malformed parameters.
+func ParsePriceFeedURL(url string) (PriceFeedURL, bool) {
+	if !strings.HasPrefix(url, constants.PriceFeedURLPrefix) {
+		return PriceFeedURL{}, false
+	}
+
+	parts := strings.Split(strings.TrimPrefix(url, constants.PriceFeedURLPrefix), " ")
+
+	token := strings.ToUpper(parts[0])
+	tokenConfig, exists := configs.GetTokenConfig(token)
+	if !exists {
+		return PriceFeedURL{}, false
+	}
+
+	priceFeedURL := PriceFeedURL{Token: token, TokenConfig: tokenConfig}
+	for _, param := range parts[1:] {
+		key, value, found := strings.Cut(param, "=")
+		if !found || key != constants.PriceFeedTWAPParam || priceFeedURL.TWAPWindow != 0 {
+			return PriceFeedURL{}, false
+		}
+		window, err := time.ParseDuration(value)
+		if err != nil || window <= 0 {
+			return PriceFeedURL{}, false
+		}
+		priceFeedURL.TWAPWindow = window
+	}
+	return priceFeedURL, true
+}
+
+// GetPriceFeedToken resolves a price feed URL like "price_feed: btc" through the token registry and returns
+// the upper case token with its registry entry. It returns false for URLs of tokens not in the registry.
+func GetPriceFeedToken(url string) (string, configs.TokenConfig, bool) {
+	priceFeedURL, ok := ParsePriceFeedURL(url)
+	if !ok {
+		return "", configs.TokenConfig{}, false
+	}
+	return priceFeedURL.Token, priceFeedURL.TokenConfig, true
+}
+
+// IsPriceFeedURL checks if the URL is the price feed URL of a token in the token registry.
 func IsPriceFeedURL(url string) bool {
-	return url == constants.PriceFeedBTCURL ||
-		url == constants.PriceFeedETHURL ||
-		url == constants.PriceFeedAleoURL || 
-		url == constants.PriceFeedUSDTURL ||
-		url == constants.PriceFeedUSDCURL
+	_, _, ok := GetPriceFeedToken(url)
+	return ok
 }
 
-// ExtractAssetFromPriceFeedURL extracts the asset name from price feed URL
+// ExtractTokenFromPriceFeedURL extracts the token from price feed URL
 func ExtractTokenFromPriceFeedURL(url string) string {
-	switch url {
-	case constants.PriceFeedBTCURL:
-		return "BTC"
-	case constants.PriceFeedETHURL:
-		return "ETH"
-	case constants.PriceFeedAleoURL:
-		return "ALEO"
-	case constants.PriceFeedUSDTURL:
-		return "USDT"
-	case constants.PriceFeedUSDCURL:
-		return "USDC"
-	default:
+	token, _, ok := GetPriceFeedToken(url)
+	if !ok {
 		return "UNKNOWN"
 	}
+	return token
 }
 
 // GetTokenIDFromPriceFeedURL gets the token ID from price feed URL
 func GetTokenIDFromPriceFeedURL(url string) int {
-	switch url {
-	case constants.PriceFeedBTCURL:
-		return constants.BTCTokenID
-	case constants.PriceFeedETHURL:
-		return constants.ETHTokenID
-	case constants.PriceFeedAleoURL:
-		return constants.AleoTokenID
-	case constants.PriceFeedUSDTURL:
-		return constants.USDTTokenID
-	case constants.PriceFeedUSDCURL:
-		return constants.USDCTokenID
-	default:
+	_, tokenConfig, ok := GetPriceFeedToken(url)
+	if !ok {
 		return 0
 	}
+	return tokenConfig.TokenID
 }
 
 // NormalizeURL adds https:// if the scheme is missing and validates the result.
@@ -155,56 +179,3 @@ func SliceToU128(buf []byte) (*big.Int, *appErrors.AppError) {
 
 	return result, nil
 }
-
-func GetTimestampFromRoughtime() (int64, *appErrors.AppError) { 
-	roughtimeConfig := configs.GetRoughtimeConfig()
-
-	server := roughtimeConfig.ServerConfig.Server
-
-	// Query roughtime servers with timeout and retry settings
-	rt,err := client.Get(server, roughtimeConfig.Retries, roughtimeConfig.Timeout, nil)
-
-	if err != nil {
-		logger.Error("Roughtime query failed: %s\n", err)
-		return 0, appErrors.ErrRoughtimeServerError
-	}
-
-	t := rt.Midpoint.UTC()
-
-	return t.Unix(), nil
-}
-
-func GetAleoCurrentBlockHeight() (int64, *appErrors.AppError) {
-  url := "https://api.explorer.provable.com/v2/mainnet/block/height/latest"
-  method := "GET"
-
-  client := &http.Client {
-  }
-  
-  req, err := http.NewRequest(method, url, nil)
-
-  if err != nil {
-    return 0, appErrors.ErrFetchingAleoBlockHeight.WithDetails(err.Error())
-  }
- 
-  req.Header.Add("Accept", "application/json")
-
-  res, err := client.Do(req)
-  if err != nil {
-    return 0, appErrors.ErrFetchingAleoBlockHeight.WithDetails(err.Error())
-  }
-  defer res.Body.Close()
-
-  body, err := io.ReadAll(res.Body)
-  if err != nil {
-    return 0, appErrors.ErrInvalidHTTPRequest.WithDetails(err.Error())
-  }
- 
-  var blockHeight int64
-  err = json.Unmarshal(body, &blockHeight)
-  if err != nil {
-    return 0, appErrors.ErrFetchingAleoBlockHeight.WithDetails(err.Error())
-  }
-
-  return blockHeight, nil
-}
\ No newline at end of file
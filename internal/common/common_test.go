@@ -2,12 +2,74 @@ package common
 
 import (
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/venture23-aleo/aleo-oracle-notarization-backend/internal/constants"
-	appErrors "github.com/venture23-aleo/aleo-oracle-notarization-backend/internal/errors"
+	appErrors "github.com/venture23-aleo/aleo-oracle-notarization-backend/pkg/errors"
 )
 
+func TestGetPriceFeedToken(t *testing.T) {
+	tests := []struct {
+		name            string
+		url             string
+		expectedToken   string
+		expectedTokenID int
+		expectedOK      bool
+	}{
+		{name: "registered token", url: constants.PriceFeedURLPrefix + "usdc", expectedToken: "USDC", expectedTokenID: 10, expectedOK: true},
+		{name: "upper case token", url: constants.PriceFeedURLPrefix + "ETH", expectedToken: "ETH", expectedTokenID: 11, expectedOK: true},
+		{name: "unregistered token", url: constants.PriceFeedURLPrefix + "doge"},
+		{name: "missing token", url: constants.PriceFeedURLPrefix},
+		{name: "missing prefix", url: "btc"},
+		{name: "regular URL", url: "https://example.com"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			token, tokenConfig, ok := GetPriceFeedToken(tt.url)
+			assert.Equal(t, tt.expectedOK, ok)
+			assert.Equal(t, tt.expectedToken, token)
+			assert.Equal(t, tt.expectedTokenID, tokenConfig.TokenID)
+			if ok {
+				assert.NotEmpty(t, tokenConfig.Exchanges)
+			}
+		})
+	}
+}
+
+func TestParsePriceFeedURL(t *testing.T) {
+	tests := []struct {
+		name           string
+		url            string
+		expectedToken  string
+		expectedWindow time.Duration
+		expectedOK     bool
+	}{
+		{name: "spot price", url: constants.PriceFeedURLPrefix + "btc", expectedToken: "BTC", expectedOK: true},
+		{name: "TWAP window", url: constants.PriceFeedURLPrefix + "btc twap=15m", expectedToken: "BTC", expectedWindow: 15 * time.Minute, expectedOK: true},
+		{name: "compound TWAP window", url: constants.PriceFeedURLPrefix + "aleo twap=1h30m", expectedToken: "ALEO", expectedWindow: 90 * time.Minute, expectedOK: true},
+		{name: "zero TWAP window", url: constants.PriceFeedURLPrefix + "btc twap=0s"},
+		{name: "negative TWAP window", url: constants.PriceFeedURLPrefix + "btc twap=-5m"},
+		{name: "invalid TWAP window", url: constants.PriceFeedURLPrefix + "btc twap=15"},
+		{name: "repeated TWAP window", url: constants.PriceFeedURLPrefix + "btc twap=15m twap=5m"},
+		{name: "unknown parameter", url: constants.PriceFeedURLPrefix + "btc vwap=15m"},
+		{name: "parameter without value", url: constants.PriceFeedURLPrefix + "btc twap"},
+		{name: "double space", url: constants.PriceFeedURLPrefix + "btc  twap=15m"},
+		{name: "unregistered token", url: constants.PriceFeedURLPrefix + "doge twap=15m"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			priceFeedURL, ok := ParsePriceFeedURL(tt.url)
+			assert.Equal(t, tt.expectedOK, ok)
+			assert.Equal(t, tt.expectedToken, priceFeedURL.Token)
+			assert.Equal(t, tt.expectedWindow, priceFeedURL.TWAPWindow)
+			assert.Equal(t, tt.expectedOK, IsPriceFeedURL(tt.url))
+		})
+	}
+}
+
 func TestIsPriceFeedURL(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -16,17 +78,17 @@ func TestIsPriceFeedURL(t *testing.T) {
 	}{
 		{
 			name:     "BTC price feed URL",
-			url:      constants.PriceFeedBTCURL,
+			url:      "price_feed: btc",
 			expected: true,
 		},
 		{
 			name:     "ETH price feed URL",
-			url:      constants.PriceFeedETHURL,
+			url:      "price_feed: eth",
 			expected: true,
 		},
 		{
 			name:     "ALEO price feed URL",
-			url:      constants.PriceFeedAleoURL,
+			url:      "price_feed: aleo",
 			expected: true,
 		},
 		{
@@ -62,17 +124,17 @@ func TestExtractTokenFromPriceFeedURL(t *testing.T) {
 	}{
 		{
 			name:     "BTC price feed URL",
-			url:      constants.PriceFeedBTCURL,
+			url:      "price_feed: btc",
 			expected: "BTC",
 		},
 		{
 			name:     "ETH price feed URL",
-			url:      constants.PriceFeedETHURL,
+			url:      "price_feed: eth",
 			expected: "ETH",
 		},
 		{
 			name:     "ALEO price feed URL",
-			url:      constants.PriceFeedAleoURL,
+			url:      "price_feed: aleo",
 			expected: "ALEO",
 		},
 		{
@@ -108,18 +170,18 @@ func TestGetTokenIDFromPriceFeedURL(t *testing.T) {
 	}{
 		{
 			name:     "BTC price feed URL",
-			url:      constants.PriceFeedBTCURL,
-			expected: constants.BTCTokenID,
+			url:      "price_feed: btc",
+			expected: 12,
 		},
 		{
 			name:     "ETH price feed URL",
-			url:      constants.PriceFeedETHURL,
-			expected: constants.ETHTokenID,
+			url:      "price_feed: eth",
+			expected: 11,
 		},
 		{
 			name:     "ALEO price feed URL",
-			url:      constants.PriceFeedAleoURL,
-			expected: constants.AleoTokenID,
+			url:      "price_feed: aleo",
+			expected: 8,
 		},
 		{
 			name:     "Unknown URL",
@@ -385,17 +447,17 @@ func TestIsTargetWhitelisted(t *testing.T) {
 	}{
 		{
 			name:     "BTC price feed URL",
-			endpoint: constants.PriceFeedBTCURL,
+			endpoint: "price_feed: btc",
 			expected: true,
 		},
 		{
 			name:     "ETH price feed URL",
-			endpoint: constants.PriceFeedETHURL,
+			endpoint: "price_feed: eth",
 			expected: true,
 		},
 		{
 			name:     "ALEO price feed URL",
-			endpoint: constants.PriceFeedAleoURL,
+			endpoint: "price_feed: aleo",
 			expected: true,
 		},
 		{
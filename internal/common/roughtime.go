@@ -0,0 +1,173 @@
+package common
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cloudflare/roughtime/client"
+	configs "github.com/venture23-aleo/aleo-oracle-notarization-backend/internal/config"
+	appErrors "github.com/venture23-aleo/aleo-oracle-notarization-backend/pkg/errors"
+	"github.com/venture23-aleo/aleo-oracle-notarization-backend/pkg/logger"
+	"github.com/venture23-aleo/aleo-oracle-notarization-backend/pkg/roughtime"
+)
+
+// RoughtimeResponse is the signed response of a single roughtime server.
+type RoughtimeResponse = roughtime.Response
+
+// RoughtimeProof proves that an attestation timestamp was agreed on by a quorum of roughtime servers
+// after the attestation request was known. See roughtime.Proof.
+type RoughtimeProof = roughtime.Proof
+
+// roughtimeSample is the time reported by a single roughtime server.
+type roughtimeSample struct {
+	Server   string        // The server name.
+	Midpoint time.Time     // The time reported by the server.
+	Radius   time.Duration // The uncertainty radius of the reported time.
+
+	Response *RoughtimeResponse // The signed response of the server.
+}
+
+// GetTimestampFromRoughtime queries the configured roughtime servers concurrently with nonces derived
+// from the request digest, and returns the Unix timestamp agreed on by a quorum of them with the
+// responses of the agreeing servers.
+func GetTimestampFromRoughtime(requestDigest []byte) (int64, *RoughtimeProof, *appErrors.AppError) {
+	roughtimeConfig := configs.GetRoughtimeConfig()
+
+	samples := queryRoughtimeServers(roughtimeConfig, requestDigest)
+	if len(samples) < roughtimeConfig.Quorum {
+		logger.Error("Not enough roughtime servers responded", "responded", len(samples), "servers", len(roughtimeConfig.ServerConfigs), "quorum", roughtimeConfig.Quorum)
+		return 0, nil, appErrors.ErrRoughtimeServerError.WithDetails(fmt.Sprintf("%d of %d servers responded, quorum is %d", len(samples), len(roughtimeConfig.ServerConfigs), roughtimeConfig.Quorum))
+	}
+
+	t, agreeing, err := selectRoughtimeQuorum(samples, roughtimeConfig.Quorum)
+	if err != nil {
+		logger.Error("Roughtime servers disagree", "error", err)
+		return 0, nil, err
+	}
+
+	logger.Debug("Roughtime quorum reached", "time", t.UTC(), "servers", strings.Join(agreeing, ","))
+
+	proof := &RoughtimeProof{RequestDigest: requestDigest}
+	for _, sample := range samples {
+		if sample.Response != nil && slices.Contains(agreeing, sample.Server) {
+			proof.Responses = append(proof.Responses, *sample.Response)
+		}
+	}
+
+	return t.UTC().Unix(), proof, nil
+}
+
+// queryRoughtimeServers queries all configured roughtime servers concurrently and returns the samples
+// of the servers that responded. The servers are queried at the same time, so the differences between
+// their reply times are network latency, which their uncertainty radii account for.
+func queryRoughtimeServers(roughtimeConfig configs.RoughtimeConfig, requestDigest []byte) []roughtimeSample {
+	responses := make([]*roughtimeSample, len(roughtimeConfig.ServerConfigs))
+
+	// The request digest takes the place of the previous response in the nonce chain.
+	prev := &client.Roughtime{Resp: requestDigest}
+
+	var wg sync.WaitGroup
+	for i, serverConfig := range roughtimeConfig.ServerConfigs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			server := serverConfig.Server
+			rt, err := client.Get(server, roughtimeConfig.Retries, roughtimeConfig.Timeout, prev)
+			if err != nil {
+				logger.Warn("Roughtime query failed", "server", server.Name, "error", err)
+				return
+			}
+
+			responses[i] = &roughtimeSample{
+				Server:   server.Name,
+				Midpoint: rt.Midpoint,
+				Radius:   rt.Radius,
+				Response: &RoughtimeResponse{
+					Server:    server.Name,
+					Version:   server.Version,
+					PublicKey: server.PublicKey,
+					Blind:     rt.Blind,
+					Midpoint:  rt.Midpoint.UnixMicro(),
+					Radius:    rt.Radius.Microseconds(),
+					Response:  rt.Resp,
+				},
+			}
+		}()
+	}
+	wg.Wait()
+
+	samples := make([]roughtimeSample, 0, len(responses))
+	for _, sample := range responses {
+		if sample != nil {
+			samples = append(samples, *sample)
+		}
+	}
+	return samples
+}
+
+// selectRoughtimeQuorum finds the largest group of servers that agree on the time and returns the
+// middle of the interval they agree on, with the names of the agreeing servers.
+//
+// Each sample is the interval [midpoint-radius, midpoint+radius]. Two servers agree when their midpoints
+// are at most the sum of their radii apart, and a group agrees when all its intervals intersect. The
+// group must have at least quorum servers, otherwise ErrRoughtimeDisagreement is returned.
+func selectRoughtimeQuorum(samples []roughtimeSample, quorum int) (time.Time, []string, *appErrors.AppError) {
+	type edge struct {
+		at    time.Time
+		start bool
+	}
+
+	edges := make([]edge, 0, 2*len(samples))
+	for _, sample := range samples {
+		edges = append(edges,
+			edge{at: sample.Midpoint.Add(-sample.Radius), start: true},
+			edge{at: sample.Midpoint.Add(sample.Radius), start: false},
+		)
+	}
+
+	// Sort by time, with interval starts before ends so that touching intervals agree.
+	sort.Slice(edges, func(i, j int) bool {
+		if !edges[i].at.Equal(edges[j].at) {
+			return edges[i].at.Before(edges[j].at)
+		}
+		return edges[i].start && !edges[j].start
+	})
+
+	// Sweep the edges, tracking the interval covered by the most samples. When the count reaches a new
+	// maximum at a start edge, the next edge is always an end edge.
+	best, count := 0, 0
+	var lower, upper time.Time
+	for i, e := range edges {
+		if !e.start {
+			count--
+			continue
+		}
+		count++
+		if count > best {
+			best = count
+			lower, upper = e.at, edges[i+1].at
+		}
+	}
+
+	if best < quorum {
+		reported := make([]string, 0, len(samples))
+		for _, sample := range samples {
+			reported = append(reported, fmt.Sprintf("%s: %s ± %s", sample.Server, sample.Midpoint.UTC().Format(time.RFC3339Nano), sample.Radius))
+		}
+		return time.Time{}, nil, appErrors.ErrRoughtimeDisagreement.WithDetails(fmt.Sprintf("at most %d servers agree, quorum is %d (%s)", best, quorum, strings.Join(reported, "; ")))
+	}
+
+	agreeing := make([]string, 0, best)
+	for _, sample := range samples {
+		if !sample.Midpoint.Add(-sample.Radius).After(lower) && !sample.Midpoint.Add(sample.Radius).Before(upper) {
+			agreeing = append(agreeing, sample.Server)
+		}
+	}
+
+	return lower.Add(upper.Sub(lower) / 2), agreeing, nil
+}
@@ -0,0 +1,99 @@
+package common
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	appErrors "github.com/venture23-aleo/aleo-oracle-notarization-backend/pkg/errors"
+)
+
+func TestSelectRoughtimeQuorum(t *testing.T) {
+	base := time.Unix(1754278324, 0)
+	sample := func(server string, offset, radius time.Duration) roughtimeSample {
+		return roughtimeSample{Server: server, Midpoint: base.Add(offset), Radius: radius}
+	}
+
+	tests := []struct {
+		name             string
+		samples          []roughtimeSample
+		quorum           int
+		expectedTime     time.Time
+		expectedAgreeing []string
+		expectedError    *appErrors.AppError
+	}{
+		{
+			name:             "single server",
+			samples:          []roughtimeSample{sample("a", 0, time.Second)},
+			quorum:           1,
+			expectedTime:     base,
+			expectedAgreeing: []string{"a"},
+		},
+		{
+			name: "all servers agree",
+			samples: []roughtimeSample{
+				sample("a", -500*time.Millisecond, time.Second),
+				sample("b", 500*time.Millisecond, time.Second),
+				sample("c", 0, time.Second),
+			},
+			quorum:           3,
+			expectedTime:     base,
+			expectedAgreeing: []string{"a", "b", "c"},
+		},
+		{
+			name: "outlier is outvoted",
+			samples: []roughtimeSample{
+				sample("a", 0, time.Second),
+				sample("b", time.Hour, time.Second),
+				sample("c", 1500*time.Millisecond, time.Second),
+			},
+			quorum:           2,
+			expectedTime:     base.Add(750 * time.Millisecond),
+			expectedAgreeing: []string{"a", "c"},
+		},
+		{
+			name: "touching intervals agree",
+			samples: []roughtimeSample{
+				sample("a", 0, time.Second),
+				sample("b", 2*time.Second, time.Second),
+			},
+			quorum:           2,
+			expectedTime:     base.Add(time.Second),
+			expectedAgreeing: []string{"a", "b"},
+		},
+		{
+			name: "servers disagree",
+			samples: []roughtimeSample{
+				sample("a", 0, time.Second),
+				sample("b", 3*time.Second, time.Second),
+			},
+			quorum:        2,
+			expectedError: appErrors.ErrRoughtimeDisagreement,
+		},
+		{
+			name: "pairwise overlap without a common quorum",
+			samples: []roughtimeSample{
+				sample("a", 0, time.Second),
+				sample("b", 1500*time.Millisecond, time.Second),
+				sample("c", 3*time.Second, time.Second),
+			},
+			quorum:        3,
+			expectedError: appErrors.ErrRoughtimeDisagreement,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, agreeing, err := selectRoughtimeQuorum(tt.samples, tt.quorum)
+			if tt.expectedError != nil {
+				require.NotNil(t, err)
+				assert.Equal(t, tt.expectedError.Code, err.Code)
+				return
+			}
+			require.Nil(t, err)
+			assert.True(t, tt.expectedTime.Equal(result), "expected %s, got %s", tt.expectedTime, result)
+			assert.ElementsMatch(t, tt.expectedAgreeing, agreeing)
+		})
+	}
+}
@@ -2,17 +2,25 @@
 package configs
 
 import (
+	"crypto/sha256"
 	"embed"
 	"encoding/base64"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
+	"net/url"
+	"path/filepath"
+	"slices"
+	"sort"
 	"strings"
 	"sync"
 	"time"
 
 	rtConfig "github.com/cloudflare/roughtime/config"
-	appErrors "github.com/venture23-aleo/aleo-oracle-notarization-backend/internal/errors"
-	"github.com/venture23-aleo/aleo-oracle-notarization-backend/internal/logger"
+	encoding "github.com/venture23-aleo/aleo-oracle-encoding"
+	"github.com/venture23-aleo/aleo-oracle-notarization-backend/internal/constants"
+	appErrors "github.com/venture23-aleo/aleo-oracle-notarization-backend/pkg/errors"
+	"github.com/venture23-aleo/aleo-oracle-notarization-backend/pkg/logger"
 )
 
 //go:embed config.json
@@ -20,34 +28,703 @@ var configFS embed.FS
 
 type TokenExchanges map[string][]string
 
+// USDQuote is the quote currency prices are aggregated in
+const USDQuote = "USD"
+
+// SymbolConfig holds a trading pair of an exchange
+type SymbolConfig struct {
+	// Symbol is the trading pair as named by the exchange, like "BTCUSDT"
+	Symbol string `json:"symbol"`
+	// Quote is the quote currency of the pair, "USD" or a token of the token registry like "USDT"
+	Quote string `json:"quote"`
+}
+
 type ExchangeConfig struct {
-	Name             string              `json:"name"`
-	BaseURL          string              `json:"baseURL"`
-	Symbols          map[string][]string `json:"symbols"`
+	Name             string                    `json:"name"`
+	BaseURL          string                    `json:"baseURL"`
+	Symbols          map[string][]SymbolConfig `json:"symbols"`
 	EndpointTemplate string              `json:"endpointTemplate"`
 	RootCAHash       string              `json:"rootCAHash"`
+	// Adapter names the hand-written response parser of the exchange, like "binance". Defaults to the exchange key
+	// when neither the adapter nor the response format is set.
+	Adapter string `json:"adapter,omitempty"`
+	// ResponseFormat describes the response for the generic parser. Mutually exclusive with Adapter.
+	ResponseFormat *ExchangeResponseFormat `json:"responseFormat,omitempty"`
+	// RateLimit throttles the requests sent to the exchange. Only Retry-After responses throttle it when unset.
+	RateLimit *ExchangeRateLimit `json:"rateLimit,omitempty"`
+	// Freshness selects the signal the freshness of the responses is verified with, the response timestamp when unset
+	Freshness *ExchangeFreshness `json:"freshness,omitempty"`
+}
+
+// FreshnessSource returns the freshness source of the exchange, defaulting to the response timestamp.
+func (c ExchangeConfig) FreshnessSource() string {
+	if c.Freshness == nil || c.Freshness.Source == "" {
+		return FreshnessSourceResponse
+	}
+	return c.Freshness.Source
+}
+
+// Freshness sources of the exchange responses
+const (
+	FreshnessSourceResponse   = "response"    // Ticker timestamp in the response body.
+	FreshnessSourceDateHeader = "date_header" // HTTP Date header of the response.
+	FreshnessSourceServerTime = "server_time" // Time returned by the server time endpoint of the exchange.
+	FreshnessSourceNone       = "none"        // No freshness signal, the prices are down-weighted or rejected per token.
+)
+
+// ExchangeFreshness configures how the freshness of the exchange responses is verified
+type ExchangeFreshness struct {
+	// Source is the freshness signal: "response" (default), "date_header", "server_time" or "none"
+	Source string `json:"source"`
+	// ServerTimeEndpoint is the path of the server time endpoint, like "/0/public/Time", with the server_time source
+	ServerTimeEndpoint string `json:"serverTimeEndpoint,omitempty"`
+	// ServerTimePath is the gjson path of the time in the server time response
+	ServerTimePath string `json:"serverTimePath,omitempty"`
+	// ServerTimeUnit is the unit of the server time: "s", "ms", "us", "ns" or "rfc3339"
+	ServerTimeUnit string `json:"serverTimeUnit,omitempty"`
+}
+
+// Validate checks the freshness source and the server time endpoint settings.
+func (f *ExchangeFreshness) Validate() error {
+	switch f.Source {
+	case "", FreshnessSourceResponse, FreshnessSourceDateHeader, FreshnessSourceNone:
+		if f.ServerTimeEndpoint != "" || f.ServerTimePath != "" || f.ServerTimeUnit != "" {
+			return fmt.Errorf("server time settings are only used by the %s source", FreshnessSourceServerTime)
+		}
+	case FreshnessSourceServerTime:
+		if !strings.HasPrefix(f.ServerTimeEndpoint, "/") {
+			return fmt.Errorf("serverTimeEndpoint=%q must be a path starting with /", f.ServerTimeEndpoint)
+		}
+		if f.ServerTimePath == "" {
+			return fmt.Errorf("missing serverTimePath")
+		}
+		if !validTimestampUnit(f.ServerTimeUnit) {
+			return fmt.Errorf("invalid serverTimeUnit %q", f.ServerTimeUnit)
+		}
+	default:
+		return fmt.Errorf("invalid source %q", f.Source)
+	}
+	return nil
+}
+
+// ExchangeRateLimit configures the client-side token bucket of an exchange.
+// Requests are skipped, not queued, while the bucket is empty or the exchange is throttled.
+type ExchangeRateLimit struct {
+	// RequestsPerSecond is the rate the bucket refills at
+	RequestsPerSecond float64 `json:"requestsPerSecond"`
+	// Burst is the capacity of the bucket
+	Burst float64 `json:"burst"`
+	// WeightPerCall is the number of tokens a request takes from the bucket, defaults to 1
+	WeightPerCall float64 `json:"weightPerCall,omitempty"`
+	// UsedWeightHeader is the response header reporting the weight used in the window of the exchange,
+	// like Binance's "X-Mbx-Used-Weight-1m", optional
+	UsedWeightHeader string `json:"usedWeightHeader,omitempty"`
+	// MaxUsedWeight is the used weight the exchange is throttled at until the end of the window
+	MaxUsedWeight float64 `json:"maxUsedWeight,omitempty"`
+	// UsedWeightWindowString is the window of the used weight, duration string like "1m"
+	UsedWeightWindowString string        `json:"usedWeightWindowString,omitempty"`
+	UsedWeightWindow       time.Duration `json:"usedWeightWindow,omitempty"`
+}
+
+// CallWeight returns the number of tokens a request takes from the bucket.
+func (r *ExchangeRateLimit) CallWeight() float64 {
+	if r.WeightPerCall == 0 {
+		return 1
+	}
+	return r.WeightPerCall
+}
+
+// Validate checks the bucket and used weight settings and parses the used weight window.
+func (r *ExchangeRateLimit) Validate() error {
+	if r.RequestsPerSecond <= 0 {
+		return fmt.Errorf("requestsPerSecond=%v must be positive", r.RequestsPerSecond)
+	}
+	if r.WeightPerCall < 0 {
+		return fmt.Errorf("weightPerCall=%v must not be negative", r.WeightPerCall)
+	}
+	if r.Burst < r.CallWeight() {
+		return fmt.Errorf("burst=%v must be at least the weight per call %v", r.Burst, r.CallWeight())
+	}
+	if r.UsedWeightHeader == "" {
+		if r.MaxUsedWeight != 0 || r.UsedWeightWindowString != "" {
+			return fmt.Errorf("maxUsedWeight and usedWeightWindowString are set without usedWeightHeader")
+		}
+		return nil
+	}
+	if r.MaxUsedWeight <= 0 {
+		return fmt.Errorf("maxUsedWeight=%v must be positive", r.MaxUsedWeight)
+	}
+	window, err := time.ParseDuration(r.UsedWeightWindowString)
+	if err != nil {
+		return fmt.Errorf("invalid usedWeightWindowString: %v", err)
+	}
+	if window <= 0 {
+		return fmt.Errorf("usedWeightWindowString=%s must be positive", r.UsedWeightWindowString)
+	}
+	r.UsedWeightWindow = window
+	return nil
+}
+
+// Timestamp units of the exchange response timestamp
+const (
+	TimestampUnitSeconds      = "s"
+	TimestampUnitMilliseconds = "ms"
+	TimestampUnitMicroseconds = "us"
+	TimestampUnitNanoseconds  = "ns"
+	TimestampUnitRFC3339      = "rfc3339"
+)
+
+// validTimestampUnit reports whether unit is a timestamp unit.
+func validTimestampUnit(unit string) bool {
+	switch unit {
+	case TimestampUnitSeconds, TimestampUnitMilliseconds, TimestampUnitMicroseconds, TimestampUnitNanoseconds, TimestampUnitRFC3339:
+		return true
+	default:
+		return false
+	}
+}
+
+// Denominations of the exchange response volume
+const (
+	VolumeDenominationBase  = "base"
+	VolumeDenominationQuote = "quote"
+)
+
+// ExchangeResponseFormat holds the gjson paths of the ticker fields in an exchange response.
+// Paths may contain the {symbol} and {token} placeholders, like "result.{symbol}.c.0".
+type ExchangeResponseFormat struct {
+	// PricePath is the path of the last traded price
+	PricePath string `json:"pricePath"`
+	// VolumePath is the path of the 24h volume
+	VolumePath string `json:"volumePath"`
+	// SymbolPath is the path of the symbol echoed by the exchange, optional
+	SymbolPath string `json:"symbolPath,omitempty"`
+	// TimestampPath is the path of the ticker timestamp, optional
+	TimestampPath string `json:"timestampPath,omitempty"`
+	// TimestampUnit is the unit of the timestamp: "s", "ms", "us", "ns" or "rfc3339"
+	TimestampUnit string `json:"timestampUnit,omitempty"`
+	// VolumeDenomination is the asset the volume is counted in: "base" (default) or "quote"
+	VolumeDenomination string `json:"volumeDenomination,omitempty"`
+}
+
+// Validate checks that the response format can be used by the generic parser.
+func (f *ExchangeResponseFormat) Validate() error {
+	if f.PricePath == "" {
+		return fmt.Errorf("missing pricePath")
+	}
+	if f.VolumePath == "" {
+		return fmt.Errorf("missing volumePath")
+	}
+	if f.TimestampPath != "" {
+		if !validTimestampUnit(f.TimestampUnit) {
+			return fmt.Errorf("invalid timestampUnit %q", f.TimestampUnit)
+		}
+	} else if f.TimestampUnit != "" {
+		return fmt.Errorf("timestampUnit is set without timestampPath")
+	}
+	switch f.VolumeDenomination {
+	case "", VolumeDenominationBase, VolumeDenominationQuote:
+	default:
+		return fmt.Errorf("invalid volumeDenomination %q", f.VolumeDenomination)
+	}
+	return nil
 }
 
 type ExchangesConfig map[string]ExchangeConfig
 
-type TokenVWAPConfig struct {
+// Price aggregation methods
+const (
+	// AggregationMethodVWAP is the volume-weighted average of the prices within the MAD and tolerance bounds,
+	// with capped exchange weights and a cross-venue dispersion check.
+	AggregationMethodVWAP = "vwap"
+	// AggregationMethodWeightedMedian is the volume-weighted median of the prices, with capped exchange weights.
+	AggregationMethodWeightedMedian = "weighted_median"
+	// AggregationMethodTrimmedMean is the mean of the prices left after dropping the lowest and highest ones.
+	AggregationMethodTrimmedMean = "trimmed_mean"
+)
+
+type TokenAggregationConfig struct {
 	Token string `json:"token"`
+	// Method is the aggregation method: "vwap" (default), "weighted_median" or "trimmed_mean"
+	Method string `json:"method,omitempty"`
 	TokenTolerancePercent float64 `json:"tokenTolerancePercent"`
 	TokenMADMultiplier float64 `json:"tokenMADMultiplier"`
 	TokenMaxSpreadPercent float64 `json:"tokenMaxSpreadPercent"`
-	TokenMinVolumePerExchange float64 `json:"tokenMinVolumePerExchange"`
+	TokenMinVolumeUSDPerExchange float64 `json:"tokenMinVolumeUSDPerExchange"` // Minimum 24h USD volume of a trading pair.
 	TokenMaxExchangeWeightPercent float64 `json:"tokenMaxExchangeWeightPercent"`
+	// TrimPercent is the percentage of prices dropped at each end by the trimmed mean
+	TrimPercent float64 `json:"trimPercent,omitempty"`
+	// MinExchangesRequired is the number of exchanges the price must be aggregated from, the global
+	// minExchangesRequired when zero
+	MinExchangesRequired int `json:"minExchangesRequired,omitempty"`
+	// Exchanges holds the settings of the token exchanges, by exchange key
+	Exchanges map[string]TokenExchangeConfig `json:"exchanges,omitempty"`
+	// MaxAgeString is how far the freshness signal of an exchange response may be from the attestation time,
+	// duration string like "2m", MaxAllowedTimeDiff seconds when empty
+	MaxAgeString string        `json:"maxAgeString,omitempty"`
+	MaxAge       time.Duration `json:"maxAge,omitempty"`
+	// UnverifiedFreshnessWeight multiplies the USD volume of the prices from exchanges without a freshness signal,
+	// which are rejected when zero
+	UnverifiedFreshnessWeight float64 `json:"unverifiedFreshnessWeight,omitempty"`
+	// Guard holds the bounds the token price must stay in before it is attested, no guard when nil
+	Guard *TokenGuardConfig `json:"guard,omitempty"`
 }
 
-type TokenVWAPConfigMap map[string]TokenVWAPConfig
+// ParseMaxAgeString parses the max age of the exchange responses, defaulting to MaxAllowedTimeDiff seconds.
+func (c *TokenAggregationConfig) ParseMaxAgeString() error {
+	if c.MaxAgeString == "" {
+		c.MaxAge = DefaultMaxAge
+		return nil
+	}
+	maxAge, err := time.ParseDuration(c.MaxAgeString)
+	if err != nil {
+		return err
+	}
+	if maxAge <= 0 {
+		return fmt.Errorf("maxAgeString=%s must be positive", c.MaxAgeString)
+	}
+	c.MaxAge = maxAge
+	return nil
+}
 
+// DefaultMaxAge is the max age of the exchange responses of the tokens without maxAgeString
+const DefaultMaxAge = time.Duration(constants.MaxAllowedTimeDiff) * time.Second
+
+// ResponseMaxAge returns the max age of the exchange responses, defaulting to DefaultMaxAge.
+func (c TokenAggregationConfig) ResponseMaxAge() time.Duration {
+	if c.MaxAge <= 0 {
+		return DefaultMaxAge
+	}
+	return c.MaxAge
+}
+
+// Guard policies of a token, applied when its price crosses a bound of the guard
+const (
+	GuardPolicyReject = "reject" // The price feed request fails.
+	GuardPolicyFlag   = "flag"   // The price is attested and the crossed bounds are flagged in the response.
+)
+
+// TokenGuardConfig holds the bounds a token price must stay in before it is attested. The change is checked
+// against the last attested price of the token
+type TokenGuardConfig struct {
+	// Policy is "reject" (default) or "flag"
+	Policy string `json:"policy,omitempty"`
+	// MaxChangePercent is the largest change from the last attested price per interval, no limit when zero
+	MaxChangePercent float64 `json:"maxChangePercent,omitempty"`
+	// MaxChangeIntervalString is the interval of MaxChangePercent, duration string like "1h". The allowed
+	// change grows with every interval started since the last attested price
+	MaxChangeIntervalString string        `json:"maxChangeIntervalString,omitempty"`
+	MaxChangeInterval       time.Duration `json:"maxChangeInterval,omitempty"`
+	// PegLowerBound and PegUpperBound are the absolute band of a pegged asset, like 0.97 and 1.03 for a USD
+	// stablecoin, no band when both are zero
+	PegLowerBound float64 `json:"pegLowerBound,omitempty"`
+	PegUpperBound float64 `json:"pegUpperBound,omitempty"`
+}
+
+// GuardPolicy returns the policy of the guard, defaulting to reject.
+func (c TokenGuardConfig) GuardPolicy() string {
+	if c.Policy == "" {
+		return GuardPolicyReject
+	}
+	return c.Policy
+}
+
+// HasPegBand reports whether the guard has a peg band.
+func (c TokenGuardConfig) HasPegBand() bool {
+	return c.PegLowerBound != 0 || c.PegUpperBound != 0
+}
+
+// Validate checks the policy and the bounds of the guard.
+func (c TokenGuardConfig) Validate() error {
+	switch c.GuardPolicy() {
+	case GuardPolicyReject, GuardPolicyFlag:
+	default:
+		return fmt.Errorf("invalid policy %q", c.Policy)
+	}
+	if c.MaxChangePercent < 0 {
+		return fmt.Errorf("maxChangePercent=%v must not be negative", c.MaxChangePercent)
+	}
+	if c.MaxChangePercent > 0 && c.MaxChangeIntervalString == "" {
+		return fmt.Errorf("maxChangeIntervalString is required with maxChangePercent")
+	}
+	if c.HasPegBand() && (c.PegLowerBound < 0 || c.PegLowerBound >= c.PegUpperBound) {
+		return fmt.Errorf("pegLowerBound=%v and pegUpperBound=%v must satisfy 0 <= pegLowerBound < pegUpperBound", c.PegLowerBound, c.PegUpperBound)
+	}
+	return nil
+}
+
+// ParseMaxChangeIntervalString parses the interval of the max change. A nil guard has nothing to parse.
+func (c *TokenGuardConfig) ParseMaxChangeIntervalString() error {
+	if c == nil || c.MaxChangeIntervalString == "" {
+		return nil
+	}
+	interval, err := time.ParseDuration(c.MaxChangeIntervalString)
+	if err != nil {
+		return err
+	}
+	if interval <= 0 {
+		return fmt.Errorf("maxChangeIntervalString=%s must be positive", c.MaxChangeIntervalString)
+	}
+	c.MaxChangeInterval = interval
+	return nil
+}
+
+// TokenExchangeConfig holds the settings of an exchange for a token
+type TokenExchangeConfig struct {
+	// Disabled skips the exchange when fetching the token price
+	Disabled bool `json:"disabled,omitempty"`
+	// TrustWeight multiplies the USD volume the exchange prices weigh with, 1 when zero
+	TrustWeight float64 `json:"trustWeight,omitempty"`
+	// MaxWeightPercent caps the weight of the exchange, the token tokenMaxExchangeWeightPercent when zero
+	MaxWeightPercent float64 `json:"maxWeightPercent,omitempty"`
+}
+
+// MinExchanges returns the number of exchanges the token price must be aggregated from, defaulting to the
+// global minimum.
+func (c TokenAggregationConfig) MinExchanges(globalMinExchangesRequired int) int {
+	if c.MinExchangesRequired == 0 {
+		return globalMinExchangesRequired
+	}
+	return c.MinExchangesRequired
+}
+
+// ExchangeEnabled reports whether the token price is fetched from the exchange.
+func (c TokenAggregationConfig) ExchangeEnabled(exchange string) bool {
+	return !c.Exchanges[exchange].Disabled
+}
+
+// ExchangeTrustWeight returns the trust weight of the exchange, defaulting to 1.
+func (c TokenAggregationConfig) ExchangeTrustWeight(exchange string) float64 {
+	if trustWeight := c.Exchanges[exchange].TrustWeight; trustWeight != 0 {
+		return trustWeight
+	}
+	return 1
+}
+
+// ExchangeMaxWeightPercent returns the weight cap of the exchange, defaulting to the token weight cap.
+func (c TokenAggregationConfig) ExchangeMaxWeightPercent(exchange string) float64 {
+	if maxWeightPercent := c.Exchanges[exchange].MaxWeightPercent; maxWeightPercent != 0 {
+		return maxWeightPercent
+	}
+	return c.TokenMaxExchangeWeightPercent
+}
+
+// AggregationMethod returns the aggregation method of the token, defaulting to the VWAP.
+func (c TokenAggregationConfig) AggregationMethod() string {
+	if c.Method == "" {
+		return AggregationMethodVWAP
+	}
+	return c.Method
+}
+
+// Validate checks the aggregation method and its parameters.
+func (c TokenAggregationConfig) Validate() error {
+	switch c.AggregationMethod() {
+	case AggregationMethodVWAP, AggregationMethodWeightedMedian:
+		if c.TokenMaxExchangeWeightPercent <= 0 || c.TokenMaxExchangeWeightPercent > 100 {
+			return fmt.Errorf("tokenMaxExchangeWeightPercent=%v must be in (0, 100]", c.TokenMaxExchangeWeightPercent)
+		}
+	case AggregationMethodTrimmedMean:
+		if c.TrimPercent < 0 || c.TrimPercent >= 50 {
+			return fmt.Errorf("trimPercent=%v must be in [0, 50)", c.TrimPercent)
+		}
+	default:
+		return fmt.Errorf("invalid method %q", c.Method)
+	}
+	if c.TrimPercent != 0 && c.AggregationMethod() != AggregationMethodTrimmedMean {
+		return fmt.Errorf("trimPercent is only used by the %s method", AggregationMethodTrimmedMean)
+	}
+	if c.TokenMinVolumeUSDPerExchange < 0 {
+		return fmt.Errorf("tokenMinVolumeUSDPerExchange=%v must not be negative", c.TokenMinVolumeUSDPerExchange)
+	}
+	if c.MinExchangesRequired < 0 {
+		return fmt.Errorf("minExchangesRequired=%d must not be negative", c.MinExchangesRequired)
+	}
+	if c.UnverifiedFreshnessWeight < 0 || c.UnverifiedFreshnessWeight > 1 {
+		return fmt.Errorf("unverifiedFreshnessWeight=%v must be in [0, 1]", c.UnverifiedFreshnessWeight)
+	}
+	if c.Guard != nil {
+		if err := c.Guard.Validate(); err != nil {
+			return fmt.Errorf("invalid guard: %w", err)
+		}
+	}
+	for exchange, exchangeConfig := range c.Exchanges {
+		if exchangeConfig.TrustWeight < 0 {
+			return fmt.Errorf("exchange %s: trustWeight=%v must not be negative", exchange, exchangeConfig.TrustWeight)
+		}
+		if exchangeConfig.MaxWeightPercent < 0 || exchangeConfig.MaxWeightPercent > 100 {
+			return fmt.Errorf("exchange %s: maxWeightPercent=%v must be in [0, 100]", exchange, exchangeConfig.MaxWeightPercent)
+		}
+		if exchangeConfig.MaxWeightPercent != 0 && c.AggregationMethod() == AggregationMethodTrimmedMean {
+			return fmt.Errorf("exchange %s: maxWeightPercent is not used by the %s method", exchange, AggregationMethodTrimmedMean)
+		}
+	}
+	return nil
+}
+
+// ValidateExchanges checks the exchange settings against the exchanges of the token registry entry, and that
+// enough exchanges remain enabled to reach the minimum.
+func (c TokenAggregationConfig) ValidateExchanges(tokenConfig TokenConfig, globalMinExchangesRequired int) error {
+	for exchange := range c.Exchanges {
+		if !slices.Contains(tokenConfig.Exchanges, exchange) {
+			return fmt.Errorf("exchange %s is not an exchange of the token", exchange)
+		}
+	}
+
+	enabledExchanges := 0
+	for _, exchange := range tokenConfig.Exchanges {
+		if c.ExchangeEnabled(exchange) {
+			enabledExchanges++
+		}
+	}
+	if minExchanges := c.MinExchanges(globalMinExchangesRequired); enabledExchanges < minExchanges {
+		return fmt.Errorf("minExchangesRequired=%d exceeds enabled exchanges=%d", minExchanges, enabledExchanges)
+	}
+	return nil
+}
+
+type TokenAggregationConfigMap map[string]TokenAggregationConfig
+
+
+// MaxTokenID is the largest token ID, as the token ID is written into a single byte of the attestation user data
+const MaxTokenID = 255
+
+// TokenConfig holds the registry entry of a price feed token
+type TokenConfig struct {
+	// TokenID is the on-chain token ID written into the attestation user data, between 1 and MaxTokenID
+	TokenID int `json:"tokenID"`
+	// DefaultPrecision is the float precision of a price feed request that does not set one
+	DefaultPrecision uint `json:"defaultPrecision"`
+	// Exchanges are the keys of the exchanges the token price is fetched from
+	Exchanges []string `json:"exchanges"`
+}
+
+// TokenRegistry maps the upper case token symbol, like "BTC", to its registry entry
+type TokenRegistry map[string]TokenConfig
 
 type PriceFeedConfig struct {
-	ExchangesConfig      ExchangesConfig `json:"exchangesConfig"`
-	TokenExchanges       TokenExchanges  `json:"tokenExchanges"`
-	MinExchangesRequired int             `json:"minExchangesRequired"`
-	TokenVWAPConfig     TokenVWAPConfigMap  `json:"tokenVWAPConfig"`
-	// ProvableBlockHeightURL string `json:"provableBlockHeightURL"`
+	ExchangesConfig      ExchangesConfig    `json:"exchangesConfig"`
+	Tokens               TokenRegistry      `json:"tokens"`
+	MinExchangesRequired int                `json:"minExchangesRequired"`
+	TokenAggregationConfig TokenAggregationConfigMap `json:"tokenAggregationConfig"`
+	Sampler              PriceSamplerConfig `json:"sampler"`
+	Cache                PriceCacheConfig   `json:"cache"`
+	ExchangeHealth       ExchangeHealthConfig `json:"exchangeHealth"`
+	Guard                PriceGuardConfig     `json:"guard"`
+	Scheduler            AttestationSchedulerConfig `json:"scheduler"`
+}
+
+// Types of the sinks the scheduled attestations are pushed to
+const (
+	SinkTypeWebhook = "webhook" // POST the attestation as JSON to a URL.
+	SinkTypeFile    = "file"    // Append the attestation as a JSON line to a local file.
+)
+
+// AttestationSchedulerConfig holds the configuration of the attestation scheduler. It attests the price feed of
+// its tokens when the price deviates from the last published attestation or when the heartbeat elapses, and
+// pushes the attestations to its sinks
+type AttestationSchedulerConfig struct {
+	// Enabled starts the scheduler
+	Enabled bool `json:"enabled"`
+	// CheckIntervalString is the cadence the prices are checked at, duration string like "15s"
+	CheckIntervalString string        `json:"checkIntervalString"`
+	CheckInterval       time.Duration `json:"checkInterval"`
+	// Tokens are the scheduled tokens
+	Tokens []ScheduledTokenConfig `json:"tokens"`
+	// Sinks are the destinations every scheduled attestation is pushed to
+	Sinks []AttestationSinkConfig `json:"sinks"`
+	// StateFile is the file the latest scheduled attestations are persisted to across restarts, inside a sealed
+	// mount like /sealed/mrsigner. They are kept in memory when empty, and when the file is inside /sealed and
+	// the quote provider is not gramine
+	StateFile string `json:"stateFile"`
+}
+
+func (c *AttestationSchedulerConfig) ParseCheckIntervalString() error {
+	checkInterval, err := time.ParseDuration(c.CheckIntervalString)
+	if err != nil {
+		return err
+	}
+	c.CheckInterval = checkInterval
+	return nil
+}
+
+// ScheduledTokenConfig holds the triggers of the scheduled attestations of a token
+type ScheduledTokenConfig struct {
+	// Token is the symbol of the token in the token registry
+	Token string `json:"token"`
+	// Precision is the float precision of the attested price, the default precision of the token when zero
+	Precision uint `json:"precision,omitempty"`
+	// DeviationPercent is the change from the last published price that triggers an attestation, disabled when zero
+	DeviationPercent float64 `json:"deviationPercent"`
+	// HeartbeatString is the longest time between two attestations, duration string like "1h"
+	HeartbeatString string        `json:"heartbeatString"`
+	Heartbeat       time.Duration `json:"heartbeat"`
+}
+
+// Validate checks the triggers of the token.
+func (c ScheduledTokenConfig) Validate() error {
+	if c.DeviationPercent < 0 {
+		return fmt.Errorf("deviationPercent=%v must not be negative", c.DeviationPercent)
+	}
+	if c.DeviationPercent == 0 && c.HeartbeatString == "" {
+		return fmt.Errorf("deviationPercent or heartbeatString is required")
+	}
+	if c.Precision > encoding.ENCODING_OPTION_FLOAT_MAX_PRECISION {
+		return fmt.Errorf("precision=%d must not exceed %d", c.Precision, encoding.ENCODING_OPTION_FLOAT_MAX_PRECISION)
+	}
+	return nil
+}
+
+// ParseHeartbeatString parses the heartbeat of the token. A token without heartbeat has nothing to parse.
+func (c *ScheduledTokenConfig) ParseHeartbeatString() error {
+	if c.HeartbeatString == "" {
+		return nil
+	}
+	heartbeat, err := time.ParseDuration(c.HeartbeatString)
+	if err != nil {
+		return err
+	}
+	if heartbeat <= 0 {
+		return fmt.Errorf("heartbeatString=%s must be positive", c.HeartbeatString)
+	}
+	c.Heartbeat = heartbeat
+	return nil
+}
+
+// AttestationSinkConfig holds a destination of the scheduled attestations
+type AttestationSinkConfig struct {
+	// Type is the sink type: webhook or file
+	Type string `json:"type"`
+	// URL is the endpoint of a webhook sink
+	URL string `json:"url,omitempty"`
+	// Path is the file of a file sink, inside a mount like /data
+	Path string `json:"path,omitempty"`
+	// TimeoutString is the timeout of a webhook delivery, duration string like "5s"
+	TimeoutString string        `json:"timeoutString,omitempty"`
+	Timeout       time.Duration `json:"timeout,omitempty"`
+}
+
+// Validate checks the destination of the sink and parses the timeout of a webhook sink.
+func (c *AttestationSinkConfig) Validate() error {
+	switch c.Type {
+	case SinkTypeWebhook:
+		parsedURL, err := url.Parse(c.URL)
+		if err != nil || parsedURL.Host == "" || (parsedURL.Scheme != "https" && parsedURL.Scheme != "http") {
+			return fmt.Errorf("url=%q is not an http(s) URL", c.URL)
+		}
+		timeout, err := time.ParseDuration(c.TimeoutString)
+		if err != nil {
+			return fmt.Errorf("invalid timeoutString: %w", err)
+		}
+		if timeout <= 0 {
+			return fmt.Errorf("timeoutString=%s must be positive", c.TimeoutString)
+		}
+		c.Timeout = timeout
+	case SinkTypeFile:
+		if !filepath.IsAbs(c.Path) {
+			return fmt.Errorf("path=%q must be an absolute path", c.Path)
+		}
+	default:
+		return fmt.Errorf("invalid type %q", c.Type)
+	}
+	return nil
+}
+
+// PriceGuardConfig holds the storage of the last attested price of every token, which the token guards check
+// the price changes against
+type PriceGuardConfig struct {
+	// StateFile is the file the last attested prices are persisted to across restarts, inside a sealed mount
+	// like /sealed/mrsigner. The prices are kept in memory when empty, and when the file is inside /sealed
+	// and the quote provider is not gramine
+	StateFile string `json:"stateFile"`
+}
+
+// ExchangeHealthConfig holds the thresholds of the exchange health tracker. The circuit of an exchange symbol
+// opens when its rates over the window exceed them, skipping the symbol until a probe request succeeds
+type ExchangeHealthConfig struct {
+	// Enabled tracks the health of every exchange symbol and opens the circuit of the unhealthy ones
+	Enabled bool `json:"enabled"`
+	// WindowSize is the number of latest requests, and of latest aggregations, the rates are computed over
+	WindowSize int `json:"windowSize"`
+	// MinRequests is the number of requests, or aggregations, in the window before the circuit can open
+	MinRequests int `json:"minRequests"`
+	// MaxErrorRatePercent is the share of failed or stale requests the circuit opens above
+	MaxErrorRatePercent float64 `json:"maxErrorRatePercent"`
+	// MaxOutlierRatePercent is the share of prices filtered as outliers by the VWAP the circuit opens above
+	MaxOutlierRatePercent float64 `json:"maxOutlierRatePercent"`
+	// MaxLatencyString is the average request latency the circuit opens above, duration string like "5s"
+	MaxLatencyString string        `json:"maxLatencyString"`
+	MaxLatency       time.Duration `json:"maxLatency"`
+	// OpenDurationString is how long the circuit stays open before a probe request, duration string like "5m"
+	OpenDurationString string        `json:"openDurationString"`
+	OpenDuration       time.Duration `json:"openDuration"`
+}
+
+func (c *ExchangeHealthConfig) ParseMaxLatencyString() error {
+	maxLatency, err := time.ParseDuration(c.MaxLatencyString)
+	if err != nil {
+		return err
+	}
+	c.MaxLatency = maxLatency
+	return nil
+}
+
+func (c *ExchangeHealthConfig) ParseOpenDurationString() error {
+	openDuration, err := time.ParseDuration(c.OpenDurationString)
+	if err != nil {
+		return err
+	}
+	c.OpenDuration = openDuration
+	return nil
+}
+
+// PriceCacheConfig holds the configuration of the shared price feed cache. Concurrent requests for the same
+// token and precision share a single exchange round-trip, and the result is reused until the TTL expires
+type PriceCacheConfig struct {
+	// Enabled caches the price feed results and coalesces concurrent requests
+	Enabled bool `json:"enabled"`
+	// TTLString is the lifetime of a cached result, duration string like "3s"
+	TTLString string        `json:"ttlString"`
+	TTL       time.Duration `json:"ttl"`
+}
+
+func (c *PriceCacheConfig) ParseTTLString() error {
+	ttl, err := time.ParseDuration(c.TTLString)
+	if err != nil {
+		return err
+	}
+	c.TTL = ttl
+	return nil
+}
+
+// PriceSamplerConfig holds the configuration of the background price sampler behind the TWAP price feeds,
+// like "price_feed: btc twap=15m"
+type PriceSamplerConfig struct {
+	// Enabled starts the sampler and accepts TWAP price feed URLs
+	Enabled bool `json:"enabled"`
+	// Tokens are the sampled tokens, every token of the registry when empty
+	Tokens []string `json:"tokens"`
+	// IntervalString is the sampling cadence, duration string like "30s"
+	IntervalString string        `json:"intervalString"`
+	Interval       time.Duration `json:"interval"`
+	// Capacity is the number of snapshots kept per token, the longest TWAP window is Capacity * Interval
+	Capacity int `json:"capacity"`
+	// MinCoveragePercent is the share of a TWAP window the snapshots must cover
+	MinCoveragePercent float64 `json:"minCoveragePercent"`
+}
+
+func (c *PriceSamplerConfig) ParseIntervalString() error {
+	interval, err := time.ParseDuration(c.IntervalString)
+	if err != nil {
+		return err
+	}
+	c.Interval = interval
+	return nil
+}
+
+// MaxWindow returns the longest TWAP window covered by the snapshots kept per token.
+func (c PriceSamplerConfig) MaxWindow() time.Duration {
+	return c.Interval * time.Duration(c.Capacity)
 }
 
 type RoughtimeServerConfig struct {
@@ -64,13 +741,15 @@ func (s *RoughtimeServerConfig) DecodePublicKey() error {
     return nil
 }
 
-// RoughtimeConfig holds the configuration for the roughtime server
+// RoughtimeConfig holds the configuration for the roughtime servers
 type RoughtimeConfig struct {
     Enabled  bool             `json:"enabled"`
     Retries  int              `json:"retries"`
     TimeoutString  string    `json:"timeoutString"` // duration string like "1s"
 	Timeout  time.Duration    `json:"timeout"`
-    ServerConfig  RoughtimeServerConfig `json:"serverConfig"`
+    ServerConfigs  []RoughtimeServerConfig `json:"serverConfigs"`
+    // Quorum is the number of servers that must agree on the time within their uncertainty radii
+    Quorum   int              `json:"quorum"`
 }
 
 func (c *RoughtimeConfig) ParseTimeoutString() error {
@@ -82,6 +761,84 @@ func (c *RoughtimeConfig) ParseTimeoutString() error {
     return nil
 }
 
+// AleoNodeConfig holds the configuration for the Aleo node REST endpoints queried for the latest block height
+type AleoNodeConfig struct {
+	// Enabled encodes the latest Aleo block height into the user data of every attestation
+	Enabled bool `json:"enabled"`
+	// Endpoints are the REST base URLs of the Aleo nodes including the network, like "https://api.explorer.provable.com/v2/mainnet"
+	Endpoints []string `json:"endpoints"`
+	// TimeoutString is the timeout of a single endpoint query, duration string like "2s"
+	TimeoutString string        `json:"timeoutString"`
+	Timeout       time.Duration `json:"timeout"`
+	// Quorum is the number of endpoints that must agree on the block height
+	Quorum int `json:"quorum"`
+	// MaxHeightDifference is the number of blocks the heights of agreeing endpoints may differ by
+	MaxHeightDifference int64 `json:"maxHeightDifference"`
+}
+
+func (c *AleoNodeConfig) ParseTimeoutString() error {
+	timeout, err := time.ParseDuration(c.TimeoutString)
+	if err != nil {
+		return err
+	}
+	c.Timeout = timeout
+	return nil
+}
+
+// SGXConfig holds the configuration for SGX report and quote generation
+type SGXConfig struct {
+	// QuoteProvider selects the quote provider: "gramine" (default) or "simulated"
+	QuoteProvider string `json:"quoteProvider"`
+	// CollateralDir is the directory holding the DCAP collateral used by /verify to check quote signatures and TCB status
+	CollateralDir string `json:"collateralDir"`
+}
+
+// SigningKeyConfig holds the configuration for the Aleo signing key
+type SigningKeyConfig struct {
+	// Sealed persists the signing key across restarts through Gramine encrypted files
+	Sealed bool `json:"sealed"`
+	// SealingPolicy selects the sealing key: "mrenclave" or "mrsigner"
+	SealingPolicy string `json:"sealingPolicy"`
+	// RotationOverlapString is how long the previous key keeps signing after a rotation, duration string like "24h"
+	RotationOverlapString string `json:"rotationOverlapString"`
+	RotationOverlap       time.Duration `json:"rotationOverlap"`
+}
+
+func (c *SigningKeyConfig) ParseRotationOverlapString() error {
+	overlap, err := time.ParseDuration(c.RotationOverlapString)
+	if err != nil {
+		return err
+	}
+	c.RotationOverlap = overlap
+	return nil
+}
+
+// AdminConfig holds the configuration of the admin listener serving the admin routes, like the signer key
+// rotation. It is bound to 127.0.0.1 and every request must carry the admin bearer token
+type AdminConfig struct {
+	// Port is the loopback port of the admin listener
+	Port int `json:"port"`
+	// TokenSha256 is the hex encoded SHA-256 of the admin bearer token. The token itself is kept by the operator,
+	// only its hash is part of the measured config. The admin listener is not started when empty
+	TokenSha256 string `json:"tokenSha256"`
+}
+
+// Enabled reports whether the admin listener is started.
+func (c AdminConfig) Enabled() bool {
+	return c.TokenSha256 != ""
+}
+
+// Validate checks the port and the token hash of the admin listener.
+func (c AdminConfig) Validate() error {
+	if c.Port < 1 || c.Port > 65535 {
+		return fmt.Errorf("port=%d must be between 1 and 65535", c.Port)
+	}
+	if tokenHash, err := hex.DecodeString(c.TokenSha256); err != nil || len(tokenHash) != sha256.Size {
+		return fmt.Errorf("tokenSha256 must be a hex encoded SHA-256 hash")
+	}
+	return nil
+}
+
 // AppConfig holds application-wide configuration
 type AppConfig struct {
 	Port               int             `json:"port"`
@@ -90,6 +847,10 @@ type AppConfig struct {
 	WhitelistedDomains []string        `json:"whitelistedDomains"`
 	LogLevel           string          `json:"logLevel"`
 	RoughtimeConfig    RoughtimeConfig `json:"roughtimeConfig"`
+	SGXConfig          SGXConfig       `json:"sgxConfig"`
+	SigningKeyConfig   SigningKeyConfig `json:"signingKeyConfig"`
+	AleoNodeConfig     AleoNodeConfig  `json:"aleoNodeConfig"`
+	AdminConfig        AdminConfig     `json:"adminConfig"`
 }
 
 type TokenTradingPairs map[string][]string
@@ -97,6 +858,7 @@ type TokenTradingPairs map[string][]string
 var (
 	appConfigOnce sync.Once
 	appConfig     AppConfig
+	appConfigHash [32]byte
 	appConfigErr  error
 )
 
@@ -108,29 +870,35 @@ var (
 // GetAppConfig returns application configuration from embedded JSON file
 func GetAppConfig() AppConfig {
 	appConfigOnce.Do(func() {
-		appConfig, appConfigErr = loadAppConfigFromFS("config.json")
+		appConfig, appConfigHash, appConfigErr = loadAppConfigFromFS("config.json")
 	})
 	return appConfig
 }
 
+// GetAppConfigHash returns the SHA-256 hash of the embedded config file.
+func GetAppConfigHash() [32]byte {
+	GetAppConfig() // Ensure initialization
+	return appConfigHash
+}
+
 // GetAppConfigWithError returns the application configuration and any loading error.
 func GetAppConfigWithError() (AppConfig, error) {
 	GetAppConfig() // Ensure initialization
 	return appConfig, appConfigErr
 }
 
-func loadAppConfigFromFS(path string) (AppConfig, error) {
+func loadAppConfigFromFS(path string) (AppConfig, [32]byte, error) {
 	data, err := configFS.ReadFile(path)
 	if err != nil {
-		return AppConfig{}, fmt.Errorf("failed to read embedded app config file %s: %w", path, err)
+		return AppConfig{}, [32]byte{}, fmt.Errorf("failed to read embedded app config file %s: %w", path, err)
 	}
 
 	var config AppConfig
 	if err := json.Unmarshal(data, &config); err != nil {
-		return AppConfig{}, fmt.Errorf("failed to parse embedded app config file %s: %w", path, err)
+		return AppConfig{}, [32]byte{}, fmt.Errorf("failed to parse embedded app config file %s: %w", path, err)
 	}
 
-	return config, nil
+	return config, sha256.Sum256(data), nil
 }
 
 // GetWhitelistedDomains returns whitelisted domains from the app config
@@ -145,10 +913,25 @@ func GetExchangesConfigs() ExchangesConfig {
 	return appConfig.PriceFeedConfig.ExchangesConfig
 }
 
-// GetTokenExchanges returns the token exchanges from the app config
-func GetTokenExchanges() TokenExchanges {
+// GetTokenRegistry returns the price feed token registry from the app config
+func GetTokenRegistry() TokenRegistry {
 	appConfig := GetAppConfig()
-	return appConfig.PriceFeedConfig.TokenExchanges
+	return appConfig.PriceFeedConfig.Tokens
+}
+
+// GetTokenConfig returns the registry entry of a price feed token. The token symbol is case insensitive.
+func GetTokenConfig(token string) (TokenConfig, bool) {
+	tokenConfig, exists := GetTokenRegistry()[strings.ToUpper(token)]
+	return tokenConfig, exists
+}
+
+// GetTokenExchanges returns the exchanges of every token in the token registry
+func GetTokenExchanges() TokenExchanges {
+	tokenExchanges := make(TokenExchanges)
+	for token, tokenConfig := range GetTokenRegistry() {
+		tokenExchanges[token] = tokenConfig.Exchanges
+	}
+	return tokenExchanges
 }
 
 // GetMinExchangesRequired returns the minimum number of exchanges required from the app config
@@ -157,10 +940,47 @@ func GetMinExchangesRequired() int {
 	return appConfig.PriceFeedConfig.MinExchangesRequired
 }
 
-// func GetProvableBlockHeightURL() string {
-// 	appConfig := GetAppConfig()
-// 	return appConfig.PriceFeedConfig.ProvableBlockHeightURL
-// }
+// GetPriceSamplerConfig returns the price sampler config from the app config
+func GetPriceSamplerConfig() PriceSamplerConfig {
+	appConfig := GetAppConfig()
+	return appConfig.PriceFeedConfig.Sampler
+}
+
+// GetPriceCacheConfig returns the price cache config from the app config
+func GetPriceCacheConfig() PriceCacheConfig {
+	appConfig := GetAppConfig()
+	return appConfig.PriceFeedConfig.Cache
+}
+
+// GetExchangeHealthConfig returns the exchange health config from the app config
+func GetExchangeHealthConfig() ExchangeHealthConfig {
+	appConfig := GetAppConfig()
+	return appConfig.PriceFeedConfig.ExchangeHealth
+}
+
+// GetPriceGuardConfig returns the price guard config from the app config
+func GetPriceGuardConfig() PriceGuardConfig {
+	appConfig := GetAppConfig()
+	return appConfig.PriceFeedConfig.Guard
+}
+
+// GetAttestationSchedulerConfig returns the attestation scheduler config from the app config
+func GetAttestationSchedulerConfig() AttestationSchedulerConfig {
+	appConfig := GetAppConfig()
+	return appConfig.PriceFeedConfig.Scheduler
+}
+
+// GetAdminConfig returns the admin listener config from the app config
+func GetAdminConfig() AdminConfig {
+	appConfig := GetAppConfig()
+	return appConfig.AdminConfig
+}
+
+// GetAleoNodeConfig returns the Aleo node config from the app config
+func GetAleoNodeConfig() AleoNodeConfig {
+	appConfig := GetAppConfig()
+	return appConfig.AleoNodeConfig
+}
 
 func loadTokenTradingPairs() {
 	exchangesConfigs := GetExchangesConfigs()
@@ -179,9 +999,9 @@ func loadTokenTradingPairs() {
 			}
 			for _, symbol := range symbols {
 				if _, exists := tokenTradingPairs[token]; !exists {
-					tokenTradingPairs[token] = []string{symbol}
+					tokenTradingPairs[token] = []string{symbol.Symbol}
 				} else {
-					tokenTradingPairs[token] = append(tokenTradingPairs[token], symbol)
+					tokenTradingPairs[token] = append(tokenTradingPairs[token], symbol.Symbol)
 				}
 			}
 		}
@@ -195,17 +1015,28 @@ func GetTokenTradingPairs() TokenTradingPairs {
 	return tokenTradingPairs
 }
 
-func GetTokenVWAPConfig(token string) (TokenVWAPConfig, *appErrors.AppError) {
-	tokenVWAPConfigMap := GetAppConfig().PriceFeedConfig.TokenVWAPConfig
+func GetTokenAggregationConfig(token string) (TokenAggregationConfig, *appErrors.AppError) {
+	tokenAggregationConfigMap := GetAppConfig().PriceFeedConfig.TokenAggregationConfig
 
-	tokenVWAPConfig, exists := tokenVWAPConfigMap[token]; 
+	tokenAggregationConfig, exists := tokenAggregationConfigMap[token]; 
 	
 	if !exists {
-		logger.Error("Token VWAP config not found", "token", token)
-		return TokenVWAPConfig{}, appErrors.ErrTokenVWAPConfigNotFound
+		logger.Error("Token aggregation config not found", "token", token)
+		return TokenAggregationConfig{}, appErrors.ErrAggregationConfigNotFound
 	}
 
-	return tokenVWAPConfig, nil
+	return tokenAggregationConfig, nil
+}
+
+// GetTokenMaxAge returns the max age of the exchange responses of a token, DefaultMaxAge when the token has no
+// aggregation config.
+func GetTokenMaxAge(token string) time.Duration {
+	return GetAppConfig().PriceFeedConfig.TokenAggregationConfig[token].ResponseMaxAge()
+}
+
+// GetTokenGuardConfig returns the guard of a token, nil when the token has no guard.
+func GetTokenGuardConfig(token string) *TokenGuardConfig {
+	return GetAppConfig().PriceFeedConfig.TokenAggregationConfig[token].Guard
 }
 
 func GetRoughtimeConfig() RoughtimeConfig {
@@ -213,6 +1044,95 @@ func GetRoughtimeConfig() RoughtimeConfig {
 	return appConfig.RoughtimeConfig
 }
 
+// validateTokenRegistry checks the token registry entries and returns the validation errors.
+// Token IDs are written into a single byte of the attestation user data, so they must be unique and between 1 and MaxTokenID.
+func validateTokenRegistry(tokenRegistry TokenRegistry, exchangesConfigs ExchangesConfig) []string {
+	var errors []string
+
+	tokens := make([]string, 0, len(tokenRegistry))
+	for token := range tokenRegistry {
+		tokens = append(tokens, token)
+	}
+	// Sort the tokens so duplicate token IDs are reported consistently.
+	sort.Strings(tokens)
+
+	tokenIDs := make(map[int]string)
+	for _, token := range tokens {
+		tokenConfig := tokenRegistry[token]
+		if token != strings.ToUpper(token) {
+			errors = append(errors, fmt.Sprintf("Token %s: symbol must be upper case", token))
+		}
+		if tokenConfig.TokenID < 1 || tokenConfig.TokenID > MaxTokenID {
+			errors = append(errors, fmt.Sprintf("Token %s: tokenID=%d must be between 1 and %d", token, tokenConfig.TokenID, MaxTokenID))
+		} else if otherToken, exists := tokenIDs[tokenConfig.TokenID]; exists {
+			errors = append(errors, fmt.Sprintf("Token %s: tokenID=%d is already used by token %s", token, tokenConfig.TokenID, otherToken))
+		} else {
+			tokenIDs[tokenConfig.TokenID] = token
+		}
+		if tokenConfig.DefaultPrecision > encoding.ENCODING_OPTION_FLOAT_MAX_PRECISION {
+			errors = append(errors, fmt.Sprintf("Token %s: defaultPrecision=%d exceeds the max float precision=%d", token, tokenConfig.DefaultPrecision, encoding.ENCODING_OPTION_FLOAT_MAX_PRECISION))
+		}
+		if len(tokenConfig.Exchanges) == 0 {
+			errors = append(errors, fmt.Sprintf("Token %s: no exchanges configured", token))
+		}
+		// Check that all referenced exchanges exist in exchange configs
+		for _, exchange := range tokenConfig.Exchanges {
+			if _, exists := exchangesConfigs[exchange]; !exists {
+				errors = append(errors, fmt.Sprintf("Token %s: exchange %s not found in exchange configs", token, exchange))
+			}
+		}
+	}
+
+	return errors
+}
+
+// validateExchangeSymbols checks the trading pairs of an exchange and returns the validation errors.
+// A pair quoted in a token is converted into USD with the price of that token, so the quote token must be in the
+// token registry and all of its own pairs must be quoted in USD.
+func validateExchangeSymbols(exchangeKey string, symbols map[string][]SymbolConfig, tokenRegistry TokenRegistry, exchangesConfigs ExchangesConfig) []string {
+	var errors []string
+
+	tokens := make([]string, 0, len(symbols))
+	for token := range symbols {
+		tokens = append(tokens, token)
+	}
+	sort.Strings(tokens)
+
+	for _, token := range tokens {
+		for _, symbol := range symbols[token] {
+			if symbol.Symbol == "" {
+				errors = append(errors, fmt.Sprintf("Exchange %s: token %s has a pair without symbol", exchangeKey, token))
+				continue
+			}
+			if symbol.Quote == "" {
+				errors = append(errors, fmt.Sprintf("Exchange %s: symbol %s has no quote", exchangeKey, symbol.Symbol))
+				continue
+			}
+			if symbol.Quote == USDQuote {
+				continue
+			}
+			if symbol.Quote == token {
+				errors = append(errors, fmt.Sprintf("Exchange %s: symbol %s is quoted in its own token %s", exchangeKey, symbol.Symbol, token))
+				continue
+			}
+			quoteConfig, exists := tokenRegistry[symbol.Quote]
+			if !exists {
+				errors = append(errors, fmt.Sprintf("Exchange %s: quote %s of symbol %s is not in the token registry", exchangeKey, symbol.Quote, symbol.Symbol))
+				continue
+			}
+			for _, quoteExchange := range quoteConfig.Exchanges {
+				for _, quoteSymbol := range exchangesConfigs[quoteExchange].Symbols[symbol.Quote] {
+					if quoteSymbol.Quote != USDQuote {
+						errors = append(errors, fmt.Sprintf("Exchange %s: quote %s of symbol %s has the %s pair %s not quoted in %s", exchangeKey, symbol.Quote, symbol.Symbol, quoteExchange, quoteSymbol.Symbol, USDQuote))
+					}
+				}
+			}
+		}
+	}
+
+	return errors
+}
+
 // ValidateConfigs validates that all configurations loaded correctly
 // Should be called during server startup to catch configuration errors early
 func ValidateConfigs() error {
@@ -226,15 +1146,11 @@ func ValidateConfigs() error {
 		errors = append(errors, "No whitelisted domains found in app config")
 	}
 
-	// if appConfig.PriceFeedConfig.ProvableBlockHeightURL == "" {
-	// 	errors = append(errors, "Provable block height URL is not set")
-	// }
-
 	var exchangeKeys []string
 	var tokenKeys []string
 
 	exchangesConfigs := appConfig.PriceFeedConfig.ExchangesConfig
-	tokenExchanges := appConfig.PriceFeedConfig.TokenExchanges
+	tokenRegistry := appConfig.PriceFeedConfig.Tokens
 	minExchangesRequired := appConfig.PriceFeedConfig.MinExchangesRequired
 
 	if minExchangesRequired < 1 {
@@ -245,8 +1161,8 @@ func ValidateConfigs() error {
 		errors = append(errors, "No exchange configurations found")
 	}
 
-	if len(tokenExchanges) == 0 {
-		errors = append(errors, "No token exchanges found")
+	if len(tokenRegistry) == 0 {
+		errors = append(errors, "No tokens found in token registry")
 	}
 
 	for exchangeKey, config := range exchangesConfigs {
@@ -264,33 +1180,52 @@ func ValidateConfigs() error {
 		} else if !strings.Contains(config.EndpointTemplate, "{symbol}") {
 			errors = append(errors, fmt.Sprintf("Exchange %s: endpointTemplate must include {symbol} placeholder", exchangeKey))
 		}
-		exchangeKeys = append(exchangeKeys, exchangeKey)
-	}
-
-	// Validate symbol exchanges mapping
-	for token, exchanges := range tokenExchanges {
-		if len(exchanges) == 0 {
-			errors = append(errors, fmt.Sprintf("Token %s: no exchanges configured", token))
+		errors = append(errors, validateExchangeSymbols(exchangeKey, config.Symbols, tokenRegistry, exchangesConfigs)...)
+		if config.ResponseFormat != nil {
+			if config.Adapter != "" {
+				errors = append(errors, fmt.Sprintf("Exchange %s: adapter and responseFormat are mutually exclusive", exchangeKey))
+			}
+			if err := config.ResponseFormat.Validate(); err != nil {
+				errors = append(errors, fmt.Sprintf("Exchange %s: invalid responseFormat: %v", exchangeKey, err))
+			}
 		}
-		// Check that all referenced exchanges exist in exchange configs
-		for _, exchange := range exchanges {
-			if _, exists := exchangesConfigs[exchange]; !exists {
-				errors = append(errors, fmt.Sprintf("Token %s: exchange %s not found in exchange configs", token, exchange))
+		if config.RateLimit != nil {
+			if err := config.RateLimit.Validate(); err != nil {
+				errors = append(errors, fmt.Sprintf("Exchange %s: invalid rateLimit: %v", exchangeKey, err))
+			}
+		}
+		if config.Freshness != nil {
+			if err := config.Freshness.Validate(); err != nil {
+				errors = append(errors, fmt.Sprintf("Exchange %s: invalid freshness: %v", exchangeKey, err))
 			}
 		}
+		if config.ResponseFormat != nil && config.ResponseFormat.TimestampPath == "" && config.FreshnessSource() == FreshnessSourceResponse {
+			errors = append(errors, fmt.Sprintf("Exchange %s: responseFormat has no timestampPath for the %s freshness source", exchangeKey, FreshnessSourceResponse))
+		}
+		exchangeKeys = append(exchangeKeys, exchangeKey)
+	}
+
+	// Validate token registry
+	errors = append(errors, validateTokenRegistry(tokenRegistry, exchangesConfigs)...)
+	for token := range tokenRegistry {
 		tokenKeys = append(tokenKeys, token)
 	}
 
+	// Ensure every token keeps enough enabled exchanges to reach its minExchangesRequired
 	for _, token := range tokenKeys {
-		_, err := GetTokenVWAPConfig(token)
+		tokenAggregationConfig, err := GetTokenAggregationConfig(token)
 		if err != nil {
 			errors = append(errors, fmt.Sprintf("Token %s: %v", token, err))
-		}
-	}
-	// Ensure minExchangesRequired is not greater than the number of configured exchanges for any token
-	for token, exchanges := range tokenExchanges {
-		if len(exchanges) < minExchangesRequired {
-			errors = append(errors, fmt.Sprintf("Token %s: minExchangesRequired=%d exceeds configured exchanges=%d", token, minExchangesRequired, len(exchanges)))
+		} else if err := tokenAggregationConfig.Validate(); err != nil {
+			errors = append(errors, fmt.Sprintf("Token %s: invalid aggregation config: %v", token, err))
+		} else if err := tokenAggregationConfig.ValidateExchanges(tokenRegistry[token], minExchangesRequired); err != nil {
+			errors = append(errors, fmt.Sprintf("Token %s: invalid aggregation config: %v", token, err))
+		} else if err := tokenAggregationConfig.ParseMaxAgeString(); err != nil {
+			errors = append(errors, fmt.Sprintf("Token %s: invalid maxAgeString: %v", token, err))
+		} else if err := tokenAggregationConfig.Guard.ParseMaxChangeIntervalString(); err != nil {
+			errors = append(errors, fmt.Sprintf("Token %s: invalid guard maxChangeIntervalString: %v", token, err))
+		} else {
+			appConfig.PriceFeedConfig.TokenAggregationConfig[token] = tokenAggregationConfig
 		}
 	}
 	// Validate roughtime config
@@ -304,30 +1239,217 @@ func ValidateConfigs() error {
 		errors = append(errors, "Roughtime timeout is not set")
 	}
 
-	if roughtimeConfig.ServerConfig.Server.Name == "" {
-		errors = append(errors, "Roughtime server name is not set")
+	if len(roughtimeConfig.ServerConfigs) == 0 {
+		errors = append(errors, "No roughtime servers configured")
 	}
 
-	if len(roughtimeConfig.ServerConfig.Server.Addresses) == 0 {
-		errors = append(errors, "No roughtime server addresses configured")
+	if roughtimeConfig.Quorum < 1 || roughtimeConfig.Quorum > len(roughtimeConfig.ServerConfigs) {
+		errors = append(errors, fmt.Sprintf("Roughtime quorum=%d must be between 1 and the number of servers=%d", roughtimeConfig.Quorum, len(roughtimeConfig.ServerConfigs)))
 	}
 
-	if roughtimeConfig.ServerConfig.Server.PublicKeyType == "" {
-		errors = append(errors, "Roughtime server public key type is not set")
-	}
+	roughtimeServerNames := make(map[string]bool)
+	for i := range roughtimeConfig.ServerConfigs {
+		serverConfig := &roughtimeConfig.ServerConfigs[i]
+		if serverConfig.Server == nil {
+			errors = append(errors, fmt.Sprintf("Roughtime server %d: missing server config", i))
+			continue
+		}
 
-	if roughtimeConfig.ServerConfig.PublicKeyBase64 == "" {
-		errors = append(errors, "Roughtime server public key is not set")
+		name := serverConfig.Server.Name
+		if name == "" {
+			errors = append(errors, fmt.Sprintf("Roughtime server %d: name is not set", i))
+		} else if roughtimeServerNames[name] {
+			errors = append(errors, fmt.Sprintf("Roughtime server %s: duplicate server name", name))
+		}
+		roughtimeServerNames[name] = true
+
+		if len(serverConfig.Server.Addresses) == 0 {
+			errors = append(errors, fmt.Sprintf("Roughtime server %s: no addresses configured", name))
+		}
+
+		if serverConfig.Server.PublicKeyType == "" {
+			errors = append(errors, fmt.Sprintf("Roughtime server %s: public key type is not set", name))
+		}
+
+		if serverConfig.PublicKeyBase64 == "" {
+			errors = append(errors, fmt.Sprintf("Roughtime server %s: public key is not set", name))
+		} else if err := serverConfig.DecodePublicKey(); err != nil {
+			errors = append(errors, fmt.Sprintf("Failed to decode roughtime server %s public key: %v", name, err))
+		}
 	}
 
-	err := roughtimeConfig.ServerConfig.DecodePublicKey()
+	err := roughtimeConfig.ParseTimeoutString()
 	if err != nil {
-		errors = append(errors, fmt.Sprintf("Failed to decode roughtime server public key: %v", err))
+		errors = append(errors, fmt.Sprintf("Failed to decode roughtime timeout: %v", err))
 	}
 
-	err = roughtimeConfig.ParseTimeoutString()
+	// Validate signing key config
+	signingKeyConfig := &appConfig.SigningKeyConfig
+
+	err = signingKeyConfig.ParseRotationOverlapString()
 	if err != nil {
-		errors = append(errors, fmt.Sprintf("Failed to decode roughtime timeout: %v", err))
+		errors = append(errors, fmt.Sprintf("Failed to decode signing key rotation overlap: %v", err))
+	} else if signingKeyConfig.RotationOverlap <= 0 {
+		errors = append(errors, "Signing key rotation overlap must be positive")
+	}
+
+	// Validate admin config
+	if adminConfig := appConfig.AdminConfig; adminConfig.Enabled() {
+		if err := adminConfig.Validate(); err != nil {
+			errors = append(errors, fmt.Sprintf("Invalid admin config: %v", err))
+		} else if adminConfig.Port == appConfig.Port || adminConfig.Port == appConfig.MetricsPort {
+			errors = append(errors, fmt.Sprintf("Admin port=%d must differ from the notarization and metrics ports", adminConfig.Port))
+		}
+	}
+
+	// Validate Aleo node config
+	aleoNodeConfig := &appConfig.AleoNodeConfig
+
+	if aleoNodeConfig.Enabled {
+		if len(aleoNodeConfig.Endpoints) == 0 {
+			errors = append(errors, "No Aleo node endpoints configured")
+		}
+
+		if aleoNodeConfig.Quorum < 1 || aleoNodeConfig.Quorum > len(aleoNodeConfig.Endpoints) {
+			errors = append(errors, fmt.Sprintf("Aleo node quorum=%d must be between 1 and the number of endpoints=%d", aleoNodeConfig.Quorum, len(aleoNodeConfig.Endpoints)))
+		}
+
+		if aleoNodeConfig.MaxHeightDifference < 0 {
+			errors = append(errors, "Aleo node max height difference must not be negative")
+		}
+
+		aleoNodeEndpoints := make(map[string]bool)
+		for i, endpoint := range aleoNodeConfig.Endpoints {
+			parsedEndpoint, err := url.Parse(endpoint)
+			if err != nil || parsedEndpoint.Host == "" || (parsedEndpoint.Scheme != "https" && parsedEndpoint.Scheme != "http") {
+				errors = append(errors, fmt.Sprintf("Aleo node endpoint %d: %q is not an http(s) URL", i, endpoint))
+			} else if aleoNodeEndpoints[endpoint] {
+				errors = append(errors, fmt.Sprintf("Aleo node endpoint %d: duplicate endpoint %s", i, endpoint))
+			}
+			aleoNodeEndpoints[endpoint] = true
+		}
+
+		if err := aleoNodeConfig.ParseTimeoutString(); err != nil {
+			errors = append(errors, fmt.Sprintf("Failed to decode Aleo node timeout: %v", err))
+		} else if aleoNodeConfig.Timeout <= 0 {
+			errors = append(errors, "Aleo node timeout must be positive")
+		}
+	}
+
+	// Validate price sampler config
+	samplerConfig := &appConfig.PriceFeedConfig.Sampler
+
+	if samplerConfig.Enabled {
+		for _, token := range samplerConfig.Tokens {
+			if _, exists := tokenRegistry[token]; !exists {
+				errors = append(errors, fmt.Sprintf("Price sampler: token %s not found in the token registry", token))
+			}
+		}
+
+		if samplerConfig.Capacity < 1 {
+			errors = append(errors, "Price sampler capacity must be positive")
+		}
+
+		if samplerConfig.MinCoveragePercent < 0 || samplerConfig.MinCoveragePercent > 100 {
+			errors = append(errors, fmt.Sprintf("Price sampler minCoveragePercent=%v must be between 0 and 100", samplerConfig.MinCoveragePercent))
+		}
+
+		if err := samplerConfig.ParseIntervalString(); err != nil {
+			errors = append(errors, fmt.Sprintf("Failed to decode price sampler interval: %v", err))
+		} else if samplerConfig.Interval <= 0 {
+			errors = append(errors, "Price sampler interval must be positive")
+		}
+	}
+
+	// Validate price cache config
+	cacheConfig := &appConfig.PriceFeedConfig.Cache
+
+	if cacheConfig.Enabled {
+		if err := cacheConfig.ParseTTLString(); err != nil {
+			errors = append(errors, fmt.Sprintf("Failed to decode price cache TTL: %v", err))
+		} else if cacheConfig.TTL <= 0 {
+			errors = append(errors, "Price cache TTL must be positive")
+		}
+	}
+
+	// Validate exchange health config
+	healthConfig := &appConfig.PriceFeedConfig.ExchangeHealth
+
+	if healthConfig.Enabled {
+		if healthConfig.WindowSize < 1 {
+			errors = append(errors, "Exchange health windowSize must be positive")
+		}
+
+		if healthConfig.MinRequests < 1 || healthConfig.MinRequests > healthConfig.WindowSize {
+			errors = append(errors, fmt.Sprintf("Exchange health minRequests=%d must be between 1 and windowSize=%d", healthConfig.MinRequests, healthConfig.WindowSize))
+		}
+
+		if healthConfig.MaxErrorRatePercent <= 0 || healthConfig.MaxErrorRatePercent > 100 {
+			errors = append(errors, fmt.Sprintf("Exchange health maxErrorRatePercent=%v must be in (0, 100]", healthConfig.MaxErrorRatePercent))
+		}
+
+		if healthConfig.MaxOutlierRatePercent <= 0 || healthConfig.MaxOutlierRatePercent > 100 {
+			errors = append(errors, fmt.Sprintf("Exchange health maxOutlierRatePercent=%v must be in (0, 100]", healthConfig.MaxOutlierRatePercent))
+		}
+
+		if err := healthConfig.ParseMaxLatencyString(); err != nil {
+			errors = append(errors, fmt.Sprintf("Failed to decode exchange health max latency: %v", err))
+		} else if healthConfig.MaxLatency <= 0 {
+			errors = append(errors, "Exchange health max latency must be positive")
+		}
+
+		if err := healthConfig.ParseOpenDurationString(); err != nil {
+			errors = append(errors, fmt.Sprintf("Failed to decode exchange health open duration: %v", err))
+		} else if healthConfig.OpenDuration <= 0 {
+			errors = append(errors, "Exchange health open duration must be positive")
+		}
+	}
+
+	// Validate price guard config
+	if stateFile := appConfig.PriceFeedConfig.Guard.StateFile; stateFile != "" && !filepath.IsAbs(stateFile) {
+		errors = append(errors, fmt.Sprintf("Price guard stateFile=%s must be an absolute path", stateFile))
+	}
+
+	// Validate attestation scheduler config
+	schedulerConfig := &appConfig.PriceFeedConfig.Scheduler
+
+	if schedulerConfig.Enabled {
+		if stateFile := schedulerConfig.StateFile; stateFile != "" && !filepath.IsAbs(stateFile) {
+			errors = append(errors, fmt.Sprintf("Attestation scheduler stateFile=%s must be an absolute path", stateFile))
+		}
+
+		if err := schedulerConfig.ParseCheckIntervalString(); err != nil {
+			errors = append(errors, fmt.Sprintf("Failed to decode attestation scheduler check interval: %v", err))
+		} else if schedulerConfig.CheckInterval <= 0 {
+			errors = append(errors, "Attestation scheduler check interval must be positive")
+		}
+
+		if len(schedulerConfig.Tokens) == 0 {
+			errors = append(errors, "Attestation scheduler: no tokens found")
+		}
+
+		scheduledTokens := make(map[string]bool)
+		for i := range schedulerConfig.Tokens {
+			tokenConfig := &schedulerConfig.Tokens[i]
+			if _, exists := tokenRegistry[tokenConfig.Token]; !exists {
+				errors = append(errors, fmt.Sprintf("Attestation scheduler: token %s not found in the token registry", tokenConfig.Token))
+			} else if scheduledTokens[tokenConfig.Token] {
+				errors = append(errors, fmt.Sprintf("Attestation scheduler: duplicate token %s", tokenConfig.Token))
+			}
+			scheduledTokens[tokenConfig.Token] = true
+
+			if err := tokenConfig.Validate(); err != nil {
+				errors = append(errors, fmt.Sprintf("Attestation scheduler: token %s: %v", tokenConfig.Token, err))
+			} else if err := tokenConfig.ParseHeartbeatString(); err != nil {
+				errors = append(errors, fmt.Sprintf("Attestation scheduler: token %s: failed to decode heartbeat: %v", tokenConfig.Token, err))
+			}
+		}
+
+		for i := range schedulerConfig.Sinks {
+			if err := schedulerConfig.Sinks[i].Validate(); err != nil {
+				errors = append(errors, fmt.Sprintf("Attestation scheduler: sink %d: %v", i, err))
+			}
+		}
 	}
 
 	// Return combined error if any validation failed
@@ -335,7 +1457,7 @@ func ValidateConfigs() error {
 		return fmt.Errorf("configuration validation failed:\n%s", strings.Join(errors, "\n"))
 	}
 
-	logger.Info("Configuration validation passed", "exchange_count", len(exchangesConfigs), "token_count", len(tokenExchanges), "exchanges", strings.Join(exchangeKeys, ","), "tokens", strings.Join(tokenKeys, ","))
+	logger.Info("Configuration validation passed", "exchange_count", len(exchangesConfigs), "token_count", len(tokenRegistry), "exchanges", strings.Join(exchangeKeys, ","), "tokens", strings.Join(tokenKeys, ","))
 
 	return nil
 }
@@ -2,6 +2,7 @@ package configs
 
 import (
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -47,3 +48,406 @@ func TestValidateConfigs(t *testing.T) {
 		assert.NotContains(t, exchangeConfigs, invalid, "Invalid exchange %s found in configs", invalid)
 	}
 }
+
+func TestExchangeResponseFormatValidate(t *testing.T) {
+	testCases := []struct {
+		name        string
+		format      ExchangeResponseFormat
+		expectedErr string
+	}{
+		{name: "minimal", format: ExchangeResponseFormat{PricePath: "last", VolumePath: "volume"}},
+		{name: "complete", format: ExchangeResponseFormat{PricePath: "last", VolumePath: "volume", SymbolPath: "symbol", TimestampPath: "time", TimestampUnit: TimestampUnitRFC3339, VolumeDenomination: VolumeDenominationQuote}},
+		{name: "missing price path", format: ExchangeResponseFormat{VolumePath: "volume"}, expectedErr: "missing pricePath"},
+		{name: "missing volume path", format: ExchangeResponseFormat{PricePath: "last"}, expectedErr: "missing volumePath"},
+		{name: "missing timestamp unit", format: ExchangeResponseFormat{PricePath: "last", VolumePath: "volume", TimestampPath: "time"}, expectedErr: "invalid timestampUnit"},
+		{name: "timestamp unit without path", format: ExchangeResponseFormat{PricePath: "last", VolumePath: "volume", TimestampUnit: TimestampUnitSeconds}, expectedErr: "timestampUnit is set without timestampPath"},
+		{name: "invalid volume denomination", format: ExchangeResponseFormat{PricePath: "last", VolumePath: "volume", VolumeDenomination: "usd"}, expectedErr: "invalid volumeDenomination"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.format.Validate()
+			if tc.expectedErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tc.expectedErr)
+		})
+	}
+}
+
+func TestExchangeRateLimitValidate(t *testing.T) {
+	testCases := []struct {
+		name           string
+		rateLimit      ExchangeRateLimit
+		expectedWindow time.Duration
+		expectedErr    string
+	}{
+		{name: "bucket only", rateLimit: ExchangeRateLimit{RequestsPerSecond: 5, Burst: 10}},
+		{name: "used weight header", rateLimit: ExchangeRateLimit{RequestsPerSecond: 20, Burst: 40, WeightPerCall: 2, UsedWeightHeader: "X-Mbx-Used-Weight-1m", MaxUsedWeight: 5000, UsedWeightWindowString: "1m"}, expectedWindow: time.Minute},
+		{name: "missing rate", rateLimit: ExchangeRateLimit{Burst: 10}, expectedErr: "requestsPerSecond=0 must be positive"},
+		{name: "burst below the call weight", rateLimit: ExchangeRateLimit{RequestsPerSecond: 5, Burst: 1, WeightPerCall: 2}, expectedErr: "burst=1 must be at least the weight per call 2"},
+		{name: "negative weight", rateLimit: ExchangeRateLimit{RequestsPerSecond: 5, Burst: 10, WeightPerCall: -1}, expectedErr: "weightPerCall=-1 must not be negative"},
+		{name: "max used weight without header", rateLimit: ExchangeRateLimit{RequestsPerSecond: 5, Burst: 10, MaxUsedWeight: 100}, expectedErr: "set without usedWeightHeader"},
+		{name: "missing max used weight", rateLimit: ExchangeRateLimit{RequestsPerSecond: 5, Burst: 10, UsedWeightHeader: "X-Used", UsedWeightWindowString: "1m"}, expectedErr: "maxUsedWeight=0 must be positive"},
+		{name: "invalid window", rateLimit: ExchangeRateLimit{RequestsPerSecond: 5, Burst: 10, UsedWeightHeader: "X-Used", MaxUsedWeight: 100, UsedWeightWindowString: "minute"}, expectedErr: "invalid usedWeightWindowString"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.rateLimit.Validate()
+			if tc.expectedErr == "" {
+				assert.NoError(t, err)
+				assert.Equal(t, tc.expectedWindow, tc.rateLimit.UsedWeightWindow)
+				return
+			}
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tc.expectedErr)
+		})
+	}
+}
+
+func TestExchangeFreshnessValidate(t *testing.T) {
+	testCases := []struct {
+		name        string
+		freshness   ExchangeFreshness
+		expectedErr string
+	}{
+		{name: "default source", freshness: ExchangeFreshness{}},
+		{name: "date header", freshness: ExchangeFreshness{Source: FreshnessSourceDateHeader}},
+		{name: "none", freshness: ExchangeFreshness{Source: FreshnessSourceNone}},
+		{name: "server time", freshness: ExchangeFreshness{Source: FreshnessSourceServerTime, ServerTimeEndpoint: "/0/public/Time", ServerTimePath: "result.unixtime", ServerTimeUnit: TimestampUnitSeconds}},
+		{name: "unknown source", freshness: ExchangeFreshness{Source: "ntp"}, expectedErr: `invalid source "ntp"`},
+		{name: "server time endpoint with another source", freshness: ExchangeFreshness{Source: FreshnessSourceDateHeader, ServerTimeEndpoint: "/time"}, expectedErr: "server time settings are only used by the server_time source"},
+		{name: "server time endpoint without leading slash", freshness: ExchangeFreshness{Source: FreshnessSourceServerTime, ServerTimeEndpoint: "time", ServerTimePath: "time", ServerTimeUnit: TimestampUnitMilliseconds}, expectedErr: `serverTimeEndpoint="time" must be a path starting with /`},
+		{name: "missing server time path", freshness: ExchangeFreshness{Source: FreshnessSourceServerTime, ServerTimeEndpoint: "/time", ServerTimeUnit: TimestampUnitMilliseconds}, expectedErr: "missing serverTimePath"},
+		{name: "missing server time unit", freshness: ExchangeFreshness{Source: FreshnessSourceServerTime, ServerTimeEndpoint: "/time", ServerTimePath: "time"}, expectedErr: "invalid serverTimeUnit"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.freshness.Validate()
+			if tc.expectedErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tc.expectedErr)
+		})
+	}
+
+	assert.Equal(t, FreshnessSourceResponse, ExchangeConfig{}.FreshnessSource())
+	assert.Equal(t, FreshnessSourceDateHeader, ExchangeConfig{Freshness: &ExchangeFreshness{Source: FreshnessSourceDateHeader}}.FreshnessSource())
+}
+
+func TestTokenAggregationConfigParseMaxAgeString(t *testing.T) {
+	testCases := []struct {
+		name           string
+		maxAgeString   string
+		expectedMaxAge time.Duration
+		expectedErr    string
+	}{
+		{name: "default", expectedMaxAge: DefaultMaxAge},
+		{name: "custom", maxAgeString: "90s", expectedMaxAge: 90 * time.Second},
+		{name: "invalid", maxAgeString: "soon", expectedErr: "invalid duration"},
+		{name: "zero", maxAgeString: "0s", expectedErr: "maxAgeString=0s must be positive"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			config := TokenAggregationConfig{MaxAgeString: tc.maxAgeString}
+			err := config.ParseMaxAgeString()
+			if tc.expectedErr == "" {
+				assert.NoError(t, err)
+				assert.Equal(t, tc.expectedMaxAge, config.MaxAge)
+				assert.Equal(t, tc.expectedMaxAge, config.ResponseMaxAge())
+				return
+			}
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tc.expectedErr)
+		})
+	}
+
+	assert.Equal(t, 10*time.Minute, TokenAggregationConfig{}.ResponseMaxAge())
+}
+
+func TestTokenAggregationConfigValidate(t *testing.T) {
+	testCases := []struct {
+		name        string
+		config      TokenAggregationConfig
+		expectedErr string
+	}{
+		{name: "default vwap", config: TokenAggregationConfig{TokenMaxExchangeWeightPercent: 50}},
+		{name: "weighted median", config: TokenAggregationConfig{Method: AggregationMethodWeightedMedian, TokenMaxExchangeWeightPercent: 100}},
+		{name: "trimmed mean", config: TokenAggregationConfig{Method: AggregationMethodTrimmedMean, TrimPercent: 20}},
+		{name: "unknown method", config: TokenAggregationConfig{Method: "mean"}, expectedErr: `invalid method "mean"`},
+		{name: "missing max weight", config: TokenAggregationConfig{Method: AggregationMethodVWAP}, expectedErr: "tokenMaxExchangeWeightPercent=0 must be in (0, 100]"},
+		{name: "trimming half of the prices", config: TokenAggregationConfig{Method: AggregationMethodTrimmedMean, TrimPercent: 50}, expectedErr: "trimPercent=50 must be in [0, 50)"},
+		{name: "trim percent with vwap", config: TokenAggregationConfig{TokenMaxExchangeWeightPercent: 50, TrimPercent: 10}, expectedErr: "trimPercent is only used by the trimmed_mean method"},
+		{name: "negative min volume", config: TokenAggregationConfig{Method: AggregationMethodTrimmedMean, TokenMinVolumeUSDPerExchange: -1}, expectedErr: "tokenMinVolumeUSDPerExchange=-1 must not be negative"},
+		{name: "negative min exchanges", config: TokenAggregationConfig{TokenMaxExchangeWeightPercent: 50, MinExchangesRequired: -1}, expectedErr: "minExchangesRequired=-1 must not be negative"},
+		{name: "exchange settings", config: TokenAggregationConfig{TokenMaxExchangeWeightPercent: 50, Exchanges: map[string]TokenExchangeConfig{"xt": {TrustWeight: 0.5, MaxWeightPercent: 20}, "gate": {Disabled: true}}}},
+		{name: "negative trust weight", config: TokenAggregationConfig{TokenMaxExchangeWeightPercent: 50, Exchanges: map[string]TokenExchangeConfig{"xt": {TrustWeight: -1}}}, expectedErr: "exchange xt: trustWeight=-1 must not be negative"},
+		{name: "exchange weight cap above 100", config: TokenAggregationConfig{TokenMaxExchangeWeightPercent: 50, Exchanges: map[string]TokenExchangeConfig{"xt": {MaxWeightPercent: 150}}}, expectedErr: "exchange xt: maxWeightPercent=150 must be in [0, 100]"},
+		{name: "unverified freshness weight", config: TokenAggregationConfig{TokenMaxExchangeWeightPercent: 50, UnverifiedFreshnessWeight: 0.25}},
+		{name: "unverified freshness weight above 1", config: TokenAggregationConfig{TokenMaxExchangeWeightPercent: 50, UnverifiedFreshnessWeight: 2}, expectedErr: "unverifiedFreshnessWeight=2 must be in [0, 1]"},
+		{name: "exchange weight cap with trimmed mean", config: TokenAggregationConfig{Method: AggregationMethodTrimmedMean, Exchanges: map[string]TokenExchangeConfig{"xt": {MaxWeightPercent: 20}}}, expectedErr: "exchange xt: maxWeightPercent is not used by the trimmed_mean method"},
+		{name: "guard", config: TokenAggregationConfig{TokenMaxExchangeWeightPercent: 50, Guard: &TokenGuardConfig{PegLowerBound: 0.97, PegUpperBound: 1.03}}},
+		{name: "invalid guard", config: TokenAggregationConfig{TokenMaxExchangeWeightPercent: 50, Guard: &TokenGuardConfig{Policy: "warn"}}, expectedErr: `invalid guard: invalid policy "warn"`},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.config.Validate()
+			if tc.expectedErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tc.expectedErr)
+		})
+	}
+}
+
+func TestTokenGuardConfigValidate(t *testing.T) {
+	testCases := []struct {
+		name        string
+		config      TokenGuardConfig
+		expectedErr string
+	}{
+		{name: "empty"},
+		{name: "max change", config: TokenGuardConfig{Policy: GuardPolicyFlag, MaxChangePercent: 10, MaxChangeIntervalString: "1h"}},
+		{name: "peg band", config: TokenGuardConfig{PegLowerBound: 0.97, PegUpperBound: 1.03}},
+		{name: "upper bound only", config: TokenGuardConfig{PegUpperBound: 1.03}},
+		{name: "unknown policy", config: TokenGuardConfig{Policy: "warn"}, expectedErr: `invalid policy "warn"`},
+		{name: "negative max change", config: TokenGuardConfig{MaxChangePercent: -1}, expectedErr: "maxChangePercent=-1 must not be negative"},
+		{name: "max change without interval", config: TokenGuardConfig{MaxChangePercent: 10}, expectedErr: "maxChangeIntervalString is required"},
+		{name: "inverted peg band", config: TokenGuardConfig{PegLowerBound: 1.03, PegUpperBound: 0.97}, expectedErr: "must satisfy 0 <= pegLowerBound < pegUpperBound"},
+		{name: "lower bound only", config: TokenGuardConfig{PegLowerBound: 0.97}, expectedErr: "must satisfy 0 <= pegLowerBound < pegUpperBound"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.config.Validate()
+			if tc.expectedErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tc.expectedErr)
+		})
+	}
+
+	assert.Equal(t, GuardPolicyReject, TokenGuardConfig{}.GuardPolicy())
+}
+
+func TestTokenGuardConfigParseMaxChangeIntervalString(t *testing.T) {
+	config := &TokenGuardConfig{MaxChangeIntervalString: "30m"}
+	require.NoError(t, config.ParseMaxChangeIntervalString())
+	assert.Equal(t, 30*time.Minute, config.MaxChangeInterval)
+
+	config = &TokenGuardConfig{MaxChangeIntervalString: "0s"}
+	assert.EqualError(t, config.ParseMaxChangeIntervalString(), "maxChangeIntervalString=0s must be positive")
+
+	var noGuard *TokenGuardConfig
+	assert.NoError(t, noGuard.ParseMaxChangeIntervalString())
+}
+
+func TestAdminConfigValidate(t *testing.T) {
+	tokenHash := "a9f3b1c6e0d4a3b2c1d0e9f8a7b6c5d4e3f2a1b0c9d8e7f6a5b4c3d2e1f0a9b8"
+
+	assert.False(t, AdminConfig{Port: 8002}.Enabled())
+	assert.True(t, AdminConfig{Port: 8002, TokenSha256: tokenHash}.Enabled())
+
+	assert.NoError(t, AdminConfig{Port: 8002, TokenSha256: tokenHash}.Validate())
+	assert.EqualError(t, AdminConfig{Port: 0, TokenSha256: tokenHash}.Validate(), "port=0 must be between 1 and 65535")
+	assert.EqualError(t, AdminConfig{Port: 8002, TokenSha256: "admin-secret"}.Validate(), "tokenSha256 must be a hex encoded SHA-256 hash")
+	assert.EqualError(t, AdminConfig{Port: 8002, TokenSha256: tokenHash[:32]}.Validate(), "tokenSha256 must be a hex encoded SHA-256 hash")
+}
+
+func TestScheduledTokenConfigValidate(t *testing.T) {
+	testCases := []struct {
+		name        string
+		config      ScheduledTokenConfig
+		expectedErr string
+	}{
+		{name: "deviation and heartbeat", config: ScheduledTokenConfig{Token: "BTC", DeviationPercent: 0.5, HeartbeatString: "1h"}},
+		{name: "deviation only", config: ScheduledTokenConfig{Token: "BTC", DeviationPercent: 0.5}},
+		{name: "heartbeat only", config: ScheduledTokenConfig{Token: "BTC", HeartbeatString: "1h", Precision: 8}},
+		{name: "no trigger", config: ScheduledTokenConfig{Token: "BTC"}, expectedErr: "deviationPercent or heartbeatString is required"},
+		{name: "negative deviation", config: ScheduledTokenConfig{Token: "BTC", DeviationPercent: -1}, expectedErr: "deviationPercent=-1 must not be negative"},
+		{name: "precision too high", config: ScheduledTokenConfig{Token: "BTC", HeartbeatString: "1h", Precision: 13}, expectedErr: "precision=13 must not exceed 12"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.config.Validate()
+			if tc.expectedErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tc.expectedErr)
+		})
+	}
+}
+
+func TestScheduledTokenConfigParseHeartbeatString(t *testing.T) {
+	config := &ScheduledTokenConfig{HeartbeatString: "1h"}
+	require.NoError(t, config.ParseHeartbeatString())
+	assert.Equal(t, time.Hour, config.Heartbeat)
+
+	config = &ScheduledTokenConfig{HeartbeatString: "-1m"}
+	assert.EqualError(t, config.ParseHeartbeatString(), "heartbeatString=-1m must be positive")
+
+	config = &ScheduledTokenConfig{}
+	require.NoError(t, config.ParseHeartbeatString())
+	assert.Zero(t, config.Heartbeat)
+}
+
+func TestAttestationSinkConfigValidate(t *testing.T) {
+	testCases := []struct {
+		name        string
+		config      AttestationSinkConfig
+		expectedErr string
+	}{
+		{name: "webhook", config: AttestationSinkConfig{Type: SinkTypeWebhook, URL: "https://example.com/hook", TimeoutString: "5s"}},
+		{name: "file", config: AttestationSinkConfig{Type: SinkTypeFile, Path: "/data/attestations.jsonl"}},
+		{name: "unknown type", config: AttestationSinkConfig{Type: "kafka"}, expectedErr: `invalid type "kafka"`},
+		{name: "webhook without scheme", config: AttestationSinkConfig{Type: SinkTypeWebhook, URL: "example.com/hook", TimeoutString: "5s"}, expectedErr: "is not an http(s) URL"},
+		{name: "webhook without timeout", config: AttestationSinkConfig{Type: SinkTypeWebhook, URL: "https://example.com/hook"}, expectedErr: "invalid timeoutString"},
+		{name: "webhook with zero timeout", config: AttestationSinkConfig{Type: SinkTypeWebhook, URL: "https://example.com/hook", TimeoutString: "0s"}, expectedErr: "timeoutString=0s must be positive"},
+		{name: "relative file", config: AttestationSinkConfig{Type: SinkTypeFile, Path: "attestations.jsonl"}, expectedErr: "must be an absolute path"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.config.Validate()
+			if tc.expectedErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tc.expectedErr)
+		})
+	}
+
+	webhook := AttestationSinkConfig{Type: SinkTypeWebhook, URL: "https://example.com/hook", TimeoutString: "5s"}
+	require.NoError(t, webhook.Validate())
+	assert.Equal(t, 5*time.Second, webhook.Timeout)
+}
+
+func TestTokenAggregationConfigValidateExchanges(t *testing.T) {
+	tokenConfig := TokenConfig{TokenID: 1, DefaultPrecision: 6, Exchanges: []string{"xt", "gate", "mexc"}}
+
+	testCases := []struct {
+		name        string
+		config      TokenAggregationConfig
+		expectedErr string
+	}{
+		{name: "global minimum", config: TokenAggregationConfig{}},
+		{name: "token minimum", config: TokenAggregationConfig{MinExchangesRequired: 3}},
+		{name: "token minimum above the exchanges", config: TokenAggregationConfig{MinExchangesRequired: 4}, expectedErr: "minExchangesRequired=4 exceeds enabled exchanges=3"},
+		{name: "disabled exchanges", config: TokenAggregationConfig{Exchanges: map[string]TokenExchangeConfig{"xt": {Disabled: true}, "gate": {Disabled: true}}}, expectedErr: "minExchangesRequired=2 exceeds enabled exchanges=1"},
+		{name: "disabled exchange with a lower token minimum", config: TokenAggregationConfig{MinExchangesRequired: 1, Exchanges: map[string]TokenExchangeConfig{"xt": {Disabled: true}, "gate": {Disabled: true}}}},
+		{name: "unknown exchange", config: TokenAggregationConfig{Exchanges: map[string]TokenExchangeConfig{"binance": {TrustWeight: 2}}}, expectedErr: "exchange binance is not an exchange of the token"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.config.ValidateExchanges(tokenConfig, 2)
+			if tc.expectedErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tc.expectedErr)
+		})
+	}
+}
+
+func TestTokenAggregationConfigExchangeSettings(t *testing.T) {
+	config := TokenAggregationConfig{
+		TokenMaxExchangeWeightPercent: 50,
+		Exchanges: map[string]TokenExchangeConfig{
+			"xt":   {Disabled: true},
+			"gate": {TrustWeight: 0.5, MaxWeightPercent: 20},
+		},
+	}
+
+	assert.Equal(t, 2, config.MinExchanges(2))
+	assert.Equal(t, 3, TokenAggregationConfig{MinExchangesRequired: 3}.MinExchanges(2))
+
+	assert.False(t, config.ExchangeEnabled("xt"))
+	assert.True(t, config.ExchangeEnabled("gate"))
+	assert.True(t, config.ExchangeEnabled("mexc"))
+
+	assert.Equal(t, 0.5, config.ExchangeTrustWeight("gate"))
+	assert.Equal(t, 1.0, config.ExchangeTrustWeight("mexc"))
+
+	assert.Equal(t, 20.0, config.ExchangeMaxWeightPercent("gate"))
+	assert.Equal(t, 50.0, config.ExchangeMaxWeightPercent("mexc"))
+}
+
+func TestValidateTokenRegistry(t *testing.T) {
+	exchangesConfigs := ExchangesConfig{"binance": {}, "coinbase": {}}
+
+	assert.Empty(t, validateTokenRegistry(TokenRegistry{
+		"BTC": {TokenID: 12, DefaultPrecision: 6, Exchanges: []string{"binance", "coinbase"}},
+		"ETH": {TokenID: 11, Exchanges: []string{"binance"}},
+	}, exchangesConfigs))
+
+	errors := validateTokenRegistry(TokenRegistry{
+		"BTC":  {TokenID: 12, Exchanges: []string{"binance"}},
+		"WBTC": {TokenID: 12, Exchanges: []string{"binance"}},
+		"ZERO": {TokenID: 0, Exchanges: []string{"binance"}},
+		"WIDE": {TokenID: MaxTokenID + 1, Exchanges: []string{"binance"}},
+		"eth":  {TokenID: 11, DefaultPrecision: 13, Exchanges: []string{"kraken"}},
+		"SOL":  {TokenID: 20},
+	}, exchangesConfigs)
+
+	assert.ElementsMatch(t, []string{
+		"Token WBTC: tokenID=12 is already used by token BTC",
+		"Token ZERO: tokenID=0 must be between 1 and 255",
+		"Token WIDE: tokenID=256 must be between 1 and 255",
+		"Token eth: symbol must be upper case",
+		"Token eth: defaultPrecision=13 exceeds the max float precision=12",
+		"Token eth: exchange kraken not found in exchange configs",
+		"Token SOL: no exchanges configured",
+	}, errors)
+}
+
+func TestValidateExchangeSymbols(t *testing.T) {
+	tokenRegistry := TokenRegistry{
+		"BTC":  {TokenID: 12, Exchanges: []string{"binance"}},
+		"USDT": {TokenID: 9, Exchanges: []string{"kraken"}},
+		"USDC": {TokenID: 10, Exchanges: []string{"binance"}},
+	}
+	exchangesConfigs := ExchangesConfig{
+		"kraken":  {Symbols: map[string][]SymbolConfig{"USDT": {{Symbol: "USDTZUSD", Quote: USDQuote}}}},
+		"binance": {Symbols: map[string][]SymbolConfig{"USDC": {{Symbol: "USDCUSDT", Quote: "USDT"}}}},
+	}
+
+	assert.Empty(t, validateExchangeSymbols("binance", map[string][]SymbolConfig{
+		"BTC": {{Symbol: "BTCUSD", Quote: USDQuote}, {Symbol: "BTCUSDT", Quote: "USDT"}},
+	}, tokenRegistry, exchangesConfigs))
+
+	errors := validateExchangeSymbols("binance", map[string][]SymbolConfig{
+		"BTC": {
+			{Quote: USDQuote},
+			{Symbol: "BTCUSD"},
+			{Symbol: "BTCEUR", Quote: "EUR"},
+			{Symbol: "BTCUSDC", Quote: "USDC"},
+		},
+		"USDT": {{Symbol: "USDTUSDT", Quote: "USDT"}},
+	}, tokenRegistry, exchangesConfigs)
+
+	assert.ElementsMatch(t, []string{
+		"Exchange binance: token BTC has a pair without symbol",
+		"Exchange binance: symbol BTCUSD has no quote",
+		"Exchange binance: quote EUR of symbol BTCEUR is not in the token registry",
+		"Exchange binance: quote USDC of symbol BTCUSDC has the binance pair USDCUSDT not quoted in USD",
+		"Exchange binance: symbol USDTUSDT is quoted in its own token USDT",
+	}, errors)
+}
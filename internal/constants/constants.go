@@ -53,8 +53,8 @@ var AllowedHeaders = []string{
 	"Sec-GPC",
 }
 
-// PriceFeedBTCURL, PriceFeedETHURL, and PriceFeedAleoURL are the URLs for the price feeds.
-// BTCTokenID, ETHTokenID, and AleoTokenID are the token IDs for the price feeds.
+// PriceFeedURLPrefix is the prefix of the price feed URLs, followed by a token of the token registry like "price_feed: btc".
+// PriceFeedTWAPParam is the price feed URL parameter selecting a TWAP window, like "price_feed: btc twap=15m".
 // AttestationDataSizeLimit is the size limit for the string attestation data.
 // PriceFeedSelector is the selector for the price feed.
 // RequestMethodGET, RequestMethodPOST, ResponseFormatHTML, ResponseFormatJSON, HTMLResultTypeValue, HTMLResultTypeElement, EncodingOptionString, EncodingOptionFloat, and EncodingOptionInt are the constants for the attestation.
@@ -62,16 +62,8 @@ const (
 	SGXReportType string = "sgx"
 
 	// Price feed Constants
-	PriceFeedBTCURL          string = "price_feed: btc"
-	PriceFeedETHURL          string = "price_feed: eth"
-	PriceFeedAleoURL         string = "price_feed: aleo"
-	PriceFeedUSDTURL         string = "price_feed: usdt"
-	PriceFeedUSDCURL         string = "price_feed: usdc"
-	AleoTokenID              int    = 8
-	USDTTokenID              int    = 9
-	USDCTokenID              int    = 10
-	ETHTokenID               int    = 11
-	BTCTokenID               int    = 12
+	PriceFeedURLPrefix       string = "price_feed: "
+	PriceFeedTWAPParam       string = "twap"
 	AttestationDataSizeLimit int    = 1024 * 3
 	PriceFeedSelector        string = "weightedAvgPrice"
 	MaxAllowedTimeDiff       int64  = 600 // 10 minutes in seconds
@@ -90,6 +82,7 @@ const (
 	// Max request and response body sizes
 	MaxRequestBodySize  = 10 * 1024   // 10 KB
 	MaxResponseBodySize = 1024 * 1024 // 1 MB
+	MaxVerifyRequestBodySize = 256 * 1024 // 256 KB, an attestation response carries the formatted report and user data
 
 	// Oracle Report Constants
 	OracleReportChunkSize   = 10
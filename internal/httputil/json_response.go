@@ -4,8 +4,8 @@ import (
 	"encoding/json"
 	"net/http"
 
-	appErrors "github.com/venture23-aleo/aleo-oracle-notarization-backend/internal/errors"
-	"github.com/venture23-aleo/aleo-oracle-notarization-backend/internal/logger"
+	appErrors "github.com/venture23-aleo/aleo-oracle-notarization-backend/pkg/errors"
+	"github.com/venture23-aleo/aleo-oracle-notarization-backend/pkg/logger"
 )
 
 // WriteJsonSuccess writes a JSON success response with optional message and data
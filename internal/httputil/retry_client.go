@@ -5,7 +5,7 @@ import (
 	"time"
 
 	"github.com/hashicorp/go-retryablehttp"
-	"github.com/venture23-aleo/aleo-oracle-notarization-backend/internal/logger"
+	"github.com/venture23-aleo/aleo-oracle-notarization-backend/pkg/logger"
 )
 
 func GetRetryableHTTPClient(maxRetries int) *retryablehttp.Client {
@@ -5,7 +5,7 @@ import (
 	"sync"
 	"time"
 
-	"github.com/venture23-aleo/aleo-oracle-notarization-backend/internal/logger"
+	"github.com/venture23-aleo/aleo-oracle-notarization-backend/pkg/logger"
 )
 
 // SystemMetricsCollector collects system-level metrics
@@ -187,6 +187,66 @@ var (
 		},
 		[]string{"feed"},
 	)
+
+	// Price feed cache metrics
+	PriceFeedCacheRequestsTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "price_feed_cache_requests_total",
+			Help: "Total number of price feed cache lookups by result (hit, miss, coalesced, stale)",
+		},
+		[]string{"token", "result"},
+	)
+
+	// Exchange circuit breaker metrics
+	ExchangeCircuitState = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "exchange_circuit_state",
+			Help: "Circuit state of an exchange symbol (0 = closed, 1 = half open, 2 = open)",
+		},
+		[]string{"exchange", "symbol"},
+	)
+
+	// Price sampler metrics
+	PriceSamplerSamplesTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "price_sampler_samples_total",
+			Help: "Total number of price samples taken for the TWAP price feeds",
+		},
+		[]string{"token", "status"},
+	)
+
+	// Price guard metrics
+	PriceGuardTriggeredTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "price_guard_triggered_total",
+			Help: "Total number of price feed values crossing a bound of the price guard of their token",
+		},
+		[]string{"token", "reason", "policy"},
+	)
+
+	PriceGuardPersistFailuresTotal = promauto.NewCounter(
+		prometheus.CounterOpts{
+			Name: "price_guard_persist_failures_total",
+			Help: "Total number of failures to persist the last attested prices to the price guard state file",
+		},
+	)
+
+	// Attestation scheduler metrics
+	ScheduledAttestationsTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "scheduled_attestations_total",
+			Help: "Total number of attestations triggered by the attestation scheduler",
+		},
+		[]string{"token", "trigger", "status"},
+	)
+
+	AttestationSinkDeliveriesTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "attestation_sink_deliveries_total",
+			Help: "Total number of scheduled attestations pushed to the sinks",
+		},
+		[]string{"sink", "status"},
+	)
 )
 
 // RecordHttpRequest records HTTP request metrics
@@ -250,3 +310,45 @@ func RecordExchangeApiError(exchange string, errorCode string) {
 func RecordPriceFeedExchangeCount(feed string, count int) {
 	PriceFeedExchangeCount.WithLabelValues(feed).Set(float64(count))
 }
+
+// RecordPriceFeedCacheRequest records a price feed cache lookup
+func RecordPriceFeedCacheRequest(token, result string) {
+	PriceFeedCacheRequestsTotal.WithLabelValues(token, result).Inc()
+}
+
+// RecordExchangeCircuitState records the circuit state of an exchange symbol
+func RecordExchangeCircuitState(exchange, symbol, state string) {
+	var value float64
+	switch state {
+	case "half_open":
+		value = 1
+	case "open":
+		value = 2
+	}
+	ExchangeCircuitState.WithLabelValues(exchange, symbol).Set(value)
+}
+
+// RecordPriceSamplerSample records a price sample of the TWAP price feeds
+func RecordPriceSamplerSample(token, status string) {
+	PriceSamplerSamplesTotal.WithLabelValues(token, status).Inc()
+}
+
+// RecordPriceGuardTriggered records a price feed value crossing a bound of the price guard of its token
+func RecordPriceGuardTriggered(token, reason, policy string) {
+	PriceGuardTriggeredTotal.WithLabelValues(token, reason, policy).Inc()
+}
+
+// RecordPriceGuardPersistFailure records a failure to persist the last attested prices
+func RecordPriceGuardPersistFailure() {
+	PriceGuardPersistFailuresTotal.Inc()
+}
+
+// RecordScheduledAttestation records an attestation triggered by the attestation scheduler
+func RecordScheduledAttestation(token, trigger, status string) {
+	ScheduledAttestationsTotal.WithLabelValues(token, trigger, status).Inc()
+}
+
+// RecordAttestationSinkDelivery records a scheduled attestation pushed to a sink
+func RecordAttestationSinkDelivery(sink, status string) {
+	AttestationSinkDeliveriesTotal.WithLabelValues(sink, status).Inc()
+}
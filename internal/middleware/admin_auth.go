@@ -0,0 +1,35 @@
+package middleware
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"net/http"
+	"strings"
+
+	httpUtil "github.com/venture23-aleo/aleo-oracle-notarization-backend/internal/httputil"
+	appErrors "github.com/venture23-aleo/aleo-oracle-notarization-backend/pkg/errors"
+	"github.com/venture23-aleo/aleo-oracle-notarization-backend/pkg/logger"
+)
+
+// AdminAuth middleware rejects the requests whose bearer token does not hash to tokenSha256, the hex encoded
+// SHA-256 of the admin token. Every request is rejected when tokenSha256 is not a valid hash.
+func AdminAuth(tokenSha256 string) Middleware {
+	expectedHash, err := hex.DecodeString(tokenSha256)
+	if err != nil || len(expectedHash) != sha256.Size {
+		expectedHash = nil
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			token, found := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+			tokenHash := sha256.Sum256([]byte(token))
+			if !found || token == "" || expectedHash == nil || subtle.ConstantTimeCompare(tokenHash[:], expectedHash) != 1 {
+				logger.FromContext(r.Context()).Warn("Rejected admin request", "path", r.URL.Path, "remote_addr", httpUtil.GetClientIP(r))
+				httpUtil.WriteJsonError(w, http.StatusUnauthorized, appErrors.ErrInvalidAdminToken)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,49 @@
+package middleware
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAdminAuth(t *testing.T) {
+	tokenHash := sha256.Sum256([]byte("admin-secret"))
+	handler := AdminAuth(hex.EncodeToString(tokenHash[:]))(createTestHandler(http.StatusOK, "rotated"))
+
+	tests := []struct {
+		name           string
+		authorization  string
+		expectedStatus int
+	}{
+		{name: "valid token", authorization: "Bearer admin-secret", expectedStatus: http.StatusOK},
+		{name: "no header", expectedStatus: http.StatusUnauthorized},
+		{name: "wrong token", authorization: "Bearer admin-secrets", expectedStatus: http.StatusUnauthorized},
+		{name: "empty token", authorization: "Bearer ", expectedStatus: http.StatusUnauthorized},
+		{name: "not a bearer token", authorization: "Basic admin-secret", expectedStatus: http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/admin/rotate-key", nil)
+			if tt.authorization != "" {
+				req.Header.Set("Authorization", tt.authorization)
+			}
+			rr := httptest.NewRecorder()
+			handler.ServeHTTP(rr, req)
+			assert.Equal(t, tt.expectedStatus, rr.Code)
+		})
+	}
+
+	t.Run("invalid token hash", func(t *testing.T) {
+		handler := AdminAuth("")(createTestHandler(http.StatusOK, "rotated"))
+		req := httptest.NewRequest(http.MethodPost, "/admin/rotate-key", nil)
+		req.Header.Set("Authorization", "Bearer ")
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+		assert.Equal(t, http.StatusUnauthorized, rr.Code)
+	})
+}
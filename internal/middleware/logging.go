@@ -6,8 +6,8 @@ import (
 
 	"github.com/venture23-aleo/aleo-oracle-notarization-backend/internal/common"
 	httpUtil "github.com/venture23-aleo/aleo-oracle-notarization-backend/internal/httputil"
-	"github.com/venture23-aleo/aleo-oracle-notarization-backend/internal/logger"
 	"github.com/venture23-aleo/aleo-oracle-notarization-backend/internal/metrics"
+	"github.com/venture23-aleo/aleo-oracle-notarization-backend/pkg/logger"
 )
 
 // responseWriter wraps http.ResponseWriter to capture status code
@@ -68,3 +68,33 @@ func NewServer() (*http.Server, *http.Server) {
 
 	return server, metricsServer
 }
+
+// AdminBindHost is the host the admin listener is bound to. The admin routes are only reachable from inside
+// the container.
+const AdminBindHost = "127.0.0.1"
+
+// NewAdminServer initializes the admin server serving the admin routes to requests carrying the admin bearer
+// token. It returns nil when the admin listener is disabled.
+func NewAdminServer() *http.Server {
+	adminConfig := configs.GetAdminConfig()
+	if !adminConfig.Enabled() {
+		return nil
+	}
+
+	// Create a new admin mux.
+	adminMux := http.NewServeMux()
+
+	// Register the admin routes.
+	api.RegisterAdminRoutes(adminMux)
+
+	// Authenticate every admin request.
+	handler := middleware.Chain(adminMux, middleware.Logging, middleware.AdminAuth(adminConfig.TokenSha256))
+
+	return &http.Server{
+		IdleTimeout:       time.Second * IdleTimeout,
+		ReadHeaderTimeout: time.Second * ReadTimeout,
+		WriteTimeout:      time.Second * WriteTimeout,
+		Addr:              fmt.Sprintf("%s:%d", AdminBindHost, adminConfig.Port),
+		Handler:           handler,
+	}
+}
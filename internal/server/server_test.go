@@ -10,7 +10,7 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	configs "github.com/venture23-aleo/aleo-oracle-notarization-backend/internal/config"
-	"github.com/venture23-aleo/aleo-oracle-notarization-backend/internal/logger"
+	"github.com/venture23-aleo/aleo-oracle-notarization-backend/pkg/logger"
 )
 
 func init() {
@@ -49,6 +49,20 @@ func TestNewServer(t *testing.T) {
 	assert.Equal(t, expectedMetricsAddr, metricsServer.Addr)
 }
 
+func TestNewAdminServer(t *testing.T) {
+	// The shipped config has no admin token, the admin listener is disabled
+	assert.False(t, configs.GetAdminConfig().Enabled())
+	assert.Nil(t, NewAdminServer())
+
+	// The admin routes are not served on the metrics port
+	_, metricsServer := NewServer()
+	req, err := http.NewRequest("POST", "/admin/rotate-key", nil)
+	require.NoError(t, err)
+	rr := httptest.NewRecorder()
+	metricsServer.Handler.ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+}
+
 func TestServerConstants(t *testing.T) {
 	// Test that constants are properly defined
 	assert.Equal(t, 30, IdleTimeout)
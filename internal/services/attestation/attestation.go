@@ -1,6 +1,8 @@
 package attestation
 
 import (
+	"crypto/sha512"
+	"encoding/json"
 	"net/url"
 	"regexp"
 	"strings"
@@ -9,7 +11,7 @@ import (
 	"github.com/venture23-aleo/aleo-oracle-notarization-backend/internal/common"
 	"github.com/venture23-aleo/aleo-oracle-notarization-backend/internal/constants"
 
-	appErrors "github.com/venture23-aleo/aleo-oracle-notarization-backend/internal/errors"
+	appErrors "github.com/venture23-aleo/aleo-oracle-notarization-backend/pkg/errors"
 
 	encoding "github.com/venture23-aleo/aleo-oracle-encoding"
 )
@@ -42,6 +44,8 @@ type AttestationResponse struct {
 
 	AttestationTimestamp int64 `json:"timestamp"` // The attestation timestamp.
 
+	AleoBlockHeight int64 `json:"aleoBlockHeight,omitempty"` // The Aleo block height encoded in the user data, omitted when not encoded.
+
 	ResponseBody string `json:"responseBody"` // The response body.
 
 	ResponseStatusCode int `json:"responseStatusCode"` // The response status code.
@@ -51,6 +55,8 @@ type AttestationResponse struct {
 	OracleData OracleData `json:"oracleData"` // The oracle data.
 
 	AttestationResults []AttestationResultForEachToken `json:"attestationResults"` // The attestation results.
+
+	RoughtimeProof *common.RoughtimeProof `json:"roughtimeProof,omitempty"` // The roughtime proof of the attestation timestamp.
 }
 
 // AttestationRequestWithDebug is the attestation request with debug request.
@@ -83,15 +89,18 @@ type AttestationResultForEachToken struct {
 	ResponseBody string `json:"responseBody"` // The response body.
 	ResponseStatusCode int `json:"responseStatusCode"`
 	AttestationTimestamp int64 `json:"timestamp"` // The attestation timestamp.
+	AleoBlockHeight int64 `json:"aleoBlockHeight,omitempty"` // The Aleo block height encoded in the user data chunk, omitted when not encoded.
 	RequestHash string `json:"requestHash"` // The request hash.
 }
 
 type AttestationResponseForMultipleTokens struct {
 	ReportType string `json:"reportType"` // The report type.
 	AttestationTimestamp int64 `json:"timestamp"` // The attestation timestamp.
+	AleoBlockHeight int64 `json:"aleoBlockHeight,omitempty"` // The Aleo block height encoded in the user data, omitted when not encoded.
 	AttestationReport string `json:"attestationReport"` // The attestation report.
 	OracleData OracleData `json:"oracleData"` // The oracle data.
 	AttestationResults []AttestationResultForEachToken `json:"attestationResults"` // The attestation results.
+	RoughtimeProof *common.RoughtimeProof `json:"roughtimeProof,omitempty"` // The roughtime proof of the attestation timestamp.
 }
 
 
@@ -105,6 +114,11 @@ func (ar *AttestationRequest) Normalize() AttestationRequest {
 	clone.ResponseFormat = strings.ToLower(strings.TrimSpace(clone.ResponseFormat))
 	clone.EncodingOptions.Value = strings.ToLower(strings.TrimSpace(clone.EncodingOptions.Value))
 
+	// Price feed requests without a precision use the default precision of the token.
+	if _, tokenConfig, ok := common.GetPriceFeedToken(clone.Url); ok && clone.EncodingOptions.Value == constants.EncodingOptionFloat && clone.EncodingOptions.Precision == 0 {
+		clone.EncodingOptions.Precision = tokenConfig.DefaultPrecision
+	}
+
 	clone.RequestHeaders = make(map[string]string)
 
 	for headerName, headerValue := range ar.RequestHeaders {	
@@ -254,6 +268,24 @@ func (ar *AttestationRequest) Validate() *appErrors.AppError {
 	return nil
 }
 
+// GetRoughtimeRequestDigest returns the digest the roughtime nonces are derived from: the SHA-512 hash of
+// the JSON encoded attestation requests, with unaccepted headers masked as they are in the response.
+func GetRoughtimeRequestDigest(requests ...AttestationRequest) ([]byte, *appErrors.AppError) {
+	maskedRequests := make([]AttestationRequest, len(requests))
+	for i, request := range requests {
+		request.MaskUnacceptedHeaders()
+		maskedRequests[i] = request
+	}
+
+	encodedRequests, err := json.Marshal(maskedRequests)
+	if err != nil {
+		return nil, appErrors.ErrJSONEncoding.WithDetails(err.Error())
+	}
+
+	digest := sha512.Sum512(encodedRequests)
+	return digest[:], nil
+}
+
 // Masks unaccepted headers by replacing their values with "******"
 func (ar *AttestationRequest) MaskUnacceptedHeaders() {
 	finalHeaders := make(map[string]string)
@@ -1,13 +1,14 @@
 package attestation
 
 import (
+	"encoding/json"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
 	encoding "github.com/venture23-aleo/aleo-oracle-encoding"
 	"github.com/venture23-aleo/aleo-oracle-notarization-backend/internal/common"
-	appErrors "github.com/venture23-aleo/aleo-oracle-notarization-backend/internal/errors"
-	// "github.com/venture23-aleo/aleo-oracle-notarization-backend/internal/logger"
+	appErrors "github.com/venture23-aleo/aleo-oracle-notarization-backend/pkg/errors"
+	// "github.com/venture23-aleo/aleo-oracle-notarization-backend/pkg/logger"
 )
 
 // TestMain initializes the logger for all tests in this package
@@ -505,6 +506,43 @@ func TestValidateAttestationRequestPayload_EdgeCases(t *testing.T) {
 	}
 }
 
+func TestNormalize_PriceFeedDefaultPrecision(t *testing.T) {
+	priceFeedRequest := func(url string, precision uint) AttestationRequest {
+		return AttestationRequest{
+			Url:            url,
+			RequestMethod:  "GET",
+			ResponseFormat: "json",
+			Selector:       "weightedAvgPrice",
+			EncodingOptions: encoding.EncodingOptions{
+				Value:     "float",
+				Precision: precision,
+			},
+		}
+	}
+
+	testCases := []struct {
+		name               string
+		attestationRequest AttestationRequest
+		expectedPrecision  uint
+	}{
+		{name: "missing precision uses the token default", attestationRequest: priceFeedRequest(" Price_Feed: BTC ", 0), expectedPrecision: 6},
+		{name: "explicit precision is kept", attestationRequest: priceFeedRequest("price_feed: btc", 2), expectedPrecision: 2},
+		{name: "unregistered token is not defaulted", attestationRequest: priceFeedRequest("price_feed: doge", 0), expectedPrecision: 0},
+		{name: "regular URL is not defaulted", attestationRequest: priceFeedRequest("google.com", 0), expectedPrecision: 0},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			normalized := testCase.attestationRequest.Normalize()
+			assert.Equal(t, testCase.expectedPrecision, normalized.EncodingOptions.Precision)
+		})
+	}
+
+	ethRequest := priceFeedRequest("price_feed: eth", 0)
+	normalized := ethRequest.Normalize()
+	assert.Nil(t, normalized.Validate())
+}
+
 func TestMaskUnacceptedHeaders_EdgeCases(t *testing.T) {
 	testCases := []struct {
 		name               string
@@ -567,6 +605,51 @@ func TestMaskUnacceptedHeaders_EdgeCases(t *testing.T) {
 	}
 }
 
+func TestGetRoughtimeRequestDigest(t *testing.T) {
+	attestationRequest := AttestationRequest{
+		Url:            "google.com",
+		RequestMethod:  "GET",
+		ResponseFormat: "html",
+		Selector:       "/html/head/title",
+		RequestHeaders: map[string]string{
+			"accept":        "*/*",
+			"authorization": "Bearer secret",
+		},
+		EncodingOptions: encoding.EncodingOptions{Value: "string"},
+	}
+
+	digest, err := GetRoughtimeRequestDigest(attestationRequest)
+	assert.Nil(t, err)
+	assert.Len(t, digest, 64)
+
+	// The digest does not depend on the values of unaccepted headers, and the request is not modified.
+	maskedRequest := attestationRequest
+	maskedRequest.MaskUnacceptedHeaders()
+	maskedDigest, err := GetRoughtimeRequestDigest(maskedRequest)
+	assert.Nil(t, err)
+	assert.Equal(t, digest, maskedDigest)
+	assert.Equal(t, "Bearer secret", attestationRequest.RequestHeaders["authorization"])
+
+	// A verifier recomputes the digest from the request in the response.
+	response, marshalErr := json.Marshal(AttestationResponse{AttestationRequest: maskedRequest})
+	assert.NoError(t, marshalErr)
+	var decoded AttestationResponse
+	assert.NoError(t, json.Unmarshal(response, &decoded))
+	decodedDigest, err := GetRoughtimeRequestDigest(decoded.AttestationRequest)
+	assert.Nil(t, err)
+	assert.Equal(t, digest, decodedDigest)
+
+	// The digest covers every request, in order.
+	otherRequest := attestationRequest
+	otherRequest.Url = "example.com"
+	digestAB, err := GetRoughtimeRequestDigest(attestationRequest, otherRequest)
+	assert.Nil(t, err)
+	digestBA, err := GetRoughtimeRequestDigest(otherRequest, attestationRequest)
+	assert.Nil(t, err)
+	assert.NotEqual(t, digest, digestAB)
+	assert.NotEqual(t, digestAB, digestBA)
+}
+
 func TestAttestationResponse_Structure(t *testing.T) {
 	// Test that AttestationResponse can be created and has expected fields
 	response := AttestationResponse{
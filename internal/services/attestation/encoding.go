@@ -2,18 +2,32 @@ package attestation
 
 import (
 	"bytes"
+	"encoding/binary"
 	"math"
 	"strings"
 
 	"github.com/venture23-aleo/aleo-oracle-notarization-backend/internal/common"
 	"github.com/venture23-aleo/aleo-oracle-notarization-backend/internal/constants"
-	appErrors "github.com/venture23-aleo/aleo-oracle-notarization-backend/internal/errors"
-	"github.com/venture23-aleo/aleo-oracle-notarization-backend/internal/logger"
+	appErrors "github.com/venture23-aleo/aleo-oracle-notarization-backend/pkg/errors"
+	"github.com/venture23-aleo/aleo-oracle-notarization-backend/pkg/logger"
 
 	encoding "github.com/venture23-aleo/aleo-oracle-encoding"
 	"github.com/venture23-aleo/aleo-oracle-encoding/positionRecorder"
 )
 
+// metaHeaderBlockHeightLenOffset is the offset of the Aleo block height length in the meta header.
+// The length is 0 when the block height is not encoded.
+const metaHeaderBlockHeightLenOffset = 22
+
+// ProofPositionalInfo contains the positions of the fields encoded in the user data, including the optional
+// fields that are only encoded when present.
+type ProofPositionalInfo struct {
+	encoding.ProofPositionalInfo
+
+	// Position of the Aleo block height. Only set when the block height is encoded, right after the timestamp.
+	BlockHeight *positionRecorder.PositionInfo `json:"blockHeight,omitempty"`
+}
+
 // prepareAttestationData formats and pads the attestation data string according to the specified encoding option.
 //
 // This function takes the raw attestation data and the encoding options, then processes the data based on the encoding type:
@@ -62,7 +76,9 @@ func prepareAttestationData(attestationData string, encodingOptions *encoding.En
 
 // PrepareProofData encodes all attestation request fields and metadata into a single aligned byte buffer for proof generation,
 // returning the encoded buffer, positional information for each field, and any error encountered during the process.
-func PrepareProofData(statusCode int, attestationData string, timestamp int64, req AttestationRequest) ([]byte, *encoding.ProofPositionalInfo, *appErrors.AppError) {
+//
+// The Aleo block height is only encoded when it is positive, so the layout is unchanged when block heights are disabled.
+func PrepareProofData(statusCode int, attestationData string, timestamp int64, aleoBlockHeight int64, req AttestationRequest) ([]byte, *ProofPositionalInfo, *appErrors.AppError) {
 	// Prepare the attestation data.
 	var preppedAttestationData string
 
@@ -114,6 +130,14 @@ func PrepareProofData(statusCode int, attestationData string, timestamp int64, r
 	}
 
 	// Write the Aleo block height to the buffer.
+	var blockHeightPositionInfo *positionRecorder.PositionInfo
+	if aleoBlockHeight > 0 {
+		blockHeightPositionInfo, err = encoding.WriteWithPadding(recorder, encoding.NumberToBytes(uint64(aleoBlockHeight)))
+		if err != nil {
+			logger.Error("Failed to write Aleo block height to buffer: ", "error", err)
+			return nil, nil, appErrors.ErrWritingAleoBlockHeight
+		}
+	}
 
 	// Write the status code to the buffer.
 	statusCodePositionInfo, err := encoding.WriteWithPadding(recorder, encoding.NumberToBytes(uint64(statusCode)))
@@ -274,57 +298,70 @@ func PrepareProofData(statusCode int, attestationData string, timestamp int64, r
 		uint16(optionalFieldsLen),
 	)
 
-	proofPositionalInfo := &encoding.ProofPositionalInfo{
-		Data:            *attestationDataPositionInfo,
-		Timestamp:       *timestampPositionInfo,
-		StatusCode:      *statusCodePositionInfo,
-		Method:          *requestMethodPositionInfo,
-		ResponseFormat:  *responseFormatPositionInfo,
-		Url:             *urlPositionInfo,
-		Selector:        *selectorPositionInfo,
-		EncodingOptions: *encodingOptionsPositionInfo,
-		RequestHeaders:  *requestHeadersPositionInfo,
-		OptionalFields:  *optionalFieldsPositionInfo,
+	// Write the Aleo block height length to the meta header.
+	if blockHeightPositionInfo != nil {
+		binary.LittleEndian.PutUint16(result[metaHeaderBlockHeightLenOffset:metaHeaderBlockHeightLenOffset+2], 8) // block height is encoded as uint64 so it's always 8 bytes
+	}
+
+	proofPositionalInfo := &ProofPositionalInfo{
+		ProofPositionalInfo: encoding.ProofPositionalInfo{
+			Data:            *attestationDataPositionInfo,
+			Timestamp:       *timestampPositionInfo,
+			StatusCode:      *statusCodePositionInfo,
+			Method:          *requestMethodPositionInfo,
+			ResponseFormat:  *responseFormatPositionInfo,
+			Url:             *urlPositionInfo,
+			Selector:        *selectorPositionInfo,
+			EncodingOptions: *encodingOptionsPositionInfo,
+			RequestHeaders:  *requestHeadersPositionInfo,
+			OptionalFields:  *optionalFieldsPositionInfo,
+		},
+		BlockHeight: blockHeightPositionInfo,
 	}
 
 	return result, proofPositionalInfo, nil
 }
 
-// PrepareEncodedRequestProof zeroes out the attestation data and timestamp fields in the userData buffer
+// PrepareEncodedRequestProof zeroes out the attestation data, timestamp and Aleo block height fields in the userData buffer
 // based on their encoded positions, producing a canonicalized request encoding for proof purposes.
 //
 // This function is used to canonicalize the userData buffer for proof generation by zeroing out the
-// attestation data and timestamp fields. This ensures that the resulting encoded request is deterministic
+// attestation data, timestamp and block height fields. This ensures that the resulting encoded request is deterministic
 // and does not leak any sensitive or variable information in these fields.
 //
 // The function performs the following steps sequentially:
-//  1. Retrieves the lengths of the attestation data and timestamp fields from the encodedPositions struct.
+//  1. Retrieves the lengths of the attestation data, timestamp and block height (when encoded) fields from the encodedPositions struct.
 //  2. Calculates the length of the meta header (always 2 * encoding.TARGET_ALIGNMENT).
-//  3. Computes the end offset in the userData buffer that covers both the attestation data and timestamp fields.
+//  3. Computes the end offset in the userData buffer that covers the attestation data, timestamp and block height fields.
 //  4. Checks if the userData buffer is large enough to accommodate the zeroing operation.
 //  5. Zeroes out the relevant section of the userData buffer using the built-in clear function.
 //  6. Returns the modified userData buffer and nil error on success, or an error if the buffer is too short.
 //
 // Parameters:
 //   - userData ([]byte): The original user data buffer to be canonicalized. This buffer will be modified in place.
-//   - encodedPositions (encoding.ProofPositionalInfo): Struct containing the encoded positions and lengths
-//     of the attestation data and timestamp fields within the userData buffer.
+//   - encodedPositions (ProofPositionalInfo): Struct containing the encoded positions and lengths
+//     of the attestation data, timestamp and block height fields within the userData buffer.
 //
 // Returns:
-//   - ([]byte): The canonicalized userData buffer with attestation data and timestamp fields zeroed out.
+//   - ([]byte): The canonicalized userData buffer with attestation data, timestamp and block height fields zeroed out.
 //   - (*appErrors.AppError): An application error if the operation fails (e.g., if the buffer is too short).
-func PrepareEncodedRequestProof(userDataProof []byte, encodedPositions encoding.ProofPositionalInfo) ([]byte, *appErrors.AppError) {
-	// Step 1: Retrieve the attestation data and timestamp lengths from the encoded positions.
+func PrepareEncodedRequestProof(userDataProof []byte, encodedPositions ProofPositionalInfo) ([]byte, *appErrors.AppError) {
+	// Step 1: Retrieve the attestation data, timestamp and block height lengths from the encoded positions.
 	attestationDataLen := encodedPositions.Data.Len
 	timestampLen := encodedPositions.Timestamp.Len
+	blockHeightLen := 0
+	if encodedPositions.BlockHeight != nil {
+		blockHeightLen = encodedPositions.BlockHeight.Len
+	}
 	logger.Debug("attestationDataLen", "attestationDataLen", attestationDataLen)
 	logger.Debug("timestampLen", "timestampLen", timestampLen)
+	logger.Debug("blockHeightLen", "blockHeightLen", blockHeightLen)
 
 	// Step 2: Calculate the meta header length (fixed size).
 	metaHeaderLen := 2 * encoding.TARGET_ALIGNMENT
 
 	// Step 3: Calculate the end offset for the section to be zeroed.
-	endOffset := metaHeaderLen + (attestationDataLen+timestampLen)*encoding.TARGET_ALIGNMENT
+	endOffset := metaHeaderLen + (attestationDataLen+timestampLen+blockHeightLen)*encoding.TARGET_ALIGNMENT
 
 	logger.Debug("endOffset", "endOffset", endOffset)
 
@@ -337,7 +374,7 @@ func PrepareEncodedRequestProof(userDataProof []byte, encodedPositions encoding.
 	// Log the userData buffer before zeroing.
 	logger.Debug("userDataProof before clearing", "userDataProof", userDataProof)
 
-	// Step 5: Zero out the attestation data and timestamp fields in the buffer.
+	// Step 5: Zero out the attestation data, timestamp and block height fields in the buffer.
 	clear(userDataProof[metaHeaderLen:endOffset])
 
 	// Log the userData buffer after zeroing.
@@ -12,7 +12,7 @@ import (
 	"github.com/venture23-aleo/aleo-oracle-encoding/positionRecorder"
 	"github.com/venture23-aleo/aleo-oracle-notarization-backend/internal/common"
 	"github.com/venture23-aleo/aleo-oracle-notarization-backend/internal/constants"
-	appErrors "github.com/venture23-aleo/aleo-oracle-notarization-backend/internal/errors"
+	appErrors "github.com/venture23-aleo/aleo-oracle-notarization-backend/pkg/errors"
 )
 
 func TestPrepareProofData_WithPositionalInfo(t *testing.T) {
@@ -254,7 +254,7 @@ func TestPrepareProofData_WithPositionalInfo(t *testing.T) {
 		{
 			name: "valid attestation data with float encoding and price feed url",
 			attestationRequest: AttestationRequest{
-				Url:            constants.PriceFeedBTCURL,
+				Url:            "price_feed: btc",
 				RequestMethod:  "GET",
 				ResponseFormat: "json",
 				Selector:       "body",
@@ -393,18 +393,15 @@ func TestPrepareProofData_WithPositionalInfo(t *testing.T) {
 
 		t.Run(testCase.name, func(t *testing.T) {
 			t.Logf("testCase.attestationData: %v", len(testCase.attestationData))
-			// aleoBlockHeight, blockHeightError := common.GetAleoCurrentBlockHeight()
-			// if blockHeightError != nil {
-			// 	t.Fatalf("failed to get aleo block height: %v", blockHeightError)
-			// }
-			proofData, encodedPositions, err := PrepareProofData(testCase.statusCode, testCase.attestationData, testCase.timestamp, testCase.attestationRequest)
+			proofData, encodedPositions, err := PrepareProofData(testCase.statusCode, testCase.attestationData, testCase.timestamp, 0, testCase.attestationRequest)
 			if testCase.expectedError != nil {
 				assert.Equal(t, testCase.expectedError, err)
 			} else {
 				assert.Nil(t, err)
 				assert.NotNil(t, proofData)
 				assert.NotNil(t, encodedPositions)
-				assert.Equal(t, testCase.expectedPositionalInfo, encodedPositions)
+				assert.Equal(t, testCase.expectedPositionalInfo, &encodedPositions.ProofPositionalInfo)
+				assert.Nil(t, encodedPositions.BlockHeight)
 
 				var attestationData = make([]byte, 2)
 				var attestationDataLen int
@@ -475,6 +472,49 @@ func TestPrepareProofData_WithPositionalInfo(t *testing.T) {
 	}
 }
 
+func TestPrepareProofData_WithAleoBlockHeight(t *testing.T) {
+	req := AttestationRequest{
+		Url:            "google.com",
+		RequestMethod:  "GET",
+		ResponseFormat: "json",
+		Selector:       "body",
+		EncodingOptions: encoding.EncodingOptions{
+			Value:     "float",
+			Precision: 6,
+		},
+	}
+	aleoBlockHeight := int64(9876543)
+
+	withoutHeight, withoutHeightPositions, err := PrepareProofData(200, "1345", 1715769600, 0, req)
+	assert.Nil(t, err)
+	withHeight, withHeightPositions, err := PrepareProofData(200, "1345", 1715769600, aleoBlockHeight, req)
+	assert.Nil(t, err)
+
+	// The block height is encoded right after the timestamp and shifts the following fields by one block.
+	assert.Equal(t, &positionRecorder.PositionInfo{Pos: 4, Len: 1}, withHeightPositions.BlockHeight)
+	assert.Equal(t, withoutHeightPositions.Timestamp, withHeightPositions.Timestamp)
+	assert.Equal(t, withoutHeightPositions.StatusCode.Pos+1, withHeightPositions.StatusCode.Pos)
+	assert.Equal(t, withoutHeightPositions.OptionalFields.Pos+1, withHeightPositions.OptionalFields.Pos)
+	assert.Len(t, withHeight, len(withoutHeight)+encoding.TARGET_ALIGNMENT)
+
+	blockHeightOffset := withHeightPositions.BlockHeight.Pos * encoding.TARGET_ALIGNMENT
+	assert.Equal(t, uint64(aleoBlockHeight), binary.LittleEndian.Uint64(withHeight[blockHeightOffset:blockHeightOffset+8]))
+
+	// The block height length is recorded in the meta header only when the block height is encoded.
+	assert.Equal(t, uint16(8), binary.LittleEndian.Uint16(withHeight[metaHeaderBlockHeightLenOffset:]))
+	assert.Equal(t, uint16(0), binary.LittleEndian.Uint16(withoutHeight[metaHeaderBlockHeightLenOffset:]))
+
+	// The block height is zeroed in the encoded request, so the request hash is the same at every height.
+	otherHeight, otherHeightPositions, err := PrepareProofData(200, "1345", 1715769600, aleoBlockHeight+1, req)
+	assert.Nil(t, err)
+	encodedRequest, err := PrepareEncodedRequestProof(withHeight, *withHeightPositions)
+	assert.Nil(t, err)
+	otherEncodedRequest, err := PrepareEncodedRequestProof(otherHeight, *otherHeightPositions)
+	assert.Nil(t, err)
+	assert.Equal(t, encodedRequest, otherEncodedRequest)
+	assert.Equal(t, make([]byte, encoding.TARGET_ALIGNMENT), encodedRequest[blockHeightOffset:blockHeightOffset+encoding.TARGET_ALIGNMENT])
+}
+
 func TestPrepareEncodedRequestProof(t *testing.T) {
 	// t.Skip()
 	testCases := []struct {
@@ -537,7 +577,7 @@ func TestPrepareEncodedRequestProof(t *testing.T) {
 
 	for _, testCase := range testCases {
 		t.Run(testCase.name, func(t *testing.T) {
-			encodedRequestProof, err := PrepareEncodedRequestProof(testCase.userData, testCase.encodedPositions)
+			encodedRequestProof, err := PrepareEncodedRequestProof(testCase.userData, ProofPositionalInfo{ProofPositionalInfo: testCase.encodedPositions})
 			if testCase.expectedError != nil {
 				assert.Equal(t, testCase.expectedError, err)
 			} else {
@@ -828,12 +868,8 @@ func TestPrepareAttestationData(t *testing.T) {
 				EncodingOptions: testCase.encodingOptions,
 			}
 
-			// aleoBlockHeight, blockHeightError := common.GetAleoCurrentBlockHeight()
-			// if blockHeightError != nil {
-			// 	t.Fatalf("failed to get aleo block height: %v", blockHeightError)
-			// }
 			// Call PrepareProofData which internally calls prepareAttestationData
-			result, _, err := PrepareProofData(testCase.statusCode, testCase.attestationData, testCase.timestamp, req)
+			result, _, err := PrepareProofData(testCase.statusCode, testCase.attestationData, testCase.timestamp, 0, req)
 
 			if testCase.expectedError != nil {
 				assert.Equal(t, testCase.expectedError, err, testCase.description)
@@ -935,7 +971,7 @@ func TestPrepareAttestationData_EdgeCases(t *testing.T) {
 			}
 
 			// aleoBlockHeight := 224254
-			_, _, err := PrepareProofData(200, testCase.attestationData, 1715769600, req)
+			_, _, err := PrepareProofData(200, testCase.attestationData, 1715769600, 0, req)
 
 			if testCase.expectedError != nil {
 				assert.Equal(t, testCase.expectedError, err, testCase.description)
@@ -943,11 +979,7 @@ func TestPrepareAttestationData_EdgeCases(t *testing.T) {
 				// For edge cases, we mainly want to ensure no panic occurs
 				// The actual behavior might vary depending on the encoding library
 				assert.NotPanics(t, func() {
-				// aleoBlockHeight, blockHeightError := common.GetAleoCurrentBlockHeight()
-				// if blockHeightError != nil {
-				// 	t.Fatalf("failed to get aleo block height: %v", blockHeightError)
-				// }
-					_, _, _ = PrepareProofData(200, testCase.attestationData, 1715769600, req)
+					_, _, _ = PrepareProofData(200, testCase.attestationData, 1715769600, 0, req)
 				}, testCase.description)
 			}
 		})
@@ -971,11 +1003,7 @@ func TestPrepareAttestationData_Performance(t *testing.T) {
 	// Benchmark the function
 	start := time.Now()
 	for i := 0; i < 100; i++ {
-			// aleoBlockHeight, blockHeightError := common.GetAleoCurrentBlockHeight()
-			// if blockHeightError != nil {
-			// 	t.Fatalf("failed to get aleo block height: %v", blockHeightError)
-			// }
-		_, _, err := PrepareProofData(200, largeData, 1715769600, req)
+		_, _, err := PrepareProofData(200, largeData, 1715769600, 0, req)
 		assert.Nil(t, err)
 	}
 	duration := time.Since(start)
@@ -1037,11 +1065,7 @@ func TestPrepareAttestationData_PriceFeedExclusion(t *testing.T) {
 
 			// For price feed URLs, the attestation data should be used as-is
 			// without calling prepareAttestationData
-			// aleoBlockHeight, blockHeightError := common.GetAleoCurrentBlockHeight()
-			// if blockHeightError != nil {
-			// 	t.Fatalf("failed to get aleo block height: %v", blockHeightError)
-			// }
-			result, _, err := PrepareProofData(200, testCase.attestationData, 1715769600, req)
+			result, _, err := PrepareProofData(200, testCase.attestationData, 1715769600, 0, req)
 
 			assert.Nil(t, err, testCase.description)
 			assert.NotNil(t, result, "Result should not be nil for price feed URLs")
@@ -5,11 +5,10 @@ import (
 	"math/big"
 	"time"
 
-	encoding "github.com/venture23-aleo/aleo-oracle-encoding"
 	aleoUtil "github.com/venture23-aleo/aleo-oracle-notarization-backend/internal/aleoutil"
 	"github.com/venture23-aleo/aleo-oracle-notarization-backend/internal/constants"
-	appErrors "github.com/venture23-aleo/aleo-oracle-notarization-backend/internal/errors"
-	"github.com/venture23-aleo/aleo-oracle-notarization-backend/internal/logger"
+	appErrors "github.com/venture23-aleo/aleo-oracle-notarization-backend/pkg/errors"
+	"github.com/venture23-aleo/aleo-oracle-notarization-backend/pkg/logger"
 )
 
 // OracleData is the data of the oracle.
@@ -28,9 +27,15 @@ type OracleData struct {
 	// Public key the signature was created against.
 	Address string `json:"address"`
 
+	// Schnorr signature of the Attestation Report by the previous key, only present during a key rotation overlap window.
+	PreviousSignature string `json:"previousSignature,omitempty"`
+
+	// Public key the previous signature was created against.
+	PreviousAddress string `json:"previousAddress,omitempty"`
+
 	// Object containing information about the positions of data included in the Attestation Report hash.
 	// To omit this field when empty, use a pointer type so omitempty works as intended.
-	EncodedPositions *encoding.ProofPositionalInfo `json:"encodedPositions,omitempty"`
+	EncodedPositions *ProofPositionalInfo `json:"encodedPositions,omitempty"`
 
 
 	// Aleo-encoded request. Same as UserData but with zeroed Data and Timestamp fields. Can be used to validate the request in Aleo programs.
@@ -41,6 +46,8 @@ type OracleData struct {
 	// it can take the UserData provided with the Attestation Report, replace Data and Timestamp with "0u128" and then compare the result with the constant UserData in the program.
 	// If both UserDatas match, then we know that the Attestation Report was made using the correct attestation target request!
 	//
+	// When the Aleo block height is encoded, it is zeroed out as well, since it also changes with every notarization request.
+	//
 	// To avoid storing the full UserData in an Aleo program, we can hash it and store only the hash in the program. See RequestHash.
 	EncodedRequest string `json:"encodedRequest,omitempty"`
 
@@ -86,7 +93,7 @@ func PrepareOracleReport(quote []byte) (oracleReport []byte, appError *appErrors
 	return oracleReport, nil
 }
 
-// PrepareOracleSignature generates a Schnorr signature for the provided oracle report.
+// PrepareOracleSignature generates the Schnorr signatures for the provided oracle report.
 //
 // This function performs the following steps sequentially:
 // 1. Retrieves the Aleo context, which provides cryptographic utilities and session management.
@@ -95,37 +102,41 @@ func PrepareOracleReport(quote []byte) (oracleReport []byte, appError *appErrors
 // 2. Hashes the oracle report using the Aleo session's HashMessage method.
 //   - If hashing fails, it logs the error and returns a report hashing application error.
 //
-// 3. Signs the hashed message using the Aleo context's Sign method to produce a Schnorr signature.
+// 3. Signs the hashed message using the Aleo context's SignWithRotation method to produce a Schnorr signature
+// and, during a key rotation overlap window, a Schnorr signature by the previous key.
 //   - If signing fails, it logs the error and returns a signature generation application error.
 //
-// 4. Returns the generated signature as a string if all steps succeed.
+// 4. Returns the generated signatures with the addresses that produced them if all steps succeed.
+//
+// The signatures and addresses are produced under one lock, so a concurrent key rotation cannot pair a
+// signature with the address of another key.
 //
 // Parameters:
 //   - oracleReport ([]byte): The oracle report to be signed.
 //
 // Returns:
-//   - signature (string): The Schnorr signature of the hashed oracle report.
+//   - signatures (*aleoUtil.RotationSignatures): The Schnorr signatures of the hashed oracle report and their addresses.
 //   - appError (*appErrors.AppError): An application error if any step fails, otherwise nil.
-func PrepareOracleSignature(oracleReport []byte) (signature string, publicKey string, appError *appErrors.AppError) {
+func PrepareOracleSignature(oracleReport []byte) (signatures *aleoUtil.RotationSignatures, appError *appErrors.AppError) {
 	// Step 1: Retrieve Aleo context
 	aleo, err := aleoUtil.GetAleoContext()
 	if err != nil {
 		logger.Error("Error getting Aleo context: ", "error", err)
-		return "", "", err
+		return nil, err
 	}
 
 	// Step 2: Hash the oracle report
 	hashedMessage, hashErr := aleo.HashMessage(oracleReport)
 	if hashErr != nil {
 		logger.Error("Failed to hash report", "error", hashErr)
-		return "", "", appErrors.ErrHashingReport
+		return nil, appErrors.ErrHashingReport
 	}
 
-	// Step 3: Sign the hashed message
-	signature, signErr := aleo.Sign(hashedMessage)
+	// Step 3: Sign the hashed message with the current key and, during a rotation overlap window, the previous key
+	signatures, signErr := aleo.SignWithRotation(hashedMessage)
 	if signErr != nil {
 		logger.Error("Error while generating signature: ", "error", signErr)
-		return "", "", appErrors.ErrGeneratingSignature
+		return nil, appErrors.ErrGeneratingSignature
 	}
 
 	// Step 4: Introduce a small random delay to mitigate timing attacks
@@ -139,8 +150,8 @@ func PrepareOracleSignature(oracleReport []byte) (signature string, publicKey st
 	delayMs := 50 + int(jm.Int64())
 	time.Sleep(time.Duration(delayMs) * time.Millisecond)
 
-	// Step 5: Return the signature
-	return signature, aleo.GetPublicKey(), nil
+	// Step 5: Return the signatures
+	return signatures, nil
 }
 
 // GenerateAttestationHash generates an attestation hash from the provided user data.
@@ -190,7 +201,8 @@ func GenerateAttestationHash(userData []byte) (attestationHash []byte, err *appE
 // 4. Generate a timestamped hash by combining the request hash with the attestation timestamp.
 // 5. Prepare the oracle report from the provided quote (attestation evidence).
 // 6. Sign the oracle report to produce a Schnorr signature.
-// 7. Assemble all the above into an OracleData struct, which is returned for use by the oracle contract.
+// 7. During a key rotation overlap window, also sign the oracle report with the previous key.
+// 8. Assemble all the above into an OracleData struct, which is returned for use by the oracle contract.
 //
 // Each step is logged, and errors are handled and logged appropriately. If any step fails, the function returns
 // a nil OracleData and the corresponding application error.
@@ -237,8 +249,8 @@ func BuildCompleteOracleData(quotePrepData *QuotePreparationData, quote []byte)
 		return nil, err
 	}
 
-	// Step 6: Prepare the oracle signature
-	signature, publicKey, err := PrepareOracleSignature(oracleReport)
+	// Step 6: Prepare the oracle signature, and the previous key signature during a key rotation overlap window
+	signatures, err := PrepareOracleSignature(oracleReport)
 
 	if err != nil {
 		logger.Error("Failed to prepare oracle signature: ", "error", err)
@@ -253,8 +265,10 @@ func BuildCompleteOracleData(quotePrepData *QuotePreparationData, quote []byte)
 		RequestHash:            requestHashString,
 		TimestampedRequestHash: timestampedHash,
 		Report:                 string(oracleReport),
-		Signature:              signature,
-		Address:                publicKey,
+		Signature:              signatures.Signature,
+		Address:                signatures.Address,
+		PreviousSignature:      signatures.PreviousSignature,
+		PreviousAddress:        signatures.PreviousAddress,
 	}
 
 	return oracleData, nil
@@ -9,7 +9,7 @@ import (
 	encoding "github.com/venture23-aleo/aleo-oracle-encoding"
 	aleoUtil "github.com/venture23-aleo/aleo-oracle-notarization-backend/internal/aleoutil"
 	"github.com/venture23-aleo/aleo-oracle-notarization-backend/internal/constants"
-	appErrors "github.com/venture23-aleo/aleo-oracle-notarization-backend/internal/errors"
+	appErrors "github.com/venture23-aleo/aleo-oracle-notarization-backend/pkg/errors"
 )
 
 // This test demonstrates how to test for panics in Go using assert.Panics from testify.
@@ -121,7 +121,7 @@ func TestPrepareOracleSignature(t *testing.T) {
 
 	for _, testCase := range testCases {
 		t.Run(testCase.name, func(t *testing.T) {
-			_, _, err := PrepareOracleSignature(testCase.oracleReport)
+			_, err := PrepareOracleSignature(testCase.oracleReport)
 			assert.Equal(t, testCase.expectedError, err)
 			// assert.Equal(t, testCase.expectedSignature, signature)
 		})
@@ -256,7 +256,7 @@ func TestBuildCompleteOracleData(t *testing.T) {
 		{
 			name: "valid attestation data for float encoding with btc price feed url",
 			attestationRequest: AttestationRequest{
-				Url:            constants.PriceFeedBTCURL,
+				Url:            "price_feed: btc",
 				RequestMethod:  "GET",
 				ResponseFormat: "json",
 				Selector:       "body",
@@ -273,7 +273,7 @@ func TestBuildCompleteOracleData(t *testing.T) {
 		{
 			name: "valid attestation data for float encoding with eth price feed url",
 			attestationRequest: AttestationRequest{
-				Url:            constants.PriceFeedETHURL,
+				Url:            "price_feed: eth",
 				RequestMethod:  "GET",
 				ResponseFormat: "json",
 				Selector:       "body",
@@ -290,7 +290,7 @@ func TestBuildCompleteOracleData(t *testing.T) {
 		{
 			name: "valid attestation data for float encoding with aleo price feed url",
 			attestationRequest: AttestationRequest{
-				Url:            constants.PriceFeedAleoURL,
+				Url:            "price_feed: aleo",
 				RequestMethod:  "GET",
 				ResponseFormat: "json",
 				Selector:       "body",
@@ -325,7 +325,7 @@ func TestBuildCompleteOracleData(t *testing.T) {
 			// if blockHeightError != nil {
 			// 	t.Fatalf("failed to get aleo block height: %v", blockHeightError)
 			// }
-			quotePreparationData, err := PrepareDataForQuoteGeneration(testCase.statusCode, testCase.attestationData, testCase.timestamp, testCase.attestationRequest)
+			quotePreparationData, err := PrepareDataForQuoteGeneration(testCase.statusCode, testCase.attestationData, testCase.timestamp, 0, testCase.attestationRequest)
 			if testCase.expectedError != nil {
 				assert.Equal(t, testCase.expectedError, err)
 			} else {
@@ -341,11 +341,11 @@ func TestBuildCompleteOracleData(t *testing.T) {
 				}
 
 				switch testCase.attestationRequest.Url {
-				case constants.PriceFeedBTCURL:
+				case "price_feed: btc":
 					assert.Equal(t, quotePreparationData.UserDataProof[21], uint8(0xc))
-				case constants.PriceFeedETHURL:
+				case "price_feed: eth":
 					assert.Equal(t, quotePreparationData.UserDataProof[21], uint8(0xb))
-				case constants.PriceFeedAleoURL:
+				case "price_feed: aleo":
 					assert.Equal(t, quotePreparationData.UserDataProof[21], uint8(0x8))
 				}
 
@@ -380,7 +380,7 @@ func TestBuildCompleteOracleData(t *testing.T) {
 
 				oracleReportHash, _ := aleoContext.HashMessage(expectedOracleReport)
 
-				_, signError := aleoContext.Sign(oracleReportHash)
+				_, _, signError := aleoContext.Sign(oracleReportHash)
 				assert.Nil(t, signError)
 			}
 		})
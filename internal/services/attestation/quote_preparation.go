@@ -9,17 +9,18 @@ import (
 	aleoUtil "github.com/venture23-aleo/aleo-oracle-notarization-backend/internal/aleoutil"
 	"github.com/venture23-aleo/aleo-oracle-notarization-backend/internal/common"
 	"github.com/venture23-aleo/aleo-oracle-notarization-backend/internal/constants"
-	appErrors "github.com/venture23-aleo/aleo-oracle-notarization-backend/internal/errors"
-	"github.com/venture23-aleo/aleo-oracle-notarization-backend/internal/logger"
+	appErrors "github.com/venture23-aleo/aleo-oracle-notarization-backend/pkg/errors"
+	"github.com/venture23-aleo/aleo-oracle-notarization-backend/pkg/logger"
 )
 
 // QuotePreparationData contains all the data needed for quote generation
 type QuotePreparationData struct {
 	UserDataProof    []byte                        `json:"userDataProof"`    // The user data proof.
 	UserData         []byte                        `json:"userData"`         // The user data.
-	EncodedPositions *encoding.ProofPositionalInfo `json:"encodedPositions"` // The encoded positions.
+	EncodedPositions *ProofPositionalInfo `json:"encodedPositions"` // The encoded positions.
 	AttestationHash  []byte                        `json:"attestationHash"`  // The attestation hash.
 	Timestamp        uint64                        `json:"timestamp"`        // The timestamp.
+	AleoBlockHeight  uint64                        `json:"aleoBlockHeight"`  // The Aleo block height, 0 when not encoded.
 }
 
 // PrepareOracleUserData prepares the user data for oracle operations.
@@ -36,6 +37,7 @@ type QuotePreparationData struct {
 //   - statusCode:        the HTTP status code associated with the attestation
 //   - attestationData:   the attestation data as a string
 //   - timestamp:         the timestamp of the attestation (as uint64)
+//   - aleoBlockHeight:   the latest Aleo block height, not encoded when 0
 //   - attestationRequest: the attestation request object containing URL and other metadata
 //
 // Returns:
@@ -47,11 +49,12 @@ func PrepareOracleUserData(
 	statusCode int,
 	attestationData string,
 	timestamp uint64,
+	aleoBlockHeight uint64,
 	attestationRequest AttestationRequest,
 ) (
 	userDataProof []byte,
 	userData []byte,
-	encodedPositions *encoding.ProofPositionalInfo,
+	encodedPositions *ProofPositionalInfo,
 	err *appErrors.AppError,
 ) {
 	// Step 1: Get the Aleo context.
@@ -60,7 +63,7 @@ func PrepareOracleUserData(
 		return nil, nil, nil, err
 	}
 
-	userDataChunk, encodedPositions, err := PrepareOracleUserDataChunk(statusCode, attestationData, timestamp, attestationRequest)
+	userDataChunk, encodedPositions, err := PrepareOracleUserDataChunk(statusCode, attestationData, timestamp, aleoBlockHeight, attestationRequest)
 	if err != nil {
 		return nil, nil, nil, err
 	}
@@ -85,9 +88,10 @@ func PrepareOracleUserData(
 func PrepareOracleUserDataChunk(statusCode int,
 	attestationData string,
 	timestamp uint64,
-	attestationRequest AttestationRequest) (userDataChunk []byte, encodedPositions *encoding.ProofPositionalInfo, err *appErrors.AppError) {
+	aleoBlockHeight uint64,
+	attestationRequest AttestationRequest) (userDataChunk []byte, encodedPositions *ProofPositionalInfo, err *appErrors.AppError) {
 	// Step 2: Prepare the proof data.
-	userDataProof, encodedPositions, err := PrepareProofData(statusCode, attestationData, int64(timestamp), attestationRequest)
+	userDataProof, encodedPositions, err := PrepareProofData(statusCode, attestationData, int64(timestamp), int64(aleoBlockHeight), attestationRequest)
 	
 	if err != nil {
 		return nil, nil, appErrors.ErrPreparingProofData
@@ -128,7 +132,7 @@ func PrepareOracleUserDataChunk(statusCode int,
 // Returns:
 //   - encodedRequest: the resulting encoded request as a byte slice
 //   - err: an application error if any step fails
-func PrepareOracleEncodedRequest(userDataChunk []byte, encodedPositions *encoding.ProofPositionalInfo) (encodedRequest []byte, err *appErrors.AppError) {
+func PrepareOracleEncodedRequest(userDataChunk []byte, encodedPositions *ProofPositionalInfo) (encodedRequest []byte, err *appErrors.AppError) {
 	// Step 1: Retrieve the Aleo context.
 	aleoContext, err := aleoUtil.GetAleoContext()
 	if err != nil {
@@ -298,6 +302,7 @@ func PrepareOracleTimestampedRequestHash(requestHash []byte, timestamp uint64) (
 //   - statusCode:        The HTTP status code to be included in the attestation.
 //   - attestationData:   The attestation data as a string (e.g., random number, price, etc.).
 //   - timestamp:         The attestation timestamp as a uint64.
+//   - aleoBlockHeight:   The latest Aleo block height as a uint64, not encoded when 0.
 //   - attestationRequest: The original attestation request details.
 //
 // Returns:
@@ -307,10 +312,11 @@ func PrepareDataForQuoteGeneration(
 	statusCode int,
 	attestationData string,
 	timestamp uint64,
+	aleoBlockHeight uint64,
 	attestationRequest AttestationRequest,
 ) (*QuotePreparationData, *appErrors.AppError) {
 	// Step 1: Prepare user data proof, user data, and encoded positions.
-	userDataProof, userData, encodedPositions, err := PrepareOracleUserData(statusCode, attestationData, timestamp, attestationRequest)
+	userDataProof, userData, encodedPositions, err := PrepareOracleUserData(statusCode, attestationData, timestamp, aleoBlockHeight, attestationRequest)
 	if err != nil {
 		return nil, err
 	}
@@ -329,6 +335,7 @@ func PrepareDataForQuoteGeneration(
 		EncodedPositions: encodedPositions,
 		AttestationHash:  attestationHash,
 		Timestamp:        timestamp,
+		AleoBlockHeight:  aleoBlockHeight,
 	}, nil
 }
 
@@ -347,7 +354,7 @@ func FormatMessage(message []byte, chunkSize int) (formattedMessage []byte, err
 }
 
 
-func GetRequestHashFromSingleChunk(userDataChunk []byte, encodedPositions *encoding.ProofPositionalInfo) (requestHash string, err *appErrors.AppError) {
+func GetRequestHashFromSingleChunk(userDataChunk []byte, encodedPositions *ProofPositionalInfo) (requestHash string, err *appErrors.AppError) {
 	aleoContext, err := aleoUtil.GetAleoContext()
 	if err != nil {
 		return "", err
@@ -7,8 +7,7 @@ import (
 	"github.com/stretchr/testify/assert"
 	encoding "github.com/venture23-aleo/aleo-oracle-encoding"
 	"github.com/venture23-aleo/aleo-oracle-encoding/positionRecorder"
-	"github.com/venture23-aleo/aleo-oracle-notarization-backend/internal/constants"
-	appErrors "github.com/venture23-aleo/aleo-oracle-notarization-backend/internal/errors"
+	appErrors "github.com/venture23-aleo/aleo-oracle-notarization-backend/pkg/errors"
 )
 
 func TestPrepareOracleUserData(t *testing.T) {
@@ -74,7 +73,7 @@ func TestPrepareOracleUserData(t *testing.T) {
 		{
 			name: "valid price feed url - btc",
 			attestationRequest: AttestationRequest{
-				Url:            constants.PriceFeedBTCURL,
+				Url:            "price_feed: btc",
 				RequestMethod:  "GET",
 				ResponseFormat: "json",
 				Selector:       "weightedAvgPrice",
@@ -96,7 +95,7 @@ func TestPrepareOracleUserData(t *testing.T) {
 			// if blockHeightError != nil {
 			// 	t.Fatalf("failed to get aleo block height: %v", blockHeightError)
 			// }
-			_, _, _, err := PrepareOracleUserData(testCase.statusCode, testCase.attestationData, testCase.timestamp, testCase.attestationRequest)
+			_, _, _, err := PrepareOracleUserData(testCase.statusCode, testCase.attestationData, testCase.timestamp, 0, testCase.attestationRequest)
 			assert.Equal(t, testCase.expectedError, err)
 		})
 	}
@@ -166,7 +165,7 @@ func TestPrepareOracleEncodedRequest(t *testing.T) {
 
 	for _, testCase := range testCases {
 		t.Run(testCase.name, func(t *testing.T) {
-			encodedRequest, err := PrepareOracleEncodedRequest(testCase.userDataProof, &testCase.encodedPositions)
+			encodedRequest, err := PrepareOracleEncodedRequest(testCase.userDataProof, &ProofPositionalInfo{ProofPositionalInfo: testCase.encodedPositions})
 			assert.Equal(t, testCase.expectedError, err)
 			if err == nil {
 				assert.Equal(t, testCase.expectedEncodedRequest, string(encodedRequest))
@@ -254,7 +253,7 @@ func TestPrepareDataForQuoteGeneration(t *testing.T) {
 		{
 			name: "valid attestation data for float encoding with btc price feed url",
 			attestationRequest: AttestationRequest{
-				Url:            constants.PriceFeedBTCURL,
+				Url:            "price_feed: btc",
 				RequestMethod:  "GET",
 				ResponseFormat: "json",
 				Selector:       "body",
@@ -271,7 +270,7 @@ func TestPrepareDataForQuoteGeneration(t *testing.T) {
 		{
 			name: "valid attestation data for float encoding with eth price feed url",
 			attestationRequest: AttestationRequest{
-				Url:            constants.PriceFeedETHURL,
+				Url:            "price_feed: eth",
 				RequestMethod:  "GET",
 				ResponseFormat: "json",
 				Selector:       "body",
@@ -288,7 +287,7 @@ func TestPrepareDataForQuoteGeneration(t *testing.T) {
 		{
 			name: "valid attestation data for float encoding with aleo price feed url",
 			attestationRequest: AttestationRequest{
-				Url:            constants.PriceFeedAleoURL,
+				Url:            "price_feed: aleo",
 				RequestMethod:  "GET",
 				ResponseFormat: "json",
 				Selector:       "body",
@@ -320,7 +319,7 @@ func TestPrepareDataForQuoteGeneration(t *testing.T) {
 	for _, testCase := range testCases {
 		t.Run(testCase.name, func(t *testing.T) {
 			// aleoBlockHeight := 224254
-			quotePreparationData, err := PrepareDataForQuoteGeneration(testCase.statusCode, testCase.attestationData, testCase.timestamp, testCase.attestationRequest)
+			quotePreparationData, err := PrepareDataForQuoteGeneration(testCase.statusCode, testCase.attestationData, testCase.timestamp, 0, testCase.attestationRequest)
 			if testCase.expectedError != nil {
 				assert.Equal(t, testCase.expectedError, err)
 			} else {
@@ -0,0 +1,474 @@
+package data_extraction
+
+import (
+	"math"
+	"math/big"
+	"sort"
+
+	configs "github.com/venture23-aleo/aleo-oracle-notarization-backend/internal/config"
+	appErrors "github.com/venture23-aleo/aleo-oracle-notarization-backend/pkg/errors"
+	"github.com/venture23-aleo/aleo-oracle-notarization-backend/pkg/logger"
+)
+
+// AggregationStats are the intermediate statistics of a price aggregation.
+type AggregationStats struct {
+	Method              string        `json:"method"`                        // Aggregation method.
+	PricesConsidered    int           `json:"pricesConsidered"`              // Number of prices with the minimum USD volume.
+	PricesUsed          int           `json:"pricesUsed"`                    // Number of prices the aggregated price is computed from.
+	MedianPrice         string        `json:"medianPrice"`                   // Median of the considered prices.
+	MAD                 string        `json:"mad,omitempty"`                 // Median absolute deviation of the considered prices (vwap).
+	LowerBound          string        `json:"lowerBound,omitempty"`          // Lowest price kept by the outlier filter (vwap).
+	UpperBound          string        `json:"upperBound,omitempty"`          // Highest price kept by the outlier filter (vwap).
+	MinPrice            string        `json:"minPrice"`                      // Lowest price used.
+	MaxPrice            string        `json:"maxPrice"`                      // Highest price used.
+	SpreadPercent       string        `json:"spreadPercent"`                 // Spread between the highest and lowest price used, in percent.
+	TrimmedCount        int           `json:"trimmedCount,omitempty"`        // Number of prices dropped at each end (trimmed_mean).
+	MADLowerBound       string        `json:"madLowerBound,omitempty"`       // Median minus the MAD multiplier times the MAD (vwap).
+	MADUpperBound       string        `json:"madUpperBound,omitempty"`       // Median plus the MAD multiplier times the MAD (vwap).
+	ToleranceLowerBound string        `json:"toleranceLowerBound,omitempty"` // Median minus the token tolerance (vwap).
+	ToleranceUpperBound string        `json:"toleranceUpperBound,omitempty"` // Median plus the token tolerance (vwap).
+	DispersionRatio     string        `json:"dispersionRatio,omitempty"`     // Highest price used divided by the lowest (vwap).
+	MaxDispersionRatio  string        `json:"maxDispersionRatio,omitempty"`  // Dispersion ratio the aggregation fails above (vwap).
+	Weights             []PriceWeight `json:"weights,omitempty"`             // Weights of the prices used (vwap, weighted_median).
+}
+
+// PriceWeight is the weight of a price used by the aggregation.
+type PriceWeight struct {
+	Exchange      string `json:"exchange"`      // Exchange name.
+	Symbol        string `json:"symbol"`        // Symbol.
+	Volume        string `json:"volume"`        // USD volume after the trust and freshness weights.
+	CappedVolume  string `json:"cappedVolume"`  // Volume after the exchange weight cap, the weight of the price.
+	WeightPercent string `json:"weightPercent"` // Share of the total capped volume.
+}
+
+// Reasons an exchange price is excluded from the aggregation
+const (
+	ExclusionReasonNoUSDPrice          = "no_usd_price"         // The quote currency of the price could not be converted into USD.
+	ExclusionReasonDuplicate           = "duplicate"            // Another price of the exchange and symbol was kept.
+	ExclusionReasonInvalidPrice        = "invalid_price"        // The price or volume is not a positive number.
+	ExclusionReasonBelowMinVolume      = "below_min_volume"     // The USD volume is below tokenMinVolumeUSDPerExchange.
+	ExclusionReasonUnverifiedFreshness = "unverified_freshness" // The exchange has no freshness signal and the token rejects such prices.
+	ExclusionReasonBelowLowerBound     = "below_lower_bound"    // Outlier below the lower bound (vwap).
+	ExclusionReasonAboveUpperBound     = "above_upper_bound"    // Outlier above the upper bound (vwap).
+	ExclusionReasonTrimmed             = "trimmed"              // Dropped at either end (trimmed_mean).
+)
+
+// ExcludedPrice is an exchange price left out of the aggregation.
+type ExcludedPrice struct {
+	ExchangePrice
+	Reason string `json:"reason"` // Reason the price is excluded.
+}
+
+// AggregationResult is the aggregated price of a token and the prices it was computed from.
+type AggregationResult struct {
+	Price         string           // Aggregated price.
+	TotalVolume   string           // Total USD volume of the prices used, after the weight cap.
+	ExchangeCount int              // Number of exchanges of the prices used.
+	PricesUsed    []ExchangePrice  // Prices used, with their weights as volume.
+	Outliers      []ExchangePrice  // Considered prices filtered as outliers (vwap).
+	Excluded      []ExcludedPrice  // Prices left out of the aggregation, with the reason.
+	Stats         AggregationStats // Intermediate statistics.
+}
+
+// validPrice is an exchange price with its parsed USD price and volume.
+type validPrice struct {
+	ExchangePrice
+	exchangeKey string // Key of the exchange, the exchange name when not configured.
+	price       *big.Rat
+	volume      *big.Rat // USD volume multiplied by the trust weight of the exchange.
+}
+
+// AggregatePrices aggregates the exchange prices of a token with the method of its aggregation config.
+func AggregatePrices(prices []ExchangePrice, precision uint, token string) (*AggregationResult, *appErrors.AppError) {
+	config, validPrices, excluded, err := prepareAggregation(prices, token)
+	if err != nil {
+		return nil, err
+	}
+
+	var result *AggregationResult
+	switch config.AggregationMethod() {
+	case configs.AggregationMethodWeightedMedian:
+		result, err = aggregateWeightedMedian(validPrices, config, precision)
+	case configs.AggregationMethodTrimmedMean:
+		result, err = aggregateTrimmedMean(validPrices, config, precision)
+	default:
+		result, err = aggregateVWAP(validPrices, config, precision)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	result.Excluded = append(excluded, result.Excluded...)
+	return result, nil
+}
+
+// prepareAggregation returns the aggregation config of the token, the prices that can be aggregated and the
+// prices excluded from the aggregation.
+func prepareAggregation(prices []ExchangePrice, token string) (configs.TokenAggregationConfig, []validPrice, []ExcludedPrice, *appErrors.AppError) {
+	if len(prices) == 0 {
+		return configs.TokenAggregationConfig{}, nil, nil, appErrors.ErrNoPricesFound
+	}
+
+	config, err := configs.GetTokenAggregationConfig(token)
+	if err != nil {
+		return configs.TokenAggregationConfig{}, nil, nil, err
+	}
+
+	// Exchange prices carry the exchange name, the exchange settings of the token are keyed by exchange key.
+	validPrices, excluded := filterValidPrices(prices, config, exchangeKeysByName(configs.GetExchangesConfigs()))
+	if len(validPrices) == 0 {
+		return configs.TokenAggregationConfig{}, nil, nil, appErrors.ErrAllPricesBelowMinVolume
+	}
+
+	return config, validPrices, excluded, nil
+}
+
+// filterValidPrices returns the first price of every exchange and symbol with a valid price and the minimum
+// USD volume. The volume of every price is multiplied by the trust weight of its exchange, and by the unverified
+// freshness weight of the token when the exchange has no freshness signal, which rejects the price when zero.
+// The other prices are returned as excluded, with the reason.
+func filterValidPrices(prices []ExchangePrice, config configs.TokenAggregationConfig, exchangeKeys map[string]string) ([]validPrice, []ExcludedPrice) {
+	tokenMinVolumeUSDPerExchange := new(big.Rat).SetFloat64(config.TokenMinVolumeUSDPerExchange)
+
+	type exchangeSymbol struct {
+		exchange string
+		symbol   string
+	}
+	exchangeSymbols := make(map[exchangeSymbol]bool)
+
+	validPrices := []validPrice{}
+	excluded := []ExcludedPrice{}
+	for _, p := range prices {
+		if p.Price == "" || p.Volume == "" {
+			excluded = append(excluded, ExcludedPrice{ExchangePrice: p, Reason: ExclusionReasonNoUSDPrice})
+			continue
+		}
+
+		key := exchangeSymbol{exchange: p.Exchange, symbol: p.Symbol}
+		if exchangeSymbols[key] {
+			logger.Error("Duplicate exchange and symbol", "exchange", p.Exchange, "symbol", p.Symbol, "price", p.Price, "volume", p.Volume)
+			excluded = append(excluded, ExcludedPrice{ExchangePrice: p, Reason: ExclusionReasonDuplicate})
+			continue
+		}
+		exchangeSymbols[key] = true
+
+		volumeRat, ok := new(big.Rat).SetString(p.Volume)
+		priceRat, priceOk := new(big.Rat).SetString(p.Price)
+		if !ok || !priceOk || priceRat.Sign() <= 0 {
+			excluded = append(excluded, ExcludedPrice{ExchangePrice: p, Reason: ExclusionReasonInvalidPrice})
+			continue
+		}
+		if volumeRat.Cmp(tokenMinVolumeUSDPerExchange) < 0 {
+			excluded = append(excluded, ExcludedPrice{ExchangePrice: p, Reason: ExclusionReasonBelowMinVolume})
+			continue
+		}
+
+		exchangeKey, exists := exchangeKeys[p.Exchange]
+		if !exists {
+			exchangeKey = p.Exchange
+		}
+		if trustWeight := config.ExchangeTrustWeight(exchangeKey); trustWeight != 1 {
+			volumeRat.Mul(volumeRat, new(big.Rat).SetFloat64(trustWeight))
+		}
+		if p.Freshness == configs.FreshnessSourceNone {
+			if config.UnverifiedFreshnessWeight == 0 {
+				logger.Warn("Price without freshness signal rejected", "exchange", p.Exchange, "symbol", p.Symbol)
+				excluded = append(excluded, ExcludedPrice{ExchangePrice: p, Reason: ExclusionReasonUnverifiedFreshness})
+				continue
+			}
+			volumeRat.Mul(volumeRat, new(big.Rat).SetFloat64(config.UnverifiedFreshnessWeight))
+		}
+
+		validPrices = append(validPrices, validPrice{ExchangePrice: p, exchangeKey: exchangeKey, price: priceRat, volume: volumeRat})
+	}
+	return validPrices, excluded
+}
+
+// aggregateVWAP computes the volume-weighted average of the prices within max(MAD bounds, tolerance bounds)
+// around the median, with the volume of every exchange capped to its max weight. The aggregation fails
+// when the used prices spread more than the max spread.
+func aggregateVWAP(validPrices []validPrice, config configs.TokenAggregationConfig, precision uint) (*AggregationResult, *appErrors.AppError) {
+	tokenToleranceFraction := new(big.Rat).Mul(new(big.Rat).SetFloat64(config.TokenTolerancePercent), big.NewRat(1, 100))
+	tokenMADMultiplier := new(big.Rat).SetFloat64(config.TokenMADMultiplier)
+	tokenMaxSpreadFraction := new(big.Rat).Mul(new(big.Rat).SetFloat64(config.TokenMaxSpreadPercent), big.NewRat(1, 100))
+
+	logger.Debug("Token aggregation config", "token", config.Token, "method", configs.AggregationMethodVWAP, "tokenToleranceFraction", tokenToleranceFraction, "tokenMADMultiplier", tokenMADMultiplier, "tokenMaxSpreadFraction", tokenMaxSpreadFraction, "tokenMaxExchangeWeightPercent", config.TokenMaxExchangeWeightPercent)
+
+	// Calculate the median price and the median absolute deviation
+	medianPrice := computeMedian(priceValues(validPrices))
+	mad := computeMAD(priceValues(validPrices), medianPrice)
+
+	// Compute bounds: max(MAD-based, token-tolerance-based)
+	madLower := new(big.Rat).Sub(medianPrice, new(big.Rat).Mul(tokenMADMultiplier, mad))
+	madUpper := new(big.Rat).Add(medianPrice, new(big.Rat).Mul(tokenMADMultiplier, mad))
+	tolLower := new(big.Rat).Mul(medianPrice, new(big.Rat).Sub(big.NewRat(1, 1), tokenToleranceFraction))
+	tolUpper := new(big.Rat).Mul(medianPrice, new(big.Rat).Add(big.NewRat(1, 1), tokenToleranceFraction))
+
+	lower := new(big.Rat)
+	if tolLower.Cmp(madLower) < 0 {
+		lower.Set(madLower)
+	} else {
+		lower.Set(tolLower)
+	}
+	upper := new(big.Rat)
+	if tolUpper.Cmp(madUpper) > 0 {
+		upper.Set(madUpper)
+	} else {
+		upper.Set(tolUpper)
+	}
+
+	logger.Info("VWAP Inputs", "mad", Truncate(mad, int(precision)), "medianPrice", Truncate(medianPrice, int(precision)), "tokenMADMultiplier", Truncate(tokenMADMultiplier, int(precision)), "tokenToleranceFraction", Truncate(tokenToleranceFraction, int(precision)), "madLower", Truncate(madLower, int(precision)), "tolLower", Truncate(tolLower, int(precision)), "madUpper", Truncate(madUpper, int(precision)), "tolUpper", Truncate(tolUpper, int(precision)), "lower", Truncate(lower, int(precision)), "upper", Truncate(upper, int(precision)))
+
+	filteredPrices := []validPrice{}
+	outliers := []ExchangePrice{}
+	excluded := []ExcludedPrice{}
+	for _, vp := range validPrices {
+		if vp.price.Cmp(lower) >= 0 && vp.price.Cmp(upper) <= 0 {
+			filteredPrices = append(filteredPrices, vp)
+		} else {
+			outliers = append(outliers, vp.ExchangePrice)
+			reason := ExclusionReasonAboveUpperBound
+			if vp.price.Cmp(lower) < 0 {
+				reason = ExclusionReasonBelowLowerBound
+			}
+			excluded = append(excluded, ExcludedPrice{ExchangePrice: vp.ExchangePrice, Reason: reason})
+			logger.Error("Outlier filtered", "exchange", vp.Exchange, "symbol", vp.Symbol, "price", Truncate(vp.price, int(precision)), "volume", Truncate(vp.volume, int(precision)), "medianPrice", Truncate(medianPrice, int(precision)), "mad", Truncate(mad, int(precision)), "lower", Truncate(lower, int(precision)), "upper", Truncate(upper, int(precision)))
+		}
+	}
+
+	if len(filteredPrices) == 0 {
+		return nil, appErrors.ErrAllPricesOutlierFiltered
+	}
+
+	cappedVolumes, cappedTotalVolume, err := capExchangeWeights(filteredPrices, config, precision)
+	if err != nil {
+		return nil, err
+	}
+
+	weightedSum := big.NewRat(0, 1)
+	for i, vp := range filteredPrices {
+		weightedSum.Add(weightedSum, new(big.Rat).Mul(vp.price, cappedVolumes[i]))
+	}
+
+	minPrice, maxPrice := priceRange(filteredPrices)
+	ratio := new(big.Rat).Quo(maxPrice, minPrice)
+	dispersionThreshold := new(big.Rat).Add(big.NewRat(1, 1), tokenMaxSpreadFraction)
+	logger.Debug("Max Price", "maxPrice", Truncate(maxPrice, int(precision)), "minPrice", Truncate(minPrice, int(precision)), "ratio", Truncate(ratio, int(precision)), "dispersionThreshold", Truncate(dispersionThreshold, int(precision)))
+
+	if ratio.Cmp(dispersionThreshold) > 0 {
+		return nil, appErrors.ErrCrossVenueDispersionTooHigh
+	}
+
+	volumeWeightedAvg := new(big.Rat).Quo(weightedSum, cappedTotalVolume)
+
+	result := newAggregationResult(configs.AggregationMethodVWAP, volumeWeightedAvg, cappedTotalVolume, validPrices, filteredPrices, cappedVolumes, medianPrice, precision)
+	result.Stats.MAD = Truncate(mad, int(precision))
+	result.Stats.LowerBound = Truncate(lower, int(precision))
+	result.Stats.UpperBound = Truncate(upper, int(precision))
+	result.Stats.MADLowerBound = Truncate(madLower, int(precision))
+	result.Stats.MADUpperBound = Truncate(madUpper, int(precision))
+	result.Stats.ToleranceLowerBound = Truncate(tolLower, int(precision))
+	result.Stats.ToleranceUpperBound = Truncate(tolUpper, int(precision))
+	result.Stats.DispersionRatio = Truncate(ratio, int(precision))
+	result.Stats.MaxDispersionRatio = Truncate(dispersionThreshold, int(precision))
+	result.Stats.Weights = priceWeights(filteredPrices, cappedVolumes, cappedTotalVolume, precision)
+	result.Outliers = outliers
+	result.Excluded = excluded
+	return result, nil
+}
+
+// aggregateWeightedMedian computes the volume-weighted median of the prices, with the volume of every exchange
+// capped to its max weight. It is the lowest price whose cumulative weight reaches half of the total weight,
+// averaged with the next price when the half is reached exactly.
+func aggregateWeightedMedian(validPrices []validPrice, config configs.TokenAggregationConfig, precision uint) (*AggregationResult, *appErrors.AppError) {
+	sortedPrices := sortByPrice(validPrices)
+
+	cappedVolumes, cappedTotalVolume, err := capExchangeWeights(sortedPrices, config, precision)
+	if err != nil {
+		return nil, err
+	}
+
+	halfVolume := new(big.Rat).Quo(cappedTotalVolume, big.NewRat(2, 1))
+	cumulativeVolume := big.NewRat(0, 1)
+	var weightedMedian *big.Rat
+	for i, vp := range sortedPrices {
+		cumulativeVolume.Add(cumulativeVolume, cappedVolumes[i])
+		cmp := cumulativeVolume.Cmp(halfVolume)
+		if cmp < 0 {
+			continue
+		}
+		weightedMedian = new(big.Rat).Set(vp.price)
+		if cmp == 0 && i+1 < len(sortedPrices) {
+			weightedMedian.Add(weightedMedian, sortedPrices[i+1].price)
+			weightedMedian.Quo(weightedMedian, big.NewRat(2, 1))
+		}
+		break
+	}
+
+	medianPrice := computeMedian(priceValues(validPrices))
+	result := newAggregationResult(configs.AggregationMethodWeightedMedian, weightedMedian, cappedTotalVolume, validPrices, sortedPrices, cappedVolumes, medianPrice, precision)
+	result.Stats.Weights = priceWeights(sortedPrices, cappedVolumes, cappedTotalVolume, precision)
+	return result, nil
+}
+
+// aggregateTrimmedMean computes the mean of the prices left after dropping the trim percentage of the
+// lowest and of the highest prices. At least one price is always kept.
+func aggregateTrimmedMean(validPrices []validPrice, config configs.TokenAggregationConfig, precision uint) (*AggregationResult, *appErrors.AppError) {
+	sortedPrices := sortByPrice(validPrices)
+
+	trimmedCount := int(math.Floor(float64(len(sortedPrices)) * config.TrimPercent / 100))
+	if len(sortedPrices)-2*trimmedCount < 1 {
+		trimmedCount = (len(sortedPrices) - 1) / 2
+	}
+	usedPrices := sortedPrices[trimmedCount : len(sortedPrices)-trimmedCount]
+
+	sum := big.NewRat(0, 1)
+	totalVolume := big.NewRat(0, 1)
+	volumes := make([]*big.Rat, len(usedPrices))
+	for i, vp := range usedPrices {
+		sum.Add(sum, vp.price)
+		totalVolume.Add(totalVolume, vp.volume)
+		volumes[i] = vp.volume
+	}
+	mean := sum.Quo(sum, big.NewRat(int64(len(usedPrices)), 1))
+
+	medianPrice := computeMedian(priceValues(validPrices))
+	result := newAggregationResult(configs.AggregationMethodTrimmedMean, mean, totalVolume, validPrices, usedPrices, volumes, medianPrice, precision)
+	result.Stats.TrimmedCount = trimmedCount
+	for _, vp := range sortedPrices[:trimmedCount] {
+		result.Excluded = append(result.Excluded, ExcludedPrice{ExchangePrice: vp.ExchangePrice, Reason: ExclusionReasonTrimmed})
+	}
+	for _, vp := range sortedPrices[len(sortedPrices)-trimmedCount:] {
+		result.Excluded = append(result.Excluded, ExcludedPrice{ExchangePrice: vp.ExchangePrice, Reason: ExclusionReasonTrimmed})
+	}
+	return result, nil
+}
+
+// capExchangeWeights returns the volume of every price with the total volume of each exchange capped to
+// its max weight percent of the total volume, and the total of the capped volumes.
+func capExchangeWeights(prices []validPrice, config configs.TokenAggregationConfig, precision uint) ([]*big.Rat, *big.Rat, *appErrors.AppError) {
+	totalVolume := big.NewRat(0, 1)
+	exchangeVolumes := make(map[string]*big.Rat)
+	for _, vp := range prices {
+		totalVolume.Add(totalVolume, vp.volume)
+		if _, exists := exchangeVolumes[vp.exchangeKey]; !exists {
+			exchangeVolumes[vp.exchangeKey] = big.NewRat(0, 1)
+		}
+		exchangeVolumes[vp.exchangeKey].Add(exchangeVolumes[vp.exchangeKey], vp.volume)
+	}
+
+	if totalVolume.Sign() <= 0 {
+		return nil, nil, appErrors.ErrZeroVolume
+	}
+
+	maxWeights := make(map[string]*big.Rat, len(exchangeVolumes))
+	for exchange := range exchangeVolumes {
+		maxWeightFraction := new(big.Rat).Mul(new(big.Rat).SetFloat64(config.ExchangeMaxWeightPercent(exchange)), big.NewRat(1, 100))
+		maxWeights[exchange] = maxWeightFraction.Mul(maxWeightFraction, totalVolume)
+	}
+
+	cappedVolumes := make([]*big.Rat, len(prices))
+	cappedTotalVolume := big.NewRat(0, 1)
+	for i, vp := range prices {
+		cappedVolume := vp.volume
+		maxWeight := maxWeights[vp.exchangeKey]
+		if exchangeVolumes[vp.exchangeKey].Cmp(maxWeight) > 0 {
+			logger.Debug("Scaling volume", "exchange", vp.Exchange, "volume", Truncate(vp.volume, int(precision)), "maxWeight", Truncate(maxWeight, int(precision)))
+			// Scale down proportionally if total volume exceeds cap
+			scale := new(big.Rat).Quo(maxWeight, exchangeVolumes[vp.exchangeKey])
+			cappedVolume = new(big.Rat).Mul(vp.volume, scale)
+		}
+		cappedVolumes[i] = cappedVolume
+		cappedTotalVolume.Add(cappedTotalVolume, cappedVolume)
+	}
+
+	if cappedTotalVolume.Sign() <= 0 {
+		return nil, nil, appErrors.ErrZeroCappedVolume
+	}
+
+	return cappedVolumes, cappedTotalVolume, nil
+}
+
+// newAggregationResult builds the result of an aggregation from the prices used and their weights.
+func newAggregationResult(method string, price, totalVolume *big.Rat, consideredPrices, usedPrices []validPrice, weights []*big.Rat, medianPrice *big.Rat, precision uint) *AggregationResult {
+	exchanges := make(map[string]bool)
+	pricesUsed := make([]ExchangePrice, 0, len(usedPrices))
+	for i, vp := range usedPrices {
+		exchanges[vp.Exchange] = true
+		usedPrice := vp.ExchangePrice
+		usedPrice.Price = Truncate(vp.price, int(precision))
+		usedPrice.Volume = Truncate(weights[i], int(precision))
+		pricesUsed = append(pricesUsed, usedPrice)
+	}
+
+	minPrice, maxPrice := priceRange(usedPrices)
+	spread := new(big.Rat).Quo(maxPrice, minPrice)
+	spread.Sub(spread, big.NewRat(1, 1)).Mul(spread, big.NewRat(100, 1))
+
+	return &AggregationResult{
+		Price:         Truncate(price, int(precision)),
+		TotalVolume:   Truncate(totalVolume, int(precision)),
+		ExchangeCount: len(exchanges),
+		PricesUsed:    pricesUsed,
+		Stats: AggregationStats{
+			Method:           method,
+			PricesConsidered: len(consideredPrices),
+			PricesUsed:       len(usedPrices),
+			MedianPrice:      Truncate(medianPrice, int(precision)),
+			MinPrice:         Truncate(minPrice, int(precision)),
+			MaxPrice:         Truncate(maxPrice, int(precision)),
+			SpreadPercent:    Truncate(spread, int(precision)),
+		},
+	}
+}
+
+// priceWeights returns the weight of every used price, from its volume and capped volume.
+func priceWeights(prices []validPrice, cappedVolumes []*big.Rat, cappedTotalVolume *big.Rat, precision uint) []PriceWeight {
+	weights := make([]PriceWeight, len(prices))
+	for i, vp := range prices {
+		weightPercent := new(big.Rat).Mul(cappedVolumes[i], big.NewRat(100, 1))
+		weightPercent.Quo(weightPercent, cappedTotalVolume)
+		weights[i] = PriceWeight{
+			Exchange:      vp.Exchange,
+			Symbol:        vp.Symbol,
+			Volume:        Truncate(vp.volume, int(precision)),
+			CappedVolume:  Truncate(cappedVolumes[i], int(precision)),
+			WeightPercent: Truncate(weightPercent, int(precision)),
+		}
+	}
+	return weights
+}
+
+// priceValues returns the prices of the valid prices.
+func priceValues(prices []validPrice) []*big.Rat {
+	values := make([]*big.Rat, len(prices))
+	for i, vp := range prices {
+		values[i] = vp.price
+	}
+	return values
+}
+
+// priceRange returns the lowest and highest of the valid prices.
+func priceRange(prices []validPrice) (minPrice, maxPrice *big.Rat) {
+	minPrice = prices[0].price
+	maxPrice = prices[0].price
+	for _, vp := range prices[1:] {
+		if vp.price.Cmp(minPrice) < 0 {
+			minPrice = vp.price
+		}
+		if vp.price.Cmp(maxPrice) > 0 {
+			maxPrice = vp.price
+		}
+	}
+	return minPrice, maxPrice
+}
+
+// sortByPrice returns a copy of the valid prices sorted by ascending price.
+func sortByPrice(prices []validPrice) []validPrice {
+	sorted := make([]validPrice, len(prices))
+	copy(sorted, prices)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].price.Cmp(sorted[j].price) < 0
+	})
+	return sorted
+}
@@ -0,0 +1,261 @@
+package data_extraction
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	configs "github.com/venture23-aleo/aleo-oracle-notarization-backend/internal/config"
+	appErrors "github.com/venture23-aleo/aleo-oracle-notarization-backend/pkg/errors"
+)
+
+func TestAggregateWeightedMedian(t *testing.T) {
+	config := configs.TokenAggregationConfig{Method: configs.AggregationMethodWeightedMedian, TokenMaxExchangeWeightPercent: 100}
+
+	tests := []struct {
+		name          string
+		prices        []ExchangePrice
+		maxWeight     float64
+		exchanges     map[string]configs.TokenExchangeConfig
+		expectedPrice string
+	}{
+		{
+			name: "heaviest venue holds the median",
+			prices: []ExchangePrice{
+				{Exchange: "Binance", Symbol: "ALEOUSDT", Price: "0.20", Volume: "1000"},
+				{Exchange: "Gate", Symbol: "ALEO_USDT", Price: "0.25", Volume: "5000"},
+				{Exchange: "XT", Symbol: "ALEO_USDT", Price: "0.90", Volume: "1000"},
+			},
+			expectedPrice: "0.250000",
+		},
+		{
+			name: "half of the weight reached exactly",
+			prices: []ExchangePrice{
+				{Exchange: "Binance", Symbol: "ALEOUSDT", Price: "0.20", Volume: "1000"},
+				{Exchange: "Gate", Symbol: "ALEO_USDT", Price: "0.30", Volume: "1000"},
+			},
+			expectedPrice: "0.250000",
+		},
+		{
+			name: "weight cap limits a dominant venue",
+			prices: []ExchangePrice{
+				{Exchange: "Binance", Symbol: "ALEOUSDT", Price: "0.20", Volume: "9000"},
+				{Exchange: "Gate", Symbol: "ALEO_USDT", Price: "0.24", Volume: "500"},
+				{Exchange: "XT", Symbol: "ALEO_USDT", Price: "0.25", Volume: "500"},
+				{Exchange: "MEXC", Symbol: "ALEOUSDT", Price: "0.26", Volume: "500"},
+				{Exchange: "Coinbase", Symbol: "ALEO-USD", Price: "0.27", Volume: "500"},
+			},
+			maxWeight:     10,
+			expectedPrice: "0.240000",
+		},
+		{
+			name: "exchange weight cap",
+			prices: []ExchangePrice{
+				{Exchange: "Binance", Symbol: "ALEOUSDT", Price: "0.20", Volume: "9000"},
+				{Exchange: "Gate", Symbol: "ALEO_USDT", Price: "0.24", Volume: "500"},
+				{Exchange: "XT", Symbol: "ALEO_USDT", Price: "0.25", Volume: "500"},
+			},
+			exchanges: map[string]configs.TokenExchangeConfig{
+				"Binance": {MaxWeightPercent: 5},
+			},
+			expectedPrice: "0.240000",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := config
+			if tt.maxWeight != 0 {
+				config.TokenMaxExchangeWeightPercent = tt.maxWeight
+			}
+			config.Exchanges = tt.exchanges
+			validPrices := mustValidPrices(t, tt.prices)
+
+			result, err := aggregateWeightedMedian(validPrices, config, 6)
+			require.Nil(t, err)
+			assert.Equal(t, tt.expectedPrice, result.Price)
+			assert.Equal(t, configs.AggregationMethodWeightedMedian, result.Stats.Method)
+			assert.Equal(t, len(tt.prices), result.Stats.PricesUsed)
+			assert.Equal(t, len(tt.prices), result.ExchangeCount)
+		})
+	}
+}
+
+func TestAggregateTrimmedMean(t *testing.T) {
+	prices := []ExchangePrice{
+		{Exchange: "Binance", Symbol: "USDCUSDT", Price: "0.9990", Volume: "100000"},
+		{Exchange: "Bybit", Symbol: "USDCUSDT", Price: "1.0000", Volume: "100000"},
+		{Exchange: "Coinbase", Symbol: "USDC-USD", Price: "0.9998", Volume: "100000"},
+		{Exchange: "Kraken", Symbol: "USDCUSD", Price: "0.9996", Volume: "100000"},
+		{Exchange: "Crypto", Symbol: "USDC_USD", Price: "1.0500", Volume: "100000"},
+	}
+
+	tests := []struct {
+		name          string
+		trimPercent   float64
+		expectedPrice string
+		expectedTrim  int
+		expectedUsed  int
+	}{
+		{name: "no trimming", trimPercent: 0, expectedPrice: "1.009680", expectedTrim: 0, expectedUsed: 5},
+		{name: "one price dropped at each end", trimPercent: 20, expectedPrice: "0.999800", expectedTrim: 1, expectedUsed: 3},
+		{name: "median kept when trimming everything", trimPercent: 49, expectedPrice: "0.999800", expectedTrim: 2, expectedUsed: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := configs.TokenAggregationConfig{Method: configs.AggregationMethodTrimmedMean, TrimPercent: tt.trimPercent}
+
+			result, err := aggregateTrimmedMean(mustValidPrices(t, prices), config, 6)
+			require.Nil(t, err)
+			assert.Equal(t, tt.expectedPrice, result.Price)
+			assert.Equal(t, tt.expectedTrim, result.Stats.TrimmedCount)
+			assert.Equal(t, tt.expectedUsed, result.Stats.PricesUsed)
+			assert.Equal(t, 5, result.Stats.PricesConsidered)
+			assert.Equal(t, "0.999800", result.Stats.MedianPrice)
+			assert.Len(t, result.Excluded, 2*tt.expectedTrim)
+			for _, price := range result.Excluded {
+				assert.Equal(t, ExclusionReasonTrimmed, price.Reason)
+			}
+		})
+	}
+}
+
+func TestAggregatePrices(t *testing.T) {
+	t.Run("vwap statistics", func(t *testing.T) {
+		result, err := AggregatePrices([]ExchangePrice{
+			{Exchange: "Binance", Symbol: "BTCUSDT", Price: "50000", Volume: "100000000"},
+			{Exchange: "Bybit", Symbol: "BTCUSDT", Price: "50010", Volume: "100000000"},
+			{Exchange: "Coinbase", Symbol: "BTC-USD", Price: "60000", Volume: "100000000"},
+		}, 6, "BTC")
+		require.Nil(t, err)
+		assert.Equal(t, "50005.000000", result.Price)
+		assert.Equal(t, configs.AggregationMethodVWAP, result.Stats.Method)
+		assert.Equal(t, 3, result.Stats.PricesConsidered)
+		assert.Equal(t, 2, result.Stats.PricesUsed)
+		assert.Equal(t, "50010.000000", result.Stats.MedianPrice)
+		assert.Equal(t, "10.000000", result.Stats.MAD)
+		assert.Equal(t, "50000.000000", result.Stats.MinPrice)
+		assert.Equal(t, "50010.000000", result.Stats.MaxPrice)
+		assert.Equal(t, "0.020000", result.Stats.SpreadPercent)
+		assert.NotEmpty(t, result.Stats.LowerBound)
+		assert.NotEmpty(t, result.Stats.UpperBound)
+		require.Len(t, result.Outliers, 1)
+		assert.Equal(t, "Coinbase", result.Outliers[0].Exchange)
+		require.Len(t, result.Excluded, 1)
+		assert.Equal(t, ExclusionReasonAboveUpperBound, result.Excluded[0].Reason)
+		assert.Equal(t, "1.000200", result.Stats.DispersionRatio)
+		assert.Equal(t, "1.006300", result.Stats.MaxDispersionRatio)
+		require.Len(t, result.Stats.Weights, 2)
+		assert.Equal(t, PriceWeight{Exchange: "Binance", Symbol: "BTCUSDT", Volume: "100000000.000000", CappedVolume: "100000000.000000", WeightPercent: "50.000000"}, result.Stats.Weights[0])
+	})
+
+	t.Run("configured method is used", func(t *testing.T) {
+		for token, method := range map[string]string{
+			"BTC":  configs.AggregationMethodVWAP,
+			"ALEO": configs.AggregationMethodWeightedMedian,
+			"USDT": configs.AggregationMethodTrimmedMean,
+		} {
+			result, err := AggregatePrices([]ExchangePrice{
+				{Exchange: "Binance", Symbol: token + "USD", Price: "1", Volume: "100000000"},
+				{Exchange: "Coinbase", Symbol: token + "-USD", Price: "1", Volume: "100000000"},
+			}, 6, token)
+			require.Nil(t, err, token)
+			assert.Equal(t, method, result.Stats.Method, token)
+		}
+	})
+
+	t.Run("no prices", func(t *testing.T) {
+		_, err := AggregatePrices(nil, 6, "BTC")
+		assert.Equal(t, appErrors.ErrNoPricesFound, err)
+	})
+
+	t.Run("prices below the minimum volume", func(t *testing.T) {
+		_, err := AggregatePrices([]ExchangePrice{{Exchange: "Binance", Symbol: "BTCUSDT", Price: "50000", Volume: "1"}}, 6, "BTC")
+		assert.Equal(t, appErrors.ErrAllPricesBelowMinVolume, err)
+	})
+
+	t.Run("token without aggregation config", func(t *testing.T) {
+		_, err := AggregatePrices([]ExchangePrice{{Exchange: "Binance", Symbol: "SOLUSDT", Price: "150", Volume: "1"}}, 6, "SOL")
+		assert.Equal(t, appErrors.ErrAggregationConfigNotFound, err)
+	})
+}
+
+// mustValidPrices parses exchange prices into valid prices.
+func TestFilterValidPrices(t *testing.T) {
+	config := configs.TokenAggregationConfig{
+		TokenMinVolumeUSDPerExchange: 1000,
+		Exchanges: map[string]configs.TokenExchangeConfig{
+			"binance": {TrustWeight: 0.5},
+			"gate":    {TrustWeight: 2},
+		},
+	}
+	exchangeKeys := map[string]string{"Binance": "binance", "Gate": "gate"}
+
+	validPrices, excluded := filterValidPrices([]ExchangePrice{
+		{Exchange: "Binance", Symbol: "ALEOUSDT", Price: "0.20", Volume: "1500"},
+		{Exchange: "Gate", Symbol: "ALEO_USDT", Price: "0.21", Volume: "1000"},
+		{Exchange: "Gate", Symbol: "ALEO_USDT", Price: "0.22", Volume: "1000"},
+		{Exchange: "XT", Symbol: "ALEO_USDT", Price: "0.23", Volume: "1000"},
+		{Exchange: "MEXC", Symbol: "ALEOUSDT", Price: "0.24", Volume: "999"},
+		{Exchange: "Coinbase", Symbol: "ALEO-USD", Price: "", Volume: "5000"},
+	}, config, exchangeKeys)
+
+	require.Len(t, validPrices, 3)
+	expected := []struct {
+		exchangeKey string
+		volume      string
+	}{
+		{exchangeKey: "binance", volume: "750"},
+		{exchangeKey: "gate", volume: "2000"},
+		{exchangeKey: "XT", volume: "1000"},
+	}
+	for i, vp := range validPrices {
+		assert.Equal(t, expected[i].exchangeKey, vp.exchangeKey)
+		assert.Equal(t, expected[i].volume, vp.volume.RatString(), "the minimum volume applies before the trust weight")
+	}
+
+	reasons := make(map[string]string)
+	for _, price := range excluded {
+		reasons[price.Exchange] = price.Reason
+	}
+	assert.Equal(t, map[string]string{
+		"Gate":     ExclusionReasonDuplicate,
+		"MEXC":     ExclusionReasonBelowMinVolume,
+		"Coinbase": ExclusionReasonNoUSDPrice,
+	}, reasons)
+
+	t.Run("prices without freshness signal", func(t *testing.T) {
+		prices := []ExchangePrice{
+			{Exchange: "XT", Symbol: "ALEO_USDT", Price: "0.23", Volume: "1000", Freshness: configs.FreshnessSourceResponse},
+			{Exchange: "Gate", Symbol: "ALEO_USDT", Price: "0.21", Volume: "1000", Freshness: configs.FreshnessSourceNone},
+		}
+
+		validPrices, excluded := filterValidPrices(prices, config, exchangeKeys)
+		require.Len(t, validPrices, 1, "rejected without an unverified freshness weight")
+		assert.Equal(t, "XT", validPrices[0].exchangeKey)
+		require.Len(t, excluded, 1)
+		assert.Equal(t, ExclusionReasonUnverifiedFreshness, excluded[0].Reason)
+
+		config.UnverifiedFreshnessWeight = 0.25
+		validPrices, excluded = filterValidPrices(prices, config, exchangeKeys)
+		require.Len(t, validPrices, 2)
+		assert.Equal(t, "1000", validPrices[0].volume.RatString())
+		assert.Equal(t, "500", validPrices[1].volume.RatString(), "down-weighted after the trust weight")
+		assert.Empty(t, excluded)
+	})
+}
+
+func mustValidPrices(t *testing.T, prices []ExchangePrice) []validPrice {
+	t.Helper()
+	validPrices := make([]validPrice, len(prices))
+	for i, p := range prices {
+		price, ok := new(big.Rat).SetString(p.Price)
+		require.True(t, ok)
+		volume, ok := new(big.Rat).SetString(p.Volume)
+		require.True(t, ok)
+		validPrices[i] = validPrice{ExchangePrice: p, exchangeKey: p.Exchange, price: price, volume: volume}
+	}
+	return validPrices
+}
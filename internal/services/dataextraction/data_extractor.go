@@ -12,18 +12,19 @@ import (
 	"github.com/hashicorp/go-retryablehttp"
 	"github.com/venture23-aleo/aleo-oracle-notarization-backend/internal/common"
 	"github.com/venture23-aleo/aleo-oracle-notarization-backend/internal/constants"
-	appErrors "github.com/venture23-aleo/aleo-oracle-notarization-backend/internal/errors"
 	httpUtil "github.com/venture23-aleo/aleo-oracle-notarization-backend/internal/httputil"
-	"github.com/venture23-aleo/aleo-oracle-notarization-backend/internal/logger"
 	"github.com/venture23-aleo/aleo-oracle-notarization-backend/internal/metrics"
 	attestation "github.com/venture23-aleo/aleo-oracle-notarization-backend/internal/services/attestation"
+	appErrors "github.com/venture23-aleo/aleo-oracle-notarization-backend/pkg/errors"
+	"github.com/venture23-aleo/aleo-oracle-notarization-backend/pkg/logger"
 )
 
 // ExtractDataResult represents the result of data extraction
 type ExtractDataResult struct {
-	ResponseBody    string // The response body.
-	AttestationData string // The attestation data.
-	StatusCode      int    // The status code.
+	ResponseBody    string         // The response body.
+	AttestationData string         // The attestation data.
+	StatusCode      int            // The status code.
+	AttestedPrice   *AttestedPrice // The price recorded as the last attested price of its token, nil outside price feeds.
 }
 
 // Truncate returns r truncated to `prec` decimal places as a *big.Rat.
@@ -172,11 +173,13 @@ func ExtractDataFromTargetURL(ctx context.Context, attestationRequest attestatio
 	// Get logger from context (includes request ID)
 	reqLogger := logger.FromContext(ctx)
 
-	if common.IsPriceFeedURL(attestationRequest.Url) {
+	if priceFeedURL, ok := common.ParsePriceFeedURL(attestationRequest.Url); ok {
 		reqLogger.Debug("Processing price feed request", "url", attestationRequest.Url)
-		token := common.ExtractTokenFromPriceFeedURL(attestationRequest.Url)
+		if priceFeedURL.TWAPWindow > 0 {
+			return ExtractTWAPPriceFeedData(ctx, attestationRequest, priceFeedURL.Token, priceFeedURL.TWAPWindow, timestamp)
+		}
 		priceFeedClient := NewPriceFeedClient()
-		return priceFeedClient.ExtractPriceFeedData(ctx, attestationRequest, token, timestamp)
+		return priceFeedClient.ExtractPriceFeedData(ctx, attestationRequest, priceFeedURL.Token, timestamp)
 	}
 
 	switch attestationRequest.ResponseFormat {
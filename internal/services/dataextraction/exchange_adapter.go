@@ -0,0 +1,312 @@
+package data_extraction
+
+import (
+	"fmt"
+	"math/big"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/tidwall/gjson"
+	configs "github.com/venture23-aleo/aleo-oracle-notarization-backend/internal/config"
+	appErrors "github.com/venture23-aleo/aleo-oracle-notarization-backend/pkg/errors"
+	"github.com/venture23-aleo/aleo-oracle-notarization-backend/pkg/logger"
+)
+
+// exchangeResponseParser parses the price and volume of a symbol from an exchange response, and the ticker
+// timestamp in Unix milliseconds, zero when the response carries none.
+type exchangeResponseParser func(data []byte, symbol string, token string) (price, volume string, responseTime int64, err *appErrors.AppError)
+
+// exchangeAdapter is a hand-written exchange parser and the unit of the volume it parses.
+type exchangeAdapter struct {
+	parse             exchangeResponseParser
+	volumeUnit        string // configs.VolumeDenominationBase or configs.VolumeDenominationQuote.
+	responseTimestamp bool   // Whether the parser returns the ticker timestamp, for the response freshness source.
+}
+
+// exchangeAdapters are the hand-written exchange parsers, selected by the adapter name of an exchange config.
+var exchangeAdapters = map[string]exchangeAdapter{
+	"binance": {
+		parse: func(data []byte, symbol string, _ string) (string, string, int64, *appErrors.AppError) {
+			return parseBinanceResponse(data, symbol)
+		},
+		volumeUnit:        configs.VolumeDenominationBase, // volume
+		responseTimestamp: true,
+	},
+	"bybit": {
+		parse: func(data []byte, symbol string, _ string) (string, string, int64, *appErrors.AppError) {
+			return parseBybitResponse(data, symbol)
+		},
+		volumeUnit:        configs.VolumeDenominationBase, // volume24h
+		responseTimestamp: true,
+	},
+	"coinbase": {
+		parse: func(data []byte, symbol string, _ string) (string, string, int64, *appErrors.AppError) {
+			return parseCoinbaseResponse(data, symbol)
+		},
+		volumeUnit:        configs.VolumeDenominationBase, // volume
+		responseTimestamp: true,
+	},
+	"crypto": {
+		parse: func(data []byte, symbol string, _ string) (string, string, int64, *appErrors.AppError) {
+			return parseCryptoResponse(data, symbol)
+		},
+		volumeUnit:        configs.VolumeDenominationBase, // v
+		responseTimestamp: true,
+	},
+	"xt": {
+		parse: func(data []byte, symbol string, _ string) (string, string, int64, *appErrors.AppError) {
+			return parseXTResponse(data, symbol)
+		},
+		volumeUnit:        configs.VolumeDenominationBase, // q, the traded quantity. v is the traded value.
+		responseTimestamp: true,
+	},
+	"gate": {
+		parse: func(data []byte, symbol string, _ string) (string, string, int64, *appErrors.AppError) {
+			return parseGateResponse(data, symbol)
+		},
+		volumeUnit:        configs.VolumeDenominationBase, // base_volume
+		responseTimestamp: false,
+	},
+	"mexc": {
+		parse: func(data []byte, symbol string, _ string) (string, string, int64, *appErrors.AppError) {
+			return parseMEXCResponse(data, symbol)
+		},
+		volumeUnit:        configs.VolumeDenominationBase, // volume
+		responseTimestamp: true,
+	},
+	"kraken": {
+		parse: func(data []byte, symbol string, _ string) (string, string, int64, *appErrors.AppError) {
+			return parseKrakenResponse(data, symbol)
+		},
+		volumeUnit:        configs.VolumeDenominationBase, // v[1], the volume of the last 24 hours.
+		responseTimestamp: false,
+	},
+	"gemini": {
+		parse:             parseGeminiResponse,
+		volumeUnit:        configs.VolumeDenominationBase, // volume.{token}, keyed by the base token.
+		responseTimestamp: true,
+	},
+	"bitstamp": {
+		parse: func(data []byte, symbol string, _ string) (string, string, int64, *appErrors.AppError) {
+			return parseBitstampResponse(data, symbol)
+		},
+		volumeUnit:        configs.VolumeDenominationBase, // volume
+		responseTimestamp: true,
+	},
+	"okx": {
+		parse: func(data []byte, symbol string, _ string) (string, string, int64, *appErrors.AppError) {
+			return parseOKXResponse(data, symbol)
+		},
+		volumeUnit:        configs.VolumeDenominationBase, // vol24h
+		responseTimestamp: true,
+	},
+	"kucoin": {
+		parse: func(data []byte, symbol string, _ string) (string, string, int64, *appErrors.AppError) {
+			return parseKuCoinResponse(data, symbol)
+		},
+		volumeUnit:        configs.VolumeDenominationBase, // vol
+		responseTimestamp: true,
+	},
+	"bitfinex": {
+		parse: func(data []byte, symbol string, _ string) (string, string, int64, *appErrors.AppError) {
+			return parseBitfinexResponse(data, symbol)
+		},
+		volumeUnit:        configs.VolumeDenominationBase, // VOLUME
+		responseTimestamp: false,
+	},
+	"htx": {
+		parse: func(data []byte, symbol string, _ string) (string, string, int64, *appErrors.AppError) {
+			return parseHTXResponse(data, symbol)
+		},
+		volumeUnit:        configs.VolumeDenominationBase, // amount
+		responseTimestamp: true,
+	},
+	"bitget": {
+		parse: func(data []byte, symbol string, _ string) (string, string, int64, *appErrors.AppError) {
+			return parseBitgetResponse(data, symbol)
+		},
+		volumeUnit:        configs.VolumeDenominationBase, // baseVolume
+		responseTimestamp: true,
+	},
+}
+
+func init() {
+	// Binance US serves the same API as Binance.
+	exchangeAdapters["binance-us"] = exchangeAdapters["binance"]
+}
+
+// ValidateExchangeAdapters checks that every exchange config without a response format names a known adapter,
+// which returns the ticker timestamp when the exchange uses the response freshness source.
+// Should be called during server startup next to configs.ValidateConfigs.
+func ValidateExchangeAdapters(exchangesConfig configs.ExchangesConfig) error {
+	var errors []string
+	for exchangeKey, config := range exchangesConfig {
+		if config.ResponseFormat != nil {
+			continue
+		}
+		adapter := exchangeAdapterName(exchangeKey, config)
+		exchangeAdapter, exists := exchangeAdapters[adapter]
+		if !exists {
+			errors = append(errors, fmt.Sprintf("Exchange %s: unknown adapter %s", exchangeKey, adapter))
+		} else if !exchangeAdapter.responseTimestamp && config.FreshnessSource() == configs.FreshnessSourceResponse {
+			errors = append(errors, fmt.Sprintf("Exchange %s: adapter %s returns no timestamp for the %s freshness source", exchangeKey, adapter, configs.FreshnessSourceResponse))
+		}
+	}
+
+	if len(errors) > 0 {
+		sort.Strings(errors)
+		return fmt.Errorf("exchange adapter validation failed:\n%s", strings.Join(errors, "\n"))
+	}
+	return nil
+}
+
+// exchangeAdapterName returns the adapter name of an exchange, defaulting to the exchange key.
+func exchangeAdapterName(exchange string, config configs.ExchangeConfig) string {
+	if config.Adapter != "" {
+		return config.Adapter
+	}
+	return exchange
+}
+
+// parseExchangeResponse parses the response from different exchanges.
+//
+// Exchanges with a response format are parsed by the generic parser, the others by their named adapter.
+// The response time is the ticker timestamp in Unix milliseconds, zero when the response carries none.
+func (c *PriceFeedClient) parseExchangeResponse(exchange string, data []byte, symbol string, token string) (price, volume string, responseTime int64, err *appErrors.AppError) {
+	config := c.exchangeConfigs[exchange]
+	if config.ResponseFormat != nil {
+		return parseGenericExchangeResponse(exchange, config.ResponseFormat, data, symbol, token)
+	}
+
+	adapter, exists := exchangeAdapters[exchangeAdapterName(exchange, config)]
+	if !exists {
+		logger.Error("Unsupported exchange: ", "exchange", exchange, "adapter", config.Adapter)
+		return "", "", 0, appErrors.ErrExchangeNotSupported
+	}
+	return adapter.parse(data, symbol, token)
+}
+
+// exchangeVolumeUnit returns the unit of the volume parsed from the responses of an exchange.
+// Response formats declare it with their volume denomination, named adapters with their volume unit.
+func (c *PriceFeedClient) exchangeVolumeUnit(exchange string) string {
+	config := c.exchangeConfigs[exchange]
+	if config.ResponseFormat != nil {
+		if config.ResponseFormat.VolumeDenomination == configs.VolumeDenominationQuote {
+			return configs.VolumeDenominationQuote
+		}
+		return configs.VolumeDenominationBase
+	}
+
+	if adapter, exists := exchangeAdapters[exchangeAdapterName(exchange, config)]; exists && adapter.volumeUnit != "" {
+		return adapter.volumeUnit
+	}
+	return configs.VolumeDenominationBase
+}
+
+// parseGenericExchangeResponse parses the response of an exchange described by a response format.
+//
+// The paths of the format are resolved with gjson after replacing the {symbol} and {token} placeholders.
+// The symbol is only validated and the timestamp only returned when their paths are set. The volume is returned
+// in the denomination declared by the format.
+func parseGenericExchangeResponse(exchange string, format *configs.ExchangeResponseFormat, data []byte, symbol string, token string) (price, volume string, responseTime int64, err *appErrors.AppError) {
+	if !gjson.ValidBytes(data) {
+		logger.Error("Error unmarshalling data: ", "exchange", exchange, "symbol", symbol)
+		return "", "", 0, appErrors.ErrDecodingExchangeResponse
+	}
+
+	resolvePath := func(path string) string {
+		path = strings.ReplaceAll(path, "{symbol}", gjson.Escape(symbol))
+		return strings.ReplaceAll(path, "{token}", gjson.Escape(token))
+	}
+
+	priceResult := gjson.GetBytes(data, resolvePath(format.PricePath))
+	volumeResult := gjson.GetBytes(data, resolvePath(format.VolumePath))
+	if !priceResult.Exists() || !volumeResult.Exists() {
+		logger.Error("No data in response", "exchange", exchange, "symbol", symbol)
+		return "", "", 0, appErrors.ErrMissingDataInResponse
+	}
+
+	price = gjsonNumberString(priceResult)
+	volume = gjsonNumberString(volumeResult)
+
+	if format.SymbolPath != "" {
+		err = validateSymbol(exchange, gjson.GetBytes(data, resolvePath(format.SymbolPath)).String(), symbol)
+		if err != nil {
+			return "", "", 0, err
+		}
+	}
+
+	if format.TimestampPath != "" {
+		timestampResult := gjson.GetBytes(data, resolvePath(format.TimestampPath))
+		timestampMillis, parseErr := parseTimestampMillis(timestampResult, format.TimestampUnit)
+		if parseErr != nil {
+			logger.Error("Error parsing timestamp: ", "exchange", exchange, "symbol", symbol, "error", parseErr)
+			return "", "", 0, appErrors.ErrParsingTimestamp
+		}
+		responseTime = timestampMillis
+	}
+
+	err = validatePriceAndVolume(price, volume)
+	if err != nil {
+		return "", "", 0, err
+	}
+
+	return price, volume, responseTime, nil
+}
+
+// gjsonNumberString returns the decimal text of a number or string value. Numbers keep their raw text,
+// so no precision is lost to float64. Other values return an empty string.
+func gjsonNumberString(result gjson.Result) string {
+	switch result.Type {
+	case gjson.Number:
+		return result.Raw
+	case gjson.String:
+		return result.Str
+	default:
+		return ""
+	}
+}
+
+// parseTimestampMillis converts a timestamp value in the given unit into Unix milliseconds.
+// Numeric timestamps may be numbers or strings and may have a fractional part.
+func parseTimestampMillis(result gjson.Result, unit string) (int64, error) {
+	value := gjsonNumberString(result)
+	if value == "" {
+		return 0, fmt.Errorf("missing timestamp")
+	}
+
+	if unit == configs.TimestampUnitRFC3339 {
+		t, err := time.Parse(time.RFC3339Nano, value)
+		if err != nil {
+			return 0, err
+		}
+		return t.UnixMilli(), nil
+	}
+
+	var millisPerUnit *big.Rat
+	switch unit {
+	case configs.TimestampUnitSeconds:
+		millisPerUnit = big.NewRat(1000, 1)
+	case configs.TimestampUnitMilliseconds:
+		millisPerUnit = big.NewRat(1, 1)
+	case configs.TimestampUnitMicroseconds:
+		millisPerUnit = big.NewRat(1, 1000)
+	case configs.TimestampUnitNanoseconds:
+		millisPerUnit = big.NewRat(1, 1000000)
+	default:
+		return 0, fmt.Errorf("unsupported timestamp unit %q", unit)
+	}
+
+	timestampRat, ok := new(big.Rat).SetString(value)
+	if !ok {
+		return 0, fmt.Errorf("invalid timestamp %q", value)
+	}
+
+	millis := timestampRat.Mul(timestampRat, millisPerUnit)
+	millisInt := new(big.Int).Quo(millis.Num(), millis.Denom())
+	if !millisInt.IsInt64() {
+		return 0, fmt.Errorf("timestamp %q out of range", value)
+	}
+	return millisInt.Int64(), nil
+}
@@ -0,0 +1,248 @@
+package data_extraction
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	configs "github.com/venture23-aleo/aleo-oracle-notarization-backend/internal/config"
+	appErrors "github.com/venture23-aleo/aleo-oracle-notarization-backend/pkg/errors"
+)
+
+func TestParseGenericExchangeResponse(t *testing.T) {
+	now := time.Now()
+
+	tickerFormat := &configs.ExchangeResponseFormat{
+		PricePath:     "lastPrice",
+		VolumePath:    "volume",
+		SymbolPath:    "symbol",
+		TimestampPath: "closeTime",
+		TimestampUnit: configs.TimestampUnitMilliseconds,
+	}
+
+	tests := []struct {
+		name                 string
+		format               *configs.ExchangeResponseFormat
+		response             string
+		symbol               string
+		token                string
+		expectedPrice        string
+		expectedVolume       string
+		expectedResponseTime int64
+		expectedError        *appErrors.AppError
+	}{
+		{
+			name:                 "ticker with millisecond timestamp",
+			format:               tickerFormat,
+			response:             fmt.Sprintf(`{"lastPrice": "1000.00", "volume": "2000.00", "symbol": "BTCUSDT", "closeTime": %d}`, now.UnixMilli()),
+			symbol:               "BTCUSDT",
+			expectedPrice:        "1000.00",
+			expectedVolume:       "2000.00",
+			expectedResponseTime: now.UnixMilli(),
+		},
+		{
+			name:           "numeric values keep their decimals",
+			format:         &configs.ExchangeResponseFormat{PricePath: "data.0.last", VolumePath: "data.0.vol"},
+			response:       `{"data": [{"last": 64123.123456789012, "vol": 12.5}]}`,
+			symbol:         "BTC-USDT",
+			expectedPrice:  "64123.123456789012",
+			expectedVolume: "12.5",
+		},
+		{
+			name:           "symbol placeholder in path",
+			format:         &configs.ExchangeResponseFormat{PricePath: "result.{symbol}.c.0", VolumePath: "result.{symbol}.v.1"},
+			response:       `{"result": {"USDTZUSD": {"c": ["1.0001", "10"], "v": ["100", "5000000"]}}}`,
+			symbol:         "USDTZUSD",
+			expectedPrice:  "1.0001",
+			expectedVolume: "5000000",
+		},
+		{
+			name: "token placeholder and seconds timestamp string",
+			format: &configs.ExchangeResponseFormat{
+				PricePath:     "last",
+				VolumePath:    "volume.{token}",
+				TimestampPath: "volume.timestamp",
+				TimestampUnit: configs.TimestampUnitSeconds,
+			},
+			response:             fmt.Sprintf(`{"last": "0.9998", "volume": {"USDC": "7000000", "timestamp": "%d.250"}}`, now.Unix()),
+			symbol:               "USDCUSD",
+			token:                "USDC",
+			expectedPrice:        "0.9998",
+			expectedVolume:       "7000000",
+			expectedResponseTime: now.Unix()*1000 + 250,
+		},
+		{
+			name: "RFC 3339 timestamp",
+			format: &configs.ExchangeResponseFormat{
+				PricePath:     "price",
+				VolumePath:    "volume",
+				TimestampPath: "time",
+				TimestampUnit: configs.TimestampUnitRFC3339,
+			},
+			response:             fmt.Sprintf(`{"price": "1000.00", "volume": "2000.00", "time": "%s"}`, now.Format(time.RFC3339Nano)),
+			symbol:               "BTC-USD",
+			expectedPrice:        "1000.00",
+			expectedVolume:       "2000.00",
+			expectedResponseTime: now.UnixMilli(),
+		},
+		{
+			name: "quote volume is returned as reported",
+			format: &configs.ExchangeResponseFormat{
+				PricePath:          "c",
+				VolumePath:         "q",
+				TimestampPath:      "t",
+				TimestampUnit:      configs.TimestampUnitNanoseconds,
+				VolumeDenomination: configs.VolumeDenominationQuote,
+			},
+			response:             fmt.Sprintf(`{"c": "0.25", "q": "1000", "t": %d}`, now.UnixNano()),
+			symbol:               "ALEO_USDT",
+			expectedPrice:        "0.25",
+			expectedVolume:       "1000",
+			expectedResponseTime: now.UnixMilli(),
+		},
+		{
+			name:          "symbol mismatch",
+			format:        tickerFormat,
+			response:      fmt.Sprintf(`{"lastPrice": "1000.00", "volume": "2000.00", "symbol": "ETHUSDT", "closeTime": %d}`, now.UnixMilli()),
+			symbol:        "BTCUSDT",
+			expectedError: appErrors.ErrSymbolMismatch,
+		},
+		{
+			name:                 "stale timestamp is returned for the freshness check",
+			format:               tickerFormat,
+			response:             fmt.Sprintf(`{"lastPrice": "1000.00", "volume": "2000.00", "symbol": "BTCUSDT", "closeTime": %d}`, now.Add(-time.Hour).UnixMilli()),
+			symbol:               "BTCUSDT",
+			expectedPrice:        "1000.00",
+			expectedVolume:       "2000.00",
+			expectedResponseTime: now.Add(-time.Hour).UnixMilli(),
+		},
+		{
+			name:          "missing timestamp",
+			format:        tickerFormat,
+			response:      `{"lastPrice": "1000.00", "volume": "2000.00", "symbol": "BTCUSDT"}`,
+			symbol:        "BTCUSDT",
+			expectedError: appErrors.ErrParsingTimestamp,
+		},
+		{
+			name:          "missing price",
+			format:        tickerFormat,
+			response:      `{"volume": "2000.00", "symbol": "BTCUSDT"}`,
+			symbol:        "BTCUSDT",
+			expectedError: appErrors.ErrMissingDataInResponse,
+		},
+		{
+			name:          "invalid price",
+			format:        tickerFormat,
+			response:      fmt.Sprintf(`{"lastPrice": {"value": 1}, "volume": "2000.00", "symbol": "BTCUSDT", "closeTime": %d}`, now.UnixMilli()),
+			symbol:        "BTCUSDT",
+			expectedError: appErrors.ErrParsingPrice,
+		},
+		{
+			name:          "invalid volume",
+			format:        tickerFormat,
+			response:      fmt.Sprintf(`{"lastPrice": "1000.00", "volume": "0", "symbol": "BTCUSDT", "closeTime": %d}`, now.UnixMilli()),
+			symbol:        "BTCUSDT",
+			expectedError: appErrors.ErrParsingVolume,
+		},
+		{
+			name:          "malformed json",
+			format:        tickerFormat,
+			response:      `test`,
+			symbol:        "BTCUSDT",
+			expectedError: appErrors.ErrDecodingExchangeResponse,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			price, volume, responseTime, err := parseGenericExchangeResponse("generic", tt.format, []byte(tt.response), tt.symbol, tt.token)
+			assert.Equal(t, tt.expectedError, err)
+			assert.Equal(t, tt.expectedPrice, price)
+			assert.Equal(t, tt.expectedVolume, volume)
+			assert.Equal(t, tt.expectedResponseTime, responseTime)
+		})
+	}
+}
+
+func TestParseExchangeResponse_Adapters(t *testing.T) {
+	responseTimestamp := time.Now().UnixMilli()
+	binanceResponse := []byte(fmt.Sprintf(`{"lastPrice": "1000.00", "volume": "2000.00", "symbol": "BTCUSDT", "closeTime": %d}`, responseTimestamp))
+
+	priceFeedClient := &PriceFeedClient{
+		exchangeConfigs: configs.ExchangesConfig{
+			"binance-mirror": {Name: "Binance Mirror", Adapter: "binance"},
+			"binance":        {Name: "Binance"},
+			"unknown":        {Name: "Unknown", Adapter: "unknown"},
+			"generic": {Name: "Generic", ResponseFormat: &configs.ExchangeResponseFormat{
+				PricePath:  "lastPrice",
+				VolumePath: "volume",
+				SymbolPath: "symbol",
+			}},
+		},
+	}
+
+	for _, exchange := range []string{"binance-mirror", "binance", "generic"} {
+		t.Run(exchange, func(t *testing.T) {
+			price, volume, responseTime, err := priceFeedClient.parseExchangeResponse(exchange, binanceResponse, "BTCUSDT", "BTC")
+			require.Nil(t, err)
+			assert.Equal(t, "1000.00", price)
+			assert.Equal(t, "2000.00", volume)
+			if exchange == "generic" {
+				assert.Zero(t, responseTime, "the format has no timestamp path")
+			} else {
+				assert.Equal(t, responseTimestamp, responseTime)
+			}
+		})
+	}
+
+	t.Run("unknown adapter", func(t *testing.T) {
+		_, _, _, err := priceFeedClient.parseExchangeResponse("unknown", binanceResponse, "BTCUSDT", "BTC")
+		assert.Equal(t, appErrors.ErrExchangeNotSupported, err)
+	})
+}
+
+func TestValidateExchangeAdapters(t *testing.T) {
+	assert.NoError(t, ValidateExchangeAdapters(configs.GetExchangesConfigs()))
+
+	err := ValidateExchangeAdapters(configs.ExchangesConfig{
+		"binance-mirror": {Adapter: "binance"},
+		"generic":        {ResponseFormat: &configs.ExchangeResponseFormat{PricePath: "price", VolumePath: "volume"}},
+		"kraken-date":    {Adapter: "kraken", Freshness: &configs.ExchangeFreshness{Source: configs.FreshnessSourceDateHeader}},
+		"kraken-mirror":  {Adapter: "kraken"},
+		"new-venue":      {},
+		"typo":           {Adapter: "binanse"},
+	})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "Exchange new-venue: unknown adapter new-venue")
+	assert.Contains(t, err.Error(), "Exchange typo: unknown adapter binanse")
+	assert.Contains(t, err.Error(), "Exchange kraken-mirror: adapter kraken returns no timestamp for the response freshness source")
+	assert.NotContains(t, err.Error(), "binance-mirror")
+	assert.NotContains(t, err.Error(), "generic")
+	assert.NotContains(t, err.Error(), "kraken-date")
+}
+
+func TestExchangeVolumeUnit(t *testing.T) {
+	priceFeedClient := &PriceFeedClient{
+		exchangeConfigs: configs.ExchangesConfig{
+			"binance":        {Name: "Binance"},
+			"binance-mirror": {Name: "Binance Mirror", Adapter: "binance"},
+			"base":           {Name: "Base", ResponseFormat: &configs.ExchangeResponseFormat{PricePath: "price", VolumePath: "volume"}},
+			"quote": {Name: "Quote", ResponseFormat: &configs.ExchangeResponseFormat{
+				PricePath:          "price",
+				VolumePath:         "volume",
+				VolumeDenomination: configs.VolumeDenominationQuote,
+			}},
+		},
+	}
+
+	assert.Equal(t, configs.VolumeDenominationBase, priceFeedClient.exchangeVolumeUnit("binance"))
+	assert.Equal(t, configs.VolumeDenominationBase, priceFeedClient.exchangeVolumeUnit("binance-mirror"))
+	assert.Equal(t, configs.VolumeDenominationBase, priceFeedClient.exchangeVolumeUnit("base"))
+	assert.Equal(t, configs.VolumeDenominationQuote, priceFeedClient.exchangeVolumeUnit("quote"))
+
+	for name, adapter := range exchangeAdapters {
+		assert.NotEmpty(t, adapter.volumeUnit, "adapter %s declares no volume unit", name)
+	}
+}
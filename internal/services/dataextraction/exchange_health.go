@@ -0,0 +1,318 @@
+package data_extraction
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	configs "github.com/venture23-aleo/aleo-oracle-notarization-backend/internal/config"
+	"github.com/venture23-aleo/aleo-oracle-notarization-backend/internal/metrics"
+	appErrors "github.com/venture23-aleo/aleo-oracle-notarization-backend/pkg/errors"
+	"github.com/venture23-aleo/aleo-oracle-notarization-backend/pkg/logger"
+)
+
+// Circuit states of an exchange symbol
+const (
+	CircuitStateClosed   = "closed"    // Requests are sent.
+	CircuitStateOpen     = "open"      // Requests are skipped until the open duration elapses.
+	CircuitStateHalfOpen = "half_open" // A single probe request is sent to decide whether to close the circuit.
+)
+
+var (
+	sharedExchangeHealth     *exchangeHealthTracker
+	sharedExchangeHealthOnce sync.Once
+)
+
+// getSharedExchangeHealth returns the exchange health tracker shared by the price feed clients, nil when the
+// tracker is disabled.
+func getSharedExchangeHealth() *exchangeHealthTracker {
+	sharedExchangeHealthOnce.Do(func() {
+		healthConfig := configs.GetExchangeHealthConfig()
+		if healthConfig.Enabled && healthConfig.OpenDuration > 0 {
+			sharedExchangeHealth = newExchangeHealthTracker(healthConfig)
+		}
+	})
+	return sharedExchangeHealth
+}
+
+// exchangeSymbolKey identifies the trading pair of an exchange.
+type exchangeSymbolKey struct {
+	exchange string
+	symbol   string
+}
+
+// requestOutcome is the outcome of a request to an exchange symbol.
+type requestOutcome struct {
+	failed  bool
+	stale   bool
+	latency time.Duration
+}
+
+// exchangeSymbolHealth is the rolling health of an exchange symbol and the state of its circuit.
+type exchangeSymbolHealth struct {
+	requests      []requestOutcome // Latest requests, oldest first.
+	outliers      []bool           // Latest aggregations, true when the price was filtered as an outlier.
+	state         string
+	openedAt      time.Time
+	probeInFlight bool
+	lastError     string
+}
+
+// exchangeHealthTracker tracks the error rate, latency, staleness and outlier frequency of every exchange symbol,
+// and opens the circuit of the chronically bad ones.
+type exchangeHealthTracker struct {
+	config  configs.ExchangeHealthConfig
+	now     func() time.Time
+	mu      sync.Mutex
+	symbols map[exchangeSymbolKey]*exchangeSymbolHealth
+}
+
+// newExchangeHealthTracker creates an exchange health tracker with the given thresholds.
+func newExchangeHealthTracker(config configs.ExchangeHealthConfig) *exchangeHealthTracker {
+	return &exchangeHealthTracker{
+		config:  config,
+		now:     time.Now,
+		symbols: make(map[exchangeSymbolKey]*exchangeSymbolHealth),
+	}
+}
+
+// health returns the health of an exchange symbol, creating it with a closed circuit. Must be called with mu held.
+func (t *exchangeHealthTracker) health(exchange, symbol string) *exchangeSymbolHealth {
+	key := exchangeSymbolKey{exchange: exchange, symbol: symbol}
+	health, exists := t.symbols[key]
+	if !exists {
+		health = &exchangeSymbolHealth{state: CircuitStateClosed}
+		t.symbols[key] = health
+		metrics.RecordExchangeCircuitState(exchange, symbol, CircuitStateClosed)
+	}
+	return health
+}
+
+// allow reports whether a request can be sent to an exchange symbol. Once the open duration of an open circuit
+// elapses, the circuit turns half-open and lets a single probe request through.
+func (t *exchangeHealthTracker) allow(exchange, symbol string) bool {
+	if t == nil {
+		return true
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	health := t.health(exchange, symbol)
+	switch health.state {
+	case CircuitStateOpen:
+		if t.now().Sub(health.openedAt) < t.config.OpenDuration {
+			return false
+		}
+		t.setState(exchange, symbol, health, CircuitStateHalfOpen)
+		health.probeInFlight = true
+		logger.Info("Sending probe request to exchange", "exchange", exchange, "symbol", symbol)
+		return true
+	case CircuitStateHalfOpen:
+		if health.probeInFlight {
+			return false
+		}
+		health.probeInFlight = true
+		return true
+	default:
+		return true
+	}
+}
+
+// recordRequest records the outcome of a request allowed by allow. Requests skipped by the rate limiter are not
+// counted, but release the probe of a half-open circuit.
+func (t *exchangeHealthTracker) recordRequest(exchange, symbol string, err *appErrors.AppError, stale bool, latency time.Duration) {
+	if t == nil {
+		return
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	health := t.health(exchange, symbol)
+	probe := health.state == CircuitStateHalfOpen && health.probeInFlight
+	health.probeInFlight = false
+	if err == appErrors.ErrExchangeRateLimited {
+		return
+	}
+
+	outcome := requestOutcome{failed: err != nil, stale: stale, latency: latency}
+	if err != nil {
+		health.lastError = err.Message
+	}
+
+	if probe {
+		if outcome.failed || (t.config.MaxLatency > 0 && latency > t.config.MaxLatency) {
+			health.openedAt = t.now()
+			t.setState(exchange, symbol, health, CircuitStateOpen)
+			return
+		}
+		// The probe succeeded: the circuit closes with a fresh window.
+		health.requests = []requestOutcome{outcome}
+		health.outliers = nil
+		t.setState(exchange, symbol, health, CircuitStateClosed)
+		return
+	}
+
+	health.requests = appendBounded(health.requests, outcome, t.config.WindowSize)
+	t.checkCircuit(exchange, symbol, health)
+}
+
+// recordAggregation records whether the price of an exchange symbol was filtered as an outlier.
+func (t *exchangeHealthTracker) recordAggregation(exchange, symbol string, outlier bool) {
+	if t == nil {
+		return
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	health := t.health(exchange, symbol)
+	health.outliers = appendBounded(health.outliers, outlier, t.config.WindowSize)
+	t.checkCircuit(exchange, symbol, health)
+}
+
+// checkCircuit opens the circuit of a closed exchange symbol whose rates exceed the thresholds.
+// Must be called with mu held.
+func (t *exchangeHealthTracker) checkCircuit(exchange, symbol string, health *exchangeSymbolHealth) {
+	if health.state != CircuitStateClosed {
+		return
+	}
+
+	stats := health.stats()
+	var reason string
+	switch {
+	case len(health.requests) >= t.config.MinRequests && stats.ErrorRatePercent > t.config.MaxErrorRatePercent:
+		reason = "error rate"
+	case len(health.requests) >= t.config.MinRequests && t.config.MaxLatency > 0 && stats.AvgLatency > t.config.MaxLatency:
+		reason = "latency"
+	case len(health.outliers) >= t.config.MinRequests && stats.OutlierRatePercent > t.config.MaxOutlierRatePercent:
+		reason = "outlier rate"
+	default:
+		return
+	}
+
+	logger.Warn("Opening exchange circuit", "exchange", exchange, "symbol", symbol, "reason", reason, "errorRatePercent", stats.ErrorRatePercent, "stalePercent", stats.StaleRatePercent, "outlierRatePercent", stats.OutlierRatePercent, "avgLatency", stats.AvgLatency)
+	health.openedAt = t.now()
+	t.setState(exchange, symbol, health, CircuitStateOpen)
+}
+
+// setState changes the circuit state of an exchange symbol. Must be called with mu held.
+func (t *exchangeHealthTracker) setState(exchange, symbol string, health *exchangeSymbolHealth, state string) {
+	if health.state != state {
+		logger.Info("Exchange circuit state changed", "exchange", exchange, "symbol", symbol, "from", health.state, "to", state)
+	}
+	health.state = state
+	metrics.RecordExchangeCircuitState(exchange, symbol, state)
+}
+
+// exchangeHealthStats are the rates of an exchange symbol over its window.
+type exchangeHealthStats struct {
+	ErrorRatePercent   float64
+	StaleRatePercent   float64
+	OutlierRatePercent float64
+	AvgLatency         time.Duration
+}
+
+// stats computes the rates over the window. Stale requests count as failed in the error rate.
+func (h *exchangeSymbolHealth) stats() exchangeHealthStats {
+	var stats exchangeHealthStats
+	if len(h.requests) > 0 {
+		var failed, stale int
+		var latency time.Duration
+		for _, outcome := range h.requests {
+			if outcome.failed || outcome.stale {
+				failed++
+			}
+			if outcome.stale {
+				stale++
+			}
+			latency += outcome.latency
+		}
+		stats.ErrorRatePercent = float64(failed) * 100 / float64(len(h.requests))
+		stats.StaleRatePercent = float64(stale) * 100 / float64(len(h.requests))
+		stats.AvgLatency = latency / time.Duration(len(h.requests))
+	}
+	if len(h.outliers) > 0 {
+		var outliers int
+		for _, outlier := range h.outliers {
+			if outlier {
+				outliers++
+			}
+		}
+		stats.OutlierRatePercent = float64(outliers) * 100 / float64(len(h.outliers))
+	}
+	return stats
+}
+
+// appendBounded appends a value, dropping the oldest values beyond size.
+func appendBounded[T any](values []T, value T, size int) []T {
+	values = append(values, value)
+	if len(values) > size {
+		values = values[len(values)-size:]
+	}
+	return values
+}
+
+// ExchangeHealthStatus is the health of an exchange symbol.
+type ExchangeHealthStatus struct {
+	Exchange           string  `json:"exchange"`            // Exchange key.
+	Symbol             string  `json:"symbol"`              // Symbol.
+	State              string  `json:"state"`               // Circuit state: closed, open or half_open.
+	Requests           int     `json:"requests"`            // Number of requests in the window.
+	ErrorRatePercent   float64 `json:"errorRatePercent"`    // Share of failed or stale requests.
+	StaleRatePercent   float64 `json:"staleRatePercent"`    // Share of stale requests.
+	OutlierRatePercent float64 `json:"outlierRatePercent"`  // Share of prices filtered as outliers.
+	AvgLatencyMs       int64   `json:"avgLatencyMs"`        // Average request latency in milliseconds.
+	OpenedAt           int64   `json:"openedAt,omitempty"`  // Unix time the circuit last opened, while not closed.
+	LastError          string  `json:"lastError,omitempty"` // Last request error.
+}
+
+// ExchangeHealthReport is the health of every exchange symbol requested since startup.
+type ExchangeHealthReport struct {
+	Enabled bool                   `json:"enabled"` // Whether the health tracker is enabled.
+	Symbols []ExchangeHealthStatus `json:"symbols"` // Health of the exchange symbols, sorted by exchange and symbol.
+}
+
+// GetExchangeHealthReport returns the health of every exchange symbol tracked by the shared health tracker.
+func GetExchangeHealthReport() ExchangeHealthReport {
+	tracker := getSharedExchangeHealth()
+	if tracker == nil {
+		return ExchangeHealthReport{Symbols: []ExchangeHealthStatus{}}
+	}
+	return ExchangeHealthReport{Enabled: true, Symbols: tracker.report()}
+}
+
+// report returns the health of every exchange symbol, sorted by exchange and symbol.
+func (t *exchangeHealthTracker) report() []ExchangeHealthStatus {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	statuses := make([]ExchangeHealthStatus, 0, len(t.symbols))
+	for key, health := range t.symbols {
+		stats := health.stats()
+		status := ExchangeHealthStatus{
+			Exchange:           key.exchange,
+			Symbol:             key.symbol,
+			State:              health.state,
+			Requests:           len(health.requests),
+			ErrorRatePercent:   stats.ErrorRatePercent,
+			StaleRatePercent:   stats.StaleRatePercent,
+			OutlierRatePercent: stats.OutlierRatePercent,
+			AvgLatencyMs:       stats.AvgLatency.Milliseconds(),
+			LastError:          health.lastError,
+		}
+		if health.state != CircuitStateClosed {
+			status.OpenedAt = health.openedAt.Unix()
+		}
+		statuses = append(statuses, status)
+	}
+
+	sort.Slice(statuses, func(i, j int) bool {
+		if statuses[i].Exchange != statuses[j].Exchange {
+			return statuses[i].Exchange < statuses[j].Exchange
+		}
+		return statuses[i].Symbol < statuses[j].Symbol
+	})
+	return statuses
+}
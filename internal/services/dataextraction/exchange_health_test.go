@@ -0,0 +1,218 @@
+package data_extraction
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/go-retryablehttp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	configs "github.com/venture23-aleo/aleo-oracle-notarization-backend/internal/config"
+	appErrors "github.com/venture23-aleo/aleo-oracle-notarization-backend/pkg/errors"
+)
+
+func TestExchangeHealthTracker_Circuit(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	tracker := newTestExchangeHealthTracker(&now)
+
+	// Failures below the minimum number of requests keep the circuit closed.
+	for i := 0; i < 3; i++ {
+		require.True(t, tracker.allow("xt", "BTC_USDT"))
+		tracker.recordRequest("xt", "BTC_USDT", appErrors.ErrFetchingFromExchange, false, 100*time.Millisecond)
+	}
+	assert.Equal(t, CircuitStateClosed, tracker.symbols[exchangeSymbolKey{"xt", "BTC_USDT"}].state)
+
+	require.True(t, tracker.allow("xt", "BTC_USDT"))
+	tracker.recordRequest("xt", "BTC_USDT", appErrors.ErrFetchingFromExchange, false, 100*time.Millisecond)
+	assert.Equal(t, CircuitStateOpen, tracker.symbols[exchangeSymbolKey{"xt", "BTC_USDT"}].state)
+	assert.False(t, tracker.allow("xt", "BTC_USDT"))
+	assert.True(t, tracker.allow("xt", "ETH_USDT"), "other symbols of the exchange are not affected")
+
+	t.Run("failed probe reopens the circuit", func(t *testing.T) {
+		now = now.Add(time.Minute)
+		require.True(t, tracker.allow("xt", "BTC_USDT"))
+		assert.False(t, tracker.allow("xt", "BTC_USDT"), "a single probe is in flight")
+
+		tracker.recordRequest("xt", "BTC_USDT", appErrors.ErrExchangeInvalidStatusCode, false, 100*time.Millisecond)
+		assert.Equal(t, CircuitStateOpen, tracker.symbols[exchangeSymbolKey{"xt", "BTC_USDT"}].state)
+		assert.False(t, tracker.allow("xt", "BTC_USDT"))
+	})
+
+	t.Run("rate limited probe is released", func(t *testing.T) {
+		now = now.Add(time.Minute)
+		require.True(t, tracker.allow("xt", "BTC_USDT"))
+		tracker.recordRequest("xt", "BTC_USDT", appErrors.ErrExchangeRateLimited, false, 0)
+		assert.Equal(t, CircuitStateHalfOpen, tracker.symbols[exchangeSymbolKey{"xt", "BTC_USDT"}].state)
+	})
+
+	t.Run("successful probe closes the circuit", func(t *testing.T) {
+		require.True(t, tracker.allow("xt", "BTC_USDT"))
+		tracker.recordRequest("xt", "BTC_USDT", nil, false, 100*time.Millisecond)
+
+		health := tracker.symbols[exchangeSymbolKey{"xt", "BTC_USDT"}]
+		assert.Equal(t, CircuitStateClosed, health.state)
+		assert.Len(t, health.requests, 1)
+		assert.True(t, tracker.allow("xt", "BTC_USDT"))
+		assert.True(t, tracker.allow("xt", "BTC_USDT"))
+	})
+}
+
+func TestExchangeHealthTracker_Thresholds(t *testing.T) {
+	tests := []struct {
+		name          string
+		record        func(tracker *exchangeHealthTracker)
+		expectedState string
+	}{
+		{
+			name: "healthy",
+			record: func(tracker *exchangeHealthTracker) {
+				for i := 0; i < 10; i++ {
+					tracker.recordRequest("gate", "BTC_USDT", nil, false, 200*time.Millisecond)
+					tracker.recordAggregation("gate", "BTC_USDT", i%4 == 0)
+				}
+			},
+			expectedState: CircuitStateClosed,
+		},
+		{
+			name: "stale responses",
+			record: func(tracker *exchangeHealthTracker) {
+				for i := 0; i < 4; i++ {
+					tracker.recordRequest("gate", "BTC_USDT", appErrors.ErrParsingExchangeResponse, true, 200*time.Millisecond)
+				}
+			},
+			expectedState: CircuitStateOpen,
+		},
+		{
+			name: "slow responses",
+			record: func(tracker *exchangeHealthTracker) {
+				for i := 0; i < 4; i++ {
+					tracker.recordRequest("gate", "BTC_USDT", nil, false, 3*time.Second)
+				}
+			},
+			expectedState: CircuitStateOpen,
+		},
+		{
+			name: "frequent outliers",
+			record: func(tracker *exchangeHealthTracker) {
+				for i := 0; i < 4; i++ {
+					tracker.recordRequest("gate", "BTC_USDT", nil, false, 200*time.Millisecond)
+					tracker.recordAggregation("gate", "BTC_USDT", true)
+				}
+			},
+			expectedState: CircuitStateOpen,
+		},
+		{
+			name: "failures below the error rate",
+			record: func(tracker *exchangeHealthTracker) {
+				for i := 0; i < 20; i++ {
+					tracker.recordRequest("gate", "BTC_USDT", nil, false, 200*time.Millisecond)
+				}
+				for i := 0; i < 5; i++ {
+					tracker.recordRequest("gate", "BTC_USDT", appErrors.ErrFetchingFromExchange, false, 200*time.Millisecond)
+				}
+			},
+			expectedState: CircuitStateClosed,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			now := time.Unix(1700000000, 0)
+			tracker := newTestExchangeHealthTracker(&now)
+			tt.record(tracker)
+			assert.Equal(t, tt.expectedState, tracker.symbols[exchangeSymbolKey{"gate", "BTC_USDT"}].state)
+		})
+	}
+}
+
+func TestExchangeHealthTracker_Report(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	tracker := newTestExchangeHealthTracker(&now)
+
+	tracker.recordRequest("xt", "BTC_USDT", nil, false, 100*time.Millisecond)
+	tracker.recordRequest("xt", "BTC_USDT", appErrors.ErrParsingExchangeResponse, true, 300*time.Millisecond)
+	tracker.recordAggregation("xt", "BTC_USDT", true)
+	for i := 0; i < 4; i++ {
+		tracker.recordRequest("binance", "BTCUSDT", appErrors.ErrFetchingFromExchange, false, 100*time.Millisecond)
+	}
+
+	report := tracker.report()
+	require.Len(t, report, 2)
+	assert.Equal(t, ExchangeHealthStatus{
+		Exchange:         "binance",
+		Symbol:           "BTCUSDT",
+		State:            CircuitStateOpen,
+		Requests:         4,
+		ErrorRatePercent: 100,
+		AvgLatencyMs:     100,
+		OpenedAt:         now.Unix(),
+		LastError:        appErrors.ErrFetchingFromExchange.Message,
+	}, report[0])
+	assert.Equal(t, ExchangeHealthStatus{
+		Exchange:           "xt",
+		Symbol:             "BTC_USDT",
+		State:              CircuitStateClosed,
+		Requests:           2,
+		ErrorRatePercent:   50,
+		StaleRatePercent:   50,
+		OutlierRatePercent: 100,
+		AvgLatencyMs:       200,
+		LastError:          appErrors.ErrParsingExchangeResponse.Message,
+	}, report[1])
+}
+
+func TestFetchPriceFromExchange_CircuitOpen(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	client := retryablehttp.NewClient()
+	client.RetryMax = 0
+	clientCache.Store("failing-venue", client)
+	t.Cleanup(func() { clientCache.Delete("failing-venue") })
+
+	now := time.Unix(1700000000, 0)
+	priceFeedClient := &PriceFeedClient{
+		exchangeConfigs: configs.ExchangesConfig{
+			"failing-venue": {
+				Name:             "Failing Venue",
+				BaseURL:          server.URL,
+				EndpointTemplate: "/ticker?symbol={symbol}",
+				ResponseFormat:   &configs.ExchangeResponseFormat{PricePath: "price", VolumePath: "volume"},
+			},
+		},
+		exchangeHealth: newTestExchangeHealthTracker(&now),
+	}
+
+	for i := 0; i < 4; i++ {
+		_, err := priceFeedClient.FetchPriceFromExchange(context.Background(), "failing-venue", "BTC", "BTC-USD", now.Unix())
+		assert.Equal(t, appErrors.ErrExchangeInvalidStatusCode, err)
+	}
+
+	_, err := priceFeedClient.FetchPriceFromExchange(context.Background(), "failing-venue", "BTC", "BTC-USD", now.Unix())
+	assert.Equal(t, appErrors.ErrExchangeCircuitOpen, err)
+	assert.Equal(t, int32(4), requests.Load())
+}
+
+// newTestExchangeHealthTracker creates an exchange health tracker opening the circuit once half of at least
+// four requests fail, whose clock reads now.
+func newTestExchangeHealthTracker(now *time.Time) *exchangeHealthTracker {
+	tracker := newExchangeHealthTracker(configs.ExchangeHealthConfig{
+		Enabled:               true,
+		WindowSize:            10,
+		MinRequests:           4,
+		MaxErrorRatePercent:   50,
+		MaxOutlierRatePercent: 50,
+		MaxLatency:            2 * time.Second,
+		OpenDuration:          time.Minute,
+	})
+	tracker.now = func() time.Time { return *now }
+	return tracker
+}
@@ -7,9 +7,8 @@ import (
 	"strings"
 	"time"
 
-	"github.com/venture23-aleo/aleo-oracle-notarization-backend/internal/constants"
-	appErrors "github.com/venture23-aleo/aleo-oracle-notarization-backend/internal/errors"
-	"github.com/venture23-aleo/aleo-oracle-notarization-backend/internal/logger"
+	appErrors "github.com/venture23-aleo/aleo-oracle-notarization-backend/pkg/errors"
+	"github.com/venture23-aleo/aleo-oracle-notarization-backend/pkg/logger"
 )
 
 type BinanceResponse struct {
@@ -105,15 +104,78 @@ type BitstampResponse struct {
 	Timestamp string `json:"timestamp"`
 }
 
-func validateTimestamp(exchange string, timestamp int64, attestationTimestamp int64) *appErrors.AppError {
-	timestampInUnix := timestamp / 1000
-	timeDiff := timestampInUnix - attestationTimestamp
+type OKXTickerItem struct {
+	Price     string `json:"last"`
+	Volume    string `json:"vol24h"` // base volume, volCcy24h is the quote volume of spot pairs
+	Symbol    string `json:"instId"`
+	Timestamp string `json:"ts"`
+}
+
+type OKXResponse struct {
+	Code    string          `json:"code"`
+	Message string          `json:"msg"`
+	Data    []OKXTickerItem `json:"data"`
+}
+
+type KuCoinStats struct {
+	Price     string `json:"last"`
+	Volume    string `json:"vol"` // base volume, volValue is the quote volume
+	Symbol    string `json:"symbol"`
+	Timestamp int64  `json:"time"`
+}
+
+type KuCoinResponse struct {
+	Code    string       `json:"code"`
+	Message string       `json:"msg"`
+	Data    *KuCoinStats `json:"data"`
+}
+
+// BitfinexResponse is the list of tickers returned by Bitfinex, each ticker being an array of
+// [SYMBOL, BID, BID_SIZE, ASK, ASK_SIZE, DAILY_CHANGE, DAILY_CHANGE_RELATIVE, LAST_PRICE, VOLUME, HIGH, LOW]
+type BitfinexResponse [][]json.RawMessage
+
+const (
+	bitfinexSymbolIndex    = 0
+	bitfinexLastPriceIndex = 7
+	bitfinexVolumeIndex    = 8
+)
+
+type HTXTick struct {
+	Price  json.Number `json:"close"`
+	Volume json.Number `json:"amount"` // base volume, vol is the quote volume
+}
+
+type HTXResponse struct {
+	Status       string   `json:"status"`
+	Channel      string   `json:"ch"` // market.{symbol}.detail.merged
+	Timestamp    int64    `json:"ts"`
+	ErrorMessage string   `json:"err-msg"`
+	Tick         *HTXTick `json:"tick"`
+}
+
+type BitgetTickerItem struct {
+	Price     string `json:"lastPr"`
+	Volume    string `json:"baseVolume"`
+	Symbol    string `json:"symbol"`
+	Timestamp string `json:"ts"`
+}
+
+type BitgetResponse struct {
+	Code    string             `json:"code"`
+	Message string             `json:"msg"`
+	Data    []BitgetTickerItem `json:"data"`
+}
+
+// validateTimestamp checks that a freshness timestamp, in Unix milliseconds, is within maxAge of the attestation
+// timestamp, in Unix seconds.
+func validateTimestamp(exchange string, timestamp int64, attestationTimestamp int64, maxAge time.Duration) *appErrors.AppError {
+	timeDiff := time.UnixMilli(timestamp).Sub(time.Unix(attestationTimestamp, 0))
 	if timeDiff < 0 {
 		timeDiff = -timeDiff
 	}
 
-	if timeDiff > constants.MaxAllowedTimeDiff {
-		logger.Error("Timestamp difference too large: ", "exchange", exchange, "expected", attestationTimestamp, "got", timestamp, "diff_seconds", timeDiff)
+	if timeDiff > maxAge {
+		logger.Error("Timestamp difference too large: ", "exchange", exchange, "expected", attestationTimestamp, "got", timestamp, "diff", timeDiff, "maxAge", maxAge)
 		return appErrors.ErrTimestampTooOld
 	}
 	return nil
@@ -165,11 +227,11 @@ func validatePriceAndVolume(price, volume string) *appErrors.AppError {
 }
 
 // parseBinanceResponse parses the response from Binance
-func parseBinanceResponse(data []byte, symbol string, timestamp int64) (price, volume string, err *appErrors.AppError) {
+func parseBinanceResponse(data []byte, symbol string) (price, volume string, responseTime int64, err *appErrors.AppError) {
 	var binanceResponse BinanceResponse
 	if err := json.Unmarshal(data, &binanceResponse); err != nil {
 		logger.Error("Error unmarshalling data: ", "exchange", "binance", "error", err)
-		return "", "", appErrors.ErrDecodingExchangeResponse
+		return "", "", 0, appErrors.ErrDecodingExchangeResponse
 	}
 
 	price = binanceResponse.Price
@@ -177,34 +239,31 @@ func parseBinanceResponse(data []byte, symbol string, timestamp int64) (price, v
 
 	err = validateSymbol("binance", binanceResponse.Symbol, symbol)
 	if err != nil {
-		return "", "", err
+		return "", "", 0, err
 	}
 
-	err = validateTimestamp("binance", binanceResponse.Timestamp, timestamp)
-	if err != nil {
-		return "", "", err
-	}
+	responseTime = binanceResponse.Timestamp
 
 	err = validatePriceAndVolume(price, volume)
 	if err != nil {
-		return "", "", err
+		return "", "", 0, err
 	}
 
-	return price, volume, nil
+	return price, volume, responseTime, nil
 }
 
 // parseBybitResponse parses the response from Bybit
-func parseBybitResponse(data []byte, symbol string, timestamp int64) (price, volume string, err *appErrors.AppError) {
+func parseBybitResponse(data []byte, symbol string) (price, volume string, responseTime int64, err *appErrors.AppError) {
 	var bybitResponse BybitResponse
 	if err := json.Unmarshal(data, &bybitResponse); err != nil {
 		logger.Error("Error unmarshalling data: ", "exchange", "bybit", "error", err)
-		return "", "", appErrors.ErrDecodingExchangeResponse
+		return "", "", 0, appErrors.ErrDecodingExchangeResponse
 	}
 	
 	list := bybitResponse.Result.List
 	if len(list) == 0 {
 		logger.Error("No data in response", "exchange", "bybit")
-		return "", "", appErrors.ErrMissingDataInResponse
+		return "", "", 0, appErrors.ErrMissingDataInResponse
 	}
 
 	item := list[0]
@@ -214,30 +273,27 @@ func parseBybitResponse(data []byte, symbol string, timestamp int64) (price, vol
 
 	err = validateSymbol("bybit", item.Symbol, symbol)
 	if err != nil {
-		return "", "", err
+		return "", "", 0, err
 	}
 	
-	err = validateTimestamp("bybit", bybitResponse.Timestamp, timestamp)
-	if err != nil {
-		return "", "", err
-	}
+	responseTime = bybitResponse.Timestamp
 
 	err = validatePriceAndVolume(price, volume)
 	if err != nil {
-		return "", "", err
+		return "", "", 0, err
 	}
 
 
-	return price, volume, nil
+	return price, volume, responseTime, nil
 }
 
 // parseCoinbaseResponse parses the response from Coinbase
-func parseCoinbaseResponse(data []byte, _ string, timestamp int64) (price, volume string, err *appErrors.AppError) {
+func parseCoinbaseResponse(data []byte, _ string) (price, volume string, responseTime int64, err *appErrors.AppError) {
 	exchange := "coinbase"
 	var coinbaseResponse CoinbaseResponse
 	if err := json.Unmarshal(data, &coinbaseResponse); err != nil {
 		logger.Error("Error unmarshalling data: ", "exchange", exchange, "error", err)
-		return "", "", appErrors.ErrDecodingExchangeResponse
+		return "", "", 0, appErrors.ErrDecodingExchangeResponse
 	}
 
 	price = coinbaseResponse.Price
@@ -246,35 +302,32 @@ func parseCoinbaseResponse(data []byte, _ string, timestamp int64) (price, volum
 	t, parseErr := time.Parse(time.RFC3339Nano, coinbaseResponse.Timestamp)
 	if parseErr != nil {
 		logger.Error("Error parsing timestamp: ", "exchange", exchange, "error", err)
-		return "", "", appErrors.ErrParsingTimestamp
+		return "", "", 0, appErrors.ErrParsingTimestamp
 	}
 
-	err = validateTimestamp("coinbase", t.UnixMilli(), timestamp)
-	if err != nil {
-		return "", "", err
-	}
+	responseTime = t.UnixMilli()
 
 	err = validatePriceAndVolume(price, volume)
 	if err != nil {
-		return "", "", err
+		return "", "", 0, err
 	}
 
-	return price, volume, nil
+	return price, volume, responseTime, nil
 }
 
 // parseCryptoResponse parses the response from Crypto.com
-func parseCryptoResponse(data []byte, symbol string, timestamp int64) (price, volume string, err *appErrors.AppError) {
+func parseCryptoResponse(data []byte, symbol string) (price, volume string, responseTime int64, err *appErrors.AppError) {
 	exchange := "crypto"
 	var cryptoResponse CryptoResponse
 	if err := json.Unmarshal(data, &cryptoResponse); err != nil {
 		logger.Error("Error unmarshalling data: ", "exchange", exchange, "error", err)
-		return "", "", appErrors.ErrDecodingExchangeResponse
+		return "", "", 0, appErrors.ErrDecodingExchangeResponse
 	}
 
 	dataArray := cryptoResponse.Result.Data
 	if len(dataArray) == 0 {
 		logger.Error("No data in response", "exchange", exchange)
-		return "", "", appErrors.ErrMissingDataInResponse
+		return "", "", 0, appErrors.ErrMissingDataInResponse
 	}
 
 	item := dataArray[0]
@@ -283,35 +336,32 @@ func parseCryptoResponse(data []byte, symbol string, timestamp int64) (price, vo
 
 	err = validateSymbol("crypto", item.Symbol, symbol)
 	if err != nil {
-		return "", "", err
+		return "", "", 0, err
 	}
 
-	err = validateTimestamp("crypto", item.Timestamp, timestamp)
-	if err != nil {
-		return "", "", err
-	}
+	responseTime = item.Timestamp
 
 	err = validatePriceAndVolume(price, volume)
 	if err != nil {
-		return "", "", err
+		return "", "", 0, err
 	}
 
-	return price, volume, nil
+	return price, volume, responseTime, nil
 }
 
 // parseXTResponse parses the response from XT
-func parseXTResponse(data []byte, symbol string, timestamp int64) (price, volume string, err *appErrors.AppError) {
+func parseXTResponse(data []byte, symbol string) (price, volume string, responseTime int64, err *appErrors.AppError) {
 	exchange := "xt"
 	var xtResponse XTResponse
 	if err := json.Unmarshal(data, &xtResponse); err != nil {
 		logger.Error("Error unmarshalling data: ", "exchange", exchange, "error", err)
-		return "", "", appErrors.ErrDecodingExchangeResponse
+		return "", "", 0, appErrors.ErrDecodingExchangeResponse
 	}
 
 	result := xtResponse.Result
 	if len(result) == 0 {
 		logger.Error("No data in response", "exchange", exchange)
-		return "", "", appErrors.ErrMissingDataInResponse
+		return "", "", 0, appErrors.ErrMissingDataInResponse
 	}
 
 	item := result[0]
@@ -321,36 +371,33 @@ func parseXTResponse(data []byte, symbol string, timestamp int64) (price, volume
 
 	err = validateSymbol("xt", item.Symbol, symbol)
 	if err != nil {
-		return "", "", err
+		return "", "", 0, err
 	}
 
-	err = validateTimestamp("xt", item.Timestamp, timestamp)
-	if err != nil {
-		return "", "", err
-	}
+	responseTime = item.Timestamp
 
 	err = validatePriceAndVolume(price, volume)
 	if err != nil {
-		return "", "", err
+		return "", "", 0, err
 	}
 
-	return price, volume, nil
+	return price, volume, responseTime, nil
 
 }
 
-// parseGateIOResponse parses the response from Gate.io
-func parseGateResponse(data []byte, symbol string, _ int64) (price, volume string, err *appErrors.AppError) {
+// parseGateResponse parses the response from Gate.io. Gate tickers carry no timestamp.
+func parseGateResponse(data []byte, symbol string) (price, volume string, responseTime int64, err *appErrors.AppError) {
 	exchange := "gate"
 	var gateResponse GateResponse
 	if err := json.Unmarshal(data, &gateResponse); err != nil {
 		logger.Error("Error unmarshalling data: ", "exchange", exchange, "error", err)
-		return "", "", appErrors.ErrDecodingExchangeResponse
+		return "", "", 0, appErrors.ErrDecodingExchangeResponse
 	}
 
 	list := gateResponse
 	if len(list) == 0 {
 		logger.Error("No data in response", "exchange", exchange)
-		return "", "", appErrors.ErrMissingDataInResponse
+		return "", "", 0, appErrors.ErrMissingDataInResponse
 	}
 
 	item := list[0]
@@ -360,24 +407,24 @@ func parseGateResponse(data []byte, symbol string, _ int64) (price, volume strin
 
 	err = validateSymbol("gate", item.Symbol, symbol)
 	if err != nil {
-		return "", "", err
+		return "", "", 0, err
 	}
 
 	err = validatePriceAndVolume(price, volume)
 	if err != nil {
-		return "", "", err
+		return "", "", 0, err
 	}
 
-	return price, volume, nil
+	return price, volume, responseTime, nil
 }
 
 // parseMEXCResponse parses the response from MEXC
-func parseMEXCResponse(data []byte, symbol string, timestamp int64) (price, volume string, err *appErrors.AppError) {
+func parseMEXCResponse(data []byte, symbol string) (price, volume string, responseTime int64, err *appErrors.AppError) {
 	exchange := "mexc"
 	var mexcResponse MEXCResponse
 	if err := json.Unmarshal(data, &mexcResponse); err != nil {
 		logger.Error("Error unmarshalling data: ", "exchange", exchange, "error", err)
-		return "", "", appErrors.ErrDecodingExchangeResponse
+		return "", "", 0, appErrors.ErrDecodingExchangeResponse
 	}
 
 	price = mexcResponse.Price
@@ -385,34 +432,32 @@ func parseMEXCResponse(data []byte, symbol string, timestamp int64) (price, volu
 
 	err = validateSymbol("mexc", mexcResponse.Symbol, symbol)
 	if err != nil {
-		return "", "", err
+		return "", "", 0, err
 	}
 
-	err = validateTimestamp("mexc", mexcResponse.Timestamp, timestamp)
-	if err != nil {
-		return "", "", err
-	}
+	responseTime = mexcResponse.Timestamp
 
 	err = validatePriceAndVolume(price, volume)
 	if err != nil {
-		return "", "", err
+		return "", "", 0, err
 	}
 
-	return price, volume, nil
+	return price, volume, responseTime, nil
 }
 
-func parseKrakenResponse(data []byte, symbol string) (price, volume string, err *appErrors.AppError) {
+// parseKrakenResponse parses the response from Kraken. Kraken tickers carry no timestamp.
+func parseKrakenResponse(data []byte, symbol string) (price, volume string, responseTime int64, err *appErrors.AppError) {
 	exchange := "kraken"
 	var krakenResponse KrakenResponse
 	if err := json.Unmarshal(data, &krakenResponse); err != nil {
 		logger.Error("Error unmarshalling data: ", "exchange", exchange, "error", err)
-		return "", "", appErrors.ErrDecodingExchangeResponse
+		return "", "", 0, appErrors.ErrDecodingExchangeResponse
 	}
 
 	result, ok := krakenResponse.Result[symbol]
 	if !ok {
 		logger.Error("No data in response", "exchange", exchange, "symbol", symbol)
-		return "", "", appErrors.ErrMissingDataInResponse
+		return "", "", 0, appErrors.ErrMissingDataInResponse
 	}
 
 	price = result.Price[0]
@@ -420,18 +465,19 @@ func parseKrakenResponse(data []byte, symbol string) (price, volume string, err
 
 	err = validatePriceAndVolume(price, volume)
 	if err != nil {
-		return "", "", err
+		return "", "", 0, err
 	}
 
-	return price, volume, nil
+	return price, volume, responseTime, nil
 }
 
-func parseGeminiResponse(data []byte, symbol string, timestamp int64, token string) (price, volume string, err *appErrors.AppError) {
+// parseGeminiResponse parses the response from Gemini. The volume is keyed by the token.
+func parseGeminiResponse(data []byte, symbol string, token string) (price, volume string, responseTime int64, err *appErrors.AppError) {
 	exchange := "gemini"
 	var geminiResponse GeminiResponse
 	if err := json.Unmarshal(data, &geminiResponse); err != nil {
 		logger.Error("Error unmarshalling data: ", "exchange", exchange, "symbol", symbol, "token", token, "error", err)
-		return "", "", appErrors.ErrDecodingExchangeResponse
+		return "", "", 0, appErrors.ErrDecodingExchangeResponse
 	}
 
 	price = geminiResponse.Price
@@ -445,28 +491,26 @@ func parseGeminiResponse(data []byte, symbol string, timestamp int64, token stri
 
 	if volume == "" {
 		logger.Error("No volume in response", "exchange", exchange, "symbol", symbol, "token", token)
-		return "", "", appErrors.ErrMissingDataInResponse
+		return "", "", 0, appErrors.ErrMissingDataInResponse
 	}
 
-	err = validateTimestamp(exchange,geminiResponse.VolumeInfo.Timestamp, timestamp)
-	if err != nil {
-		return "", "", err
-	}
+	responseTime = geminiResponse.VolumeInfo.Timestamp
 	
 	err = validatePriceAndVolume(price, volume)
 	if err != nil {
-		return "", "", err
+		return "", "", 0, err
 	}
 
-	return price, volume, nil
+	return price, volume, responseTime, nil
 }
 
-func parseBitstampResponse(data []byte, symbol string, timestamp int64) (price, volume string, err *appErrors.AppError) {
+// parseBitstampResponse parses the response from Bitstamp
+func parseBitstampResponse(data []byte, symbol string) (price, volume string, responseTime int64, err *appErrors.AppError) {
 	exchange := "bitstamp"
 	var bitstampResponse BitstampResponse
 	if err := json.Unmarshal(data, &bitstampResponse); err != nil {
 		logger.Error("Error unmarshalling data: ", "exchange", exchange, "symbol", symbol, "error", err)
-		return "", "", appErrors.ErrDecodingExchangeResponse
+		return "", "", 0, appErrors.ErrDecodingExchangeResponse
 	}
 
 	price = bitstampResponse.Price
@@ -475,47 +519,201 @@ func parseBitstampResponse(data []byte, symbol string, timestamp int64) (price,
 	parsedTimestamp, parseErr := strconv.ParseInt(bitstampResponse.Timestamp, 10, 64)
 	if parseErr != nil {
 		logger.Error("Error parsing timestamp: ", "exchange", exchange, "symbol", symbol, "error", parseErr)
-		return "", "", appErrors.ErrParsingTimestamp
+		return "", "", 0, appErrors.ErrParsingTimestamp
+	}
+
+	responseTime = parsedTimestamp * 1000
+
+	err = validatePriceAndVolume(price, volume)
+	if err != nil {
+		return "", "", 0, err
+	}
+	
+	return price, volume, responseTime, nil
+}
+
+// parseOKXResponse parses the response from OKX
+func parseOKXResponse(data []byte, symbol string) (price, volume string, responseTime int64, err *appErrors.AppError) {
+	exchange := "okx"
+	var okxResponse OKXResponse
+	if err := json.Unmarshal(data, &okxResponse); err != nil {
+		logger.Error("Error unmarshalling data: ", "exchange", exchange, "symbol", symbol, "error", err)
+		return "", "", 0, appErrors.ErrDecodingExchangeResponse
+	}
+
+	if len(okxResponse.Data) == 0 {
+		logger.Error("No data in response", "exchange", exchange, "symbol", symbol, "code", okxResponse.Code, "message", okxResponse.Message)
+		return "", "", 0, appErrors.ErrMissingDataInResponse
 	}
 
-	err = validateTimestamp(exchange, parsedTimestamp * 1000, timestamp)
+	item := okxResponse.Data[0]
+	price = item.Price
+	volume = item.Volume
+
+	err = validateSymbol(exchange, item.Symbol, symbol)
 	if err != nil {
-		return "", "", err
+		return "", "", 0, err
 	}
 
+	parsedTimestamp, parseErr := strconv.ParseInt(item.Timestamp, 10, 64)
+	if parseErr != nil {
+		logger.Error("Error parsing timestamp: ", "exchange", exchange, "symbol", symbol, "error", parseErr)
+		return "", "", 0, appErrors.ErrParsingTimestamp
+	}
+
+	responseTime = parsedTimestamp
+
 	err = validatePriceAndVolume(price, volume)
 	if err != nil {
-		return "", "", err
+		return "", "", 0, err
 	}
-	
-	return price, volume, nil
-}
-
-// parseExchangeResponse parses the response from different exchanges
-func (c *PriceFeedClient) parseExchangeResponse(exchange string, data []byte, symbol string, timestamp int64, token string) (price, volume string, err *appErrors.AppError) {
-	switch exchange {
-	case "binance", "binance-us":
-		return parseBinanceResponse(data, symbol, timestamp)
-	case "bybit":
-		return parseBybitResponse(data, symbol, timestamp)
-	case "coinbase":
-		return parseCoinbaseResponse(data, symbol, timestamp)
-	case "crypto":
-		return parseCryptoResponse(data, symbol, timestamp)
-	case "xt":
-		return parseXTResponse(data, symbol, timestamp)
-	case "gate":
-		return parseGateResponse(data, symbol, timestamp)
-	case "mexc":
-		return parseMEXCResponse(data, symbol, timestamp)
-	case "kraken":
-		return parseKrakenResponse(data, symbol)
-	case "gemini":
-		return parseGeminiResponse(data, symbol, timestamp, token)
-	case "bitstamp":
-		return parseBitstampResponse(data, symbol, timestamp)
-	default:
-		logger.Error("Unsupported exchange: ", "exchange", exchange)
-		return "", "", appErrors.ErrExchangeNotSupported
+
+	return price, volume, responseTime, nil
+}
+
+// parseKuCoinResponse parses the response from KuCoin
+func parseKuCoinResponse(data []byte, symbol string) (price, volume string, responseTime int64, err *appErrors.AppError) {
+	exchange := "kucoin"
+	var kucoinResponse KuCoinResponse
+	if err := json.Unmarshal(data, &kucoinResponse); err != nil {
+		logger.Error("Error unmarshalling data: ", "exchange", exchange, "symbol", symbol, "error", err)
+		return "", "", 0, appErrors.ErrDecodingExchangeResponse
+	}
+
+	stats := kucoinResponse.Data
+	if stats == nil {
+		logger.Error("No data in response", "exchange", exchange, "symbol", symbol, "code", kucoinResponse.Code, "message", kucoinResponse.Message)
+		return "", "", 0, appErrors.ErrMissingDataInResponse
+	}
+
+	price = stats.Price
+	volume = stats.Volume
+
+	err = validateSymbol(exchange, stats.Symbol, symbol)
+	if err != nil {
+		return "", "", 0, err
 	}
+
+	responseTime = stats.Timestamp
+
+	err = validatePriceAndVolume(price, volume)
+	if err != nil {
+		return "", "", 0, err
+	}
+
+	return price, volume, responseTime, nil
+}
+
+// parseBitfinexResponse parses the response from Bitfinex. Bitfinex tickers carry no timestamp.
+func parseBitfinexResponse(data []byte, symbol string) (price, volume string, responseTime int64, err *appErrors.AppError) {
+	exchange := "bitfinex"
+	var bitfinexResponse BitfinexResponse
+	if err := json.Unmarshal(data, &bitfinexResponse); err != nil {
+		logger.Error("Error unmarshalling data: ", "exchange", exchange, "symbol", symbol, "error", err)
+		return "", "", 0, appErrors.ErrDecodingExchangeResponse
+	}
+
+	if len(bitfinexResponse) == 0 || len(bitfinexResponse[0]) <= bitfinexVolumeIndex {
+		logger.Error("No data in response", "exchange", exchange, "symbol", symbol)
+		return "", "", 0, appErrors.ErrMissingDataInResponse
+	}
+
+	ticker := bitfinexResponse[0]
+
+	var parsedSymbol string
+	var parsedPrice, parsedVolume json.Number
+	if json.Unmarshal(ticker[bitfinexSymbolIndex], &parsedSymbol) != nil ||
+		json.Unmarshal(ticker[bitfinexLastPriceIndex], &parsedPrice) != nil ||
+		json.Unmarshal(ticker[bitfinexVolumeIndex], &parsedVolume) != nil {
+		logger.Error("Error unmarshalling ticker: ", "exchange", exchange, "symbol", symbol)
+		return "", "", 0, appErrors.ErrDecodingExchangeResponse
+	}
+
+	price = parsedPrice.String()
+	volume = parsedVolume.String()
+
+	err = validateSymbol(exchange, parsedSymbol, symbol)
+	if err != nil {
+		return "", "", 0, err
+	}
+
+	err = validatePriceAndVolume(price, volume)
+	if err != nil {
+		return "", "", 0, err
+	}
+
+	return price, volume, responseTime, nil
+}
+
+// parseHTXResponse parses the response from HTX. The symbol is echoed in the channel of the response.
+func parseHTXResponse(data []byte, symbol string) (price, volume string, responseTime int64, err *appErrors.AppError) {
+	exchange := "htx"
+	var htxResponse HTXResponse
+	if err := json.Unmarshal(data, &htxResponse); err != nil {
+		logger.Error("Error unmarshalling data: ", "exchange", exchange, "symbol", symbol, "error", err)
+		return "", "", 0, appErrors.ErrDecodingExchangeResponse
+	}
+
+	tick := htxResponse.Tick
+	if htxResponse.Status != "ok" || tick == nil {
+		logger.Error("No data in response", "exchange", exchange, "symbol", symbol, "status", htxResponse.Status, "message", htxResponse.ErrorMessage)
+		return "", "", 0, appErrors.ErrMissingDataInResponse
+	}
+
+	price = tick.Price.String()
+	volume = tick.Volume.String()
+
+	parsedSymbol := strings.TrimSuffix(strings.TrimPrefix(htxResponse.Channel, "market."), ".detail.merged")
+	err = validateSymbol(exchange, parsedSymbol, symbol)
+	if err != nil {
+		return "", "", 0, err
+	}
+
+	responseTime = htxResponse.Timestamp
+
+	err = validatePriceAndVolume(price, volume)
+	if err != nil {
+		return "", "", 0, err
+	}
+
+	return price, volume, responseTime, nil
+}
+
+// parseBitgetResponse parses the response from Bitget
+func parseBitgetResponse(data []byte, symbol string) (price, volume string, responseTime int64, err *appErrors.AppError) {
+	exchange := "bitget"
+	var bitgetResponse BitgetResponse
+	if err := json.Unmarshal(data, &bitgetResponse); err != nil {
+		logger.Error("Error unmarshalling data: ", "exchange", exchange, "symbol", symbol, "error", err)
+		return "", "", 0, appErrors.ErrDecodingExchangeResponse
+	}
+
+	if len(bitgetResponse.Data) == 0 {
+		logger.Error("No data in response", "exchange", exchange, "symbol", symbol, "code", bitgetResponse.Code, "message", bitgetResponse.Message)
+		return "", "", 0, appErrors.ErrMissingDataInResponse
+	}
+
+	item := bitgetResponse.Data[0]
+	price = item.Price
+	volume = item.Volume
+
+	err = validateSymbol(exchange, item.Symbol, symbol)
+	if err != nil {
+		return "", "", 0, err
+	}
+
+	parsedTimestamp, parseErr := strconv.ParseInt(item.Timestamp, 10, 64)
+	if parseErr != nil {
+		logger.Error("Error parsing timestamp: ", "exchange", exchange, "symbol", symbol, "error", parseErr)
+		return "", "", 0, appErrors.ErrParsingTimestamp
+	}
+
+	responseTime = parsedTimestamp
+
+	err = validatePriceAndVolume(price, volume)
+	if err != nil {
+		return "", "", 0, err
+	}
+
+	return price, volume, responseTime, nil
 }
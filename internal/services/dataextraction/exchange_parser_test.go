@@ -7,7 +7,7 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	configs "github.com/venture23-aleo/aleo-oracle-notarization-backend/internal/config"
-	appErrors "github.com/venture23-aleo/aleo-oracle-notarization-backend/internal/errors"
+	appErrors "github.com/venture23-aleo/aleo-oracle-notarization-backend/pkg/errors"
 )
 
 func TestParseExchangeResponse(t *testing.T) {
@@ -354,6 +354,336 @@ func TestParseExchangeResponse(t *testing.T) {
 			symbol:         "BTCUSDT",
 			timestamp:      attestationTimestamp,
 		},
+		{
+			name:           "OKX valid response",
+			exchange:       "okx",
+			response:       []byte(fmt.Sprintf(`{"code":"0","msg":"","data":[{"instType":"SPOT","instId":"BTC-USDT","last":"64012.1","lastSz":"0.0011","askPx":"64012.1","askSz":"0.35","bidPx":"64012","bidSz":"1.2","open24h":"63500","high24h":"64500","low24h":"63000","volCcy24h":"552841234.5","vol24h":"2871.09544","ts":"%d","sodUtc0":"63800","sodUtc8":"63900"}]}`, responseTimestamp)),
+			expectedPrice:  "64012.1",
+			expectedVolume: "2871.09544",
+			expectedError:  nil,
+			symbol:         "BTC-USDT",
+			timestamp:      attestationTimestamp,
+		},
+		{
+			name:           "Invalid okx response with invalid price",
+			exchange:       "okx",
+			response:       []byte(fmt.Sprintf(`{"code":"0","msg":"","data":[{"instType":"SPOT","instId":"BTC-USDT","last":"invalid","lastSz":"0.0011","askPx":"64012.1","askSz":"0.35","bidPx":"64012","bidSz":"1.2","open24h":"63500","high24h":"64500","low24h":"63000","volCcy24h":"552841234.5","vol24h":"2871.09544","ts":"%d","sodUtc0":"63800","sodUtc8":"63900"}]}`, responseTimestamp)),
+			expectedPrice:  "",
+			expectedVolume: "",
+			expectedError:  appErrors.ErrParsingPrice,
+			symbol:         "BTC-USDT",
+			timestamp:      attestationTimestamp,
+		},
+		{
+			name:           "Invalid okx response with invalid volume",
+			exchange:       "okx",
+			response:       []byte(fmt.Sprintf(`{"code":"0","msg":"","data":[{"instType":"SPOT","instId":"BTC-USDT","last":"64012.1","lastSz":"0.0011","askPx":"64012.1","askSz":"0.35","bidPx":"64012","bidSz":"1.2","open24h":"63500","high24h":"64500","low24h":"63000","volCcy24h":"552841234.5","vol24h":"0","ts":"%d","sodUtc0":"63800","sodUtc8":"63900"}]}`, responseTimestamp)),
+			expectedPrice:  "",
+			expectedVolume: "",
+			expectedError:  appErrors.ErrParsingVolume,
+			symbol:         "BTC-USDT",
+			timestamp:      attestationTimestamp,
+		},
+		{
+			name:           "Invalid okx response with symbol mismatch",
+			exchange:       "okx",
+			response:       []byte(fmt.Sprintf(`{"code":"0","msg":"","data":[{"instType":"SPOT","instId":"ETH-USDT","last":"64012.1","lastSz":"0.0011","askPx":"64012.1","askSz":"0.35","bidPx":"64012","bidSz":"1.2","open24h":"63500","high24h":"64500","low24h":"63000","volCcy24h":"552841234.5","vol24h":"2871.09544","ts":"%d","sodUtc0":"63800","sodUtc8":"63900"}]}`, responseTimestamp)),
+			expectedPrice:  "",
+			expectedVolume: "",
+			expectedError:  appErrors.ErrSymbolMismatch,
+			symbol:         "BTC-USDT",
+			timestamp:      attestationTimestamp,
+		},
+		{
+			name:           "Invalid okx response with invalid timestamp",
+			exchange:       "okx",
+			response:       []byte(`{"code":"0","msg":"","data":[{"instId":"BTC-USDT","last":"64012.1","vol24h":"2871.09544","ts":""}]}`),
+			expectedPrice:  "",
+			expectedVolume: "",
+			expectedError:  appErrors.ErrParsingTimestamp,
+			symbol:         "BTC-USDT",
+			timestamp:      attestationTimestamp,
+		},
+		{
+			name:           "Missing data in okx response",
+			exchange:       "okx",
+			response:       []byte(`{"code":"51001","msg":"Instrument ID does not exist","data":[]}`),
+			expectedPrice:  "",
+			expectedVolume: "",
+			expectedError:  appErrors.ErrMissingDataInResponse,
+			symbol:         "BTC-USDT",
+			timestamp:      attestationTimestamp,
+		},
+		{
+			name:           "KuCoin valid response",
+			exchange:       "kucoin",
+			response:       []byte(fmt.Sprintf(`{"code":"200000","data":{"time":%d,"symbol":"BTC-USDT","buy":"64011.9","sell":"64012","changeRate":"0.0081","changePrice":"514.2","high":"64500","low":"63000","vol":"2871.09544","volValue":"182744123.41","last":"64012","averagePrice":"63672.4","takerFeeRate":"0.001","makerFeeRate":"0.001"}}`, responseTimestamp)),
+			expectedPrice:  "64012",
+			expectedVolume: "2871.09544",
+			expectedError:  nil,
+			symbol:         "BTC-USDT",
+			timestamp:      attestationTimestamp,
+		},
+		{
+			name:           "Invalid kucoin response with invalid price",
+			exchange:       "kucoin",
+			response:       []byte(fmt.Sprintf(`{"code":"200000","data":{"time":%d,"symbol":"BTC-USDT","buy":"64011.9","sell":"64012","changeRate":"0.0081","changePrice":"514.2","high":"64500","low":"63000","vol":"2871.09544","volValue":"182744123.41","last":"invalid","averagePrice":"63672.4","takerFeeRate":"0.001","makerFeeRate":"0.001"}}`, responseTimestamp)),
+			expectedPrice:  "",
+			expectedVolume: "",
+			expectedError:  appErrors.ErrParsingPrice,
+			symbol:         "BTC-USDT",
+			timestamp:      attestationTimestamp,
+		},
+		{
+			name:           "Invalid kucoin response with invalid volume",
+			exchange:       "kucoin",
+			response:       []byte(fmt.Sprintf(`{"code":"200000","data":{"time":%d,"symbol":"BTC-USDT","buy":"64011.9","sell":"64012","changeRate":"0.0081","changePrice":"514.2","high":"64500","low":"63000","vol":"invalid","volValue":"182744123.41","last":"64012","averagePrice":"63672.4","takerFeeRate":"0.001","makerFeeRate":"0.001"}}`, responseTimestamp)),
+			expectedPrice:  "",
+			expectedVolume: "",
+			expectedError:  appErrors.ErrParsingVolume,
+			symbol:         "BTC-USDT",
+			timestamp:      attestationTimestamp,
+		},
+		{
+			name:           "Invalid kucoin response with symbol mismatch",
+			exchange:       "kucoin",
+			response:       []byte(fmt.Sprintf(`{"code":"200000","data":{"time":%d,"symbol":"ETH-USDT","buy":"64011.9","sell":"64012","changeRate":"0.0081","changePrice":"514.2","high":"64500","low":"63000","vol":"2871.09544","volValue":"182744123.41","last":"64012","averagePrice":"63672.4","takerFeeRate":"0.001","makerFeeRate":"0.001"}}`, responseTimestamp)),
+			expectedPrice:  "",
+			expectedVolume: "",
+			expectedError:  appErrors.ErrSymbolMismatch,
+			symbol:         "BTC-USDT",
+			timestamp:      attestationTimestamp,
+		},
+		{
+			name:           "Missing data in kucoin response",
+			exchange:       "kucoin",
+			response:       []byte(`{"code":"400100","msg":"This pair is not provided at present"}`),
+			expectedPrice:  "",
+			expectedVolume: "",
+			expectedError:  appErrors.ErrMissingDataInResponse,
+			symbol:         "BTC-USDT",
+			timestamp:      attestationTimestamp,
+		},
+		{
+			name:           "Bitfinex valid response",
+			exchange:       "bitfinex",
+			response:       []byte(`[["tBTCUSD",64010,12.5,64011,9.8,520,0.0082,64012,2871.09544064,64500,63000]]`),
+			expectedPrice:  "64012",
+			expectedVolume: "2871.09544064",
+			expectedError:  nil,
+			symbol:         "tBTCUSD",
+			timestamp:      attestationTimestamp,
+		},
+		{
+			name:           "Invalid bitfinex response with invalid price",
+			exchange:       "bitfinex",
+			response:       []byte(`[["tBTCUSD",64010,12.5,64011,9.8,520,0.0082,-1,2871.09544064,64500,63000]]`),
+			expectedPrice:  "",
+			expectedVolume: "",
+			expectedError:  appErrors.ErrParsingPrice,
+			symbol:         "tBTCUSD",
+			timestamp:      attestationTimestamp,
+		},
+		{
+			name:           "Invalid bitfinex response with invalid volume",
+			exchange:       "bitfinex",
+			response:       []byte(`[["tBTCUSD",64010,12.5,64011,9.8,520,0.0082,64012,0,64500,63000]]`),
+			expectedPrice:  "",
+			expectedVolume: "",
+			expectedError:  appErrors.ErrParsingVolume,
+			symbol:         "tBTCUSD",
+			timestamp:      attestationTimestamp,
+		},
+		{
+			name:           "Invalid bitfinex response with symbol mismatch",
+			exchange:       "bitfinex",
+			response:       []byte(`[["tETHUSD",64010,12.5,64011,9.8,520,0.0082,64012,2871.09544064,64500,63000]]`),
+			expectedPrice:  "",
+			expectedVolume: "",
+			expectedError:  appErrors.ErrSymbolMismatch,
+			symbol:         "tBTCUSD",
+			timestamp:      attestationTimestamp,
+		},
+		{
+			name:           "Invalid bitfinex response with missing price",
+			exchange:       "bitfinex",
+			response:       []byte(`[["tBTCUSD",64010,12.5,64011,9.8,520,0.0082,null,2871.09544064,64500,63000]]`),
+			expectedPrice:  "",
+			expectedVolume: "",
+			expectedError:  appErrors.ErrParsingPrice,
+			symbol:         "tBTCUSD",
+			timestamp:      attestationTimestamp,
+		},
+		{
+			name:           "Missing data in bitfinex response",
+			exchange:       "bitfinex",
+			response:       []byte(`[]`),
+			expectedPrice:  "",
+			expectedVolume: "",
+			expectedError:  appErrors.ErrMissingDataInResponse,
+			symbol:         "tBTCUSD",
+			timestamp:      attestationTimestamp,
+		},
+		{
+			name:           "Invalid bitfinex error response",
+			exchange:       "bitfinex",
+			response:       []byte(`["error",10020,"symbol: invalid"]`),
+			expectedPrice:  "",
+			expectedVolume: "",
+			expectedError:  appErrors.ErrDecodingExchangeResponse,
+			symbol:         "tBTCUSD",
+			timestamp:      attestationTimestamp,
+		},
+		{
+			name:           "HTX valid response",
+			exchange:       "htx",
+			response:       []byte(fmt.Sprintf(`{"ch":"market.btcusdt.detail.merged","status":"ok","ts":%d,"tick":{"id":330512345678,"version":330512345678,"open":63500.12,"close":64012.01,"low":63000,"high":64500,"amount":2871.095440641238,"vol":183421554.21,"count":921455,"bid":[64011.99,0.52],"ask":[64012.0,1.3]}}`, responseTimestamp)),
+			expectedPrice:  "64012.01",
+			expectedVolume: "2871.095440641238",
+			expectedError:  nil,
+			symbol:         "btcusdt",
+			timestamp:      attestationTimestamp,
+		},
+		{
+			name:           "Invalid htx response with invalid price",
+			exchange:       "htx",
+			response:       []byte(fmt.Sprintf(`{"ch":"market.btcusdt.detail.merged","status":"ok","ts":%d,"tick":{"id":330512345678,"version":330512345678,"open":63500.12,"close":0,"low":63000,"high":64500,"amount":2871.095440641238,"vol":183421554.21,"count":921455,"bid":[64011.99,0.52],"ask":[64012.0,1.3]}}`, responseTimestamp)),
+			expectedPrice:  "",
+			expectedVolume: "",
+			expectedError:  appErrors.ErrParsingPrice,
+			symbol:         "btcusdt",
+			timestamp:      attestationTimestamp,
+		},
+		{
+			name:           "Invalid htx response with invalid volume",
+			exchange:       "htx",
+			response:       []byte(fmt.Sprintf(`{"ch":"market.btcusdt.detail.merged","status":"ok","ts":%d,"tick":{"id":330512345678,"version":330512345678,"open":63500.12,"close":64012.01,"low":63000,"high":64500,"amount":0,"vol":183421554.21,"count":921455,"bid":[64011.99,0.52],"ask":[64012.0,1.3]}}`, responseTimestamp)),
+			expectedPrice:  "",
+			expectedVolume: "",
+			expectedError:  appErrors.ErrParsingVolume,
+			symbol:         "btcusdt",
+			timestamp:      attestationTimestamp,
+		},
+		{
+			name:           "Invalid htx response with symbol mismatch",
+			exchange:       "htx",
+			response:       []byte(fmt.Sprintf(`{"ch":"market.ethusdt.detail.merged","status":"ok","ts":%d,"tick":{"id":330512345678,"version":330512345678,"open":63500.12,"close":64012.01,"low":63000,"high":64500,"amount":2871.095440641238,"vol":183421554.21,"count":921455,"bid":[64011.99,0.52],"ask":[64012.0,1.3]}}`, responseTimestamp)),
+			expectedPrice:  "",
+			expectedVolume: "",
+			expectedError:  appErrors.ErrSymbolMismatch,
+			symbol:         "btcusdt",
+			timestamp:      attestationTimestamp,
+		},
+		{
+			name:           "Missing data in htx response",
+			exchange:       "htx",
+			response:       []byte(`{"status":"error","err-code":"invalid-parameter","err-msg":"invalid symbol","data":null}`),
+			expectedPrice:  "",
+			expectedVolume: "",
+			expectedError:  appErrors.ErrMissingDataInResponse,
+			symbol:         "btcusdt",
+			timestamp:      attestationTimestamp,
+		},
+		{
+			name:           "Bitget valid response",
+			exchange:       "bitget",
+			response:       []byte(fmt.Sprintf(`{"code":"00000","msg":"success","requestTime":%d,"data":[{"open":"63500.1","symbol":"BTCUSDT","high24h":"64500","low24h":"63000","lastPr":"64012.1","quoteVolume":"182744123.41","baseVolume":"2871.0954","usdtVolume":"182744123.41","ts":"%d","bidPr":"64011.9","askPr":"64012","bidSz":"0.52","askSz":"1.3","openUtc":"63800","changeUtc24h":"0.0033","change24h":"0.0081"}]}`, responseTimestamp, responseTimestamp)),
+			expectedPrice:  "64012.1",
+			expectedVolume: "2871.0954",
+			expectedError:  nil,
+			symbol:         "BTCUSDT",
+			timestamp:      attestationTimestamp,
+		},
+		{
+			name:           "Invalid bitget response with invalid price",
+			exchange:       "bitget",
+			response:       []byte(fmt.Sprintf(`{"code":"00000","msg":"success","requestTime":%d,"data":[{"open":"63500.1","symbol":"BTCUSDT","high24h":"64500","low24h":"63000","lastPr":"invalid","quoteVolume":"182744123.41","baseVolume":"2871.0954","usdtVolume":"182744123.41","ts":"%d","bidPr":"64011.9","askPr":"64012","bidSz":"0.52","askSz":"1.3","openUtc":"63800","changeUtc24h":"0.0033","change24h":"0.0081"}]}`, responseTimestamp, responseTimestamp)),
+			expectedPrice:  "",
+			expectedVolume: "",
+			expectedError:  appErrors.ErrParsingPrice,
+			symbol:         "BTCUSDT",
+			timestamp:      attestationTimestamp,
+		},
+		{
+			name:           "Invalid bitget response with invalid volume",
+			exchange:       "bitget",
+			response:       []byte(fmt.Sprintf(`{"code":"00000","msg":"success","requestTime":%d,"data":[{"open":"63500.1","symbol":"BTCUSDT","high24h":"64500","low24h":"63000","lastPr":"64012.1","quoteVolume":"182744123.41","baseVolume":"invalid","usdtVolume":"182744123.41","ts":"%d","bidPr":"64011.9","askPr":"64012","bidSz":"0.52","askSz":"1.3","openUtc":"63800","changeUtc24h":"0.0033","change24h":"0.0081"}]}`, responseTimestamp, responseTimestamp)),
+			expectedPrice:  "",
+			expectedVolume: "",
+			expectedError:  appErrors.ErrParsingVolume,
+			symbol:         "BTCUSDT",
+			timestamp:      attestationTimestamp,
+		},
+		{
+			name:           "Invalid bitget response with symbol mismatch",
+			exchange:       "bitget",
+			response:       []byte(fmt.Sprintf(`{"code":"00000","msg":"success","requestTime":%d,"data":[{"open":"63500.1","symbol":"ETHUSDT","high24h":"64500","low24h":"63000","lastPr":"64012.1","quoteVolume":"182744123.41","baseVolume":"2871.0954","usdtVolume":"182744123.41","ts":"%d","bidPr":"64011.9","askPr":"64012","bidSz":"0.52","askSz":"1.3","openUtc":"63800","changeUtc24h":"0.0033","change24h":"0.0081"}]}`, responseTimestamp, responseTimestamp)),
+			expectedPrice:  "",
+			expectedVolume: "",
+			expectedError:  appErrors.ErrSymbolMismatch,
+			symbol:         "BTCUSDT",
+			timestamp:      attestationTimestamp,
+		},
+		{
+			name:           "Missing data in bitget response",
+			exchange:       "bitget",
+			response:       []byte(`{"code":"40034","msg":"Parameter does not exist","requestTime":1700000000000,"data":null}`),
+			expectedPrice:  "",
+			expectedVolume: "",
+			expectedError:  appErrors.ErrMissingDataInResponse,
+			symbol:         "BTCUSDT",
+			timestamp:      attestationTimestamp,
+		},
+		{
+			name:           "malformed json response",
+			exchange:       "okx",
+			response:       []byte(`test`),
+			expectedPrice:  "",
+			expectedVolume: "",
+			expectedError:  appErrors.ErrDecodingExchangeResponse,
+			symbol:         "BTCUSDT",
+			timestamp:      attestationTimestamp,
+		},
+		{
+			name:           "malformed json response",
+			exchange:       "kucoin",
+			response:       []byte(`test`),
+			expectedPrice:  "",
+			expectedVolume: "",
+			expectedError:  appErrors.ErrDecodingExchangeResponse,
+			symbol:         "BTCUSDT",
+			timestamp:      attestationTimestamp,
+		},
+		{
+			name:           "malformed json response",
+			exchange:       "bitfinex",
+			response:       []byte(`test`),
+			expectedPrice:  "",
+			expectedVolume: "",
+			expectedError:  appErrors.ErrDecodingExchangeResponse,
+			symbol:         "BTCUSDT",
+			timestamp:      attestationTimestamp,
+		},
+		{
+			name:           "malformed json response",
+			exchange:       "htx",
+			response:       []byte(`test`),
+			expectedPrice:  "",
+			expectedVolume: "",
+			expectedError:  appErrors.ErrDecodingExchangeResponse,
+			symbol:         "BTCUSDT",
+			timestamp:      attestationTimestamp,
+		},
+		{
+			name:           "malformed json response",
+			exchange:       "bitget",
+			response:       []byte(`test`),
+			expectedPrice:  "",
+			expectedVolume: "",
+			expectedError:  appErrors.ErrDecodingExchangeResponse,
+			symbol:         "BTCUSDT",
+			timestamp:      attestationTimestamp,
+		},
 	}
 
 	exchangesConfigs := configs.GetExchangesConfigs()
@@ -368,7 +698,7 @@ func TestParseExchangeResponse(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(fmt.Sprintf("%s_%s", tt.exchange, tt.name), func(t *testing.T) {
-			price, volume, err := priceFeedClient.parseExchangeResponse(tt.exchange, tt.response, tt.symbol, time.Now().Unix(), "USDT")
+			price, volume, _, err := priceFeedClient.parseExchangeResponse(tt.exchange, tt.response, tt.symbol, "USDT")
 			assert.Equal(t, tt.expectedPrice, price)
 			assert.Equal(t, tt.expectedVolume, volume)
 			assert.Equal(t, tt.expectedError, err)
@@ -0,0 +1,108 @@
+package data_extraction
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/hashicorp/go-retryablehttp"
+	"github.com/tidwall/gjson"
+	configs "github.com/venture23-aleo/aleo-oracle-notarization-backend/internal/config"
+	appErrors "github.com/venture23-aleo/aleo-oracle-notarization-backend/pkg/errors"
+	"github.com/venture23-aleo/aleo-oracle-notarization-backend/pkg/logger"
+)
+
+// exchangeURL returns the URL of an endpoint of an exchange. Accepting protocol scheme in the base URL for
+// unit testing.
+func exchangeURL(config configs.ExchangeConfig, endpoint string) string {
+	if strings.HasPrefix(strings.ToLower(config.BaseURL), "https://") || strings.HasPrefix(strings.ToLower(config.BaseURL), "http://") {
+		return fmt.Sprintf("%s%s", config.BaseURL, endpoint)
+	}
+	return fmt.Sprintf("https://%s%s", config.BaseURL, endpoint)
+}
+
+// responseFreshness returns the freshness time of an exchange response in Unix milliseconds, read from the
+// freshness source of the exchange:
+//   - response: the ticker timestamp returned by the parser.
+//   - date_header: the HTTP Date header of the response.
+//   - server_time: the time returned by the server time endpoint, requested through the same client and rate limiter.
+//   - none: no freshness time, zero is returned.
+//
+// A response without the signal of its source fails with ErrMissingFreshnessSignal.
+func responseFreshness(ctx context.Context, exchange string, config configs.ExchangeConfig, httpClient *retryablehttp.Client, rateLimiter *exchangeRateLimiter, resp *http.Response, responseTime int64) (int64, *appErrors.AppError) {
+	reqLogger := logger.FromContext(ctx)
+
+	switch source := config.FreshnessSource(); source {
+	case configs.FreshnessSourceResponse:
+		if responseTime == 0 {
+			reqLogger.Error("Exchange response has no timestamp", "exchange", exchange)
+			return 0, appErrors.ErrMissingFreshnessSignal
+		}
+		return responseTime, nil
+	case configs.FreshnessSourceDateHeader:
+		date, err := http.ParseTime(resp.Header.Get("Date"))
+		if err != nil {
+			reqLogger.Error("Exchange response has no valid Date header", "exchange", exchange, "date", resp.Header.Get("Date"))
+			return 0, appErrors.ErrMissingFreshnessSignal
+		}
+		return date.UnixMilli(), nil
+	case configs.FreshnessSourceServerTime:
+		return fetchServerTime(ctx, exchange, config, httpClient, rateLimiter)
+	case configs.FreshnessSourceNone:
+		return 0, nil
+	default:
+		reqLogger.Error("Unknown freshness source", "exchange", exchange, "source", source)
+		return 0, appErrors.ErrMissingFreshnessSignal
+	}
+}
+
+// fetchServerTime requests the server time endpoint of an exchange and returns its time in Unix milliseconds.
+func fetchServerTime(ctx context.Context, exchange string, config configs.ExchangeConfig, httpClient *retryablehttp.Client, rateLimiter *exchangeRateLimiter) (int64, *appErrors.AppError) {
+	reqLogger := logger.FromContext(ctx)
+
+	if !rateLimiter.allow() {
+		reqLogger.Warn("Exchange server time skipped by the rate limiter", "exchange", exchange)
+		return 0, appErrors.ErrExchangeRateLimited
+	}
+
+	req, err := retryablehttp.NewRequestWithContext(ctx, "GET", exchangeURL(config, config.Freshness.ServerTimeEndpoint), nil)
+	if err != nil {
+		reqLogger.Error("Error creating server time request", "error", err, "exchange", exchange)
+		return 0, appErrors.ErrCreatingExchangeRequest
+	}
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		reqLogger.Error("Error fetching server time from exchange", "error", err, "exchange", exchange)
+		return 0, appErrors.ErrFetchingFromExchange
+	}
+	defer resp.Body.Close()
+	rateLimiter.observe(resp)
+
+	if resp.StatusCode != http.StatusOK {
+		_, err := io.Copy(io.Discard, resp.Body)
+		if err != nil {
+			reqLogger.Warn("Error draining response body", "error", err)
+		}
+		reqLogger.Error("Invalid server time status code", "status_code", resp.StatusCode, "exchange", exchange)
+		if isRateLimitStatus(resp.StatusCode) {
+			return 0, appErrors.ErrExchangeRateLimited
+		}
+		return 0, appErrors.ErrExchangeInvalidStatusCode
+	}
+
+	bodyBytes, err := io.ReadAll(resp.Body)
+	if err != nil {
+		reqLogger.Error("Error reading server time response", "error", err, "exchange", exchange)
+		return 0, appErrors.ErrReadingExchangeResponse
+	}
+
+	serverTime, parseErr := parseTimestampMillis(gjson.GetBytes(bodyBytes, config.Freshness.ServerTimePath), config.Freshness.ServerTimeUnit)
+	if parseErr != nil {
+		reqLogger.Error("Error parsing server time", "error", parseErr, "exchange", exchange)
+		return 0, appErrors.ErrMissingFreshnessSignal
+	}
+	return serverTime, nil
+}
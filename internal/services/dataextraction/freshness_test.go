@@ -0,0 +1,133 @@
+package data_extraction
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/go-retryablehttp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	configs "github.com/venture23-aleo/aleo-oracle-notarization-backend/internal/config"
+	appErrors "github.com/venture23-aleo/aleo-oracle-notarization-backend/pkg/errors"
+)
+
+func TestValidateTimestamp(t *testing.T) {
+	attestationTimestamp := int64(1700000000)
+
+	tests := []struct {
+		name          string
+		timestamp     int64
+		expectedError *appErrors.AppError
+	}{
+		{name: "same time", timestamp: attestationTimestamp * 1000},
+		{name: "at the max age", timestamp: (attestationTimestamp - 60) * 1000},
+		{name: "ahead within the max age", timestamp: (attestationTimestamp + 30) * 1000},
+		{name: "older than the max age", timestamp: (attestationTimestamp-60)*1000 - 1, expectedError: appErrors.ErrTimestampTooOld},
+		{name: "ahead beyond the max age", timestamp: (attestationTimestamp + 61) * 1000, expectedError: appErrors.ErrTimestampTooOld},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expectedError, validateTimestamp("test", tt.timestamp, attestationTimestamp, time.Minute))
+		})
+	}
+}
+
+func TestFetchPriceFromExchange_Freshness(t *testing.T) {
+	now := time.Now()
+	stale := now.Add(-time.Hour)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/ticker":
+			tickerTime := now
+			if r.URL.Query().Get("symbol") == "STALE" {
+				tickerTime = stale
+			}
+			fmt.Fprintf(w, `{"price": "50000", "volume": "10", "time": %d}`, tickerTime.UnixMilli())
+		case "/time":
+			fmt.Fprintf(w, `{"serverTime": %d}`, now.UnixMilli())
+		case "/stale-time":
+			fmt.Fprintf(w, `{"serverTime": %d}`, stale.UnixMilli())
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	withTimestamp := &configs.ExchangeResponseFormat{PricePath: "price", VolumePath: "volume", TimestampPath: "time", TimestampUnit: configs.TimestampUnitMilliseconds}
+	withoutTimestamp := &configs.ExchangeResponseFormat{PricePath: "price", VolumePath: "volume"}
+	serverTime := func(endpoint string) *configs.ExchangeFreshness {
+		return &configs.ExchangeFreshness{
+			Source:             configs.FreshnessSourceServerTime,
+			ServerTimeEndpoint: endpoint,
+			ServerTimePath:     "serverTime",
+			ServerTimeUnit:     configs.TimestampUnitMilliseconds,
+		}
+	}
+
+	exchangeConfigs := configs.ExchangesConfig{
+		"fresh-response":    {ResponseFormat: withTimestamp},
+		"no-timestamp":      {ResponseFormat: withoutTimestamp},
+		"date-header":       {ResponseFormat: withoutTimestamp, Freshness: &configs.ExchangeFreshness{Source: configs.FreshnessSourceDateHeader}},
+		"server-time":       {ResponseFormat: withoutTimestamp, Freshness: serverTime("/time")},
+		"stale-server-time": {ResponseFormat: withoutTimestamp, Freshness: serverTime("/stale-time")},
+		"missing-time":      {ResponseFormat: withoutTimestamp, Freshness: serverTime("/missing")},
+		"unverified":        {ResponseFormat: withTimestamp, Freshness: &configs.ExchangeFreshness{Source: configs.FreshnessSourceNone}},
+	}
+	for exchange, config := range exchangeConfigs {
+		config.Name = exchange
+		config.BaseURL = server.URL
+		config.EndpointTemplate = "/ticker?symbol={symbol}"
+		exchangeConfigs[exchange] = config
+
+		client := retryablehttp.NewClient()
+		client.RetryMax = 0
+		clientCache.Store(exchange, client)
+		t.Cleanup(func() {
+			clientCache.Delete(exchange)
+			exchangeRateLimiters.Delete(exchange)
+		})
+	}
+
+	healthNow := now
+	priceFeedClient := &PriceFeedClient{exchangeConfigs: exchangeConfigs, exchangeHealth: newTestExchangeHealthTracker(&healthNow)}
+
+	tests := []struct {
+		exchange          string
+		symbol            string
+		expectedFreshness string
+		expectedError     *appErrors.AppError
+		expectedStale     bool
+	}{
+		{exchange: "fresh-response", symbol: "BTC-USD", expectedFreshness: configs.FreshnessSourceResponse},
+		{exchange: "fresh-response", symbol: "STALE", expectedError: appErrors.ErrTimestampTooOld, expectedStale: true},
+		{exchange: "no-timestamp", symbol: "BTC-USD", expectedError: appErrors.ErrMissingFreshnessSignal},
+		{exchange: "date-header", symbol: "BTC-USD", expectedFreshness: configs.FreshnessSourceDateHeader},
+		{exchange: "server-time", symbol: "BTC-USD", expectedFreshness: configs.FreshnessSourceServerTime},
+		{exchange: "stale-server-time", symbol: "BTC-USD", expectedError: appErrors.ErrTimestampTooOld, expectedStale: true},
+		{exchange: "missing-time", symbol: "BTC-USD", expectedError: appErrors.ErrExchangeInvalidStatusCode},
+		{exchange: "unverified", symbol: "STALE", expectedFreshness: configs.FreshnessSourceNone},
+	}
+
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("%s_%s", tt.exchange, tt.symbol), func(t *testing.T) {
+			exchangePrice, err := priceFeedClient.FetchPriceFromExchange(context.Background(), tt.exchange, "BTC", tt.symbol, now.Unix())
+			assert.Equal(t, tt.expectedError, err)
+
+			health := priceFeedClient.exchangeHealth.symbols[exchangeSymbolKey{tt.exchange, tt.symbol}]
+			require.Len(t, health.requests, 1)
+			assert.Equal(t, tt.expectedStale, health.requests[0].stale)
+			if tt.expectedError != nil {
+				return
+			}
+			require.NotNil(t, exchangePrice)
+			assert.Equal(t, "50000", exchangePrice.Price)
+			assert.Equal(t, tt.expectedFreshness, exchangePrice.Freshness)
+		})
+	}
+}
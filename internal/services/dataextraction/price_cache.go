@@ -0,0 +1,136 @@
+package data_extraction
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	configs "github.com/venture23-aleo/aleo-oracle-notarization-backend/internal/config"
+	"github.com/venture23-aleo/aleo-oracle-notarization-backend/internal/metrics"
+	appErrors "github.com/venture23-aleo/aleo-oracle-notarization-backend/pkg/errors"
+	"github.com/venture23-aleo/aleo-oracle-notarization-backend/pkg/logger"
+)
+
+var (
+	sharedPriceCache     *priceCache
+	sharedPriceCacheOnce sync.Once
+)
+
+// getSharedPriceCache returns the price cache shared by the price feed clients, nil when the cache is disabled.
+func getSharedPriceCache() *priceCache {
+	sharedPriceCacheOnce.Do(func() {
+		cacheConfig := configs.GetPriceCacheConfig()
+		if cacheConfig.Enabled && cacheConfig.TTL > 0 {
+			sharedPriceCache = newPriceCache(cacheConfig.TTL)
+		}
+	})
+	return sharedPriceCache
+}
+
+// priceCacheKey identifies a cached price feed result.
+type priceCacheKey struct {
+	token     string
+	precision uint
+}
+
+// priceCacheEntry is a cached price feed result, or the in-flight request computing it.
+type priceCacheEntry struct {
+	done      chan struct{} // Closed when the request completes.
+	result    *PriceFeedResult
+	err       *appErrors.AppError
+	expiresAt time.Time
+}
+
+// priceCache caches the price feed results per token and precision for a short TTL. Concurrent requests
+// for the same key wait for the in-flight request instead of fetching the exchanges again.
+type priceCache struct {
+	ttl     time.Duration
+	now     func() time.Time
+	mu      sync.Mutex
+	entries map[priceCacheKey]*priceCacheEntry
+}
+
+// newPriceCache creates a price cache keeping the results for ttl.
+func newPriceCache(ttl time.Duration) *priceCache {
+	return &priceCache{
+		ttl:     ttl,
+		now:     time.Now,
+		entries: make(map[priceCacheKey]*priceCacheEntry),
+	}
+}
+
+// get returns the cached price feed result of a token, waits for the in-flight request of the token, or
+// computes the result with fetch. Failed results are not cached.
+//
+// A shared result was fetched for the attestation timestamp of another request, so it is only returned when its
+// prices are also fresh at timestamp. Otherwise the result is fetched again.
+//
+// fetch runs without the cancellation of ctx, so a caller giving up does not fail the callers sharing its result.
+func (c *priceCache) get(ctx context.Context, token string, timestamp int64, precision uint, fetch func(ctx context.Context) (*PriceFeedResult, *appErrors.AppError)) (*PriceFeedResult, *appErrors.AppError) {
+	key := priceCacheKey{token: strings.ToUpper(token), precision: precision}
+
+	c.mu.Lock()
+	if entry, exists := c.entries[key]; exists {
+		select {
+		case <-entry.done:
+			if c.now().Before(entry.expiresAt) && entry.result.freshAt(timestamp) {
+				c.mu.Unlock()
+				metrics.RecordPriceFeedCacheRequest(key.token, "hit")
+				return copyPriceFeedResult(entry.result), nil
+			}
+		default:
+			c.mu.Unlock()
+			result, err := c.wait(ctx, key.token, entry)
+			if err != nil || result.freshAt(timestamp) {
+				metrics.RecordPriceFeedCacheRequest(key.token, "coalesced")
+				return result, err
+			}
+			// The in-flight result is not fresh at timestamp, so it is fetched again without replacing it.
+			metrics.RecordPriceFeedCacheRequest(key.token, "stale")
+			return fetch(ctx)
+		}
+	}
+
+	entry := &priceCacheEntry{done: make(chan struct{})}
+	c.entries[key] = entry
+	c.mu.Unlock()
+	metrics.RecordPriceFeedCacheRequest(key.token, "miss")
+
+	result, err := fetch(context.WithoutCancel(ctx))
+
+	c.mu.Lock()
+	entry.result = result
+	entry.err = err
+	entry.expiresAt = c.now().Add(c.ttl)
+	if err != nil && c.entries[key] == entry {
+		delete(c.entries, key)
+	}
+	c.mu.Unlock()
+	close(entry.done)
+
+	if err != nil {
+		return nil, err
+	}
+	return copyPriceFeedResult(result), nil
+}
+
+// wait waits for the in-flight request of an entry, or for the cancellation of ctx.
+func (c *priceCache) wait(ctx context.Context, token string, entry *priceCacheEntry) (*PriceFeedResult, *appErrors.AppError) {
+	select {
+	case <-entry.done:
+		if entry.err != nil {
+			return nil, entry.err
+		}
+		return copyPriceFeedResult(entry.result), nil
+	case <-ctx.Done():
+		logger.FromContext(ctx).Error("Price feed request cancelled while waiting for the in-flight request", "token", token, "error", ctx.Err())
+		return nil, appErrors.ErrFetchingFromExchange
+	}
+}
+
+// copyPriceFeedResult returns a shallow copy of a cached result, so callers can update its fields.
+func copyPriceFeedResult(result *PriceFeedResult) *PriceFeedResult {
+	resultCopy := *result
+	return &resultCopy
+}
@@ -0,0 +1,143 @@
+package data_extraction
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	configs "github.com/venture23-aleo/aleo-oracle-notarization-backend/internal/config"
+	appErrors "github.com/venture23-aleo/aleo-oracle-notarization-backend/pkg/errors"
+)
+
+func TestPriceCache(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	cache := newPriceCache(3 * time.Second)
+	cache.now = func() time.Time { return now }
+
+	var fetches atomic.Int32
+	fetch := func(price string) func(context.Context) (*PriceFeedResult, *appErrors.AppError) {
+		return func(context.Context) (*PriceFeedResult, *appErrors.AppError) {
+			fetches.Add(1)
+			return &PriceFeedResult{Token: "BTC", VolumeWeightedAvg: price, Success: true}, nil
+		}
+	}
+
+	result, err := cache.get(context.Background(), "btc", 1700000000, 6, fetch("50000.000000"))
+	require.Nil(t, err)
+	assert.Equal(t, "50000.000000", result.VolumeWeightedAvg)
+
+	t.Run("hit within the TTL", func(t *testing.T) {
+		result.VolumeWeightedAvg = "updated by the caller"
+		cached, err := cache.get(context.Background(), "BTC", 1700000000, 6, fetch("60000.000000"))
+		require.Nil(t, err)
+		assert.Equal(t, "50000.000000", cached.VolumeWeightedAvg)
+		assert.Equal(t, int32(1), fetches.Load())
+	})
+
+	t.Run("miss when the prices are not fresh at the timestamp", func(t *testing.T) {
+		timestamp := int64(1700000100)
+		fetchFresh := func(context.Context) (*PriceFeedResult, *appErrors.AppError) {
+			fetches.Add(1)
+			return &PriceFeedResult{
+				Token:             "SOL",
+				VolumeWeightedAvg: "150.000000",
+				Success:           true,
+				freshness:         []priceFreshness{{token: "SOL", time: time.Unix(timestamp, 0).UnixMilli()}},
+			}, nil
+		}
+
+		_, err := cache.get(context.Background(), "SOL", timestamp, 6, fetchFresh)
+		require.Nil(t, err)
+		fetchesBefore := fetches.Load()
+
+		_, err = cache.get(context.Background(), "SOL", timestamp+1, 6, fetchFresh)
+		require.Nil(t, err)
+		assert.Equal(t, fetchesBefore, fetches.Load(), "prices still fresh at the later timestamp")
+
+		later := timestamp + int64(configs.GetTokenMaxAge("SOL")/time.Second) + 1
+		_, err = cache.get(context.Background(), "SOL", later, 6, fetchFresh)
+		require.Nil(t, err)
+		assert.Equal(t, fetchesBefore+1, fetches.Load(), "prices older than the max age at the later timestamp")
+	})
+
+	t.Run("precision is part of the key", func(t *testing.T) {
+		result, err := cache.get(context.Background(), "BTC", 1700000000, 2, fetch("50000.00"))
+		require.Nil(t, err)
+		assert.Equal(t, "50000.00", result.VolumeWeightedAvg)
+		assert.Equal(t, int32(4), fetches.Load())
+	})
+
+	t.Run("miss after the TTL", func(t *testing.T) {
+		now = now.Add(3 * time.Second)
+		result, err := cache.get(context.Background(), "BTC", 1700000000, 6, fetch("60000.000000"))
+		require.Nil(t, err)
+		assert.Equal(t, "60000.000000", result.VolumeWeightedAvg)
+		assert.Equal(t, int32(5), fetches.Load())
+	})
+
+	t.Run("errors are not cached", func(t *testing.T) {
+		_, err := cache.get(context.Background(), "ETH", 1700000000, 6, func(context.Context) (*PriceFeedResult, *appErrors.AppError) {
+			return nil, appErrors.ErrInsufficientExchangeData
+		})
+		assert.Equal(t, appErrors.ErrInsufficientExchangeData, err)
+
+		result, err := cache.get(context.Background(), "ETH", 1700000000, 6, fetch("3000.000000"))
+		require.Nil(t, err)
+		assert.Equal(t, "3000.000000", result.VolumeWeightedAvg)
+	})
+}
+
+func TestPriceCache_CoalescesConcurrentRequests(t *testing.T) {
+	cache := newPriceCache(3 * time.Second)
+
+	var fetches atomic.Int32
+	release := make(chan struct{})
+	fetch := func(ctx context.Context) (*PriceFeedResult, *appErrors.AppError) {
+		fetches.Add(1)
+		<-release
+		if ctx.Err() != nil {
+			return nil, appErrors.ErrFetchingFromExchange
+		}
+		return &PriceFeedResult{Token: "BTC", VolumeWeightedAvg: "50000.000000", Success: true}, nil
+	}
+
+	// The first request is cancelled while in flight, the others still share its result.
+	leaderCtx, cancelLeader := context.WithCancel(context.Background())
+	leaderDone := make(chan *appErrors.AppError)
+	go func() {
+		_, err := cache.get(leaderCtx, "BTC", 1700000000, 6, fetch)
+		leaderDone <- err
+	}()
+	require.Eventually(t, func() bool { return fetches.Load() == 1 }, time.Second, time.Millisecond)
+
+	var wg sync.WaitGroup
+	results := make([]*PriceFeedResult, 10)
+	errs := make([]*appErrors.AppError, 10)
+	for i := range results {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			results[i], errs[i] = cache.get(context.Background(), "BTC", 1700000000, 6, fetch)
+		}(i)
+	}
+
+	cancelledCtx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := cache.get(cancelledCtx, "BTC", 1700000000, 6, fetch)
+	assert.Equal(t, appErrors.ErrFetchingFromExchange, err)
+
+	cancelLeader()
+	close(release)
+	wg.Wait()
+
+	assert.Nil(t, <-leaderDone)
+	assert.Equal(t, int32(1), fetches.Load())
+	for i := range results {
+		require.Nil(t, errs[i])
+		assert.Equal(t, "50000.000000", results[i].VolumeWeightedAvg)
+	}
+}
@@ -12,6 +12,7 @@ import (
 	"math/big"
 	"net/http"
 	"os"
+	"slices"
 	"sort"
 	"strconv"
 	"strings"
@@ -19,34 +20,78 @@ import (
 	"time"
 
 	"github.com/hashicorp/go-retryablehttp"
+	encoding "github.com/venture23-aleo/aleo-oracle-encoding"
 	configs "github.com/venture23-aleo/aleo-oracle-notarization-backend/internal/config"
-	appErrors "github.com/venture23-aleo/aleo-oracle-notarization-backend/internal/errors"
-	"github.com/venture23-aleo/aleo-oracle-notarization-backend/internal/logger"
 	"github.com/venture23-aleo/aleo-oracle-notarization-backend/internal/metrics"
 	"github.com/venture23-aleo/aleo-oracle-notarization-backend/internal/services/attestation"
+	appErrors "github.com/venture23-aleo/aleo-oracle-notarization-backend/pkg/errors"
+	"github.com/venture23-aleo/aleo-oracle-notarization-backend/pkg/logger"
 )
 
 // ExchangePrice represents a price from a single exchange
 type ExchangePrice struct {
-	Exchange string `json:"exchange"` // Exchange name.
-	Price    string `json:"price"`    // Price.
-	Volume   string `json:"volume"`   // Volume.
-	Token    string `json:"token"`    // Token.
-	Symbol   string `json:"symbol"`   // Symbol.
+	Exchange     string `json:"exchange"`               // Exchange name.
+	Price        string `json:"price"`                  // Price in USD. Empty when the quote currency could not be converted.
+	Volume       string `json:"volume"`                 // 24h volume in USD, the weight of the price in the aggregation.
+	BaseVolume   string `json:"baseVolume,omitempty"`   // 24h volume in the token.
+	QuoteVolume  string `json:"quoteVolume,omitempty"`  // 24h volume in the quote currency.
+	VolumeUnit   string `json:"volumeUnit,omitempty"`   // Unit of the volume reported by the exchange, base or quote.
+	Token        string `json:"token"`                  // Token.
+	Symbol       string `json:"symbol"`                 // Symbol.
+	Quote        string `json:"quote,omitempty"`        // Quote currency of the symbol.
+	PriceInQuote string `json:"priceInQuote,omitempty"` // Price in the quote currency.
+	Freshness    string `json:"freshness,omitempty"`    // Freshness source the price was verified with, none when unverified.
+
+	freshnessTime int64 // Time of the freshness signal in Unix milliseconds, zero when unverified.
 }
 
 // PriceFeedResult represents the result of a price feed calculation
+
 type PriceFeedResult struct {
-	Token              string          `json:"token"`              // Token.
-	VolumeWeightedAvg  string          `json:"volumeWeightedAvg"`  // Volume-weighted average price.
-	TotalVolume        string          `json:"totalVolume"`        // Total volume.
-	ExchangeCount      int             `json:"exchangeCount"`      // Number of exchanges.
-	Timestamp          int64           `json:"timestamp"`          // Timestamp.
-	ExchangePricesRaw  []ExchangePrice `json:"exchangePricesRaw"`  // Exchange prices.
-	ExchangePricesUsed []ExchangePrice `json:"exchangePricesUsed"` // Exchange prices.
-	Success            bool            `json:"success"`            // Success.
+	Token                  string            `json:"token"`                            // Token.
+	VolumeWeightedAvg      string            `json:"volumeWeightedAvg"`                // Aggregated price, computed with the method of the token.
+	TotalVolume            string            `json:"totalVolume"`                      // Total volume in USD.
+	ExchangeCount          int               `json:"exchangeCount"`                    // Number of exchanges.
+	Timestamp              int64             `json:"timestamp"`                        // Timestamp.
+	ExchangePricesRaw      []ExchangePrice   `json:"exchangePricesRaw"`                // Exchange prices.
+	ExchangePricesUsed     []ExchangePrice   `json:"exchangePricesUsed"`               // Exchange prices.
+	ExchangePricesExcluded []ExcludedPrice   `json:"exchangePricesExcluded,omitempty"` // Exchange prices left out of the aggregation, with the reason.
+	ConversionRates        map[string]string `json:"conversionRates,omitempty"`        // USD price of each quote currency the prices were converted from.
+	Aggregation            *AggregationStats `json:"aggregation,omitempty"`            // Aggregation method and its intermediate statistics.
+	Guard                  *PriceGuardStatus `json:"guard,omitempty"`                  // Price guard of the token, nil when the token has no guard.
+	Success                bool              `json:"success"`                          // Success.
+
+	freshness []priceFreshness // Freshness of the prices behind the result, including the conversion rates.
+}
+
+// priceFreshness is the time of the freshness signal of a price used in a price feed result, so that a cached
+// result can be checked again against the attestation timestamp of a later request.
+type priceFreshness struct {
+	token string // Token of the price, whose max age applies.
+	time  int64  // Time of the freshness signal in Unix milliseconds.
 }
 
+// freshAt reports whether every price behind the result is within the max age of its token at an attestation
+// timestamp, the check the prices passed when they were fetched.
+func (r *PriceFeedResult) freshAt(timestamp int64) bool {
+	for _, freshness := range r.freshness {
+		timeDiff := time.UnixMilli(freshness.time).Sub(time.Unix(timestamp, 0))
+		if timeDiff < 0 {
+			timeDiff = -timeDiff
+		}
+		if timeDiff > configs.GetTokenMaxAge(freshness.token) {
+			return false
+		}
+	}
+	return true
+}
+
+// convertedPricePrecision is the number of decimals kept when converting a price into USD.
+const convertedPricePrecision = 18
+
+// normalizedVolumePrecision is the number of decimals kept when deriving a volume in another unit.
+const normalizedVolumePrecision = 18
+
 // Fetch prices from all exchanges concurrently
 type fetchResult struct {
 	exchange string
@@ -59,6 +104,8 @@ type PriceFeedClient struct {
 	exchangeConfigs   configs.ExchangesConfig   // Exchange configurations.
 	tokenExchanges    configs.TokenExchanges    // Token exchanges.
 	tokenTradingPairs configs.TokenTradingPairs // Token trading pairs.
+	priceCache        *priceCache               // Shared price cache, nil when caching is disabled.
+	exchangeHealth    *exchangeHealthTracker    // Shared exchange health tracker, nil when disabled.
 }
 
 // NewPriceFeedClient creates a new PriceFeedClient with default configurations
@@ -71,6 +118,8 @@ func NewPriceFeedClient() *PriceFeedClient {
 		exchangeConfigs:   exchangeConfigs,
 		tokenExchanges:    tokenExchanges,
 		tokenTradingPairs: tokenTradingPairs,
+		priceCache:        getSharedPriceCache(),
+		exchangeHealth:    getSharedExchangeHealth(),
 	}
 }
 
@@ -159,26 +208,41 @@ func GetRetryableHTTPClientForExchange(exchange string, maxRetries int) (*retrya
 	retryClient.RetryWaitMin = 2 * time.Second
 	retryClient.RetryWaitMax = 3 * time.Second
 	retryClient.RetryMax = maxRetries
+	retryClient.CheckRetry = checkExchangeRetry
 
 	clientCache.Store(exchange, retryClient)
 	logger.Info("Stored retryable HTTP client in cache", "exchange", exchange)
 	return retryClient, nil
 }
 
+// checkExchangeRetry is the retry policy of the exchange clients. Rate limit responses are not retried, so
+// the rate limiter sees them and throttles the exchange instead of waiting for the Retry-After.
+func checkExchangeRetry(ctx context.Context, resp *http.Response, err error) (bool, error) {
+	if err == nil && isRateLimitStatus(resp.StatusCode) {
+		return false, nil
+	}
+	return retryablehttp.DefaultRetryPolicy(ctx, resp, err)
+}
+
 // FetchPriceFromExchange fetches price and volume data from a specific exchange.
 //
 // This function performs the following steps sequentially:
 //  1. Retrieves the exchange configuration for the given exchange.
 //  2. Replace the symbol in the endpoint template.
 //  3. Constructs the full URL for the API request, handling cases where the BaseURL may or may not include the protocol.
-//  4. Creates a retryable HTTP client for robust network requests.
+//     Skips the exchange symbol while its circuit is open, and records the outcome of the request in its health.
+//  4. Creates a retryable HTTP client for robust network requests, skipping the exchange when its rate limiter
+//     is empty or throttled.
 //  5. Builds an HTTP GET request with the provided context.
 //  6. Executes the HTTP request and handles any network errors.
-//  7. Checks the HTTP response status code for success.
+//  7. Checks the HTTP response status code for success, throttling the exchange on rate limit responses.
 //  8. Reads the response body from the exchange API.
 //  9. Attempts to decode the response body as a JSON object. If decoding fails and the exchange is "gate.io", attempts to decode as a JSON array and adapts the data structure accordingly.
 //  10. Parses the price and volume from the decoded response using the appropriate exchange-specific parser.
-//  11. Returns an ExchangePrice struct with the parsed data, or an error if any step fails.
+//  11. Verifies that the freshness signal of the exchange is within the max age of the token from the attestation
+//     timestamp. Exchanges with the none freshness source are not verified, their prices are down-weighted or
+//     rejected by the aggregation.
+//  12. Returns an ExchangePrice struct with the parsed data, or an error if any step fails.
 //
 // Parameters:
 //   - ctx: The context for request cancellation and logging.
@@ -189,7 +253,7 @@ func GetRetryableHTTPClientForExchange(exchange string, maxRetries int) (*retrya
 // Returns:
 //   - *ExchangePrice: The parsed price and volume data from the exchange.
 //   - *appErrors.AppError: An application error if any step fails, otherwise nil.
-func (c *PriceFeedClient) FetchPriceFromExchange(ctx context.Context, exchange, token, symbol string, timestamp int64) (*ExchangePrice, *appErrors.AppError) {
+func (c *PriceFeedClient) FetchPriceFromExchange(ctx context.Context, exchange, token, symbol string, timestamp int64) (_ *ExchangePrice, appErr *appErrors.AppError) {
 	reqLogger := logger.FromContext(ctx)
 
 	// Step 1: Get exchange configuration.
@@ -211,13 +275,18 @@ func (c *PriceFeedClient) FetchPriceFromExchange(ctx context.Context, exchange,
 	}
 	endpoint := strings.Replace(config.EndpointTemplate, "{symbol}", symbol, 1)
 
-	// Step 3: Construct the full URL. Accepting protocol scheme in the base URL for unit testing.
-	var url string
-	if strings.HasPrefix(strings.ToLower(config.BaseURL), "https://") || strings.HasPrefix(strings.ToLower(config.BaseURL), "http://") {
-		url = fmt.Sprintf("%s%s", config.BaseURL, endpoint)
-	} else {
-		url = fmt.Sprintf("https://%s%s", config.BaseURL, endpoint)
+	// Step 3: Construct the full URL.
+	url := exchangeURL(config, endpoint)
+
+	if !c.exchangeHealth.allow(exchange, symbol) {
+		reqLogger.Warn("Exchange skipped by the open circuit", "exchange", exchange, "token", token, "symbol", symbol)
+		return nil, appErrors.ErrExchangeCircuitOpen
 	}
+	requestStart := time.Now()
+	stale := false
+	defer func() {
+		c.exchangeHealth.recordRequest(exchange, symbol, appErr, stale, time.Since(requestStart))
+	}()
 
 	// Step 4: Create retryable HTTP client.
 	httpClient, err := GetRetryableHTTPClientForExchange(exchange, 1)
@@ -226,6 +295,12 @@ func (c *PriceFeedClient) FetchPriceFromExchange(ctx context.Context, exchange,
 		return nil, appErrors.ErrCreatingExchangeRequest
 	}
 
+	rateLimiter := getExchangeRateLimiter(exchange, config)
+	if !rateLimiter.allow() {
+		reqLogger.Warn("Exchange skipped by the rate limiter", "exchange", exchange, "token", token, "symbol", symbol)
+		return nil, appErrors.ErrExchangeRateLimited
+	}
+
 	// Step 5: Create request with context.
 	req, err := retryablehttp.NewRequestWithContext(ctx, "GET", url, nil)
 	if err != nil {
@@ -240,6 +315,7 @@ func (c *PriceFeedClient) FetchPriceFromExchange(ctx context.Context, exchange,
 		return nil, appErrors.ErrFetchingFromExchange
 	}
 	defer resp.Body.Close()
+	rateLimiter.observe(resp)
 
 	// Step 7: Check for valid HTTP status code.
 	if resp.StatusCode != http.StatusOK {
@@ -248,6 +324,9 @@ func (c *PriceFeedClient) FetchPriceFromExchange(ctx context.Context, exchange,
 			reqLogger.Warn("Error draining response body", "error", err)
 		}
 		reqLogger.Error("Invalid status code", "status_code", resp.StatusCode, "exchange", exchange, "token", token, "symbol", symbol)
+		if isRateLimitStatus(resp.StatusCode) {
+			return nil, appErrors.ErrExchangeRateLimited
+		}
 		return nil, appErrors.ErrExchangeInvalidStatusCode
 	}
 
@@ -264,219 +343,92 @@ func (c *PriceFeedClient) FetchPriceFromExchange(ctx context.Context, exchange,
 	}
 
 	// Step 10: Parse price and volume from the decoded response.
-	price, volume, parseErr := c.parseExchangeResponse(exchange, bodyBytes,symbol, timestamp, token)
+	price, volume, responseTime, parseErr := c.parseExchangeResponse(exchange, bodyBytes, symbol, token)
 	if parseErr != nil {
 		reqLogger.Error("Error parsing exchange response", "error", parseErr, "exchange", exchange, "token", token, "symbol", symbol)
 		return nil, appErrors.ErrParsingExchangeResponse
 	}
 
-	// Step 11: Return the parsed ExchangePrice.
+	// Step 11: Verify the freshness of the response against the attestation timestamp.
+	freshnessSource := config.FreshnessSource()
+	freshnessTime, freshnessErr := responseFreshness(ctx, exchange, config, httpClient, rateLimiter, resp, responseTime)
+	if freshnessErr != nil {
+		return nil, freshnessErr
+	}
+	if freshnessSource != configs.FreshnessSourceNone {
+		if freshnessErr := validateTimestamp(exchange, freshnessTime, timestamp, configs.GetTokenMaxAge(token)); freshnessErr != nil {
+			stale = true
+			return nil, freshnessErr
+		}
+	}
+
+	// Step 12: Return the parsed ExchangePrice. The USD volume is set once the price is converted into USD.
+	volumeUnit := c.exchangeVolumeUnit(exchange)
+	baseVolume, quoteVolume := normalizeVolumes(price, volume, volumeUnit)
 	return &ExchangePrice{
-		Exchange: config.Name,
-		Price:    price,
-		Volume:   volume,
-		Token:    token,
-		Symbol:   symbol,
+		Exchange:    config.Name,
+		Price:       price,
+		BaseVolume:  baseVolume,
+		QuoteVolume: quoteVolume,
+		VolumeUnit:  volumeUnit,
+		Token:       token,
+		Symbol:      symbol,
+		Freshness:   freshnessSource,
+
+		freshnessTime: freshnessTime,
 	}, nil
 }
 
-// CalculateVolumeWeightedAverage calculates the volume-weighted average price
-func CalculateVolumeWeightedAverage(prices []ExchangePrice, precision uint, token string) (string, string, int, []ExchangePrice, *appErrors.AppError) {
-	if len(prices) == 0 {
-		return "", "", 0, nil, appErrors.ErrNoPricesFound
-	}
-
-	// ValidPrice represents a valid price from an exchange
-	type ValidPrice struct {
-		Exchange string   `json:"exchange"` // Exchange name.
-		Symbol   string   `json:"symbol"`   // Symbol.
-		Price    *big.Rat `json:"price"`    // Price.
-		Volume   *big.Rat `json:"volume"`   // Volume.
-		Token    string   `json:"token"`    // Token.
+// normalizeVolumes returns the base and quote volumes of a symbol from the volume reported in volumeUnit,
+// deriving the other one at the given price.
+func normalizeVolumes(price, volume, volumeUnit string) (baseVolume, quoteVolume string) {
+	priceRat, ok := new(big.Rat).SetString(price)
+	volumeRat, volumeOk := new(big.Rat).SetString(volume)
+	if !ok || !volumeOk || priceRat.Sign() <= 0 {
+		return "", ""
 	}
 
-	validPrices := []ValidPrice{}
-
-	type ExchangeSymbol struct {
-		exchange string
-		symbol   string
+	if volumeUnit == configs.VolumeDenominationQuote {
+		return Truncate(volumeRat.Quo(volumeRat, priceRat), normalizedVolumePrecision), volume
 	}
+	return volume, Truncate(volumeRat.Mul(volumeRat, priceRat), normalizedVolumePrecision)
+}
 
-	exchangeSymbols := make(map[ExchangeSymbol]bool)
-
-	tokenVWAPConfig, err := configs.GetTokenVWAPConfig(token)
+// CalculateVolumeWeightedAverage calculates the volume-weighted average price, whatever the aggregation
+// method configured for the token.
+func CalculateVolumeWeightedAverage(prices []ExchangePrice, precision uint, token string) (string, string, int, []ExchangePrice, *appErrors.AppError) {
+	config, validPrices, _, err := prepareAggregation(prices, token)
 	if err != nil {
 		return "", "", 0, nil, err
 	}
 
-	tokenToleranceFraction := new(big.Rat).Mul(new(big.Rat).SetFloat64(tokenVWAPConfig.TokenTolerancePercent), big.NewRat(1, 100))
-	tokenMADMultiplier := new(big.Rat).SetFloat64(tokenVWAPConfig.TokenMADMultiplier)
-	tokenMaxSpreadFraction := new(big.Rat).Mul(new(big.Rat).SetFloat64(tokenVWAPConfig.TokenMaxSpreadPercent), big.NewRat(1, 100))
-	tokenMinVolumePerExchange := new(big.Rat).SetFloat64(tokenVWAPConfig.TokenMinVolumePerExchange)
-	tokenMaxExchangeWeightFraction := new(big.Rat).Mul(new(big.Rat).SetFloat64(tokenVWAPConfig.TokenMaxExchangeWeightPercent), big.NewRat(1, 100))
-
-	logger.Debug("Token VWAP Config", "token", token, "tokenToleranceFraction", tokenToleranceFraction, "tokenMADMultiplier", tokenMADMultiplier, "tokenMaxSpreadFraction", tokenMaxSpreadFraction, "tokenMinVolumePerExchange", tokenMinVolumePerExchange, "tokenMaxExchangeWeightFraction", tokenMaxExchangeWeightFraction)
-
-	for _, p := range prices {
-		if p.Price == "" || p.Volume == "" {
-			continue
-		}
-
-		key := ExchangeSymbol{exchange: p.Exchange, symbol: p.Symbol}
-
-		if _, exists := exchangeSymbols[key]; exists {
-			logger.Error("Duplicate exchange and symbol", "exchange", p.Exchange, "symbol", p.Symbol, "price", p.Price, "volume", p.Volume)
-			continue
-		}
-
-		exchangeSymbols[key] = true
-
-		volumeRat, ok := new(big.Rat).SetString(p.Volume)
-		if !ok || volumeRat.Cmp(tokenMinVolumePerExchange) < 0 {
-			continue
-		}
-
-		priceRat, ok := new(big.Rat).SetString(p.Price)
-		if !ok || priceRat.Sign() <= 0 {
-			continue
-		}
-
-		validPrices = append(validPrices, ValidPrice{
-			Exchange: p.Exchange,
-			Symbol:   p.Symbol,
-			Price:    priceRat,
-			Volume:   volumeRat,
-			Token:    p.Token,
-		})
-	}
-
-	if len(validPrices) == 0 {
-		return "", "", 0, nil, appErrors.ErrAllPricesBelowMinVolume
-	}
-
-	priceValues := []*big.Rat{}
-	for _, vp := range validPrices {
-		priceValues = append(priceValues, vp.Price)
-	}
-
-	// Calculate the median price
-	medianPrice := computeMedian(priceValues)
-
-	// Calculate the median absolute deviation
-	mad := computeMAD(priceValues, medianPrice)
-
-	// Compute bounds: max(MAD-based, token-tolerance-based)
-	madLower := new(big.Rat).Sub(medianPrice, new(big.Rat).Mul(tokenMADMultiplier, mad))
-	madUpper := new(big.Rat).Add(medianPrice, new(big.Rat).Mul(tokenMADMultiplier, mad))
-	tolLower := new(big.Rat).Mul(medianPrice, new(big.Rat).Sub(big.NewRat(1, 1), tokenToleranceFraction))
-	tolUpper := new(big.Rat).Mul(medianPrice, new(big.Rat).Add(big.NewRat(1, 1), tokenToleranceFraction))
-
-	lower := new(big.Rat)
-	if tolLower.Cmp(madLower) < 0 {
-		lower.Set(madLower)
-	} else {
-		lower.Set(tolLower)
-	}
-	upper := new(big.Rat)
-	if tolUpper.Cmp(madUpper) > 0 {
-		upper.Set(madUpper)
-	} else {
-		upper.Set(tolUpper)
-	}
-	
-	logger.Info("VWAP Inputs", "mad", Truncate(mad, int(precision)), "medianPrice", Truncate(medianPrice, int(precision)), "tokenMADMultiplier", Truncate(tokenMADMultiplier, int(precision)), "tokenToleranceFraction", Truncate(tokenToleranceFraction, int(precision)), "madLower", Truncate(madLower, int(precision)), "tolLower", Truncate(tolLower, int(precision)), "madUpper", Truncate(madUpper, int(precision)), "tolUpper", Truncate(tolUpper, int(precision)), "lower", Truncate(lower, int(precision)), "upper", Truncate(upper, int(precision)))
-
-	filteredPrices := []ValidPrice{}
-	for _, vp := range validPrices {
-		if vp.Price.Cmp(lower) >= 0 && vp.Price.Cmp(upper) <= 0 {
-			filteredPrices = append(filteredPrices, vp)
-		} else {
-			logger.Error("Outlier filtered", "exchange", vp.Exchange, "symbol", vp.Symbol, "price", Truncate(vp.Price, int(precision)), "volume", Truncate(vp.Volume, int(precision)), "medianPrice", Truncate(medianPrice, int(precision)), "mad", Truncate(mad, int(precision)), "lower", Truncate(lower, int(precision)), "upper", Truncate(upper, int(precision)))
-		}
-	}
-
-	if len(filteredPrices) == 0 {
-		return "", "", 0, nil, appErrors.ErrAllPricesOutlierFiltered
-	}
-
-	// Calculate the total volume of the filtered prices
-	totalVolume := big.NewRat(0, 1)
-	for _, vp := range filteredPrices {
-		totalVolume.Add(totalVolume, vp.Volume)
-	}
-
-	if totalVolume.Sign() <= 0 {
-		return "", "", 0, nil, appErrors.ErrZeroVolume
-	}
-
-	// Step 6: Apply per-exchange weight cap and compute weighted sum
-	weightedSum := big.NewRat(0, 1)
-	exchangeVolumes := make(map[string]*big.Rat)
-	for _, vp := range filteredPrices {
-		if _, exists := exchangeVolumes[vp.Exchange]; !exists {
-			exchangeVolumes[vp.Exchange] = big.NewRat(0, 1)
-		}
-		exchangeVolumes[vp.Exchange].Add(exchangeVolumes[vp.Exchange], vp.Volume)
-	}
-
-	maxWeight := new(big.Rat).Mul(tokenMaxExchangeWeightFraction, totalVolume)
-
-	cappedTotalVolume := big.NewRat(0, 1)
-
-	filteredExchangesPrices := []ExchangePrice{}
-
-	for _, vp := range filteredPrices {
-		cappedVolume := vp.Volume
-		if exchangeVolumes[vp.Exchange].Cmp(maxWeight) > 0 {
-			logger.Debug("Scaling volume", "exchange", vp.Exchange, "volume", Truncate(vp.Volume, int(precision)), "maxWeight", Truncate(maxWeight, int(precision)))
-			// Scale down proportionally if total volume exceeds cap
-			scale := new(big.Rat).Quo(maxWeight, exchangeVolumes[vp.Exchange])
-			cappedVolume = new(big.Rat).Mul(vp.Volume, scale)
-		}
-		weightedSum.Add(weightedSum, new(big.Rat).Mul(vp.Price, cappedVolume))
-		cappedTotalVolume.Add(cappedTotalVolume, cappedVolume)
-		filteredExchangesPrices = append(filteredExchangesPrices, ExchangePrice{
-			Exchange: vp.Exchange,
-			Symbol:   vp.Symbol,
-			Price:    Truncate(vp.Price, int(precision)),
-			Volume:   Truncate(cappedVolume, int(precision)),
-			Token:    vp.Token,
-		})
-	}
-
-	if cappedTotalVolume.Sign() <= 0 {
-		return "", "", 0, nil, appErrors.ErrZeroCappedVolume
-	}
-
-	minPrice := filteredPrices[0].Price
-	maxPrice := filteredPrices[0].Price
-	for _, vp := range filteredPrices[1:] {
-		if vp.Price.Cmp(minPrice) < 0 {
-			minPrice = vp.Price
-		}
-		if vp.Price.Cmp(maxPrice) > 0 {
-			maxPrice = vp.Price
-		}
-	}
-
-	ratio := new(big.Rat).Quo(maxPrice, minPrice)
-	dispersionThreshold := new(big.Rat).Add(big.NewRat(1, 1), tokenMaxSpreadFraction)
-	logger.Debug("Max Price", "maxPrice", Truncate(maxPrice, int(precision)), "minPrice", Truncate(minPrice, int(precision)), "ratio", Truncate(ratio, int(precision)), "dispersionThreshold", Truncate(dispersionThreshold, int(precision)))
-
-	if ratio.Cmp(dispersionThreshold) > 0 {
-		return "", "", 0, nil, appErrors.ErrCrossVenueDispersionTooHigh
+	result, err := aggregateVWAP(validPrices, config, precision)
+	if err != nil {
+		return "", "", 0, nil, err
 	}
 
-	volumeWeightedAvg := new(big.Rat).Quo(weightedSum, cappedTotalVolume)
-
-	volumeWeightedAvgStr := Truncate(volumeWeightedAvg, int(precision))
-	totalVolumeStr := Truncate(cappedTotalVolume, int(precision))
-
-	return volumeWeightedAvgStr, totalVolumeStr, len(exchangeVolumes), filteredExchangesPrices, nil
+	return result.Price, result.TotalVolume, result.ExchangeCount, result.PricesUsed, nil
 }
 
 // GetPriceFeed fetches and calculates the volume-weighted average price for a given token
+//
+// Prices of symbols quoted in another token, like BTCUSDT, are converted into USD with the volume-weighted
+// average price of the quote token, computed concurrently with the exchange requests.
+//
+// When the price cache is enabled, results are reused for the cache TTL and concurrent requests for the same
+// token and precision share a single exchange round-trip.
 func (c *PriceFeedClient) GetPriceFeed(ctx context.Context, tokenName string, timestamp int64, precision uint) (*PriceFeedResult, *appErrors.AppError) {
+	if c.priceCache == nil {
+		return c.getPriceFeed(ctx, tokenName, timestamp, precision, true)
+	}
+	return c.priceCache.get(ctx, tokenName, timestamp, precision, func(ctx context.Context) (*PriceFeedResult, *appErrors.AppError) {
+		return c.getPriceFeed(ctx, tokenName, timestamp, precision, true)
+	})
+}
+
+// getPriceFeed calculates the volume-weighted average price of a token. Quote currencies other than USD are only
+// converted when allowConversion is set, so the price feed of a quote token never converts again.
+func (c *PriceFeedClient) getPriceFeed(ctx context.Context, tokenName string, timestamp int64, precision uint, allowConversion bool) (*PriceFeedResult, *appErrors.AppError) {
 	reqLogger := logger.FromContext(ctx)
 
 	exchanges, exists := c.tokenExchanges[strings.ToUpper(tokenName)]
@@ -498,14 +450,26 @@ func (c *PriceFeedClient) GetPriceFeed(ctx context.Context, tokenName string, ti
 		return nil, appErrors.ErrNoTradingPairsConfigured
 	}
 
-	// Create a buffered channel to collect results from goroutines
-	// Buffer size matches the number of trading pairs to prevent blocking
-	results := make(chan fetchResult, totalTradingPairs)
+	token := strings.ToUpper(tokenName)
 
-	// Launch concurrent goroutines to fetch prices from each exchange
-	// Each goroutine fetches data independently and sends results through the channel
+	aggregationConfig, err := configs.GetTokenAggregationConfig(token)
+	if err != nil {
+		return nil, err
+	}
+
+	// Collect the symbols of every enabled exchange and the quote currencies they need converted.
+	type exchangeSymbol struct {
+		exchange string
+		symbol   configs.SymbolConfig
+	}
+	var exchangeSymbols []exchangeSymbol
+	var quotes []string
 	for _, exchange := range exchanges {
-		token := strings.ToUpper(tokenName)
+		if !aggregationConfig.ExchangeEnabled(exchange) {
+			reqLogger.Debug("Exchange disabled for token", "token", token, "exchange", exchange)
+			continue
+		}
+
 		// Step 1: Get exchange configuration.
 		config, exists := c.exchangeConfigs[exchange]
 		if !exists {
@@ -526,16 +490,55 @@ func (c *PriceFeedClient) GetPriceFeed(ctx context.Context, tokenName string, ti
 		}
 
 		for _, symbol := range symbolList {
-			go func(ex string, tk string, sym string) {
-				price, err := c.FetchPriceFromExchange(ctx, ex, tk, sym, timestamp)
-				results <- fetchResult{price: price, err: err, exchange: ex}
-			}(exchange, token, symbol)
+			exchangeSymbols = append(exchangeSymbols, exchangeSymbol{exchange: exchange, symbol: symbol})
+			if allowConversion && symbol.Quote != configs.USDQuote && !slices.Contains(quotes, symbol.Quote) {
+				quotes = append(quotes, symbol.Quote)
+			}
 		}
 	}
 
+	// Compute the USD price of every quote currency concurrently with the exchange requests.
+	quoteRates := make([]*big.Rat, len(quotes))
+	quoteFreshness := make([][]priceFreshness, len(quotes))
+	var quoteWg sync.WaitGroup
+	for i, quote := range quotes {
+		quoteWg.Add(1)
+		go func() {
+			defer quoteWg.Done()
+			quoteResult, err := c.getPriceFeed(ctx, quote, timestamp, encoding.ENCODING_OPTION_FLOAT_MAX_PRECISION, false)
+			if err != nil {
+				reqLogger.Error("Failed to get quote conversion rate", "token", token, "quote", quote, "error", err)
+				return
+			}
+			rate, ok := new(big.Rat).SetString(quoteResult.VolumeWeightedAvg)
+			if !ok || rate.Sign() <= 0 {
+				reqLogger.Error("Invalid quote conversion rate", "token", token, "quote", quote, "rate", quoteResult.VolumeWeightedAvg)
+				return
+			}
+			quoteRates[i] = rate
+			quoteFreshness[i] = quoteResult.freshness
+		}()
+	}
+
+	// Create a buffered channel to collect results from goroutines
+	// Buffer size matches the number of trading pairs to prevent blocking
+	results := make(chan fetchResult, len(exchangeSymbols))
+
+	// Launch concurrent goroutines to fetch prices from each exchange
+	// Each goroutine fetches data independently and sends results through the channel
+	for _, es := range exchangeSymbols {
+		go func(ex string, tk string, sym configs.SymbolConfig) {
+			price, err := c.FetchPriceFromExchange(ctx, ex, tk, sym.Symbol, timestamp)
+			if price != nil {
+				price.Quote = sym.Quote
+			}
+			results <- fetchResult{price: price, err: err, exchange: ex}
+		}(es.exchange, token, es.symbol)
+	}
+
 	// Collect results from all goroutines
 	// Process results in the order they complete, not necessarily the order of exchanges
-	for i := 0; i < totalTradingPairs; i++ {
+	for i := 0; i < len(exchangeSymbols); i++ {
 		result := <-results
 		if result.err != nil {
 			metrics.RecordExchangeApiError(result.exchange, strconv.Itoa(int(result.err.Code)))
@@ -552,34 +555,129 @@ func (c *PriceFeedClient) GetPriceFeed(ctx context.Context, tokenName string, ti
 
 	reqLogger.Debug("Total trading pairs", "totalTradingPairs", totalTradingPairs)
 
-	// Calculate volume-weighted average
-	volumeWeightAvgStr, totalVolumeStr, exchangeCount, filteredExchangesPrices, err := CalculateVolumeWeightedAverage(exchangePrices, precision, tokenName)
+	quoteWg.Wait()
+
+	conversionRates := make(map[string]*big.Rat)
+	conversionFreshness := make(map[string][]priceFreshness)
+	for i, quote := range quotes {
+		if quoteRates[i] != nil {
+			conversionRates[quote] = quoteRates[i]
+			conversionFreshness[quote] = quoteFreshness[i]
+		}
+	}
+
+	// Convert the prices and volumes of every symbol into USD before aggregating them.
+	convertExchangePricesToUSD(ctx, exchangePrices, conversionRates)
+
+	// Aggregate the prices with the method configured for the token
+	aggregation, err := AggregatePrices(exchangePrices, precision, tokenName)
 	if err != nil {
-		reqLogger.Error("Error calculating volume-weighted average", "error", err)
+		reqLogger.Error("Error aggregating prices", "error", err)
 		return nil, err
 	}
+	exchangeCount := aggregation.ExchangeCount
 
 	metrics.RecordPriceFeedExchangeCount(tokenName, exchangeCount)
+	c.recordOutliers(aggregation)
 
-	// Ensure at least the minimum number of exchanges responded successfully
-	if exchangeCount < configs.GetMinExchangesRequired() {
+	// Ensure at least the minimum number of exchanges of the token responded successfully
+	minExchangesRequired := aggregationConfig.MinExchanges(configs.GetMinExchangesRequired())
+	if exchangeCount < minExchangesRequired {
 		metrics.RecordError("insufficient_exchange_data", "price_feed")
-		reqLogger.Error("Insufficient exchange data", "exchangeCount", exchangeCount, "minExchangesRequired", configs.GetMinExchangesRequired())
+		reqLogger.Error("Insufficient exchange data", "exchangeCount", exchangeCount, "minExchangesRequired", minExchangesRequired)
 		return nil, appErrors.ErrInsufficientExchangeData
 	}
 
+	var reportedConversionRates map[string]string
+	var freshness []priceFreshness
+	for _, price := range aggregation.PricesUsed {
+		if price.freshnessTime != 0 {
+			freshness = append(freshness, priceFreshness{token: price.Token, time: price.freshnessTime})
+		}
+		if rate, exists := conversionRates[price.Quote]; exists {
+			if reportedConversionRates == nil {
+				reportedConversionRates = make(map[string]string)
+			}
+			if _, reported := reportedConversionRates[price.Quote]; !reported {
+				freshness = append(freshness, conversionFreshness[price.Quote]...)
+			}
+			reportedConversionRates[price.Quote] = rate.FloatString(encoding.ENCODING_OPTION_FLOAT_MAX_PRECISION)
+		}
+	}
+
 	return &PriceFeedResult{
 		Token:              strings.ToUpper(tokenName),
-		VolumeWeightedAvg:  volumeWeightAvgStr,
-		TotalVolume:        totalVolumeStr,
+		VolumeWeightedAvg:  aggregation.Price,
+		TotalVolume:        aggregation.TotalVolume,
 		ExchangeCount:      exchangeCount,
 		Timestamp:          time.Now().Unix(),
 		ExchangePricesRaw:  exchangePrices,
-		ExchangePricesUsed: filteredExchangesPrices,
+		ExchangePricesUsed: aggregation.PricesUsed,
+		ExchangePricesExcluded: aggregation.Excluded,
+		Aggregation:        &aggregation.Stats,
+		ConversionRates:    reportedConversionRates,
 		Success:            true,
+
+		freshness: freshness,
 	}, nil
 }
 
+// recordOutliers records in the exchange health whether the price of every aggregated exchange symbol was
+// filtered as an outlier.
+func (c *PriceFeedClient) recordOutliers(aggregation *AggregationResult) {
+	if c.exchangeHealth == nil {
+		return
+	}
+
+	// Exchange prices carry the exchange name, the health is tracked by exchange key.
+	exchangeKeys := exchangeKeysByName(c.exchangeConfigs)
+
+	for _, price := range aggregation.PricesUsed {
+		c.exchangeHealth.recordAggregation(exchangeKeys[price.Exchange], price.Symbol, false)
+	}
+	for _, price := range aggregation.Outliers {
+		c.exchangeHealth.recordAggregation(exchangeKeys[price.Exchange], price.Symbol, true)
+	}
+}
+
+// exchangeKeysByName maps the name of every exchange to its key.
+func exchangeKeysByName(exchangeConfigs configs.ExchangesConfig) map[string]string {
+	exchangeKeys := make(map[string]string, len(exchangeConfigs))
+	for exchange, config := range exchangeConfigs {
+		exchangeKeys[config.Name] = exchange
+	}
+	return exchangeKeys
+}
+
+// convertExchangePricesToUSD converts the price of every exchange price quoted in another currency than USD,
+// keeping the original price in PriceInQuote, and sets its USD volume from the quote volume. Prices without
+// a conversion rate for their quote are cleared, so they are skipped by CalculateVolumeWeightedAverage.
+func convertExchangePricesToUSD(ctx context.Context, prices []ExchangePrice, conversionRates map[string]*big.Rat) {
+	reqLogger := logger.FromContext(ctx)
+
+	for i := range prices {
+		price := &prices[i]
+		price.PriceInQuote = price.Price
+		if price.Quote == "" || price.Quote == configs.USDQuote {
+			price.Volume = price.QuoteVolume
+			continue
+		}
+
+		rate, exists := conversionRates[price.Quote]
+		priceRat, ok := new(big.Rat).SetString(price.Price)
+		quoteVolumeRat, volumeOk := new(big.Rat).SetString(price.QuoteVolume)
+		if !exists || !ok || !volumeOk {
+			reqLogger.Error("Price not converted to USD", "exchange", price.Exchange, "symbol", price.Symbol, "quote", price.Quote, "price", price.Price)
+			price.Price = ""
+			price.Volume = ""
+			continue
+		}
+
+		price.Price = Truncate(priceRat.Mul(priceRat, rate), convertedPricePrecision)
+		price.Volume = Truncate(quoteVolumeRat.Mul(quoteVolumeRat, rate), normalizedVolumePrecision)
+	}
+}
+
 // ExtractPriceFeedData handles price feed requests and always returns the volume-weighted average price (VWAP)
 // This ensures consistent and reliable price data for oracle attestations
 func (c *PriceFeedClient) ExtractPriceFeedData(ctx context.Context, attestationRequest attestation.AttestationRequest, token string, timestamp int64) (ExtractDataResult, *appErrors.AppError) {
@@ -603,6 +701,12 @@ func (c *PriceFeedClient) ExtractPriceFeedData(ctx context.Context, attestationR
 		return ExtractDataResult{}, appErr
 	}
 
+	// Check the price against the guard of the token
+	result.Guard, appErr = guardPrice(ctx, token, result.VolumeWeightedAvg, timestamp)
+	if appErr != nil {
+		return ExtractDataResult{}, appErr
+	}
+
 	jsonBytes, err := json.Marshal(result)
 	if err != nil {
 		reqLogger.Error("Error marshalling price feed data", "error", err)
@@ -617,9 +721,30 @@ func (c *PriceFeedClient) ExtractPriceFeedData(ctx context.Context, attestationR
 		ResponseBody:    string(jsonBytes),
 		AttestationData: attestationData,
 		StatusCode:      http.StatusOK,
+		AttestedPrice:   &AttestedPrice{Token: token, Price: attestationData, Timestamp: timestamp},
 	}, nil
 }
 
+// PreviewPriceFeed returns the price feed result of a token with its aggregation breakdown, without attesting it.
+// The guard of the token is reported but never fails the preview, and the price is not recorded as attested.
+func (c *PriceFeedClient) PreviewPriceFeed(ctx context.Context, token string, precision uint) (*PriceFeedResult, *appErrors.AppError) {
+	reqLogger := logger.FromContext(ctx)
+
+	timestamp := time.Now().Unix()
+	result, appErr := c.GetPriceFeed(ctx, token, timestamp, precision)
+	if appErr != nil {
+		reqLogger.Error("Error getting price feed preview for ", "token", token, "error", appErr)
+		return nil, appErr
+	}
+
+	result.Guard, appErr = priceGuardStatus(token, result.VolumeWeightedAvg, timestamp)
+	if appErr != nil {
+		reqLogger.Error("Error checking the price guard of the price feed preview", "token", token, "error", appErr)
+		return nil, appErr
+	}
+	return result, nil
+}
+
 // computeMedian computes the median of a slice of *big.Rat
 func computeMedian(values []*big.Rat) *big.Rat {
 	sort.Slice(values, func(i, j int) bool {
@@ -4,18 +4,20 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"math/big"
 	"net/http"
 	"net/http/httptest"
 	"strings"
 	"testing"
 	"time"
 
+	"github.com/hashicorp/go-retryablehttp"
 	"github.com/stretchr/testify/assert"
 	encoding "github.com/venture23-aleo/aleo-oracle-encoding"
 	configs "github.com/venture23-aleo/aleo-oracle-notarization-backend/internal/config"
-	appErrors "github.com/venture23-aleo/aleo-oracle-notarization-backend/internal/errors"
-	"github.com/venture23-aleo/aleo-oracle-notarization-backend/internal/logger"
 	"github.com/venture23-aleo/aleo-oracle-notarization-backend/internal/services/attestation"
+	appErrors "github.com/venture23-aleo/aleo-oracle-notarization-backend/pkg/errors"
+	"github.com/venture23-aleo/aleo-oracle-notarization-backend/pkg/logger"
 )
 
 var (
@@ -246,6 +248,9 @@ func TestMain(m *testing.M) {
 
 			logger.Error("Response: ", "response", response)
 			json.NewEncoder(w).Encode(response)
+
+		case strings.HasPrefix(r.URL.Path, "/0/public/Time"):
+			fmt.Fprintf(w, `{"error": [], "result": {"unixtime": %d}}`, time.Now().Unix())
 		
 		case strings.HasPrefix(r.URL.Path, "/v1/pubticker/"):
 			response := GeminiResponse{}
@@ -697,10 +702,13 @@ func TestGetPriceFeed_ErrorScenarios(t *testing.T) {
 	for _, testCase := range testCases {
 		t.Run(testCase.name, func(t *testing.T) {
 			exchangeConfig := make(map[string]configs.ExchangeConfig)
-			symbols := make(map[string][]string)
+			symbols := make(map[string][]configs.SymbolConfig)
 
 			for _, exchangeToken := range testCase.exchangeTokens {
-				symbols[exchangeToken] = testCase.testSymbols
+				symbols[exchangeToken] = []configs.SymbolConfig{}
+				for _, testSymbol := range testCase.testSymbols {
+					symbols[exchangeToken] = append(symbols[exchangeToken], configs.SymbolConfig{Symbol: testSymbol, Quote: configs.USDQuote})
+				}
 			}
 
 			for _, exchange := range testCase.exchanges {
@@ -743,11 +751,11 @@ func TestCalculateVolumeWeightedAveragePrice(t *testing.T) {
 		{
 			name: "Normal case",
 			prices: []ExchangePrice{
-				{Exchange: "Binance", Price: "50000.0", Volume: "1000.0", Symbol: "BTC"},
-				{Exchange: "Bybit", Price: "50003.0", Volume: "8000.0", Symbol: "BTC"},
+				{Exchange: "Binance", Price: "50000.0", Volume: "100000000.0", Symbol: "BTC"},
+				{Exchange: "Bybit", Price: "50003.0", Volume: "800000000.0", Symbol: "BTC"},
 			},
-			expectedAvg:    "50002.4545454545", // (50000*1000 + 50003*8000) / (1000 + 8000)
-			expectedVolume: "5500.0000000000",
+			expectedAvg:    "50002.4545454545", // (50000*1e8 + 50003*8e8) / (1e8 + 8e8)
+			expectedVolume: "550000000.0000000000",
 			expectedCount:  2,
 		},
 		{
@@ -761,10 +769,10 @@ func TestCalculateVolumeWeightedAveragePrice(t *testing.T) {
 			name: "Partial zero volume",
 			prices: []ExchangePrice{
 				{Exchange: "Binance", Price: "50000.0", Volume: "0.0", Symbol: "BTC"},
-				{Exchange: "Bybit", Price: "50100.0", Volume: "8000", Symbol: "BTC"},
+				{Exchange: "Bybit", Price: "50100.0", Volume: "800000000", Symbol: "BTC"},
 			},
 			expectedAvg:    "50100.0000000000", // Only Bybit contributes
-			expectedVolume: "4000.0000000000",
+			expectedVolume: "400000000.0000000000",
 			expectedCount:  1,
 		},
 		{
@@ -780,13 +788,13 @@ func TestCalculateVolumeWeightedAveragePrice(t *testing.T) {
 		{
 			name: "All exchanges",
 			prices: []ExchangePrice{
-				{Exchange: "Binance", Price: "50004.0", Volume: "10000.5", Symbol: "BTC"},
-				{Exchange: "Bybit", Price: "50005.0", Volume: "80000.25", Symbol: "BTC"},
-				{Exchange: "Coinbase", Price: "50007.0", Volume: "12000.75", Symbol: "BTC"},
-				{Exchange: "Crypto.com", Price: "50008.0", Volume: "9000.3", Symbol: "BTC"},
+				{Exchange: "Binance", Price: "50004.0", Volume: "1000050000", Symbol: "BTC"},
+				{Exchange: "Bybit", Price: "50005.0", Volume: "8000025000", Symbol: "BTC"},
+				{Exchange: "Coinbase", Price: "50007.0", Volume: "1200075000", Symbol: "BTC"},
+				{Exchange: "Crypto.com", Price: "50008.0", Volume: "900030000", Symbol: "BTC"},
 			},
 			expectedAvg:    "50005.4739969792",
-			expectedVolume: "86502.4500000000",
+			expectedVolume: "8650245000.0000000000",
 			expectedCount:  4,
 		},
 	}
@@ -953,3 +961,136 @@ func TestFetchPriceFromExchange(t *testing.T) {
 	}
 
 }
+
+func TestConvertExchangePricesToUSD(t *testing.T) {
+	prices := []ExchangePrice{
+		{Exchange: "Coinbase", Symbol: "BTC-USD", Quote: "USD", Price: "50000.00", BaseVolume: "10", QuoteVolume: "500000"},
+		{Exchange: "Binance", Symbol: "BTCUSDT", Quote: "USDT", Price: "50000.00", BaseVolume: "10", QuoteVolume: "500000"},
+		{Exchange: "Binance", Symbol: "BTCUSDC", Quote: "USDC", Price: "50000.00", BaseVolume: "10", QuoteVolume: "500000"},
+	}
+
+	convertExchangePricesToUSD(context.Background(), prices, map[string]*big.Rat{"USDT": big.NewRat(9990, 10000)})
+
+	assert.Equal(t, "50000.00", prices[0].Price)
+	assert.Equal(t, "50000.00", prices[0].PriceInQuote)
+	assert.Equal(t, "500000", prices[0].Volume)
+	assert.Equal(t, "49950.000000000000000000", prices[1].Price)
+	assert.Equal(t, "50000.00", prices[1].PriceInQuote)
+	assert.Equal(t, "499500.000000000000000000", prices[1].Volume)
+	// Without a USDC rate the price is cleared and skipped by the aggregation.
+	assert.Equal(t, "", prices[2].Price)
+	assert.Equal(t, "", prices[2].Volume)
+	assert.Equal(t, "50000.00", prices[2].PriceInQuote)
+}
+
+func TestGetPriceFeed_ConvertsQuoteCurrencies(t *testing.T) {
+	tickers := map[string]string{
+		"/a/BTC-USD":  `{"price": "50000", "volume": "10000"}`,
+		"/b/BTCUSDT":  `{"price": "50050.05", "volume": "10000"}`,
+		"/a/USDT-USD": `{"price": "0.999", "volume": "1000000"}`,
+		"/b/USDTUSD":  `{"price": "0.999", "volume": "1000000"}`,
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ticker, exists := tickers[r.URL.Path]
+		if !exists {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Write([]byte(ticker))
+	}))
+	defer server.Close()
+
+	// Plain HTTP clients, as the test server has no pinned root CA.
+	for _, exchange := range []string{"conversion-a", "conversion-b"} {
+		client := retryablehttp.NewClient()
+		client.RetryMax = 0
+		clientCache.Store(exchange, client)
+		t.Cleanup(func() { clientCache.Delete(exchange) })
+	}
+
+	responseFormat := &configs.ExchangeResponseFormat{PricePath: "price", VolumePath: "volume"}
+	freshness := &configs.ExchangeFreshness{Source: configs.FreshnessSourceDateHeader}
+	exchangeConfigs := configs.ExchangesConfig{
+		"conversion-a": {
+			Name:             "Conversion A",
+			BaseURL:          server.URL,
+			EndpointTemplate: "/a/{symbol}",
+			ResponseFormat:   responseFormat,
+			Freshness:        freshness,
+			Symbols: map[string][]configs.SymbolConfig{
+				"BTC":  {{Symbol: "BTC-USD", Quote: configs.USDQuote}},
+				"USDT": {{Symbol: "USDT-USD", Quote: configs.USDQuote}},
+			},
+		},
+		"conversion-b": {
+			Name:             "Conversion B",
+			BaseURL:          server.URL,
+			EndpointTemplate: "/b/{symbol}",
+			ResponseFormat:   responseFormat,
+			Freshness:        freshness,
+			Symbols: map[string][]configs.SymbolConfig{
+				"BTC":  {{Symbol: "BTCUSDT", Quote: "USDT"}},
+				"USDT": {{Symbol: "USDTUSD", Quote: configs.USDQuote}},
+			},
+		},
+	}
+	tokenTradingPairs := configs.TokenTradingPairs{
+		"BTC":  {"BTC-USD", "BTCUSDT"},
+		"USDT": {"USDT-USD", "USDTUSD"},
+	}
+
+	t.Run("quote converted with the quote token price", func(t *testing.T) {
+		client := &PriceFeedClient{
+			exchangeConfigs:   exchangeConfigs,
+			tokenExchanges:    configs.TokenExchanges{"BTC": {"conversion-a", "conversion-b"}, "USDT": {"conversion-a", "conversion-b"}},
+			tokenTradingPairs: tokenTradingPairs,
+		}
+
+		result, err := client.GetPriceFeed(context.Background(), "BTC", time.Now().Unix(), 6)
+		assert.Nil(t, err)
+		if !assert.NotNil(t, result) {
+			return
+		}
+		assert.Equal(t, "49999.999975", result.VolumeWeightedAvg)
+		assert.Equal(t, 2, result.ExchangeCount)
+		assert.Equal(t, map[string]string{"USDT": "0.999000000000"}, result.ConversionRates)
+
+		for _, price := range result.ExchangePricesUsed {
+			if price.Symbol == "BTCUSDT" {
+				assert.Equal(t, "USDT", price.Quote)
+				assert.Equal(t, "49999.999950", price.Price)
+				assert.Equal(t, "50050.05", price.PriceInQuote)
+				assert.Equal(t, "10000", price.BaseVolume)
+				assert.Equal(t, "500500500.000000000000000000", price.QuoteVolume)
+				assert.Equal(t, configs.VolumeDenominationBase, price.VolumeUnit)
+				assert.Equal(t, "499999999.500000", price.Volume)
+			}
+		}
+	})
+
+	t.Run("quote without price feed is dropped", func(t *testing.T) {
+		client := &PriceFeedClient{
+			exchangeConfigs:   exchangeConfigs,
+			tokenExchanges:    configs.TokenExchanges{"BTC": {"conversion-a", "conversion-b"}},
+			tokenTradingPairs: tokenTradingPairs,
+		}
+
+		result, err := client.GetPriceFeed(context.Background(), "BTC", time.Now().Unix(), 6)
+		assert.Equal(t, appErrors.ErrInsufficientExchangeData, err)
+		assert.Nil(t, result)
+	})
+}
+
+func TestNormalizeVolumes(t *testing.T) {
+	baseVolume, quoteVolume := normalizeVolumes("50000.5", "2", configs.VolumeDenominationBase)
+	assert.Equal(t, "2", baseVolume)
+	assert.Equal(t, "100001.000000000000000000", quoteVolume)
+
+	baseVolume, quoteVolume = normalizeVolumes("0.25", "1000", configs.VolumeDenominationQuote)
+	assert.Equal(t, "4000.000000000000000000", baseVolume)
+	assert.Equal(t, "1000", quoteVolume)
+
+	baseVolume, quoteVolume = normalizeVolumes("price", "1000", configs.VolumeDenominationBase)
+	assert.Equal(t, "", baseVolume)
+	assert.Equal(t, "", quoteVolume)
+}
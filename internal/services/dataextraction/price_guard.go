@@ -0,0 +1,339 @@
+package data_extraction
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	configs "github.com/venture23-aleo/aleo-oracle-notarization-backend/internal/config"
+	"github.com/venture23-aleo/aleo-oracle-notarization-backend/internal/metrics"
+	appErrors "github.com/venture23-aleo/aleo-oracle-notarization-backend/pkg/errors"
+	"github.com/venture23-aleo/aleo-oracle-notarization-backend/pkg/logger"
+	"github.com/venture23-aleo/aleo-oracle-notarization-backend/pkg/sgx"
+)
+
+// Bounds of a price guard crossed by a price
+const (
+	GuardReasonMaxChange = "max_change" // The change from the last attested price exceeds the allowed change.
+	GuardReasonPegBand   = "peg_band"   // The price is outside the peg band.
+)
+
+// guardChangePrecision is the number of decimals of the change percentages reported by the price guard.
+const guardChangePrecision = 4
+
+// sealedMountDir is the parent directory of the Gramine encrypted mounts declared in the manifest.
+const sealedMountDir = "/sealed"
+
+var (
+	sharedPriceGuard     *priceGuard
+	sharedPriceGuardOnce sync.Once
+)
+
+// getSharedPriceGuard returns the store of the last attested prices, loaded from the state file on first use.
+func getSharedPriceGuard() *priceGuard {
+	sharedPriceGuardOnce.Do(func() {
+		sharedPriceGuard = newPriceGuard(priceGuardStateFile())
+	})
+	return sharedPriceGuard
+}
+
+// AttestedPrice is a price feed value of a token, recorded as the last attested price of the token once the
+// attestation report is generated.
+type AttestedPrice struct {
+	Token     string `json:"token"`     // Token.
+	Price     string `json:"price"`     // Attested price.
+	Timestamp int64  `json:"timestamp"` // Attestation timestamp, Unix seconds.
+}
+
+// PriceGuardStatus is the outcome of the price guard of a token.
+type PriceGuardStatus struct {
+	Policy            string   `json:"policy"`                      // Guard policy: reject or flag.
+	Triggered         bool     `json:"triggered"`                   // Whether the price crossed a bound of the guard.
+	Reasons           []string `json:"reasons,omitempty"`           // Crossed bounds: max_change or peg_band.
+	LastAttestedPrice string   `json:"lastAttestedPrice,omitempty"` // Last attested price of the token.
+	LastAttestedAt    int64    `json:"lastAttestedAt,omitempty"`    // Attestation timestamp of the last attested price.
+	ChangePercent     string   `json:"changePercent,omitempty"`     // Change from the last attested price.
+	MaxChangePercent  string   `json:"maxChangePercent,omitempty"`  // Change allowed since the last attested price.
+}
+
+// priceGuard keeps the last attested price of every token, persisted to the state file when set.
+type priceGuard struct {
+	stateFile string
+	mu        sync.Mutex
+	prices    map[string]AttestedPrice
+}
+
+// newPriceGuard creates the store of the last attested prices, loading them from the state file. A state file
+// that cannot be read is logged and the store starts empty, so the guards only check the peg bands until the
+// next attestation of each token.
+func newPriceGuard(stateFile string) *priceGuard {
+	guard := &priceGuard{stateFile: stateFile, prices: make(map[string]AttestedPrice)}
+	if stateFile == "" {
+		return guard
+	}
+
+	prices, err := loadAttestedPrices(stateFile)
+	if err != nil {
+		logger.Error("Failed to load the last attested prices", "path", stateFile, "error", err)
+		return guard
+	}
+	if prices != nil {
+		guard.prices = prices
+		logger.Info("Loaded the last attested prices", "path", stateFile, "tokens", len(prices))
+	}
+	return guard
+}
+
+// loadAttestedPrices reads the last attested prices from the state file at path, nil when the file does not exist.
+func loadAttestedPrices(path string) (map[string]AttestedPrice, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading last attested prices %s: %w", path, err)
+	}
+
+	var prices map[string]AttestedPrice
+	if err := json.Unmarshal(data, &prices); err != nil {
+		return nil, fmt.Errorf("parsing last attested prices %s: %w", path, err)
+	}
+	return prices, nil
+}
+
+// priceGuardStateFile returns the state file the last attested prices are persisted to, empty to keep them in memory.
+func priceGuardStateFile() string {
+	return ResolveStateFile(configs.GetPriceGuardConfig().StateFile)
+}
+
+// ResolveStateFile returns the state file to use with the configured quote provider, empty when a state file inside
+// the Gramine encrypted mounts is used outside an enclave.
+func ResolveStateFile(stateFile string) string {
+	return resolveStateFile(stateFile, sgx.GetQuoteProviderName())
+}
+
+// resolveStateFile returns the state file to use with the active quote provider. The Gramine encrypted mounts only
+// exist inside the enclave, so a state file inside them is only used with the Gramine quote provider. Elsewhere,
+// for example with the simulated quote provider, the state is kept in memory.
+func resolveStateFile(stateFile string, quoteProvider string) string {
+	if quoteProvider != sgx.QuoteProviderGramine && strings.HasPrefix(filepath.Clean(stateFile), sealedMountDir+"/") {
+		return ""
+	}
+	return stateFile
+}
+
+// CheckPriceGuardStateFile checks at startup that the state file of the price guard can be written, so that the
+// last attested prices are not silently lost on restart. It does nothing when the prices are kept in memory.
+func CheckPriceGuardStateFile() error {
+	stateFile := priceGuardStateFile()
+	if stateFile == "" {
+		if configured := configs.GetPriceGuardConfig().StateFile; configured != "" {
+			logger.Warn("Price guard state file is inside the Gramine encrypted mounts and not used outside an enclave, the last attested prices are kept in memory", "path", configured, "quoteProvider", sgx.GetQuoteProviderName())
+		}
+		return nil
+	}
+	return CheckStateFileWritable(stateFile)
+}
+
+// CheckStateFileWritable checks that a file can be created next to the state file at path and that the state file,
+// if it exists, can be written.
+func CheckStateFileWritable(path string) error {
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return fmt.Errorf("creating state file directory: %w", err)
+	}
+
+	probe, err := os.CreateTemp(dir, "."+filepath.Base(path)+"-*")
+	if err != nil {
+		return fmt.Errorf("state file directory %s is not writable: %w", dir, err)
+	}
+	probe.Close()
+	os.Remove(probe.Name())
+
+	file, err := os.OpenFile(path, os.O_WRONLY, 0)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("state file %s is not writable: %w", path, err)
+	}
+	if file != nil {
+		file.Close()
+	}
+	return nil
+}
+
+// writeAttestedPrices writes the last attested prices to the state file at path. The prices are written to a
+// temporary file in the same directory, synced and renamed over the state file, so a crash mid-write never leaves
+// a truncated state file that would turn off the max change guards on the next start.
+func writeAttestedPrices(path string, prices map[string]AttestedPrice) error {
+	data, err := json.Marshal(prices)
+	if err != nil {
+		return fmt.Errorf("encoding last attested prices: %w", err)
+	}
+
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return fmt.Errorf("creating last attested prices directory: %w", err)
+	}
+
+	file, err := os.CreateTemp(dir, ".last_attested_prices-*")
+	if err != nil {
+		return fmt.Errorf("creating temporary last attested prices: %w", err)
+	}
+	tempPath := file.Name()
+	defer os.Remove(tempPath)
+
+	if _, err := file.Write(data); err != nil {
+		file.Close()
+		return fmt.Errorf("writing last attested prices %s: %w", tempPath, err)
+	}
+	if err := file.Sync(); err != nil {
+		file.Close()
+		return fmt.Errorf("syncing last attested prices %s: %w", tempPath, err)
+	}
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("closing last attested prices %s: %w", tempPath, err)
+	}
+
+	if err := os.Rename(tempPath, path); err != nil {
+		return fmt.Errorf("replacing last attested prices %s: %w", path, err)
+	}
+
+	return nil
+}
+
+// last returns the last attested price of a token.
+func (g *priceGuard) last(token string) (AttestedPrice, bool) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	price, exists := g.prices[token]
+	return price, exists
+}
+
+// record stores the last attested price of a token and persists the prices. A price attested before the stored
+// one is ignored. The state file is checked for writes at startup, so a failure to persist is only logged and
+// counted.
+func (g *priceGuard) record(price AttestedPrice) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if last, exists := g.prices[price.Token]; exists && last.Timestamp > price.Timestamp {
+		return
+	}
+	g.prices[price.Token] = price
+
+	if g.stateFile == "" {
+		return
+	}
+	if err := writeAttestedPrices(g.stateFile, g.prices); err != nil {
+		metrics.RecordPriceGuardPersistFailure()
+		logger.Error("Failed to persist the last attested prices", "path", g.stateFile, "error", err)
+	}
+}
+
+// RecordAttestation records the price of a price feed result as the last attested price of its token. It is called
+// once the attestation report of the result is generated, and does nothing for other results.
+func (r ExtractDataResult) RecordAttestation() {
+	if r.AttestedPrice == nil {
+		return
+	}
+	getSharedPriceGuard().record(*r.AttestedPrice)
+}
+
+// guardPrice checks the price of a token against the guard of the token before it is attested. A price crossing a
+// bound fails under the reject policy, and is returned with the status flagged under the flag policy. The status
+// is nil when the token has no guard.
+func guardPrice(ctx context.Context, token string, price string, timestamp int64) (*PriceGuardStatus, *appErrors.AppError) {
+	status, appErr := priceGuardStatus(token, price, timestamp)
+	if appErr != nil {
+		logger.FromContext(ctx).Error("Invalid price for the price guard", "token", token, "price", price, "error", appErr)
+		return nil, appErr
+	}
+	if status == nil || !status.Triggered {
+		return status, nil
+	}
+
+	for _, reason := range status.Reasons {
+		metrics.RecordPriceGuardTriggered(token, reason, status.Policy)
+	}
+	logger.FromContext(ctx).Warn("Price guard triggered", "token", token, "price", price, "policy", status.Policy, "reasons", status.Reasons, "lastAttestedPrice", status.LastAttestedPrice, "changePercent", status.ChangePercent, "maxChangePercent", status.MaxChangePercent)
+
+	if status.Policy == configs.GuardPolicyFlag {
+		return status, nil
+	}
+	if status.Reasons[0] == GuardReasonPegBand {
+		return nil, appErrors.ErrPriceOutsidePegBand
+	}
+	return nil, appErrors.ErrPriceChangeLimitExceeded
+}
+
+// priceGuardStatus checks the price of a token against the guard of the token and its last attested price. The
+// status is nil when the token has no guard.
+func priceGuardStatus(token string, price string, timestamp int64) (*PriceGuardStatus, *appErrors.AppError) {
+	guardConfig := configs.GetTokenGuardConfig(token)
+	if guardConfig == nil {
+		return nil, nil
+	}
+
+	var last *AttestedPrice
+	if lastPrice, exists := getSharedPriceGuard().last(token); exists {
+		last = &lastPrice
+	}
+	return checkPriceGuard(*guardConfig, price, timestamp, last)
+}
+
+// checkPriceGuard checks a price against the peg band of the guard and, when the token has a last attested price,
+// against the change allowed since then. The allowed change is MaxChangePercent for every interval started since
+// the last attested price.
+func checkPriceGuard(guardConfig configs.TokenGuardConfig, price string, timestamp int64, last *AttestedPrice) (*PriceGuardStatus, *appErrors.AppError) {
+	priceRat, ok := new(big.Rat).SetString(price)
+	if !ok {
+		return nil, appErrors.ErrInvalidRationalNumber
+	}
+
+	status := &PriceGuardStatus{Policy: guardConfig.GuardPolicy()}
+
+	if guardConfig.HasPegBand() {
+		lowerBound := new(big.Rat).SetFloat64(guardConfig.PegLowerBound)
+		upperBound := new(big.Rat).SetFloat64(guardConfig.PegUpperBound)
+		if priceRat.Cmp(lowerBound) < 0 || priceRat.Cmp(upperBound) > 0 {
+			status.Reasons = append(status.Reasons, GuardReasonPegBand)
+		}
+	}
+
+	if last != nil {
+		status.LastAttestedPrice = last.Price
+		status.LastAttestedAt = last.Timestamp
+
+		lastRat, ok := new(big.Rat).SetString(last.Price)
+		if ok && lastRat.Sign() > 0 {
+			change := new(big.Rat).Sub(priceRat, lastRat)
+			change.Abs(change)
+			change.Mul(change, big.NewRat(100, 1))
+			change.Quo(change, lastRat)
+			status.ChangePercent = Truncate(change, guardChangePrecision)
+
+			if guardConfig.MaxChangePercent > 0 && guardConfig.MaxChangeInterval > 0 {
+				intervals := int64(1)
+				if elapsed := time.Duration(timestamp-last.Timestamp) * time.Second; elapsed > guardConfig.MaxChangeInterval {
+					intervals = int64((elapsed + guardConfig.MaxChangeInterval - 1) / guardConfig.MaxChangeInterval)
+				}
+				maxChange := new(big.Rat).SetFloat64(guardConfig.MaxChangePercent)
+				maxChange.Mul(maxChange, big.NewRat(intervals, 1))
+				status.MaxChangePercent = Truncate(maxChange, guardChangePrecision)
+
+				if change.Cmp(maxChange) > 0 {
+					status.Reasons = append(status.Reasons, GuardReasonMaxChange)
+				}
+			}
+		}
+	}
+
+	status.Triggered = len(status.Reasons) > 0
+	return status, nil
+}
@@ -0,0 +1,182 @@
+package data_extraction
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	configs "github.com/venture23-aleo/aleo-oracle-notarization-backend/internal/config"
+	appErrors "github.com/venture23-aleo/aleo-oracle-notarization-backend/pkg/errors"
+	"github.com/venture23-aleo/aleo-oracle-notarization-backend/pkg/sgx"
+)
+
+func TestCheckPriceGuard(t *testing.T) {
+	maxChange := configs.TokenGuardConfig{Policy: configs.GuardPolicyFlag, MaxChangePercent: 5, MaxChangeInterval: time.Hour}
+	pegBand := configs.TokenGuardConfig{PegLowerBound: 0.97, PegUpperBound: 1.03}
+	last := &AttestedPrice{Token: "BTC", Price: "100", Timestamp: 1700000000}
+
+	tests := []struct {
+		name           string
+		config         configs.TokenGuardConfig
+		price          string
+		timestamp      int64
+		last           *AttestedPrice
+		expectedStatus *PriceGuardStatus
+		expectedError  *appErrors.AppError
+	}{
+		{
+			name:           "no last attested price",
+			config:         maxChange,
+			price:          "150",
+			timestamp:      1700000000,
+			expectedStatus: &PriceGuardStatus{Policy: configs.GuardPolicyFlag},
+		},
+		{
+			name:      "change within the limit",
+			config:    maxChange,
+			price:     "95",
+			timestamp: 1700000060,
+			last:      last,
+			expectedStatus: &PriceGuardStatus{
+				Policy:            configs.GuardPolicyFlag,
+				LastAttestedPrice: "100",
+				LastAttestedAt:    1700000000,
+				ChangePercent:     "5.0000",
+				MaxChangePercent:  "5.0000",
+			},
+		},
+		{
+			name:      "change above the limit",
+			config:    maxChange,
+			price:     "106",
+			timestamp: 1700000060,
+			last:      last,
+			expectedStatus: &PriceGuardStatus{
+				Policy:            configs.GuardPolicyFlag,
+				Triggered:         true,
+				Reasons:           []string{GuardReasonMaxChange},
+				LastAttestedPrice: "100",
+				LastAttestedAt:    1700000000,
+				ChangePercent:     "6.0000",
+				MaxChangePercent:  "5.0000",
+			},
+		},
+		{
+			name:      "allowed change grows with the intervals",
+			config:    maxChange,
+			price:     "112",
+			timestamp: 1700000000 + 2*3600 + 1,
+			last:      last,
+			expectedStatus: &PriceGuardStatus{
+				Policy:            configs.GuardPolicyFlag,
+				LastAttestedPrice: "100",
+				LastAttestedAt:    1700000000,
+				ChangePercent:     "12.0000",
+				MaxChangePercent:  "15.0000",
+			},
+		},
+		{
+			name:           "inside the peg band",
+			config:         pegBand,
+			price:          "1.03",
+			timestamp:      1700000000,
+			expectedStatus: &PriceGuardStatus{Policy: configs.GuardPolicyReject},
+		},
+		{
+			name:      "outside the peg band",
+			config:    pegBand,
+			price:     "0.9699",
+			timestamp: 1700000000,
+			expectedStatus: &PriceGuardStatus{
+				Policy:    configs.GuardPolicyReject,
+				Triggered: true,
+				Reasons:   []string{GuardReasonPegBand},
+			},
+		},
+		{
+			name:          "invalid price",
+			config:        pegBand,
+			price:         "one",
+			expectedError: appErrors.ErrInvalidRationalNumber,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, err := checkPriceGuard(tt.config, tt.price, tt.timestamp, tt.last)
+			assert.Equal(t, tt.expectedError, err)
+			assert.Equal(t, tt.expectedStatus, status)
+		})
+	}
+}
+
+func TestPriceGuard_Persistence(t *testing.T) {
+	stateFile := filepath.Join(t.TempDir(), "sealed", "last_attested_prices.json")
+
+	guard := newPriceGuard(stateFile)
+	_, exists := guard.last("BTC")
+	assert.False(t, exists)
+
+	guard.record(AttestedPrice{Token: "BTC", Price: "50000.5", Timestamp: 1700000060})
+	guard.record(AttestedPrice{Token: "BTC", Price: "49000", Timestamp: 1700000000})
+	guard.record(AttestedPrice{Token: "USDT", Price: "1.0001", Timestamp: 1700000000})
+
+	reloaded := newPriceGuard(stateFile)
+	btc, exists := reloaded.last("BTC")
+	require.True(t, exists)
+	assert.Equal(t, AttestedPrice{Token: "BTC", Price: "50000.5", Timestamp: 1700000060}, btc, "an older attestation does not replace the last attested price")
+	usdt, exists := reloaded.last("USDT")
+	require.True(t, exists)
+	assert.Equal(t, "1.0001", usdt.Price)
+
+	t.Run("atomic write", func(t *testing.T) {
+		info, err := os.Stat(stateFile)
+		require.NoError(t, err)
+		assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+
+		// A write that cannot replace the state file leaves no temporary file behind.
+		dir := t.TempDir()
+		blocked := filepath.Join(dir, "last_attested_prices.json")
+		require.NoError(t, os.MkdirAll(filepath.Join(blocked, "entry"), 0700))
+		require.Error(t, writeAttestedPrices(blocked, reloaded.prices))
+
+		entries, err := os.ReadDir(dir)
+		require.NoError(t, err)
+		require.Len(t, entries, 1)
+		assert.Equal(t, filepath.Base(blocked), entries[0].Name())
+	})
+
+	t.Run("unreadable state file", func(t *testing.T) {
+		require.NoError(t, os.WriteFile(stateFile, []byte("not json"), 0600))
+		guard := newPriceGuard(stateFile)
+		_, exists := guard.last("BTC")
+		assert.False(t, exists)
+	})
+
+	t.Run("writable state file", func(t *testing.T) {
+		assert.NoError(t, CheckStateFileWritable(stateFile))
+		assert.NoError(t, CheckStateFileWritable(filepath.Join(t.TempDir(), "missing", "last_attested_prices.json")))
+
+		// A state file below a regular file can never be written.
+		assert.Error(t, CheckStateFileWritable(filepath.Join(stateFile, "last_attested_prices.json")))
+	})
+
+	t.Run("sealed state file outside an enclave", func(t *testing.T) {
+		sealedStateFile := "/sealed/mrsigner/last_attested_prices.json"
+		assert.Equal(t, sealedStateFile, resolveStateFile(sealedStateFile, sgx.QuoteProviderGramine))
+		assert.Empty(t, resolveStateFile(sealedStateFile, sgx.QuoteProviderSimulated))
+		assert.Equal(t, stateFile, resolveStateFile(stateFile, sgx.QuoteProviderSimulated))
+		assert.Equal(t, "/sealed-data/last_attested_prices.json", resolveStateFile("/sealed-data/last_attested_prices.json", sgx.QuoteProviderSimulated))
+	})
+
+	t.Run("in memory", func(t *testing.T) {
+		guard := newPriceGuard("")
+		guard.record(AttestedPrice{Token: "BTC", Price: "50000", Timestamp: 1700000000})
+		btc, exists := guard.last("BTC")
+		require.True(t, exists)
+		assert.Equal(t, "50000", btc.Price)
+	})
+}
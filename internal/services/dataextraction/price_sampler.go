@@ -0,0 +1,345 @@
+package data_extraction
+
+import (
+	"context"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	configs "github.com/venture23-aleo/aleo-oracle-notarization-backend/internal/config"
+	"github.com/venture23-aleo/aleo-oracle-notarization-backend/internal/metrics"
+	"github.com/venture23-aleo/aleo-oracle-notarization-backend/internal/services/attestation"
+	appErrors "github.com/venture23-aleo/aleo-oracle-notarization-backend/pkg/errors"
+	"github.com/venture23-aleo/aleo-oracle-notarization-backend/pkg/logger"
+)
+
+// samplePrecision is the number of decimals kept for the sampled prices.
+const samplePrecision = 18
+
+// activeSampler is the running price sampler serving the TWAP price feeds, nil when the sampler is stopped.
+var activeSampler atomic.Pointer[PriceSampler]
+
+// PriceSnapshot is the aggregated price of a token at a sampling time.
+type PriceSnapshot struct {
+	Timestamp     time.Time // Sampling time.
+	Price         *big.Rat  // Aggregated price in USD.
+	ExchangeCount int       // Number of exchanges in the aggregated price.
+}
+
+// priceRing is a bounded ring of the latest price snapshots of a token.
+type priceRing struct {
+	snapshots []PriceSnapshot
+	next      int
+	full      bool
+}
+
+// newPriceRing creates a ring keeping the latest capacity snapshots.
+func newPriceRing(capacity int) *priceRing {
+	return &priceRing{snapshots: make([]PriceSnapshot, capacity)}
+}
+
+// add stores a snapshot, overwriting the oldest one when the ring is full.
+func (r *priceRing) add(snapshot PriceSnapshot) {
+	r.snapshots[r.next] = snapshot
+	r.next = (r.next + 1) % len(r.snapshots)
+	if r.next == 0 {
+		r.full = true
+	}
+}
+
+// ordered returns a copy of the snapshots, oldest first.
+func (r *priceRing) ordered() []PriceSnapshot {
+	if !r.full {
+		return append([]PriceSnapshot(nil), r.snapshots[:r.next]...)
+	}
+	ordered := make([]PriceSnapshot, 0, len(r.snapshots))
+	ordered = append(ordered, r.snapshots[r.next:]...)
+	return append(ordered, r.snapshots[:r.next]...)
+}
+
+// TWAPResult represents the time-weighted average price of a token over a window.
+type TWAPResult struct {
+	Token           string            `json:"token"`           // Token.
+	TWAP            string            `json:"twap"`            // Time-weighted average price.
+	Window          string            `json:"window"`          // Window, duration string like "15m0s".
+	WindowStart     int64             `json:"windowStart"`     // Start of the window, Unix seconds.
+	WindowEnd       int64             `json:"windowEnd"`       // End of the window, Unix seconds.
+	SampleCount     int               `json:"sampleCount"`     // Number of snapshots in the window.
+	CoveragePercent string            `json:"coveragePercent"` // Share of the window covered by the snapshots.
+	Timestamp       int64             `json:"timestamp"`       // Timestamp.
+	Success         bool              `json:"success"`         // Success.
+	Guard           *PriceGuardStatus `json:"guard,omitempty"` // Price guard of the token, nil when the token has no guard.
+}
+
+// PriceSampler polls the price feed of its tokens at a fixed cadence and keeps the latest snapshots per token.
+type PriceSampler struct {
+	client   *PriceFeedClient
+	config   configs.PriceSamplerConfig
+	tokens   []string
+	now      func() time.Time
+	ringsMu  sync.RWMutex
+	rings    map[string]*priceRing
+	stopChan chan struct{}
+	mu       sync.Mutex
+	started  bool
+}
+
+// NewPriceSampler creates a price sampler for the tokens of the config, every token of the registry when none is set.
+func NewPriceSampler(config configs.PriceSamplerConfig) *PriceSampler {
+	var tokens []string
+	for _, token := range config.Tokens {
+		tokens = append(tokens, strings.ToUpper(token))
+	}
+	if len(tokens) == 0 {
+		for token := range configs.GetTokenRegistry() {
+			tokens = append(tokens, token)
+		}
+	}
+	sort.Strings(tokens)
+
+	rings := make(map[string]*priceRing, len(tokens))
+	for _, token := range tokens {
+		rings[token] = newPriceRing(config.Capacity)
+	}
+
+	return &PriceSampler{
+		client:   NewPriceFeedClient(),
+		config:   config,
+		tokens:   tokens,
+		now:      time.Now,
+		rings:    rings,
+		stopChan: make(chan struct{}),
+	}
+}
+
+// Start begins sampling and serves the TWAP price feeds from this sampler.
+func (s *PriceSampler) Start() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.started {
+		logger.Warn("Price sampler already started")
+		return
+	}
+	logger.Info("Starting price sampler", "tokens", s.tokens, "interval", s.config.Interval, "capacity", s.config.Capacity)
+	// Reinitialize stop channel in case of restart after Stop.
+	s.stopChan = make(chan struct{})
+	s.started = true
+	activeSampler.Store(s)
+	go s.run()
+}
+
+// Stop stops sampling. TWAP price feeds fail until a sampler is started again.
+func (s *PriceSampler) Stop() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.started {
+		logger.Warn("Price sampler not running")
+		return
+	}
+	logger.Info("Stopping price sampler")
+	select {
+	case <-s.stopChan:
+		// already closed
+	default:
+		close(s.stopChan)
+	}
+	s.started = false
+	activeSampler.CompareAndSwap(s, nil)
+}
+
+// run samples the tokens right away and then at every interval.
+func (s *PriceSampler) run() {
+	ticker := time.NewTicker(s.config.Interval)
+	defer ticker.Stop()
+
+	s.sampleAll()
+	for {
+		select {
+		case <-ticker.C:
+			s.sampleAll()
+		case <-s.stopChan:
+			return
+		}
+	}
+}
+
+// sampleAll samples the tokens concurrently. Each sample must complete within the interval.
+func (s *PriceSampler) sampleAll() {
+	ctx, cancel := context.WithTimeout(context.Background(), s.config.Interval)
+	defer cancel()
+
+	sampledAt := s.now()
+
+	var wg sync.WaitGroup
+	for _, token := range s.tokens {
+		wg.Add(1)
+		go func(token string) {
+			defer wg.Done()
+			s.sample(ctx, token, sampledAt)
+		}(token)
+	}
+	wg.Wait()
+}
+
+// sample stores the aggregated price of a token. Failed samples leave a gap in the window.
+func (s *PriceSampler) sample(ctx context.Context, token string, sampledAt time.Time) {
+	result, appErr := s.client.GetPriceFeed(ctx, token, sampledAt.Unix(), samplePrecision)
+	if appErr != nil {
+		logger.Warn("Price sample failed", "token", token, "error", appErr)
+		metrics.RecordPriceSamplerSample(token, "failed")
+		return
+	}
+
+	price, ok := new(big.Rat).SetString(result.VolumeWeightedAvg)
+	if !ok {
+		logger.Warn("Price sample has an invalid price", "token", token, "price", result.VolumeWeightedAvg)
+		metrics.RecordPriceSamplerSample(token, "failed")
+		return
+	}
+
+	s.record(token, PriceSnapshot{Timestamp: sampledAt, Price: price, ExchangeCount: result.ExchangeCount})
+	metrics.RecordPriceSamplerSample(token, "success")
+}
+
+// record stores a snapshot in the ring of a token.
+func (s *PriceSampler) record(token string, snapshot PriceSnapshot) {
+	s.ringsMu.Lock()
+	defer s.ringsMu.Unlock()
+	if ring, exists := s.rings[token]; exists {
+		ring.add(snapshot)
+	}
+}
+
+// TWAP computes the time-weighted average price of a token over the window ending at the attestation timestamp,
+// so the attested window rests on the Roughtime-attested time rather than the clock of the host.
+func (s *PriceSampler) TWAP(token string, window time.Duration, timestamp int64, precision uint) (*TWAPResult, *appErrors.AppError) {
+	s.ringsMu.RLock()
+	ring, exists := s.rings[token]
+	var snapshots []PriceSnapshot
+	if exists {
+		snapshots = ring.ordered()
+	}
+	s.ringsMu.RUnlock()
+
+	if !exists {
+		return nil, appErrors.ErrPriceSamplerDisabled
+	}
+
+	if window < s.config.Interval || window > s.config.MaxWindow() {
+		logger.Error("TWAP window outside the sampled range", "token", token, "window", window, "interval", s.config.Interval, "maxWindow", s.config.MaxWindow())
+		return nil, appErrors.ErrInvalidTWAPWindow
+	}
+
+	end := time.Unix(timestamp, 0)
+	start := end.Add(-window)
+	twap, sampleCount, coverage := computeTWAP(snapshots, start, end, s.config.Interval)
+	if sampleCount == 0 || coverage.Cmp(new(big.Rat).SetFloat64(s.config.MinCoveragePercent)) < 0 {
+		logger.Error("Insufficient TWAP coverage", "token", token, "window", window, "sampleCount", sampleCount, "coveragePercent", coverage.FloatString(2), "minCoveragePercent", s.config.MinCoveragePercent)
+		return nil, appErrors.ErrInsufficientTWAPCoverage
+	}
+
+	return &TWAPResult{
+		Token:           token,
+		TWAP:            Truncate(twap, int(precision)),
+		Window:          window.String(),
+		WindowStart:     start.Unix(),
+		WindowEnd:       end.Unix(),
+		SampleCount:     sampleCount,
+		CoveragePercent: coverage.FloatString(2),
+		Timestamp:       end.Unix(),
+		Success:         true,
+	}, nil
+}
+
+// computeTWAP computes the time-weighted average of the snapshots, oldest first, over [start, end].
+//
+// Each snapshot holds its price until the next snapshot, for at most one interval, so failed samples leave
+// uncovered gaps. It returns the average over the covered time, the number of snapshots contributing to it
+// and the covered share of the window in percent.
+func computeTWAP(snapshots []PriceSnapshot, start, end time.Time, interval time.Duration) (*big.Rat, int, *big.Rat) {
+	weightedSum := new(big.Rat)
+	covered := time.Duration(0)
+	sampleCount := 0
+
+	for i, snapshot := range snapshots {
+		segmentEnd := snapshot.Timestamp.Add(interval)
+		if i+1 < len(snapshots) && snapshots[i+1].Timestamp.Before(segmentEnd) {
+			segmentEnd = snapshots[i+1].Timestamp
+		}
+		if segmentEnd.After(end) {
+			segmentEnd = end
+		}
+		segmentStart := snapshot.Timestamp
+		if segmentStart.Before(start) {
+			segmentStart = start
+		}
+
+		duration := segmentEnd.Sub(segmentStart)
+		if duration <= 0 {
+			continue
+		}
+
+		weightedSum.Add(weightedSum, new(big.Rat).Mul(snapshot.Price, big.NewRat(int64(duration), 1)))
+		covered += duration
+		sampleCount++
+	}
+
+	coverage := new(big.Rat)
+	if window := end.Sub(start); window > 0 {
+		coverage.SetFrac64(int64(covered)*100, int64(window))
+	}
+	if covered == 0 {
+		return new(big.Rat), 0, coverage
+	}
+	return weightedSum.Quo(weightedSum, big.NewRat(int64(covered), 1)), sampleCount, coverage
+}
+
+// ExtractTWAPPriceFeedData handles TWAP price feed requests like "price_feed: btc twap=15m" with the running
+// price sampler. The attestation data is the time-weighted average price over the window.
+func ExtractTWAPPriceFeedData(ctx context.Context, attestationRequest attestation.AttestationRequest, token string, window time.Duration, timestamp int64) (ExtractDataResult, *appErrors.AppError) {
+	priceFeedStart := time.Now()
+
+	status := "failed"
+	defer func() {
+		metrics.RecordPriceFeedRequest(token, status, time.Since(priceFeedStart).Seconds())
+	}()
+
+	reqLogger := logger.FromContext(ctx)
+
+	sampler := activeSampler.Load()
+	if sampler == nil {
+		reqLogger.Error("Price sampler not running for TWAP price feed", "token", token)
+		return ExtractDataResult{}, appErrors.ErrPriceSamplerDisabled
+	}
+
+	result, appErr := sampler.TWAP(token, window, timestamp, attestationRequest.EncodingOptions.Precision)
+	if appErr != nil {
+		reqLogger.Error("Error getting TWAP price feed for ", "token", token, "window", window, "error", appErr)
+		return ExtractDataResult{}, appErr
+	}
+
+	result.Guard, appErr = guardPrice(ctx, token, result.TWAP, timestamp)
+	if appErr != nil {
+		return ExtractDataResult{}, appErr
+	}
+
+	jsonBytes, err := json.Marshal(result)
+	if err != nil {
+		reqLogger.Error("Error marshalling TWAP price feed data", "error", err)
+		return ExtractDataResult{}, appErrors.ErrEncodingPriceFeedData
+	}
+
+	status = "success"
+
+	return ExtractDataResult{
+		ResponseBody:    string(jsonBytes),
+		AttestationData: result.TWAP,
+		StatusCode:      http.StatusOK,
+		AttestedPrice:   &AttestedPrice{Token: token, Price: result.TWAP, Timestamp: timestamp},
+	}, nil
+}
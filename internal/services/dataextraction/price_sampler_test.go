@@ -0,0 +1,194 @@
+package data_extraction
+
+import (
+	"context"
+	"encoding/json"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	encoding "github.com/venture23-aleo/aleo-oracle-encoding"
+	configs "github.com/venture23-aleo/aleo-oracle-notarization-backend/internal/config"
+	"github.com/venture23-aleo/aleo-oracle-notarization-backend/internal/services/attestation"
+	appErrors "github.com/venture23-aleo/aleo-oracle-notarization-backend/pkg/errors"
+)
+
+func TestPriceRing(t *testing.T) {
+	base := time.Unix(1700000000, 0)
+	ring := newPriceRing(3)
+	assert.Empty(t, ring.ordered())
+
+	for i := 0; i < 5; i++ {
+		ring.add(PriceSnapshot{Timestamp: base.Add(time.Duration(i) * time.Second), Price: big.NewRat(int64(i), 1)})
+		snapshots := ring.ordered()
+		assert.Len(t, snapshots, min(i+1, 3))
+		assert.Equal(t, base.Add(time.Duration(i)*time.Second), snapshots[len(snapshots)-1].Timestamp)
+	}
+
+	snapshots := ring.ordered()
+	for i, snapshot := range snapshots {
+		assert.Equal(t, base.Add(time.Duration(i+2)*time.Second), snapshot.Timestamp)
+	}
+}
+
+func TestComputeTWAP(t *testing.T) {
+	end := time.Unix(1700000600, 0)
+	interval := time.Minute
+
+	snapshotsAt := func(prices map[time.Duration]int64) []PriceSnapshot {
+		var snapshots []PriceSnapshot
+		for offset := 10 * time.Minute; offset >= 0; offset -= 30 * time.Second {
+			if price, exists := prices[offset]; exists {
+				snapshots = append(snapshots, PriceSnapshot{Timestamp: end.Add(-offset), Price: big.NewRat(price, 1)})
+			}
+		}
+		return snapshots
+	}
+
+	tests := []struct {
+		name             string
+		snapshots        []PriceSnapshot
+		window           time.Duration
+		expectedTWAP     string
+		expectedSamples  int
+		expectedCoverage string
+	}{
+		{
+			name:             "full coverage",
+			snapshots:        snapshotsAt(map[time.Duration]int64{4 * time.Minute: 100, 3 * time.Minute: 100, 2 * time.Minute: 200, time.Minute: 200}),
+			window:           4 * time.Minute,
+			expectedTWAP:     "150.00",
+			expectedSamples:  4,
+			expectedCoverage: "100.00",
+		},
+		{
+			name:             "failed sample leaves a gap",
+			snapshots:        snapshotsAt(map[time.Duration]int64{4 * time.Minute: 100, 3 * time.Minute: 100, time.Minute: 400}),
+			window:           4 * time.Minute,
+			expectedTWAP:     "200.00",
+			expectedSamples:  3,
+			expectedCoverage: "75.00",
+		},
+		{
+			name: "snapshot before the window holds into it",
+			snapshots: snapshotsAt(map[time.Duration]int64{
+				5 * time.Minute:                5000,
+				4*time.Minute + 30*time.Second: 1000,
+				3*time.Minute + 30*time.Second: 100,
+				2*time.Minute + 30*time.Second: 100,
+				1*time.Minute + 30*time.Second: 100,
+				30 * time.Second:               100,
+			}),
+			window:           4 * time.Minute,
+			expectedTWAP:     "212.50",
+			expectedSamples:  5,
+			expectedCoverage: "100.00",
+		},
+		{
+			name:             "no snapshots",
+			window:           4 * time.Minute,
+			expectedTWAP:     "0.00",
+			expectedCoverage: "0.00",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			twap, sampleCount, coverage := computeTWAP(tt.snapshots, end.Add(-tt.window), end, interval)
+			assert.Equal(t, tt.expectedTWAP, twap.FloatString(2))
+			assert.Equal(t, tt.expectedSamples, sampleCount)
+			assert.Equal(t, tt.expectedCoverage, coverage.FloatString(2))
+		})
+	}
+}
+
+func TestPriceSamplerTWAP(t *testing.T) {
+	now := time.Unix(1700000600, 0)
+	sampler := newTestPriceSampler(now)
+	for offset := 10 * time.Minute; offset > 0; offset -= time.Minute {
+		sampler.record("BTC", PriceSnapshot{Timestamp: now.Add(-offset), Price: big.NewRat(50000, 1), ExchangeCount: 3})
+	}
+	sampler.record("ETH", PriceSnapshot{Timestamp: now.Add(-time.Minute), Price: big.NewRat(3000, 1), ExchangeCount: 3})
+
+	t.Run("window covered by the snapshots", func(t *testing.T) {
+		result, err := sampler.TWAP("BTC", 5*time.Minute, now.Unix(), 2)
+		require.Nil(t, err)
+		assert.Equal(t, "50000.00", result.TWAP)
+		assert.Equal(t, "5m0s", result.Window)
+		assert.Equal(t, now.Add(-5*time.Minute).Unix(), result.WindowStart)
+		assert.Equal(t, now.Unix(), result.WindowEnd)
+		assert.Equal(t, 5, result.SampleCount)
+		assert.Equal(t, "100.00", result.CoveragePercent)
+		assert.True(t, result.Success)
+	})
+
+	t.Run("window shorter than the interval", func(t *testing.T) {
+		_, err := sampler.TWAP("BTC", 30*time.Second, now.Unix(), 2)
+		assert.Equal(t, appErrors.ErrInvalidTWAPWindow, err)
+	})
+
+	t.Run("window longer than the ring", func(t *testing.T) {
+		_, err := sampler.TWAP("BTC", 11*time.Minute, now.Unix(), 2)
+		assert.Equal(t, appErrors.ErrInvalidTWAPWindow, err)
+	})
+
+	t.Run("insufficient coverage", func(t *testing.T) {
+		_, err := sampler.TWAP("ETH", 5*time.Minute, now.Unix(), 2)
+		assert.Equal(t, appErrors.ErrInsufficientTWAPCoverage, err)
+	})
+
+	t.Run("token not sampled", func(t *testing.T) {
+		_, err := sampler.TWAP("ALEO", 5*time.Minute, now.Unix(), 2)
+		assert.Equal(t, appErrors.ErrPriceSamplerDisabled, err)
+	})
+}
+
+func TestExtractTWAPPriceFeedData(t *testing.T) {
+	request := attestation.AttestationRequest{
+		Url:             "price_feed: btc twap=5m",
+		EncodingOptions: encoding.EncodingOptions{Value: "float", Precision: 4},
+	}
+
+	_, err := ExtractTWAPPriceFeedData(context.Background(), request, "BTC", 5*time.Minute, 1700000600)
+	assert.Equal(t, appErrors.ErrPriceSamplerDisabled, err)
+
+	timestamp := time.Unix(1700000600, 0)
+	// The clock of the host is ahead of the attested time, the window still ends at the attestation timestamp.
+	sampler := newTestPriceSampler(timestamp.Add(time.Minute))
+	for offset := 5 * time.Minute; offset > 0; offset -= time.Minute {
+		sampler.record("BTC", PriceSnapshot{Timestamp: timestamp.Add(-offset), Price: big.NewRat(int64(50000+offset/time.Minute), 1)})
+	}
+	activeSampler.Store(sampler)
+	t.Cleanup(func() { activeSampler.Store(nil) })
+
+	result, err := ExtractTWAPPriceFeedData(context.Background(), request, "BTC", 5*time.Minute, timestamp.Unix())
+	require.Nil(t, err)
+	assert.Equal(t, "50003.0000", result.AttestationData)
+
+	var twapResult TWAPResult
+	require.NoError(t, json.Unmarshal([]byte(result.ResponseBody), &twapResult))
+	assert.Equal(t, "BTC", twapResult.Token)
+	assert.Equal(t, "50003.0000", twapResult.TWAP)
+	assert.Equal(t, 5, twapResult.SampleCount)
+	assert.Equal(t, "100.00", twapResult.CoveragePercent)
+	assert.Equal(t, timestamp.Add(-5*time.Minute).Unix(), twapResult.WindowStart)
+	assert.Equal(t, timestamp.Unix(), twapResult.WindowEnd)
+	assert.Equal(t, timestamp.Unix(), twapResult.Timestamp)
+}
+
+// newTestPriceSampler creates a price sampler of BTC and ETH with a one minute interval and ten snapshots,
+// whose clock is stopped at now.
+func newTestPriceSampler(now time.Time) *PriceSampler {
+	config := configs.PriceSamplerConfig{
+		Enabled:            true,
+		Tokens:             []string{"btc", "eth"},
+		Interval:           time.Minute,
+		Capacity:           10,
+		MinCoveragePercent: 80,
+	}
+	sampler := NewPriceSampler(config)
+	sampler.now = func() time.Time { return now }
+	return sampler
+}
@@ -0,0 +1,136 @@
+package data_extraction
+
+import (
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	configs "github.com/venture23-aleo/aleo-oracle-notarization-backend/internal/config"
+	"github.com/venture23-aleo/aleo-oracle-notarization-backend/pkg/logger"
+)
+
+// defaultThrottleDuration is how long an exchange is throttled after a rate limit response without Retry-After.
+const defaultThrottleDuration = time.Minute
+
+// exchangeRateLimiters holds the rate limiter of each exchange, created on the first request.
+var exchangeRateLimiters sync.Map // map[string]*exchangeRateLimiter
+
+// exchangeRateLimiter is the client-side token bucket of an exchange. It also throttles the exchange after rate
+// limit responses, until their Retry-After or the end of the used weight window.
+type exchangeRateLimiter struct {
+	exchange       string
+	config         *configs.ExchangeRateLimit // Bucket settings, nil when only rate limit responses throttle the exchange.
+	now            func() time.Time
+	mu             sync.Mutex
+	tokens         float64
+	lastRefill     time.Time // Time of the last refill, zero before the first request.
+	throttledUntil time.Time
+}
+
+// newExchangeRateLimiter creates a rate limiter with a full bucket.
+func newExchangeRateLimiter(exchange string, config *configs.ExchangeRateLimit) *exchangeRateLimiter {
+	limiter := &exchangeRateLimiter{exchange: exchange, config: config, now: time.Now}
+	if config != nil {
+		limiter.tokens = config.Burst
+	}
+	return limiter
+}
+
+// getExchangeRateLimiter returns the rate limiter of an exchange, creating it from the exchange config.
+func getExchangeRateLimiter(exchange string, config configs.ExchangeConfig) *exchangeRateLimiter {
+	if limiter, ok := exchangeRateLimiters.Load(exchange); ok {
+		return limiter.(*exchangeRateLimiter)
+	}
+	limiter, _ := exchangeRateLimiters.LoadOrStore(exchange, newExchangeRateLimiter(exchange, config.RateLimit))
+	return limiter.(*exchangeRateLimiter)
+}
+
+// allow takes the weight of a request from the bucket. It returns false without waiting when the exchange is
+// throttled or the bucket holds less than the weight of a request.
+func (l *exchangeRateLimiter) allow() bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	if now.Before(l.throttledUntil) {
+		return false
+	}
+	if l.config == nil {
+		return true
+	}
+
+	if now.After(l.lastRefill) {
+		if !l.lastRefill.IsZero() {
+			elapsed := now.Sub(l.lastRefill).Seconds()
+			l.tokens = min(l.config.Burst, l.tokens+elapsed*l.config.RequestsPerSecond)
+		}
+		l.lastRefill = now
+	}
+
+	weight := l.config.CallWeight()
+	if l.tokens < weight {
+		return false
+	}
+	l.tokens -= weight
+	return true
+}
+
+// throttle skips the requests to the exchange until the given time.
+func (l *exchangeRateLimiter) throttle(until time.Time) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if until.After(l.throttledUntil) {
+		l.throttledUntil = until
+		logger.Warn("Exchange throttled by the rate limiter", "exchange", l.exchange, "until", until)
+	}
+}
+
+// observe throttles the exchange from a response: rate limit responses throttle it until their Retry-After,
+// and a used weight reaching the configured maximum throttles it until the end of the weight window.
+func (l *exchangeRateLimiter) observe(resp *http.Response) {
+	now := l.now()
+
+	if isRateLimitStatus(resp.StatusCode) {
+		retryAfter, ok := parseRetryAfter(resp.Header.Get("Retry-After"), now)
+		if !ok {
+			retryAfter = defaultThrottleDuration
+		}
+		l.throttle(now.Add(retryAfter))
+		return
+	}
+
+	if l.config == nil || l.config.UsedWeightHeader == "" {
+		return
+	}
+	usedWeight, err := strconv.ParseFloat(resp.Header.Get(l.config.UsedWeightHeader), 64)
+	if err != nil {
+		return
+	}
+	if usedWeight >= l.config.MaxUsedWeight {
+		l.throttle(now.Truncate(l.config.UsedWeightWindow).Add(l.config.UsedWeightWindow))
+	}
+}
+
+// isRateLimitStatus reports whether a status code rejects a request for exceeding the rate limit.
+// Binance answers 418 once an IP is banned for ignoring 429 responses.
+func isRateLimitStatus(statusCode int) bool {
+	return statusCode == http.StatusTooManyRequests || statusCode == http.StatusTeapot
+}
+
+// parseRetryAfter parses a Retry-After header, in seconds or as an HTTP date.
+func parseRetryAfter(value string, now time.Time) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds < 0 {
+			return 0, false
+		}
+		return time.Duration(seconds) * time.Second, true
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		return max(date.Sub(now), 0), true
+	}
+	return 0, false
+}
@@ -0,0 +1,193 @@
+package data_extraction
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/go-retryablehttp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	configs "github.com/venture23-aleo/aleo-oracle-notarization-backend/internal/config"
+	appErrors "github.com/venture23-aleo/aleo-oracle-notarization-backend/pkg/errors"
+)
+
+func TestExchangeRateLimiter_TokenBucket(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	limiter := newExchangeRateLimiter("test", &configs.ExchangeRateLimit{RequestsPerSecond: 2, Burst: 4, WeightPerCall: 2})
+	limiter.now = func() time.Time { return now }
+
+	assert.True(t, limiter.allow())
+	assert.True(t, limiter.allow())
+	assert.False(t, limiter.allow(), "burst exhausted")
+
+	now = now.Add(500 * time.Millisecond)
+	assert.False(t, limiter.allow(), "one token refilled, the call weighs two")
+
+	now = now.Add(500 * time.Millisecond)
+	assert.True(t, limiter.allow())
+
+	now = now.Add(time.Hour)
+	assert.True(t, limiter.allow())
+	assert.True(t, limiter.allow())
+	assert.False(t, limiter.allow(), "refill capped at the burst")
+}
+
+func TestExchangeRateLimiter_Observe(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 20, 0, time.UTC)
+	response := func(statusCode int, header http.Header) *http.Response {
+		if header == nil {
+			header = http.Header{}
+		}
+		return &http.Response{StatusCode: statusCode, Header: header}
+	}
+
+	tests := []struct {
+		name                   string
+		config                 *configs.ExchangeRateLimit
+		response               *http.Response
+		expectedThrottledUntil time.Time
+	}{
+		{
+			name:                   "Retry-After in seconds",
+			response:               response(http.StatusTooManyRequests, http.Header{"Retry-After": {"30"}}),
+			expectedThrottledUntil: now.Add(30 * time.Second),
+		},
+		{
+			name:                   "Retry-After as HTTP date",
+			response:               response(http.StatusTooManyRequests, http.Header{"Retry-After": {now.Add(10 * time.Minute).Format(http.TimeFormat)}}),
+			expectedThrottledUntil: now.Add(10 * time.Minute),
+		},
+		{
+			name:                   "rate limit response without Retry-After",
+			response:               response(http.StatusTeapot, nil),
+			expectedThrottledUntil: now.Add(defaultThrottleDuration),
+		},
+		{
+			name:     "used weight below the maximum",
+			config:   binanceRateLimit(),
+			response: response(http.StatusOK, http.Header{"X-Mbx-Used-Weight-1m": {"4999"}}),
+		},
+		{
+			name:                   "used weight reaching the maximum",
+			config:                 binanceRateLimit(),
+			response:               response(http.StatusOK, http.Header{"X-Mbx-Used-Weight-1m": {"5000"}}),
+			expectedThrottledUntil: time.Date(2025, 1, 1, 12, 1, 0, 0, time.UTC),
+		},
+		{
+			name:     "server error",
+			response: response(http.StatusServiceUnavailable, http.Header{"Retry-After": {"30"}}),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			limiter := newExchangeRateLimiter("test", tt.config)
+			limiter.now = func() time.Time { return now }
+
+			limiter.observe(tt.response)
+			assert.True(t, tt.expectedThrottledUntil.Equal(limiter.throttledUntil), "throttled until %s", limiter.throttledUntil)
+			assert.Equal(t, tt.expectedThrottledUntil.IsZero(), limiter.allow())
+		})
+	}
+}
+
+func TestFetchPriceFromExchange_RateLimited(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		if r.URL.Query().Get("symbol") == "LIMITED" {
+			w.Header().Set("Retry-After", "600")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.Write([]byte(`{"price": "50000", "volume": "10"}`))
+	}))
+	defer server.Close()
+
+	exchangeConfigs := configs.ExchangesConfig{}
+	for _, exchange := range []string{"limited-bucket", "limited-venue"} {
+		client := retryablehttp.NewClient()
+		client.RetryMax = 1
+		client.RetryWaitMin = time.Millisecond
+		client.RetryWaitMax = time.Millisecond
+		client.CheckRetry = checkExchangeRetry
+		clientCache.Store(exchange, client)
+		t.Cleanup(func() {
+			clientCache.Delete(exchange)
+			exchangeRateLimiters.Delete(exchange)
+		})
+
+		exchangeConfigs[exchange] = configs.ExchangeConfig{
+			Name:             exchange,
+			BaseURL:          server.URL,
+			EndpointTemplate: "/ticker?symbol={symbol}",
+			ResponseFormat:   &configs.ExchangeResponseFormat{PricePath: "price", VolumePath: "volume"},
+			RateLimit:        &configs.ExchangeRateLimit{RequestsPerSecond: 0.001, Burst: 2},
+			Freshness:        &configs.ExchangeFreshness{Source: configs.FreshnessSourceDateHeader},
+		}
+	}
+	priceFeedClient := &PriceFeedClient{exchangeConfigs: exchangeConfigs}
+	timestamp := time.Now().Unix()
+
+	t.Run("skipped once the bucket is empty", func(t *testing.T) {
+		requests.Store(0)
+		for i := 0; i < 2; i++ {
+			_, err := priceFeedClient.FetchPriceFromExchange(context.Background(), "limited-bucket", "BTC", "BTC-USD", timestamp)
+			require.Nil(t, err)
+		}
+		_, err := priceFeedClient.FetchPriceFromExchange(context.Background(), "limited-bucket", "BTC", "BTC-USD", timestamp)
+		assert.Equal(t, appErrors.ErrExchangeRateLimited, err)
+		assert.Equal(t, int32(2), requests.Load())
+	})
+
+	t.Run("throttled by a rate limit response without retrying", func(t *testing.T) {
+		requests.Store(0)
+		_, err := priceFeedClient.FetchPriceFromExchange(context.Background(), "limited-venue", "BTC", "LIMITED", timestamp)
+		assert.Equal(t, appErrors.ErrExchangeRateLimited, err)
+		assert.Equal(t, int32(1), requests.Load())
+
+		_, err = priceFeedClient.FetchPriceFromExchange(context.Background(), "limited-venue", "BTC", "BTC-USD", timestamp)
+		assert.Equal(t, appErrors.ErrExchangeRateLimited, err)
+		assert.Equal(t, int32(1), requests.Load())
+	})
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		value    string
+		expected time.Duration
+		ok       bool
+	}{
+		{value: "120", expected: 2 * time.Minute, ok: true},
+		{value: "0", expected: 0, ok: true},
+		{value: now.Add(time.Minute).Format(http.TimeFormat), expected: time.Minute, ok: true},
+		{value: now.Add(-time.Minute).Format(http.TimeFormat), expected: 0, ok: true},
+		{value: ""},
+		{value: "-5"},
+		{value: "soon"},
+	}
+
+	for _, tt := range tests {
+		retryAfter, ok := parseRetryAfter(tt.value, now)
+		assert.Equal(t, tt.ok, ok, tt.value)
+		assert.Equal(t, tt.expected, retryAfter, tt.value)
+	}
+}
+
+// binanceRateLimit returns the rate limit of Binance with a used weight window of one minute.
+func binanceRateLimit() *configs.ExchangeRateLimit {
+	return &configs.ExchangeRateLimit{
+		RequestsPerSecond: 20,
+		Burst:             40,
+		WeightPerCall:     2,
+		UsedWeightHeader:  "X-Mbx-Used-Weight-1m",
+		MaxUsedWeight:     5000,
+		UsedWeightWindow:  time.Minute,
+	}
+}
@@ -1,16 +1,19 @@
 package enclave_info
 
 import (
+	"crypto/sha256"
 	"encoding/base64"
 	"encoding/binary"
+	"encoding/hex"
 	"fmt"
 	"sync"
 
 	common "github.com/venture23-aleo/aleo-oracle-notarization-backend/internal/common"
-	"github.com/venture23-aleo/aleo-oracle-notarization-backend/internal/logger"
-	"github.com/venture23-aleo/aleo-oracle-notarization-backend/internal/sgx"
+	"github.com/venture23-aleo/aleo-oracle-notarization-backend/pkg/logger"
+	"github.com/venture23-aleo/aleo-oracle-notarization-backend/pkg/sgx"
 
-	appErrors "github.com/venture23-aleo/aleo-oracle-notarization-backend/internal/errors"
+	configs "github.com/venture23-aleo/aleo-oracle-notarization-backend/internal/config"
+	appErrors "github.com/venture23-aleo/aleo-oracle-notarization-backend/pkg/errors"
 )
 
 // AleoEncodedSGXInfo is the information about the SGX enclave for Aleo.
@@ -30,11 +33,34 @@ type SGXEnclaveInfo struct {
 	Aleo            AleoEncodedSGXInfo `json:"aleo"`            // Some of the SGX report values encoded for Aleo.
 }
 
+// SignerKeyBinding binds the Aleo signer public key and the config to the enclave.
+//
+// The quote's user_report_data is SHA256(signerPubKey) || SHA256(config.json), so a verifier can
+// check the key-to-enclave binding from the quote alone.
+type SignerKeyBinding struct {
+	Quote      string `json:"quote"`      // Base64 encoded DCAP quote wrapped as Open Enclave evidence.
+	PubKeyHash string `json:"pubKeyHash"` // Hex encoded SHA-256 hash of the signer public key.
+	ConfigHash string `json:"configHash"` // Hex encoded SHA-256 hash of the embedded config file.
+}
+
+// SigningKeyInfo is the information about the Aleo signing key.
+type SigningKeyInfo struct {
+	SealingPolicy      string `json:"sealingPolicy"`                // The sealing policy: "none", "mrenclave" or "mrsigner".
+	Fingerprint        string `json:"fingerprint"`                  // Hex encoded SHA-256 hash of the signer public key.
+	PreviousPubKey     string `json:"previousPubKey,omitempty"`     // The previous signer public key during a key rotation overlap window.
+	PreviousValidUntil int64  `json:"previousValidUntil,omitempty"` // Unix timestamp until which the previous signer public key keeps signing.
+	PlannedPubKey      string `json:"plannedPubKey,omitempty"`      // The signer public key of a planned key rotation, before it takes effect.
+	PlannedEffectiveAt int64  `json:"plannedEffectiveAt,omitempty"` // Unix timestamp from which the planned signer public key signs.
+}
+
 // EnclaveInfoResponse is the information about the enclave.
 type EnclaveInfoResponse struct {
-	ReportType   string         `json:"reportType"`   // The type of report.
-	Info         SGXEnclaveInfo `json:"info"`         // The SGX Enclave info.
-	SignerPubKey string         `json:"signerPubKey"` // The signer public key.
+	ReportType    string           `json:"reportType"`    // The type of report.
+	QuoteProvider string           `json:"quoteProvider"` // The active SGX quote provider.
+	Info          SGXEnclaveInfo   `json:"info"`          // The SGX Enclave info.
+	SignerPubKey  string           `json:"signerPubKey"`  // The signer public key.
+	SigningKey    SigningKeyInfo   `json:"signingKey"`    // The signing key sealing information.
+	SignerBinding SignerKeyBinding `json:"signerBinding"` // The quote binding the signer public key to the enclave.
 }
 
 // formatSgxReport formats the SGX report
@@ -144,3 +170,51 @@ func GetSGXEnclaveInfo() (SGXEnclaveInfo, *appErrors.AppError) {
 	})
 	return sgxEnclaveInfo, sgxEnclaveInfoErr
 }
+
+// Signer key binding cache, regenerated only when the signer public key changes.
+var (
+	signerBindingMu     sync.Mutex       // Guards the cached signer key binding.
+	signerBinding       SignerKeyBinding // Cached signer key binding.
+	signerBindingPubKey string           // Public key the cached binding was generated for.
+)
+
+// PrepareSignerReportData prepares the 64-byte report data committing to the signer public key and the config.
+//
+// Layout: SHA256(signerPubKey) (32 bytes) || SHA256(config.json) (32 bytes).
+func PrepareSignerReportData(signerPubKey string, configHash [32]byte) []byte {
+	pubKeyHash := sha256.Sum256([]byte(signerPubKey))
+
+	reportData := make([]byte, 0, sgx.SGXReportDataSize)
+	reportData = append(reportData, pubKeyHash[:]...)
+	reportData = append(reportData, configHash[:]...)
+	return reportData
+}
+
+// GetSignerKeyBinding returns a quote binding the given signer public key and the config to the enclave.
+// The quote is generated once per public key and reused for subsequent requests.
+func GetSignerKeyBinding(signerPubKey string) (SignerKeyBinding, *appErrors.AppError) {
+	signerBindingMu.Lock()
+	defer signerBindingMu.Unlock()
+
+	if signerBindingPubKey == signerPubKey && signerBinding.Quote != "" {
+		return signerBinding, nil
+	}
+
+	configHash := configs.GetAppConfigHash()
+	reportData := PrepareSignerReportData(signerPubKey, configHash)
+
+	quote, err := sgx.GenerateQuote(reportData)
+	if err != nil {
+		logger.Error("Failed to generate signer key binding quote", "error", err)
+		return SignerKeyBinding{}, err
+	}
+
+	signerBinding = SignerKeyBinding{
+		Quote:      base64.StdEncoding.EncodeToString(quote),
+		PubKeyHash: hex.EncodeToString(reportData[:32]),
+		ConfigHash: hex.EncodeToString(configHash[:]),
+	}
+	signerBindingPubKey = signerPubKey
+
+	return signerBinding, nil
+}
@@ -2,12 +2,16 @@ package enclave_info
 
 import (
 	"bytes"
+	"crypto/sha256"
 	"encoding/base64"
+	"encoding/hex"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
-	"github.com/venture23-aleo/aleo-oracle-notarization-backend/internal/logger"
-	"github.com/venture23-aleo/aleo-oracle-notarization-backend/internal/sgx"
+	"github.com/stretchr/testify/require"
+	configs "github.com/venture23-aleo/aleo-oracle-notarization-backend/internal/config"
+	"github.com/venture23-aleo/aleo-oracle-notarization-backend/pkg/logger"
+	"github.com/venture23-aleo/aleo-oracle-notarization-backend/pkg/sgx"
 )
 
 // TestMain initializes the logger for all tests in this package
@@ -135,3 +139,41 @@ func TestCreateAleoEncodedInfo_ZeroValues(t *testing.T) {
 	assert.Equal(t, aleoInfo.SignerID, "{ chunk_1: 0u128, chunk_2: 0u128 }")
 	assert.Equal(t, "0u128", aleoInfo.ProductID)
 }
+
+func TestPrepareSignerReportData(t *testing.T) {
+	signerPubKey := "aleo1fcmp6c3pgjqke2pe8hzspr3l5j0ejssj7c48jc56pk089s4jhqgqxlf7y5"
+	configHash := sha256.Sum256([]byte("config"))
+
+	reportData := PrepareSignerReportData(signerPubKey, configHash)
+	pubKeyHash := sha256.Sum256([]byte(signerPubKey))
+
+	assert.Len(t, reportData, sgx.SGXReportDataSize)
+	assert.Equal(t, pubKeyHash[:], reportData[:32])
+	assert.Equal(t, configHash[:], reportData[32:])
+}
+
+func TestGetSignerKeyBinding_SimulatedProvider(t *testing.T) {
+	require.NoError(t, sgx.InitQuoteProvider(sgx.QuoteProviderSimulated, true))
+	t.Cleanup(func() {
+		provider, _ := sgx.NewQuoteProvider(sgx.QuoteProviderGramine, false)
+		sgx.SetQuoteProvider(provider)
+	})
+
+	signerPubKey := "aleo1fcmp6c3pgjqke2pe8hzspr3l5j0ejssj7c48jc56pk089s4jhqgqxlf7y5"
+	binding, err := GetSignerKeyBinding(signerPubKey)
+	require.Nil(t, err)
+
+	configHash := configs.GetAppConfigHash()
+	assert.Equal(t, hex.EncodeToString(configHash[:]), binding.ConfigHash)
+
+	// The raw quote follows the 16-byte Open Enclave header; report data sits at the end of the report body.
+	quote, decodeErr := base64.StdEncoding.DecodeString(binding.Quote)
+	require.NoError(t, decodeErr)
+	reportDataOffset := 16 + sgx.QuoteHeaderSize + sgx.SGXReportBodySize - sgx.SGXReportDataSize
+	assert.Equal(t, PrepareSignerReportData(signerPubKey, configHash), quote[reportDataOffset:reportDataOffset+sgx.SGXReportDataSize])
+
+	// The binding is cached per public key.
+	cached, err := GetSignerKeyBinding(signerPubKey)
+	require.Nil(t, err)
+	assert.Equal(t, binding, cached)
+}
@@ -0,0 +1,238 @@
+// Package key_rotation rotates the Aleo signing key and produces attested handover records.
+package key_rotation
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	aleoUtil "github.com/venture23-aleo/aleo-oracle-notarization-backend/internal/aleoutil"
+	configs "github.com/venture23-aleo/aleo-oracle-notarization-backend/internal/config"
+	appErrors "github.com/venture23-aleo/aleo-oracle-notarization-backend/pkg/errors"
+	"github.com/venture23-aleo/aleo-oracle-notarization-backend/pkg/logger"
+	"github.com/venture23-aleo/aleo-oracle-notarization-backend/pkg/sgx"
+)
+
+// handoverFileName is the file the latest handover record is persisted to, next to the sealed signing key.
+const handoverFileName = "key_handover.json"
+
+// KeyHandoverRecord is the key handover signed by the old key together with an SGX quote over it.
+type KeyHandoverRecord struct {
+	aleoUtil.KeyHandover
+
+	// Base64 encoded DCAP quote wrapped as Open Enclave evidence. The report data is SHA256(message).
+	Quote string `json:"quote"`
+}
+
+// RotateSignerKeyRequest is the optional body of the admin request to rotate the signing key.
+type RotateSignerKeyRequest struct {
+	// Unix timestamp from which the new key signs attestations. The rotation takes effect immediately when zero.
+	EffectiveAt int64 `json:"effectiveAt,omitempty"`
+}
+
+var (
+	rotationMu       sync.Mutex         // Serializes key rotations.
+	handoverMu       sync.RWMutex       // Guards the latest handover record.
+	latestHandover   *KeyHandoverRecord // The latest handover record.
+	loadHandoverOnce sync.Once          // Loads the persisted handover record on first use.
+)
+
+// RotateSignerKey rotates the Aleo signing key at effectiveAt and returns the attested handover record.
+//
+// This function performs the following steps sequentially:
+// 1. Generates the new key in the Aleo context, which signs the handover message with the old key.
+// 2. Generates an SGX quote whose report data is the SHA-256 hash of the handover message, and persists the
+// record next to the sealed signing key. The rotation is only committed once both succeed, so a failure leaves
+// the old key in place.
+// 3. Stores the record as the latest handover.
+//
+// A zero effectiveAt rotates the key immediately. A future effectiveAt plans the rotation: the old key signs
+// alone until then. The old key keeps signing alongside the new key for the configured rotation overlap window
+// from effectiveAt.
+func RotateSignerKey(effectiveAt time.Time) (*KeyHandoverRecord, *appErrors.AppError) {
+	rotationMu.Lock()
+	defer rotationMu.Unlock()
+
+	now := time.Now()
+	if effectiveAt.IsZero() {
+		effectiveAt = now
+	}
+	if effectiveAt.Unix() < now.Unix() {
+		logger.Error("Key rotation effective time is in the past", "effectiveAt", effectiveAt)
+		return nil, appErrors.ErrInvalidRotationTime
+	}
+
+	aleoContext, err := aleoUtil.GetAleoContext()
+	if err != nil {
+		logger.Error("Error getting Aleo context: ", "error", err)
+		return nil, err
+	}
+
+	// Step 1 and 2: Rotate the key once the handover message is attested and the record persisted.
+	var quoteErr *appErrors.AppError
+	var record *KeyHandoverRecord
+	attestHandover := func(handover *aleoUtil.KeyHandover) error {
+		messageHash := sha256.Sum256([]byte(handover.Message))
+		var quote []byte
+		quote, quoteErr = sgx.GenerateQuote(messageHash[:])
+		if quoteErr != nil {
+			return quoteErr
+		}
+
+		record = &KeyHandoverRecord{KeyHandover: *handover, Quote: base64.StdEncoding.EncodeToString(quote)}
+		if path := handoverPath(); path != "" {
+			return writeKeyHandover(path, record)
+		}
+		return nil
+	}
+
+	overlap := configs.GetAppConfig().SigningKeyConfig.RotationOverlap
+	handover, rotateErr := aleoContext.RotateKey(effectiveAt, overlap, attestHandover)
+	if quoteErr != nil {
+		logger.Error("Failed to generate key handover quote, the signing key was not rotated", "error", quoteErr)
+		return nil, quoteErr
+	}
+	if errors.Is(rotateErr, aleoUtil.ErrKeyRotationPlanned) {
+		logger.Error("Key rotation already planned", "error", rotateErr)
+		return nil, appErrors.ErrKeyRotationPlanned.WithDetails(rotateErr.Error())
+	}
+	if rotateErr != nil {
+		logger.Error("Failed to rotate signing key", "error", rotateErr)
+		return nil, appErrors.ErrRotatingSigningKey.WithDetails(rotateErr.Error())
+	}
+
+	logger.Info("Key handover attested", "oldAddress", handover.OldAddress, "newAddress", handover.NewAddress, "effectiveAt", handover.EffectiveAt)
+
+	// Step 3: Store the latest handover.
+	handoverMu.Lock()
+	latestHandover = record
+	handoverMu.Unlock()
+
+	return record, nil
+}
+
+// GetLatestKeyHandover returns the latest key handover record, loaded from the sealed mount on first use so that
+// it survives restarts together with the sealed signing key.
+func GetLatestKeyHandover() (*KeyHandoverRecord, *appErrors.AppError) {
+	loadHandoverOnce.Do(func() {
+		path := handoverPath()
+		if path == "" {
+			return
+		}
+		record := restoreKeyHandover(path)
+
+		handoverMu.Lock()
+		defer handoverMu.Unlock()
+		if latestHandover == nil {
+			latestHandover = record
+		}
+	})
+
+	handoverMu.RLock()
+	defer handoverMu.RUnlock()
+
+	if latestHandover == nil {
+		return nil, appErrors.ErrNoKeyHandover
+	}
+	return latestHandover, nil
+}
+
+// handoverPath returns the path of the persisted handover record, empty when the signing key is not sealed.
+func handoverPath() string {
+	signingKeyConfig := configs.GetAppConfig().SigningKeyConfig
+	if !signingKeyConfig.Sealed {
+		return ""
+	}
+
+	keyPath, err := aleoUtil.GetSealedKeyPath(signingKeyConfig.SealingPolicy)
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(filepath.Dir(keyPath), handoverFileName)
+}
+
+// restoreKeyHandover loads the handover record persisted at path. A record that cannot be read, or whose new key
+// is neither the current nor the planned signing key, is logged and ignored.
+func restoreKeyHandover(path string) *KeyHandoverRecord {
+	record, err := loadKeyHandover(path)
+	if err != nil {
+		logger.Error("Failed to load the key handover record", "path", path, "error", err)
+		return nil
+	}
+	if record == nil {
+		return nil
+	}
+
+	aleoContext, appErr := aleoUtil.GetAleoContext()
+	if appErr != nil {
+		logger.Error("Error getting Aleo context: ", "error", appErr)
+		return nil
+	}
+
+	plannedKey, _ := aleoContext.GetPlannedPublicKey()
+	if record.NewAddress != aleoContext.GetPublicKey() && record.NewAddress != plannedKey {
+		logger.Warn("Ignoring a key handover record that does not match the signing key", "path", path, "newAddress", record.NewAddress)
+		return nil
+	}
+
+	logger.Info("Loaded the key handover record", "path", path, "newAddress", record.NewAddress, "effectiveAt", record.EffectiveAt)
+	return record
+}
+
+// loadKeyHandover reads the handover record from the file at path, nil when the file does not exist.
+func loadKeyHandover(path string) (*KeyHandoverRecord, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading key handover %s: %w", path, err)
+	}
+
+	var record KeyHandoverRecord
+	if err := json.Unmarshal(data, &record); err != nil {
+		return nil, fmt.Errorf("parsing key handover %s: %w", path, err)
+	}
+	return &record, nil
+}
+
+// writeKeyHandover writes the handover record to the file at path. The record is written to a temporary file in
+// the same directory and renamed over the previous record, so a crash mid-write never leaves a truncated record.
+func writeKeyHandover(path string, record *KeyHandoverRecord) error {
+	data, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("encoding key handover: %w", err)
+	}
+
+	dir := filepath.Dir(path)
+	file, err := os.CreateTemp(dir, ".key_handover-*")
+	if err != nil {
+		return fmt.Errorf("creating temporary key handover: %w", err)
+	}
+	tempPath := file.Name()
+	defer os.Remove(tempPath)
+
+	if _, err := file.Write(data); err != nil {
+		file.Close()
+		return fmt.Errorf("writing key handover %s: %w", tempPath, err)
+	}
+	if err := file.Sync(); err != nil {
+		file.Close()
+		return fmt.Errorf("syncing key handover %s: %w", tempPath, err)
+	}
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("closing key handover %s: %w", tempPath, err)
+	}
+
+	if err := os.Rename(tempPath, path); err != nil {
+		return fmt.Errorf("replacing key handover %s: %w", path, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,140 @@
+package key_rotation
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	aleoUtil "github.com/venture23-aleo/aleo-oracle-notarization-backend/internal/aleoutil"
+	appErrors "github.com/venture23-aleo/aleo-oracle-notarization-backend/pkg/errors"
+	"github.com/venture23-aleo/aleo-oracle-notarization-backend/pkg/logger"
+	"github.com/venture23-aleo/aleo-oracle-notarization-backend/pkg/sgx"
+)
+
+// TestMain initializes the logger for all tests in this package
+func TestMain(m *testing.M) {
+	logger.InitLogger("DEBUG")
+	m.Run()
+}
+
+func TestRotateSignerKey(t *testing.T) {
+	_, err := GetLatestKeyHandover()
+	assert.Equal(t, appErrors.ErrNoKeyHandover, err)
+
+	require.NoError(t, sgx.InitQuoteProvider(sgx.QuoteProviderSimulated, true))
+	t.Cleanup(func() {
+		provider, _ := sgx.NewQuoteProvider(sgx.QuoteProviderGramine, false)
+		sgx.SetQuoteProvider(provider)
+	})
+
+	aleoContext, err := aleoUtil.GetAleoContext()
+	require.Nil(t, err)
+	oldAddress := aleoContext.GetPublicKey()
+
+	record, err := RotateSignerKey(time.Time{})
+	require.Nil(t, err)
+
+	assert.Equal(t, oldAddress, record.OldAddress)
+	assert.Equal(t, aleoContext.GetPublicKey(), record.NewAddress)
+	assert.NotEmpty(t, record.Signature)
+
+	// The quote report data commits to the handover message.
+	quote, decodeErr := base64.StdEncoding.DecodeString(record.Quote)
+	require.NoError(t, decodeErr)
+	messageHash := sha256.Sum256([]byte(record.Message))
+	reportDataOffset := 16 + sgx.QuoteHeaderSize + sgx.SGXReportBodySize - sgx.SGXReportDataSize
+	assert.Equal(t, messageHash[:], quote[reportDataOffset:reportDataOffset+32])
+
+	latest, err := GetLatestKeyHandover()
+	require.Nil(t, err)
+	assert.Equal(t, record, latest)
+}
+
+func TestRotateSignerKey_QuoteFailed(t *testing.T) {
+	// The Gramine quote provider fails outside of an enclave.
+	provider, providerErr := sgx.NewQuoteProvider(sgx.QuoteProviderGramine, false)
+	require.NoError(t, providerErr)
+	sgx.SetQuoteProvider(provider)
+
+	aleoContext, err := aleoUtil.GetAleoContext()
+	require.Nil(t, err)
+	oldAddress := aleoContext.GetPublicKey()
+	latestBefore, _ := GetLatestKeyHandover()
+
+	record, err := RotateSignerKey(time.Time{})
+	require.NotNil(t, err)
+	assert.Nil(t, record)
+
+	// The signing key was not rotated and no handover was recorded.
+	assert.Equal(t, oldAddress, aleoContext.GetPublicKey())
+	latestAfter, _ := GetLatestKeyHandover()
+	assert.Equal(t, latestBefore, latestAfter)
+}
+
+func TestRotateSignerKey_Planned(t *testing.T) {
+	require.NoError(t, sgx.InitQuoteProvider(sgx.QuoteProviderSimulated, true))
+	t.Cleanup(func() {
+		provider, _ := sgx.NewQuoteProvider(sgx.QuoteProviderGramine, false)
+		sgx.SetQuoteProvider(provider)
+	})
+
+	_, err := RotateSignerKey(time.Now().Add(-time.Minute))
+	assert.Equal(t, appErrors.ErrInvalidRotationTime, err)
+
+	aleoContext, err := aleoUtil.GetAleoContext()
+	require.Nil(t, err)
+	oldAddress := aleoContext.GetPublicKey()
+
+	effectiveAt := time.Now().Add(time.Hour)
+	record, err := RotateSignerKey(effectiveAt)
+	require.Nil(t, err)
+	assert.Equal(t, effectiveAt.Unix(), record.EffectiveAt)
+
+	// The old key keeps signing until the planned effective time.
+	assert.Equal(t, oldAddress, aleoContext.GetPublicKey())
+	plannedKey, _ := aleoContext.GetPlannedPublicKey()
+	assert.Equal(t, record.NewAddress, plannedKey)
+
+	_, err = RotateSignerKey(time.Time{})
+	require.NotNil(t, err)
+	assert.Equal(t, appErrors.ErrKeyRotationPlanned.Code, err.Code)
+
+	latest, err := GetLatestKeyHandover()
+	require.Nil(t, err)
+	assert.Equal(t, record, latest)
+}
+
+func TestKeyHandoverPersistence(t *testing.T) {
+	path := filepath.Join(t.TempDir(), handoverFileName)
+
+	record, err := loadKeyHandover(path)
+	require.NoError(t, err)
+	assert.Nil(t, record)
+
+	aleoContext, appErr := aleoUtil.GetAleoContext()
+	require.Nil(t, appErr)
+	written := &KeyHandoverRecord{
+		KeyHandover: aleoUtil.KeyHandover{OldAddress: "aleo1old", NewAddress: aleoContext.GetPublicKey(), EffectiveAt: 1754278324, OverlapUntil: 1754364724},
+		Quote:       "AQAAAA==",
+	}
+	require.NoError(t, writeKeyHandover(path, written))
+
+	record, err = loadKeyHandover(path)
+	require.NoError(t, err)
+	assert.Equal(t, written, record)
+	assert.Equal(t, written, restoreKeyHandover(path))
+
+	// A record for a key the signer does not hold is ignored.
+	written.NewAddress = "aleo1unknown"
+	require.NoError(t, writeKeyHandover(path, written))
+	assert.Nil(t, restoreKeyHandover(path))
+
+	// A record that cannot be parsed is ignored.
+	require.NoError(t, os.WriteFile(path, []byte("not json"), 0600))
+	assert.Nil(t, restoreKeyHandover(path))
+}
@@ -0,0 +1,449 @@
+// Package scheduler attests the price feed of the scheduled tokens when their price deviates from the last
+// published attestation or when their heartbeat elapses, and pushes the attestations to the configured sinks.
+package scheduler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	encoding "github.com/venture23-aleo/aleo-oracle-encoding"
+	configs "github.com/venture23-aleo/aleo-oracle-notarization-backend/internal/config"
+	"github.com/venture23-aleo/aleo-oracle-notarization-backend/internal/constants"
+	"github.com/venture23-aleo/aleo-oracle-notarization-backend/internal/metrics"
+	"github.com/venture23-aleo/aleo-oracle-notarization-backend/internal/services/attestation"
+	data_extraction "github.com/venture23-aleo/aleo-oracle-notarization-backend/internal/services/dataextraction"
+	appErrors "github.com/venture23-aleo/aleo-oracle-notarization-backend/pkg/errors"
+	"github.com/venture23-aleo/aleo-oracle-notarization-backend/pkg/logger"
+)
+
+// Triggers of a scheduled attestation
+const (
+	TriggerInitial   = "initial"   // The token has no published attestation yet.
+	TriggerHeartbeat = "heartbeat" // The heartbeat elapsed since the last published attestation.
+	TriggerDeviation = "deviation" // The price deviates from the last published price beyond the threshold.
+)
+
+// deviationPrecision is the number of decimals of the deviation percentages reported by the scheduler.
+const deviationPrecision = 4
+
+// activeScheduler is the running attestation scheduler serving the latest scheduled attestations, nil when the
+// scheduler is stopped.
+var activeScheduler atomic.Pointer[Scheduler]
+
+// AttestFunc generates the attestation report, with its SGX quote and oracle signature, of a normalized and
+// validated attestation request.
+type AttestFunc func(ctx context.Context, attestationRequest attestation.AttestationRequest) (*attestation.AttestationResponse, *appErrors.AppError)
+
+// ScheduledAttestation is an attestation of the price feed of a token published by the scheduler.
+type ScheduledAttestation struct {
+	Token            string                           `json:"token"`                      // Token.
+	Trigger          string                           `json:"trigger"`                    // Trigger: initial, heartbeat or deviation.
+	Price            string                           `json:"price"`                      // Attested price.
+	DeviationPercent string                           `json:"deviationPercent,omitempty"` // Deviation from the previous published price.
+	Attestation      *attestation.AttestationResponse `json:"attestation"`                // Attestation report.
+}
+
+// scheduledToken is a scheduled token with the attestation request of its price feed.
+type scheduledToken struct {
+	config  configs.ScheduledTokenConfig
+	request attestation.AttestationRequest
+}
+
+// priceFetcher returns the current price feed of a token.
+type priceFetcher interface {
+	GetPriceFeed(ctx context.Context, tokenName string, timestamp int64, precision uint) (*data_extraction.PriceFeedResult, *appErrors.AppError)
+}
+
+// Scheduler checks the price of its tokens at a fixed cadence and publishes an attestation of a token when one of
+// its triggers fires.
+type Scheduler struct {
+	client    priceFetcher
+	attest    AttestFunc
+	config    configs.AttestationSchedulerConfig
+	tokens    []scheduledToken
+	sinks     []Sink
+	now       func() time.Time
+	stateFile string
+	latestMu  sync.RWMutex
+	latest    map[string]*ScheduledAttestation
+	stopChan  chan struct{}
+	mu        sync.Mutex
+	started   bool
+}
+
+// NewScheduler creates an attestation scheduler for the tokens and sinks of the config. The attestations are
+// generated with attest. The latest scheduled attestations are loaded from the state file, so a restart keeps the
+// heartbeat and deviation of every token relative to its last published attestation.
+func NewScheduler(config configs.AttestationSchedulerConfig, attest AttestFunc) *Scheduler {
+	tokens := make([]scheduledToken, 0, len(config.Tokens))
+	for _, tokenConfig := range config.Tokens {
+		tokens = append(tokens, scheduledToken{config: tokenConfig, request: priceFeedRequest(tokenConfig)})
+	}
+	sort.Slice(tokens, func(i, j int) bool { return tokens[i].config.Token < tokens[j].config.Token })
+
+	sinks := make([]Sink, 0, len(config.Sinks))
+	for _, sinkConfig := range config.Sinks {
+		sinks = append(sinks, NewSink(sinkConfig))
+	}
+
+	stateFile := data_extraction.ResolveStateFile(config.StateFile)
+	return &Scheduler{
+		client:    data_extraction.NewPriceFeedClient(),
+		attest:    attest,
+		config:    config,
+		tokens:    tokens,
+		sinks:     sinks,
+		now:       time.Now,
+		stateFile: stateFile,
+		latest:    restoreLatest(stateFile, tokens),
+		stopChan:  make(chan struct{}),
+	}
+}
+
+// CheckStateFile checks at startup that the state file of the scheduler can be written, so that the latest
+// scheduled attestations are not silently lost on restart. It does nothing when they are kept in memory.
+func CheckStateFile(config configs.AttestationSchedulerConfig) error {
+	stateFile := data_extraction.ResolveStateFile(config.StateFile)
+	if stateFile == "" {
+		if config.StateFile != "" {
+			logger.Warn("Attestation scheduler state file is inside the Gramine encrypted mounts and not used outside an enclave, the latest scheduled attestations are kept in memory", "path", config.StateFile)
+		}
+		return nil
+	}
+	return data_extraction.CheckStateFileWritable(stateFile)
+}
+
+// restoreLatest loads the latest scheduled attestations persisted to the state file. The attestations of tokens
+// that are no longer scheduled are dropped. A state file that cannot be read is logged and the scheduler starts
+// empty, so every token is attested again on the first check.
+func restoreLatest(stateFile string, tokens []scheduledToken) map[string]*ScheduledAttestation {
+	latest := make(map[string]*ScheduledAttestation)
+	if stateFile == "" {
+		return latest
+	}
+
+	persisted, err := loadScheduledAttestations(stateFile)
+	if err != nil {
+		logger.Error("Failed to load the latest scheduled attestations", "path", stateFile, "error", err)
+		return latest
+	}
+
+	for _, token := range tokens {
+		scheduled := persisted[token.config.Token]
+		if scheduled == nil || scheduled.Attestation == nil || scheduled.Token != token.config.Token {
+			continue
+		}
+		latest[token.config.Token] = scheduled
+	}
+	if persisted != nil {
+		logger.Info("Loaded the latest scheduled attestations", "path", stateFile, "tokens", len(latest))
+	}
+	return latest
+}
+
+// loadScheduledAttestations reads the latest scheduled attestations from the state file at path, nil when the file
+// does not exist.
+func loadScheduledAttestations(path string) (map[string]*ScheduledAttestation, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading scheduled attestations %s: %w", path, err)
+	}
+
+	var latest map[string]*ScheduledAttestation
+	if err := json.Unmarshal(data, &latest); err != nil {
+		return nil, fmt.Errorf("parsing scheduled attestations %s: %w", path, err)
+	}
+	return latest, nil
+}
+
+// writeScheduledAttestations writes the latest scheduled attestations to the state file at path. They are written
+// to a temporary file in the same directory, synced and renamed over the state file, so a crash mid-write never
+// leaves a truncated state file that would attest every token again on the next start.
+func writeScheduledAttestations(path string, latest map[string]*ScheduledAttestation) error {
+	data, err := json.Marshal(latest)
+	if err != nil {
+		return fmt.Errorf("encoding scheduled attestations: %w", err)
+	}
+
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return fmt.Errorf("creating scheduled attestations directory: %w", err)
+	}
+
+	file, err := os.CreateTemp(dir, ".scheduled_attestations-*")
+	if err != nil {
+		return fmt.Errorf("creating temporary scheduled attestations: %w", err)
+	}
+	tempPath := file.Name()
+	defer os.Remove(tempPath)
+
+	if _, err := file.Write(data); err != nil {
+		file.Close()
+		return fmt.Errorf("writing scheduled attestations %s: %w", tempPath, err)
+	}
+	if err := file.Sync(); err != nil {
+		file.Close()
+		return fmt.Errorf("syncing scheduled attestations %s: %w", tempPath, err)
+	}
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("closing scheduled attestations %s: %w", tempPath, err)
+	}
+
+	if err := os.Rename(tempPath, path); err != nil {
+		return fmt.Errorf("replacing scheduled attestations %s: %w", path, err)
+	}
+
+	return nil
+}
+
+// priceFeedRequest returns the normalized attestation request of the price feed of a scheduled token, like a
+// "price_feed: btc" request to /notarize.
+func priceFeedRequest(tokenConfig configs.ScheduledTokenConfig) attestation.AttestationRequest {
+	request := attestation.AttestationRequest{
+		Url:            constants.PriceFeedURLPrefix + strings.ToLower(tokenConfig.Token),
+		RequestMethod:  constants.RequestMethodGET,
+		Selector:       constants.PriceFeedSelector,
+		ResponseFormat: constants.ResponseFormatJSON,
+		EncodingOptions: encoding.EncodingOptions{
+			Value:     constants.EncodingOptionFloat,
+			Precision: tokenConfig.Precision,
+		},
+	}
+	return request.Normalize()
+}
+
+// Start begins checking the tokens and serves the latest scheduled attestations from this scheduler.
+func (s *Scheduler) Start() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.started {
+		logger.Warn("Attestation scheduler already started")
+		return
+	}
+	logger.Info("Starting attestation scheduler", "tokens", len(s.tokens), "sinks", len(s.sinks), "checkInterval", s.config.CheckInterval)
+	// Reinitialize stop channel in case of restart after Stop.
+	s.stopChan = make(chan struct{})
+	s.started = true
+	activeScheduler.Store(s)
+	go s.run()
+}
+
+// Stop stops checking the tokens. The latest scheduled attestations are unavailable until a scheduler is started
+// again.
+func (s *Scheduler) Stop() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.started {
+		logger.Warn("Attestation scheduler not running")
+		return
+	}
+	logger.Info("Stopping attestation scheduler")
+	select {
+	case <-s.stopChan:
+		// already closed
+	default:
+		close(s.stopChan)
+	}
+	s.started = false
+	activeScheduler.CompareAndSwap(s, nil)
+}
+
+// run checks the tokens right away and then at every check interval.
+func (s *Scheduler) run() {
+	ticker := time.NewTicker(s.config.CheckInterval)
+	defer ticker.Stop()
+
+	s.checkAll()
+	for {
+		select {
+		case <-ticker.C:
+			s.checkAll()
+		case <-s.stopChan:
+			return
+		}
+	}
+}
+
+// checkAll checks the tokens concurrently and waits for their attestations and deliveries.
+func (s *Scheduler) checkAll() {
+	var wg sync.WaitGroup
+	for _, token := range s.tokens {
+		wg.Add(1)
+		go func(token scheduledToken) {
+			defer wg.Done()
+			s.check(context.Background(), token)
+		}(token)
+	}
+	wg.Wait()
+}
+
+// check publishes an attestation of a token when one of its triggers fires. A failed attestation is retried at the
+// next check.
+func (s *Scheduler) check(ctx context.Context, token scheduledToken) {
+	tokenSymbol := token.config.Token
+	last := s.Latest(tokenSymbol)
+
+	trigger, deviation, ok := s.trigger(ctx, token, last)
+	if !ok {
+		return
+	}
+
+	if err := token.request.Validate(); err != nil {
+		logger.Error("Invalid scheduled attestation request", "token", tokenSymbol, "error", err)
+		metrics.RecordScheduledAttestation(tokenSymbol, trigger, "failed")
+		return
+	}
+
+	response, err := s.attest(ctx, token.request)
+	if err != nil {
+		logger.Error("Scheduled attestation failed", "token", tokenSymbol, "trigger", trigger, "error", err)
+		metrics.RecordScheduledAttestation(tokenSymbol, trigger, "failed")
+		return
+	}
+
+	scheduled := &ScheduledAttestation{
+		Token:            tokenSymbol,
+		Trigger:          trigger,
+		Price:            response.AttestationData,
+		DeviationPercent: deviation,
+		Attestation:      response,
+	}
+	s.record(scheduled)
+	metrics.RecordScheduledAttestation(tokenSymbol, trigger, "success")
+	logger.Info("Scheduled attestation published", "token", tokenSymbol, "trigger", trigger, "price", scheduled.Price, "deviationPercent", deviation, "timestamp", response.AttestationTimestamp)
+
+	s.deliver(scheduled)
+}
+
+// trigger returns the trigger firing for a token and the deviation of its price from the last published price.
+// The heartbeat is checked before the deviation, so the price is only fetched when the heartbeat has not elapsed.
+func (s *Scheduler) trigger(ctx context.Context, token scheduledToken, last *ScheduledAttestation) (string, string, bool) {
+	if last == nil {
+		return TriggerInitial, "", true
+	}
+
+	now := s.now()
+	if token.config.Heartbeat > 0 && now.Sub(time.Unix(last.Attestation.AttestationTimestamp, 0)) >= token.config.Heartbeat {
+		return TriggerHeartbeat, "", true
+	}
+
+	if token.config.DeviationPercent <= 0 {
+		return "", "", false
+	}
+
+	result, err := s.client.GetPriceFeed(ctx, token.config.Token, now.Unix(), token.request.EncodingOptions.Precision)
+	if err != nil {
+		logger.Warn("Scheduled price check failed", "token", token.config.Token, "error", err)
+		return "", "", false
+	}
+
+	deviation, ok := deviationPercent(last.Price, result.VolumeWeightedAvg)
+	if !ok {
+		logger.Warn("Scheduled price check has an invalid price", "token", token.config.Token, "lastPrice", last.Price, "price", result.VolumeWeightedAvg)
+		return "", "", false
+	}
+	if deviation.Cmp(new(big.Rat).SetFloat64(token.config.DeviationPercent)) <= 0 {
+		return "", "", false
+	}
+	return TriggerDeviation, data_extraction.Truncate(deviation, deviationPrecision), true
+}
+
+// deviationPercent returns the absolute change of price from lastPrice in percent.
+func deviationPercent(lastPrice string, price string) (*big.Rat, bool) {
+	lastRat, ok := new(big.Rat).SetString(lastPrice)
+	if !ok || lastRat.Sign() <= 0 {
+		return nil, false
+	}
+	priceRat, ok := new(big.Rat).SetString(price)
+	if !ok {
+		return nil, false
+	}
+
+	deviation := new(big.Rat).Sub(priceRat, lastRat)
+	deviation.Abs(deviation)
+	deviation.Mul(deviation, big.NewRat(100, 1))
+	return deviation.Quo(deviation, lastRat), true
+}
+
+// record stores the latest scheduled attestation of a token and persists the latest scheduled attestations. The
+// state file is checked for writes at startup, so a failure to persist is only logged.
+func (s *Scheduler) record(scheduled *ScheduledAttestation) {
+	s.latestMu.Lock()
+	defer s.latestMu.Unlock()
+	s.latest[scheduled.Token] = scheduled
+
+	if s.stateFile == "" {
+		return
+	}
+	if err := writeScheduledAttestations(s.stateFile, s.latest); err != nil {
+		logger.Error("Failed to persist the latest scheduled attestations", "path", s.stateFile, "error", err)
+	}
+}
+
+// deliver pushes a scheduled attestation to every sink. A failed delivery is logged and not retried.
+func (s *Scheduler) deliver(scheduled *ScheduledAttestation) {
+	for _, sink := range s.sinks {
+		if err := sink.Push(context.Background(), scheduled); err != nil {
+			logger.Error("Failed to push scheduled attestation", "token", scheduled.Token, "sink", sink.Name(), "error", err)
+			metrics.RecordAttestationSinkDelivery(sink.Name(), "failed")
+			continue
+		}
+		metrics.RecordAttestationSinkDelivery(sink.Name(), "success")
+	}
+}
+
+// Latest returns the latest scheduled attestation of a token, nil when none has been published.
+func (s *Scheduler) Latest(token string) *ScheduledAttestation {
+	s.latestMu.RLock()
+	defer s.latestMu.RUnlock()
+	return s.latest[token]
+}
+
+// LatestAll returns the latest scheduled attestation of every token with one, sorted by token.
+func (s *Scheduler) LatestAll() []*ScheduledAttestation {
+	s.latestMu.RLock()
+	defer s.latestMu.RUnlock()
+
+	latest := make([]*ScheduledAttestation, 0, len(s.latest))
+	for _, scheduled := range s.latest {
+		latest = append(latest, scheduled)
+	}
+	sort.Slice(latest, func(i, j int) bool { return latest[i].Token < latest[j].Token })
+	return latest
+}
+
+// GetLatestScheduledAttestation returns the latest attestation of a token published by the running scheduler.
+func GetLatestScheduledAttestation(token string) (*ScheduledAttestation, *appErrors.AppError) {
+	scheduler := activeScheduler.Load()
+	if scheduler == nil {
+		return nil, appErrors.ErrSchedulerDisabled
+	}
+
+	scheduled := scheduler.Latest(token)
+	if scheduled == nil {
+		return nil, appErrors.ErrNoScheduledAttestation
+	}
+	return scheduled, nil
+}
+
+// GetLatestScheduledAttestations returns the latest attestation of every token published by the running scheduler.
+func GetLatestScheduledAttestations() ([]*ScheduledAttestation, *appErrors.AppError) {
+	scheduler := activeScheduler.Load()
+	if scheduler == nil {
+		return nil, appErrors.ErrSchedulerDisabled
+	}
+	return scheduler.LatestAll(), nil
+}
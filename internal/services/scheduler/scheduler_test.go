@@ -0,0 +1,255 @@
+package scheduler
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	configs "github.com/venture23-aleo/aleo-oracle-notarization-backend/internal/config"
+	"github.com/venture23-aleo/aleo-oracle-notarization-backend/internal/services/attestation"
+	data_extraction "github.com/venture23-aleo/aleo-oracle-notarization-backend/internal/services/dataextraction"
+	appErrors "github.com/venture23-aleo/aleo-oracle-notarization-backend/pkg/errors"
+)
+
+// stubPriceFetcher returns a fixed price feed and counts the requests.
+type stubPriceFetcher struct {
+	price    string
+	err      *appErrors.AppError
+	requests int
+}
+
+func (f *stubPriceFetcher) GetPriceFeed(ctx context.Context, tokenName string, timestamp int64, precision uint) (*data_extraction.PriceFeedResult, *appErrors.AppError) {
+	f.requests++
+	if f.err != nil {
+		return nil, f.err
+	}
+	return &data_extraction.PriceFeedResult{Token: tokenName, VolumeWeightedAvg: f.price, Timestamp: timestamp, Success: true}, nil
+}
+
+func newTestScheduler(fetcher priceFetcher, attest AttestFunc, now time.Time, sinks ...Sink) *Scheduler {
+	return &Scheduler{
+		client: fetcher,
+		attest: attest,
+		sinks:  sinks,
+		now:    func() time.Time { return now },
+		latest: make(map[string]*ScheduledAttestation),
+	}
+}
+
+func TestSchedulerTrigger(t *testing.T) {
+	now := time.Unix(1700003600, 0)
+	last := &ScheduledAttestation{
+		Token:       "BTC",
+		Price:       "100",
+		Attestation: &attestation.AttestationResponse{AttestationTimestamp: 1700000000, AttestationData: "100"},
+	}
+	token := scheduledToken{config: configs.ScheduledTokenConfig{Token: "BTC", DeviationPercent: 0.5, Heartbeat: 2 * time.Hour}}
+
+	tests := []struct {
+		name              string
+		token             scheduledToken
+		last              *ScheduledAttestation
+		fetcher           *stubPriceFetcher
+		expectedTrigger   string
+		expectedDeviation string
+		expectedFire      bool
+		expectedRequests  int
+	}{
+		{
+			name:            "no published attestation",
+			token:           token,
+			fetcher:         &stubPriceFetcher{price: "100"},
+			expectedTrigger: TriggerInitial,
+			expectedFire:    true,
+		},
+		{
+			name:            "heartbeat elapsed",
+			token:           scheduledToken{config: configs.ScheduledTokenConfig{Token: "BTC", DeviationPercent: 0.5, Heartbeat: time.Hour}},
+			last:            last,
+			fetcher:         &stubPriceFetcher{price: "100"},
+			expectedTrigger: TriggerHeartbeat,
+			expectedFire:    true,
+		},
+		{
+			name:              "deviation above the threshold",
+			token:             token,
+			last:              last,
+			fetcher:           &stubPriceFetcher{price: "99.4"},
+			expectedTrigger:   TriggerDeviation,
+			expectedDeviation: "0.6000",
+			expectedFire:      true,
+			expectedRequests:  1,
+		},
+		{
+			name:             "deviation at the threshold",
+			token:            token,
+			last:             last,
+			fetcher:          &stubPriceFetcher{price: "100.5"},
+			expectedRequests: 1,
+		},
+		{
+			name:             "price check failed",
+			token:            token,
+			last:             last,
+			fetcher:          &stubPriceFetcher{err: appErrors.ErrInsufficientExchangeData},
+			expectedRequests: 1,
+		},
+		{
+			name:    "heartbeat only",
+			token:   scheduledToken{config: configs.ScheduledTokenConfig{Token: "BTC", Heartbeat: 2 * time.Hour}},
+			last:    last,
+			fetcher: &stubPriceFetcher{price: "200"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scheduler := newTestScheduler(tt.fetcher, nil, now)
+			trigger, deviation, fire := scheduler.trigger(context.Background(), tt.token, tt.last)
+			assert.Equal(t, tt.expectedFire, fire)
+			assert.Equal(t, tt.expectedTrigger, trigger)
+			assert.Equal(t, tt.expectedDeviation, deviation)
+			assert.Equal(t, tt.expectedRequests, tt.fetcher.requests)
+		})
+	}
+}
+
+func TestSchedulerCheck(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "attestations", "btc.jsonl")
+	now := time.Unix(1700000000, 0)
+
+	var attestErr *appErrors.AppError
+	attested := 0
+	attest := func(ctx context.Context, attestationRequest attestation.AttestationRequest) (*attestation.AttestationResponse, *appErrors.AppError) {
+		if attestErr != nil {
+			return nil, attestErr
+		}
+		attested++
+		return &attestation.AttestationResponse{
+			AttestationRequest:   attestationRequest,
+			AttestationTimestamp: now.Unix(),
+			AttestationData:      "50000.000000",
+		}, nil
+	}
+
+	fetcher := &stubPriceFetcher{price: "50000.000000"}
+	scheduler := newTestScheduler(fetcher, attest, now, NewSink(configs.AttestationSinkConfig{Type: configs.SinkTypeFile, Path: path}))
+	token := scheduledToken{
+		config:  configs.ScheduledTokenConfig{Token: "BTC", DeviationPercent: 1},
+		request: priceFeedRequest(configs.ScheduledTokenConfig{Token: "BTC", Precision: 6}),
+	}
+	assert.Equal(t, "price_feed: btc", token.request.Url)
+
+	_, err := GetLatestScheduledAttestation("BTC")
+	assert.Equal(t, appErrors.ErrSchedulerDisabled, err)
+	activeScheduler.Store(scheduler)
+	t.Cleanup(func() { activeScheduler.CompareAndSwap(scheduler, nil) })
+
+	_, err = GetLatestScheduledAttestation("BTC")
+	assert.Equal(t, appErrors.ErrNoScheduledAttestation, err)
+
+	// A failed attestation is not published.
+	attestErr = appErrors.ErrGeneratingQuote
+	scheduler.check(context.Background(), token)
+	assert.Nil(t, scheduler.Latest("BTC"))
+	attestErr = nil
+
+	scheduler.check(context.Background(), token)
+	latest, err := GetLatestScheduledAttestation("BTC")
+	require.Nil(t, err)
+	assert.Equal(t, TriggerInitial, latest.Trigger)
+	assert.Equal(t, "50000.000000", latest.Price)
+	assert.Equal(t, uint(6), latest.Attestation.AttestationRequest.EncodingOptions.Precision)
+
+	// The price did not deviate, nothing is published.
+	scheduler.check(context.Background(), token)
+	assert.Equal(t, 1, attested)
+
+	fetcher.price = "50600"
+	scheduler.check(context.Background(), token)
+	assert.Equal(t, 2, attested)
+	latest = scheduler.Latest("BTC")
+	assert.Equal(t, TriggerDeviation, latest.Trigger)
+	assert.Equal(t, "1.2000", latest.DeviationPercent)
+
+	all, err := GetLatestScheduledAttestations()
+	require.Nil(t, err)
+	assert.Equal(t, []*ScheduledAttestation{latest}, all)
+
+	file, openErr := os.Open(path)
+	require.NoError(t, openErr)
+	defer file.Close()
+
+	var triggers []string
+	lines := bufio.NewScanner(file)
+	for lines.Scan() {
+		var scheduled ScheduledAttestation
+		require.NoError(t, json.Unmarshal(lines.Bytes(), &scheduled))
+		triggers = append(triggers, scheduled.Trigger)
+	}
+	assert.Equal(t, []string{TriggerInitial, TriggerDeviation}, triggers)
+}
+
+func TestSchedulerStateFile(t *testing.T) {
+	stateFile := filepath.Join(t.TempDir(), "mrsigner", "scheduled_attestations.json")
+	now := time.Unix(1700000000, 0)
+	btc := scheduledToken{config: configs.ScheduledTokenConfig{Token: "BTC", DeviationPercent: 1, Heartbeat: time.Hour}}
+	eth := scheduledToken{config: configs.ScheduledTokenConfig{Token: "ETH", DeviationPercent: 1}}
+
+	fetcher := &stubPriceFetcher{price: "50100"}
+	scheduler := newTestScheduler(fetcher, nil, now)
+	scheduler.stateFile = stateFile
+	scheduler.record(&ScheduledAttestation{
+		Token:       "BTC",
+		Trigger:     TriggerInitial,
+		Price:       "50000.000000",
+		Attestation: &attestation.AttestationResponse{AttestationTimestamp: now.Add(-time.Minute).Unix(), AttestationData: "50000.000000"},
+	})
+	scheduler.record(&ScheduledAttestation{
+		Token:       "ETH",
+		Trigger:     TriggerInitial,
+		Price:       "3000.000000",
+		Attestation: &attestation.AttestationResponse{AttestationTimestamp: now.Unix(), AttestationData: "3000.000000"},
+	})
+
+	t.Run("restart", func(t *testing.T) {
+		// ETH is no longer scheduled, its attestation is dropped.
+		restarted := newTestScheduler(fetcher, nil, now)
+		restarted.latest = restoreLatest(stateFile, []scheduledToken{btc})
+		assert.Nil(t, restarted.Latest("ETH"))
+
+		last := restarted.Latest("BTC")
+		require.NotNil(t, last)
+		assert.Equal(t, "50000.000000", last.Price)
+
+		// The deviation is checked against the last published price, no initial attestation is published.
+		_, _, ok := restarted.trigger(context.Background(), btc, last)
+		assert.False(t, ok)
+
+		fetcher.price = "50600"
+		trigger, deviation, ok := restarted.trigger(context.Background(), btc, last)
+		assert.True(t, ok)
+		assert.Equal(t, TriggerDeviation, trigger)
+		assert.Equal(t, "1.2000", deviation)
+	})
+
+	t.Run("missing state file", func(t *testing.T) {
+		assert.Empty(t, restoreLatest(filepath.Join(t.TempDir(), "scheduled_attestations.json"), []scheduledToken{btc, eth}))
+	})
+
+	t.Run("unreadable state file", func(t *testing.T) {
+		unreadable := filepath.Join(t.TempDir(), "scheduled_attestations.json")
+		require.NoError(t, os.WriteFile(unreadable, []byte("not json"), 0600))
+		assert.Empty(t, restoreLatest(unreadable, []scheduledToken{btc, eth}))
+	})
+
+	t.Run("in memory", func(t *testing.T) {
+		assert.Empty(t, restoreLatest("", []scheduledToken{btc, eth}))
+	})
+}
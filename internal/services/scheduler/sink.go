@@ -0,0 +1,104 @@
+package scheduler
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+
+	configs "github.com/venture23-aleo/aleo-oracle-notarization-backend/internal/config"
+)
+
+// Sink is a destination the scheduled attestations are pushed to.
+type Sink interface {
+	// Name returns the name of the sink in the logs and metrics.
+	Name() string
+	// Push delivers a scheduled attestation.
+	Push(ctx context.Context, scheduled *ScheduledAttestation) error
+}
+
+// NewSink creates the sink of a validated sink config.
+func NewSink(config configs.AttestationSinkConfig) Sink {
+	if config.Type == configs.SinkTypeFile {
+		return &fileSink{path: config.Path}
+	}
+	return &webhookSink{url: config.URL, client: &http.Client{Timeout: config.Timeout}}
+}
+
+// webhookSink posts every scheduled attestation as JSON to a URL.
+type webhookSink struct {
+	url    string
+	client *http.Client
+}
+
+func (s *webhookSink) Name() string {
+	return configs.SinkTypeWebhook
+}
+
+// Push posts the scheduled attestation. Any status code other than 2xx fails the delivery.
+func (s *webhookSink) Push(ctx context.Context, scheduled *ScheduledAttestation) error {
+	body, err := json.Marshal(scheduled)
+	if err != nil {
+		return fmt.Errorf("encoding scheduled attestation: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("creating webhook request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("posting to webhook: %w", err)
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("webhook responded with status code %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// fileSink appends every scheduled attestation as a JSON line to a local file.
+type fileSink struct {
+	path string
+	mu   sync.Mutex
+}
+
+func (s *fileSink) Name() string {
+	return configs.SinkTypeFile
+}
+
+// Push appends the scheduled attestation to the file, creating the file and its directory when missing.
+func (s *fileSink) Push(_ context.Context, scheduled *ScheduledAttestation) error {
+	line, err := json.Marshal(scheduled)
+	if err != nil {
+		return fmt.Errorf("encoding scheduled attestation: %w", err)
+	}
+	line = append(line, '\n')
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := os.MkdirAll(filepath.Dir(s.path), 0700); err != nil {
+		return fmt.Errorf("creating attestation file directory: %w", err)
+	}
+
+	file, err := os.OpenFile(s.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return fmt.Errorf("opening attestation file %s: %w", s.path, err)
+	}
+	defer file.Close()
+
+	if _, err := file.Write(line); err != nil {
+		return fmt.Errorf("writing attestation file %s: %w", s.path, err)
+	}
+	return nil
+}
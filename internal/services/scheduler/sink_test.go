@@ -0,0 +1,71 @@
+package scheduler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	configs "github.com/venture23-aleo/aleo-oracle-notarization-backend/internal/config"
+	"github.com/venture23-aleo/aleo-oracle-notarization-backend/internal/services/attestation"
+)
+
+func TestWebhookSink(t *testing.T) {
+	scheduled := &ScheduledAttestation{
+		Token:       "BTC",
+		Trigger:     TriggerHeartbeat,
+		Price:       "50000.000000",
+		Attestation: &attestation.AttestationResponse{AttestationTimestamp: 1700000000, AttestationData: "50000.000000"},
+	}
+
+	var received ScheduledAttestation
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/failing" {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&received))
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	sink := NewSink(configs.AttestationSinkConfig{Type: configs.SinkTypeWebhook, URL: server.URL + "/hook", Timeout: time.Second})
+	assert.Equal(t, configs.SinkTypeWebhook, sink.Name())
+	require.NoError(t, sink.Push(context.Background(), scheduled))
+	assert.Equal(t, *scheduled, received)
+
+	failing := NewSink(configs.AttestationSinkConfig{Type: configs.SinkTypeWebhook, URL: server.URL + "/failing", Timeout: time.Second})
+	assert.EqualError(t, failing.Push(context.Background(), scheduled), "webhook responded with status code 500")
+}
+
+func TestFileSink(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "sinks", "attestations.jsonl")
+	sink := NewSink(configs.AttestationSinkConfig{Type: configs.SinkTypeFile, Path: path})
+	assert.Equal(t, configs.SinkTypeFile, sink.Name())
+
+	for _, token := range []string{"BTC", "ETH"} {
+		require.NoError(t, sink.Push(context.Background(), &ScheduledAttestation{Token: token, Trigger: TriggerInitial}))
+	}
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	require.Len(t, lines, 2)
+
+	var scheduled ScheduledAttestation
+	require.NoError(t, json.Unmarshal([]byte(lines[1]), &scheduled))
+	assert.Equal(t, "ETH", scheduled.Token)
+
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+}
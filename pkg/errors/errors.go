@@ -93,18 +93,26 @@ var (
 	// =============================================================================
 	// ENCLAVE ERRORS (2000-2999)
 	// =============================================================================
-	ErrReadingTargetInfo    = NewAppError(2001, "enclave error: failed to read the target info")
-	ErrWritingReportData   = NewAppError(2002, "enclave error: failed to write the report data")
-	ErrGeneratingQuote      = NewAppError(2003, "enclave error: failed to generate the quote")
-	ErrReadingQuote         = NewAppError(2004, "enclave error: failed to read the quote")
-	ErrWritingTargetInfo   = NewAppError(2005, "enclave error: failed to write the target info")
-	ErrWrappingQuote        = NewAppError(2006, "enclave error: failed to wrap quote in openenclave format")
-	ErrReadingReport        = NewAppError(2007, "enclave error: failed to read the report")
-	ErrInvalidSGXReportSize = NewAppError(2008, "enclave error: invalid SGX report size")
-	ErrParsingSGXReport     = NewAppError(2009, "enclave error: failed to parse SGX report")
-	ErrEmptyQuote           = NewAppError(2010, "enclave error: empty quote")
-	ErrNilSGXReport         = NewAppError(2011, "enclave error: SGX report is nil")
-	ErrInvalidSGXQuoteSize  = NewAppError(2012, "enclave error: invalid SGX quote size")
+	ErrReadingTargetInfo       = NewAppError(2001, "enclave error: failed to read the target info")
+	ErrWritingReportData       = NewAppError(2002, "enclave error: failed to write the report data")
+	ErrGeneratingQuote         = NewAppError(2003, "enclave error: failed to generate the quote")
+	ErrReadingQuote            = NewAppError(2004, "enclave error: failed to read the quote")
+	ErrWritingTargetInfo       = NewAppError(2005, "enclave error: failed to write the target info")
+	ErrWrappingQuote           = NewAppError(2006, "enclave error: failed to wrap quote in openenclave format")
+	ErrReadingReport           = NewAppError(2007, "enclave error: failed to read the report")
+	ErrInvalidSGXReportSize    = NewAppError(2008, "enclave error: invalid SGX report size")
+	ErrParsingSGXReport        = NewAppError(2009, "enclave error: failed to parse SGX report")
+	ErrEmptyQuote              = NewAppError(2010, "enclave error: empty quote")
+	ErrNilSGXReport            = NewAppError(2011, "enclave error: SGX report is nil")
+	ErrInvalidSGXQuoteSize     = NewAppError(2012, "enclave error: invalid SGX quote size")
+	ErrUnwrappingQuote         = NewAppError(2013, "enclave error: failed to unwrap quote from openenclave format")
+	ErrParsingQuote            = NewAppError(2014, "enclave error: failed to parse SGX quote")
+	ErrUnsupportedQuote        = NewAppError(2015, "enclave error: unsupported SGX quote")
+	ErrParsingPCKExtensions    = NewAppError(2016, "enclave error: failed to parse PCK certificate SGX extensions")
+	ErrVerifyingQuoteSignature = NewAppError(2017, "enclave error: quote signature verification failed")
+	ErrLoadingCollateral       = NewAppError(2018, "enclave error: failed to load quote verification collateral")
+	ErrInvalidCollateral       = NewAppError(2019, "enclave error: invalid quote verification collateral")
+	ErrTCBLevelNotFound        = NewAppError(2020, "enclave error: no matching TCB level in collateral")
 
 	// =============================================================================
 	// ATTESTATION ERRORS (3000-3999)
@@ -123,26 +131,27 @@ var (
 	// =============================================================================
 	// DATA EXTRACTION ERRORS (4000-4999)
 	// =============================================================================
-	ErrInvalidHTTPRequest      = NewAppError(4001, "data extraction error: invalid http request")
-	ErrFetchingData            = NewAppError(4002, "data extraction error: failed to fetch the data from the provided endpoint")
-	ErrInvalidStatusCode       = NewAppError(4003, "data extraction error: invalid status code returned from endpoint")
-	ErrReadingHTMLContent      = NewAppError(4004, "data extraction error: failed to read HTML content from target url")
-	ErrParsingHTMLContent      = NewAppError(4005, "data extraction error: failed to parse HTML content from target url")
-	ErrReadingJSONResponse     = NewAppError(4006, "data extraction error: failed to read the json response from target url")
-	ErrDecodingJSONResponse    = NewAppError(4007, "data extraction error: failed to decode JSON response from target url")
-	ErrSelectorNotFound        = NewAppError(4008, "data extraction error: selector not found")
-	ErrUnsupportedPriceFeedURL = NewAppError(4009, "data extraction error: unsupported price feed URL")
-	ErrAttestationDataTooLarge = NewAppError(4010, "data extraction error: attestation data too large")
-	ErrParsingFloatValue       = NewAppError(4011, "data extraction error: extracted value expected to be float but failed to parse as float")
-	ErrParsingIntValue         = NewAppError(4012, "data extraction error: extracted value expected to be int but failed to parse as int")
-	ErrEmptyAttestationData    = NewAppError(4013, "data extraction error: extracted attestation data is empty")
-	ErrInvalidRationalNumber   = NewAppError(4015, "data extraction error: invalid rational number")
-	ErrSymbolMismatch          = NewAppError(4014, "data extraction error: symbol mismatch")
-	ErrParsingTimestamp        = NewAppError(4015, "data extraction error: failed to parse timestamp")
-	ErrTimestampTooOld         = NewAppError(4016, "data extraction error: timestamp too old")
+	ErrInvalidHTTPRequest          = NewAppError(4001, "data extraction error: invalid http request")
+	ErrFetchingData                = NewAppError(4002, "data extraction error: failed to fetch the data from the provided endpoint")
+	ErrInvalidStatusCode           = NewAppError(4003, "data extraction error: invalid status code returned from endpoint")
+	ErrReadingHTMLContent          = NewAppError(4004, "data extraction error: failed to read HTML content from target url")
+	ErrParsingHTMLContent          = NewAppError(4005, "data extraction error: failed to parse HTML content from target url")
+	ErrReadingJSONResponse         = NewAppError(4006, "data extraction error: failed to read the json response from target url")
+	ErrDecodingJSONResponse        = NewAppError(4007, "data extraction error: failed to decode JSON response from target url")
+	ErrSelectorNotFound            = NewAppError(4008, "data extraction error: selector not found")
+	ErrUnsupportedPriceFeedURL     = NewAppError(4009, "data extraction error: unsupported price feed URL")
+	ErrAttestationDataTooLarge     = NewAppError(4010, "data extraction error: attestation data too large")
+	ErrParsingFloatValue           = NewAppError(4011, "data extraction error: extracted value expected to be float but failed to parse as float")
+	ErrParsingIntValue             = NewAppError(4012, "data extraction error: extracted value expected to be int but failed to parse as int")
+	ErrEmptyAttestationData        = NewAppError(4013, "data extraction error: extracted attestation data is empty")
+	ErrInvalidRationalNumber       = NewAppError(4015, "data extraction error: invalid rational number")
+	ErrSymbolMismatch              = NewAppError(4014, "data extraction error: symbol mismatch")
+	ErrParsingTimestamp            = NewAppError(4015, "data extraction error: failed to parse timestamp")
+	ErrTimestampTooOld             = NewAppError(4016, "data extraction error: timestamp too old")
 	ErrMaxResponseBodySizeExceeded = NewAppError(4018, "data extraction error: response body size exceeds the allowed limit")
 	ErrReadingResponseBody         = NewAppError(4019, "data extraction error: failed to read the response body")
 	ErrFetchingAleoBlockHeight     = NewAppError(4020, "data extraction error: failed to fetch the Aleo block height")
+	ErrAleoBlockHeightDisagreement = NewAppError(4021, "data extraction error: Aleo nodes disagree on the block height")
 
 	// =============================================================================
 	// ENCODING ERRORS (5000-5999)
@@ -153,16 +162,16 @@ var (
 	ErrEncodingHeaders         = NewAppError(5004, "encoding error: failed to encode headers")
 	ErrEncodingOptionalFields  = NewAppError(5005, "encoding error: failed to encode optional fields")
 	ErrPreparingMetaHeader     = NewAppError(5006, "encoding error: error while preparing meta header")
-	ErrWritingAttestationData = NewAppError(5007, "encoding error: failed to write attestation data to buffer")
-	ErrWritingTimestamp       = NewAppError(5008, "encoding error: failed to write timestamp to buffer")
-	ErrWritingStatusCode      = NewAppError(5009, "encoding error: failed to write status code to buffer")
-	ErrWritingUrl             = NewAppError(5010, "encoding error: failed to write url to buffer")
-	ErrWritingSelector        = NewAppError(5011, "encoding error: failed to write selector to buffer")
-	ErrWritingResponseFormat  = NewAppError(5012, "encoding error: failed to write response format to buffer")
-	ErrWritingRequestMethod   = NewAppError(5013, "encoding error: failed to write request method to buffer")
-	ErrWritingEncodingOptions = NewAppError(5014, "encoding error: failed to write encoding options to buffer")
-	ErrWritingRequestHeaders  = NewAppError(5015, "encoding error: failed to write request headers to buffer")
-	ErrWritingOptionalFields  = NewAppError(5016, "encoding error: failed to write optional headers to buffer")
+	ErrWritingAttestationData  = NewAppError(5007, "encoding error: failed to write attestation data to buffer")
+	ErrWritingTimestamp        = NewAppError(5008, "encoding error: failed to write timestamp to buffer")
+	ErrWritingStatusCode       = NewAppError(5009, "encoding error: failed to write status code to buffer")
+	ErrWritingUrl              = NewAppError(5010, "encoding error: failed to write url to buffer")
+	ErrWritingSelector         = NewAppError(5011, "encoding error: failed to write selector to buffer")
+	ErrWritingResponseFormat   = NewAppError(5012, "encoding error: failed to write response format to buffer")
+	ErrWritingRequestMethod    = NewAppError(5013, "encoding error: failed to write request method to buffer")
+	ErrWritingEncodingOptions  = NewAppError(5014, "encoding error: failed to write encoding options to buffer")
+	ErrWritingRequestHeaders   = NewAppError(5015, "encoding error: failed to write request headers to buffer")
+	ErrWritingOptionalFields   = NewAppError(5016, "encoding error: failed to write optional headers to buffer")
 	ErrUserDataTooShort        = NewAppError(5017, "encoding error: userData too short for expected zeroing")
 	ErrSliceToU128             = NewAppError(5018, "encoding error: failed to convert slice to u128")
 	ErrNilEncodingOptions      = NewAppError(5019, "encoding error: encoding options is empty")
@@ -171,7 +180,7 @@ var (
 	// =============================================================================
 	// PRICE FEED ERRORS (6000-6999)
 	// =============================================================================
-	ErrTokenNotSupported           = NewAppError(6001, "price feed error: token not supported. Supported tokens: BTC, ETH, ALEO")
+	ErrTokenNotSupported           = NewAppError(6001, "price feed error: token not supported")
 	ErrExchangeNotConfigured       = NewAppError(6002, "price feed error: exchange not configured")
 	ErrSymbolNotConfigured         = NewAppError(6003, "price feed error: symbol not configured")
 	ErrExchangeNotSupported        = NewAppError(6004, "price feed error: exchange not supported")
@@ -194,7 +203,17 @@ var (
 	ErrCrossVenueDispersionTooHigh = NewAppError(6019, "price feed error: cross-venue dispersion too high")
 	ErrZeroVolume                  = NewAppError(6020, "price feed error: total volume is zero")
 	ErrZeroCappedVolume            = NewAppError(6021, "price feed error: total capped volume is zero")
-	ErrTokenVWAPConfigNotFound     = NewAppError(6022, "price feed error: token VWAP config not found")
+	ErrAggregationConfigNotFound   = NewAppError(6022, "price feed error: token aggregation config not found")
+	ErrPriceSamplerDisabled        = NewAppError(6023, "price feed error: price sampler is not running for token")
+	ErrInvalidTWAPWindow           = NewAppError(6024, "price feed error: TWAP window outside the sampled range")
+	ErrInsufficientTWAPCoverage    = NewAppError(6025, "price feed error: insufficient price samples in TWAP window")
+	ErrExchangeRateLimited         = NewAppError(6026, "price feed error: exchange skipped by the rate limiter")
+	ErrExchangeCircuitOpen         = NewAppError(6027, "price feed error: exchange skipped by the open circuit breaker")
+	ErrMissingFreshnessSignal      = NewAppError(6028, "price feed error: freshness of the exchange response could not be verified")
+	ErrPriceChangeLimitExceeded    = NewAppError(6029, "price feed error: price change since the last attested price exceeds the limit of the token")
+	ErrPriceOutsidePegBand         = NewAppError(6030, "price feed error: price outside the peg band of the token")
+	ErrSchedulerDisabled           = NewAppError(6031, "price feed error: attestation scheduler is not running")
+	ErrNoScheduledAttestation      = NewAppError(6032, "price feed error: no scheduled attestation available for token")
 
 	// =============================================================================
 	// REQUEST/RESPONSE ERRORS (7000-7999)
@@ -203,6 +222,9 @@ var (
 	ErrReadingRequestBody  = NewAppError(7002, "request error: failed to read the request body")
 	ErrInvalidContentType  = NewAppError(7003, "request error: invalid content type, expected application/json")
 	ErrDecodingRequestBody = NewAppError(7004, "request error: failed to decode request body, invalid request structure")
+	ErrInvalidAdminToken   = NewAppError(7005, "request error: missing or invalid admin bearer token")
+	ErrInvalidRotationTime = NewAppError(7006, "request error: key rotation effective time is in the past")
+	ErrKeyRotationPlanned  = NewAppError(7007, "request error: a key rotation is already planned")
 
 	// =============================================================================
 	// INTERNAL ERRORS (8000-8999)
@@ -212,4 +234,8 @@ var (
 	ErrJSONEncoding           = NewAppError(8003, "internal error: failed to encode data to JSON")
 	ErrAleoContext            = NewAppError(8004, "internal error: failed to initialize Aleo context")
 	ErrRoughtimeServerError   = NewAppError(8005, "internal error: failed to get timestamp from roughtime server")
+	ErrRotatingSigningKey     = NewAppError(8006, "internal error: failed to rotate the signing key")
+	ErrNoKeyHandover          = NewAppError(8007, "internal error: no key handover available")
+	ErrRoughtimeDisagreement  = NewAppError(8008, "internal error: roughtime servers disagree on the time")
+	ErrInvalidRoughtimeProof  = NewAppError(8009, "internal error: invalid roughtime proof")
 )
@@ -0,0 +1,112 @@
+// Package roughtime verifies the roughtime proofs that back attestation timestamps.
+package roughtime
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"fmt"
+	"time"
+
+	"github.com/cloudflare/roughtime/protocol"
+	appErrors "github.com/venture23-aleo/aleo-oracle-notarization-backend/pkg/errors"
+)
+
+// Roughtime protocol versions, as named in the server configuration.
+const (
+	VersionIETF   = "IETF-Roughtime"
+	VersionGoogle = "Google-Roughtime"
+)
+
+// Response is the signed response of a single roughtime server.
+type Response struct {
+	Server    string `json:"server"`    // The server name.
+	Version   string `json:"version"`   // The protocol version: IETF-Roughtime, or Google-Roughtime when empty.
+	PublicKey []byte `json:"publicKey"` // The Ed25519 root public key of the server.
+	Blind     []byte `json:"blind"`     // The blind the nonce is derived from, together with the request digest.
+	Midpoint  int64  `json:"midpoint"`  // The time reported by the server, in Unix microseconds.
+	Radius    int64  `json:"radius"`    // The uncertainty radius of the reported time, in microseconds.
+	Response  []byte `json:"response"`  // The signed response of the server.
+}
+
+// Proof proves that an attestation timestamp was agreed on by a quorum of roughtime servers
+// after the attestation request was known.
+//
+// The nonce sent to each server is SHA-512(SHA-512(RequestDigest) || Blind), truncated to the nonce size
+// of the protocol version. This is the Roughtime chaining nonce with the request digest in place of the
+// previous response.
+type Proof struct {
+	RequestDigest []byte     `json:"requestDigest"` // The digest of the attestation requests.
+	Responses     []Response `json:"responses"`     // The responses of the servers that agreed on the time.
+}
+
+// VerifyProof verifies that a roughtime proof backs an attestation timestamp:
+//
+//  1. The proof is for the given request digest.
+//  2. Each server is trusted (server name to Ed25519 public key). A proof is never accepted without trusted servers.
+//  3. Each response is signed by its server for the nonce derived from the request digest and its blind.
+//  4. The timestamp, truncated to seconds, lies within the interval reported by each server.
+//  5. At least quorum distinct trusted public keys signed a response, so repeating a response does not count twice.
+func VerifyProof(proof *Proof, requestDigest []byte, timestamp int64, trustedServers map[string][]byte, quorum int) *appErrors.AppError {
+	if len(trustedServers) == 0 {
+		return appErrors.ErrInvalidRoughtimeProof.WithDetails("no trusted roughtime servers configured")
+	}
+
+	if !bytes.Equal(proof.RequestDigest, requestDigest) {
+		return appErrors.ErrInvalidRoughtimeProof.WithDetails("request digest does not match the attestation requests")
+	}
+
+	attestedTime := time.Unix(timestamp, 0)
+	trustedKeys := make(map[string]struct{}, len(proof.Responses))
+	for _, response := range proof.Responses {
+		trustedKey, ok := trustedServers[response.Server]
+		if !ok {
+			return appErrors.ErrInvalidRoughtimeProof.WithDetails(fmt.Sprintf("server %s is not trusted", response.Server))
+		}
+		if !bytes.Equal(trustedKey, response.PublicKey) {
+			return appErrors.ErrInvalidRoughtimeProof.WithDetails(fmt.Sprintf("server %s public key does not match the trusted key", response.Server))
+		}
+		if len(response.PublicKey) != ed25519.PublicKeySize {
+			return appErrors.ErrInvalidRoughtimeProof.WithDetails(fmt.Sprintf("server %s public key is not an Ed25519 key", response.Server))
+		}
+
+		versionPreference, nonceSize, err := VersionPreference(response.Version)
+		if err != nil {
+			return appErrors.ErrInvalidRoughtimeProof.WithDetails(fmt.Sprintf("server %s: %v", response.Server, err))
+		}
+
+		nonce := make([]byte, nonceSize)
+		protocol.CalculateChainNonce(nonce, requestDigest, response.Blind)
+
+		midpoint, radius, err := protocol.VerifyReply(versionPreference, response.Response, response.PublicKey, nonce)
+		if err != nil {
+			return appErrors.ErrInvalidRoughtimeProof.WithDetails(fmt.Sprintf("server %s: %v", response.Server, err))
+		}
+
+		// The attested timestamp is the agreed time truncated to seconds, so it may be up to a second
+		// before the start of the interval.
+		if !attestedTime.After(midpoint.Add(-radius-time.Second)) || attestedTime.After(midpoint.Add(radius)) {
+			return appErrors.ErrInvalidRoughtimeProof.WithDetails(fmt.Sprintf("timestamp %d is outside the interval %s ± %s of server %s", timestamp, midpoint.UTC().Format(time.RFC3339Nano), radius, response.Server))
+		}
+
+		trustedKeys[string(response.PublicKey)] = struct{}{}
+	}
+
+	if len(trustedKeys) < max(quorum, 1) {
+		return appErrors.ErrInvalidRoughtimeProof.WithDetails(fmt.Sprintf("%d distinct trusted servers responded, quorum is %d", len(trustedKeys), quorum))
+	}
+
+	return nil
+}
+
+// VersionPreference returns the version preference and nonce size for a configured protocol version.
+func VersionPreference(version string) ([]protocol.Version, int, error) {
+	switch version {
+	case VersionIETF:
+		// An empty version preference advertises the supported IETF drafts.
+		return []protocol.Version{}, 32, nil
+	case VersionGoogle, "":
+		return []protocol.Version{protocol.VersionGoogle}, 64, nil
+	default:
+		return nil, 0, fmt.Errorf("unrecognized roughtime version %q", version)
+	}
+}
@@ -0,0 +1,162 @@
+package roughtime
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha512"
+	"testing"
+	"time"
+
+	"github.com/cloudflare/roughtime/protocol"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	appErrors "github.com/venture23-aleo/aleo-oracle-notarization-backend/pkg/errors"
+)
+
+// newResponse signs a roughtime response for a request with a nonce derived from the request digest,
+// the way a roughtime server answers a request chained to it.
+func newResponse(t *testing.T, server string, rootKey ed25519.PrivateKey, requestDigest []byte, midpoint time.Time, radius time.Duration) Response {
+	_, onlineKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	cert, err := protocol.NewCertificate(midpoint.Add(-time.Hour), midpoint.Add(time.Hour), onlineKey, rootKey)
+	require.NoError(t, err)
+
+	publicKey := rootKey.Public().(ed25519.PublicKey)
+	_, blind, request, err := protocol.CreateRequest([]protocol.Version{}, rand.Reader, requestDigest, publicKey)
+	require.NoError(t, err)
+	parsedRequest, err := protocol.ParseRequest(request)
+	require.NoError(t, err)
+	version, err := protocol.ResponseVersionFromSupported(parsedRequest.Versions)
+	require.NoError(t, err)
+	replies, err := protocol.CreateReplies(version, []protocol.Request{*parsedRequest}, midpoint, radius, cert)
+	require.NoError(t, err)
+
+	return Response{
+		Server:    server,
+		Version:   VersionIETF,
+		PublicKey: publicKey,
+		Blind:     blind,
+		Midpoint:  midpoint.UnixMicro(),
+		Radius:    radius.Microseconds(),
+		Response:  replies[0],
+	}
+}
+
+func TestVerifyProof(t *testing.T) {
+	requestDigest := sha512.New().Sum([]byte("request"))
+	midpoint := time.Unix(1754278324, 0)
+	timestamp := midpoint.Unix()
+
+	_, rootKeyA, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	_, rootKeyB, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	newProof := func() *Proof {
+		return &Proof{
+			RequestDigest: requestDigest,
+			Responses: []Response{
+				newResponse(t, "a", rootKeyA, requestDigest, midpoint, time.Second),
+				newResponse(t, "b", rootKeyB, requestDigest, midpoint.Add(500*time.Millisecond), time.Second),
+			},
+		}
+	}
+	trustedServers := map[string][]byte{
+		"a": rootKeyA.Public().(ed25519.PublicKey),
+		"b": rootKeyB.Public().(ed25519.PublicKey),
+	}
+
+	tests := []struct {
+		name           string
+		tamper         func(proof *Proof)
+		requestDigest  []byte
+		timestamp      int64
+		trustedServers map[string][]byte
+		quorum         int
+		expectedError  bool
+	}{
+		{name: "valid proof", quorum: 2},
+		{name: "no trusted servers", trustedServers: map[string][]byte{}, quorum: 2, expectedError: true},
+		{name: "different request", requestDigest: sha512.New().Sum([]byte("other request")), quorum: 2, expectedError: true},
+		{name: "timestamp outside interval", timestamp: timestamp + 10, quorum: 2, expectedError: true},
+		{name: "quorum not reached", quorum: 3, expectedError: true},
+		{
+			name: "duplicate response",
+			tamper: func(proof *Proof) {
+				proof.Responses = append(proof.Responses, proof.Responses[0])
+			},
+			quorum:        3,
+			expectedError: true,
+		},
+		{
+			name: "same key under another trusted name",
+			tamper: func(proof *Proof) {
+				proof.Responses[1] = newResponse(t, "a2", rootKeyA, requestDigest, midpoint, time.Second)
+			},
+			trustedServers: map[string][]byte{
+				"a":  rootKeyA.Public().(ed25519.PublicKey),
+				"a2": rootKeyA.Public().(ed25519.PublicKey),
+				"b":  rootKeyB.Public().(ed25519.PublicKey),
+			},
+			quorum:        2,
+			expectedError: true,
+		},
+		{
+			name:          "untrusted server",
+			tamper:        func(proof *Proof) { proof.Responses[1].Server = "c" },
+			quorum:        2,
+			expectedError: true,
+		},
+		{
+			name: "request digest replaced in proof",
+			tamper: func(proof *Proof) {
+				proof.RequestDigest = sha512.New().Sum([]byte("other request"))
+			},
+			quorum:        2,
+			expectedError: true,
+		},
+		{
+			name: "response for another request",
+			tamper: func(proof *Proof) {
+				proof.Responses[0] = newResponse(t, "a", rootKeyA, sha512.New().Sum([]byte("other request")), midpoint, time.Second)
+			},
+			quorum:        2,
+			expectedError: true,
+		},
+		{
+			name:          "tampered blind",
+			tamper:        func(proof *Proof) { proof.Responses[0].Blind[0] ^= 0xff },
+			quorum:        2,
+			expectedError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			proof := newProof()
+			if tt.tamper != nil {
+				tt.tamper(proof)
+			}
+			digest := requestDigest
+			if tt.requestDigest != nil {
+				digest = tt.requestDigest
+			}
+			ts := timestamp
+			if tt.timestamp != 0 {
+				ts = tt.timestamp
+			}
+			servers := trustedServers
+			if tt.trustedServers != nil {
+				servers = tt.trustedServers
+			}
+
+			err := VerifyProof(proof, digest, ts, servers, tt.quorum)
+			if tt.expectedError {
+				require.NotNil(t, err)
+				assert.Equal(t, appErrors.ErrInvalidRoughtimeProof.Code, err.Code)
+				return
+			}
+			assert.Nil(t, err)
+		})
+	}
+}
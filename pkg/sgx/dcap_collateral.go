@@ -0,0 +1,361 @@
+package sgx
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"time"
+
+	appErrors "github.com/venture23-aleo/aleo-oracle-notarization-backend/pkg/errors"
+)
+
+// Collateral file names in a collateral directory, as downloaded from the Intel PCS.
+// Refer to https://api.portal.trustedservices.intel.com/content/documentation.html
+const (
+	CollateralRootCAFile          = "root_ca.pem"           // Trusted Intel SGX Root CA certificate.
+	CollateralRootCACRLFile       = "root_ca_crl.der"       // CRL issued by the root CA, PEM or DER.
+	CollateralPCKCRLFile          = "pck_crl.der"           // CRL issued by the PCK Platform or Processor CA, PEM or DER.
+	CollateralTCBInfoFile         = "tcb_info.json"         // Signed TCB info for the platform FMSPC.
+	CollateralQEIdentityFile      = "qe_identity.json"      // Signed Quoting Enclave identity.
+	CollateralTCBSigningChainFile = "tcb_signing_chain.pem" // TCB signing certificate chain, leaf first.
+)
+
+// TCB statuses.
+const (
+	TCBStatusUpToDate                          = "UpToDate"
+	TCBStatusSWHardeningNeeded                 = "SWHardeningNeeded"
+	TCBStatusConfigurationNeeded               = "ConfigurationNeeded"
+	TCBStatusConfigurationAndSWHardeningNeeded = "ConfigurationAndSWHardeningNeeded"
+	TCBStatusOutOfDate                         = "OutOfDate"
+	TCBStatusOutOfDateConfigurationNeeded      = "OutOfDateConfigurationNeeded"
+	TCBStatusRevoked                           = "Revoked"
+)
+
+// TCBInfoVersion is the supported TCB info version.
+const TCBInfoVersion = 3
+
+// Collateral identifiers for SGX quotes. TDX quotes use the "TDX" TCB info and the "TDQE" identity, and
+// are not supported.
+const (
+	TCBInfoIDSGX   = "SGX" // ID of the TCB info of an SGX platform.
+	QEIdentityIDQE = "QE"  // ID of the identity of the SGX Quoting Enclave.
+)
+
+// intelSGXRootCAPublicKey is the hex encoded subject public key info of the Intel SGX Root CA
+// (CN=Intel SGX Root CA, O=Intel Corporation), as published at https://certificates.trustedservices.intel.com/IntelSGXRootCA.der.
+// Collateral is only trusted when its root CA carries this key.
+const intelSGXRootCAPublicKey = "3059301306072a8648ce3d020106082a8648ce3d030107034200040ba9c4c0c0c86193a3fe23d6b02cda10a8bbd4e88e48b4458561a36e705525f567918e2edc88e40d860bd0cc4ee26aacc988e505a953558c453f6b0904ae7394"
+
+// Collateral is the data needed to verify a DCAP quote offline.
+type Collateral struct {
+	RootCA          *x509.Certificate
+	CRLs            []*x509.RevocationList
+	TCBInfo         *TCBInfo
+	QEIdentity      *QEIdentity
+	TCBSigningChain []*x509.Certificate
+
+	// Subject public key info the root CA must carry. The Intel SGX Root CA key when nil; only
+	// simulated collateral pins another key.
+	rootCAPublicKey []byte
+
+	// Raw signed bodies and signatures of the TCB info and QE identity.
+	tcbInfoBody         []byte
+	tcbInfoSignature    []byte
+	qeIdentityBody      []byte
+	qeIdentitySignature []byte
+}
+
+// TCBInfo is the TCB info of a platform FMSPC.
+type TCBInfo struct {
+	ID                      string     `json:"id"`
+	Version                 int        `json:"version"`
+	IssueDate               time.Time  `json:"issueDate"`
+	NextUpdate              time.Time  `json:"nextUpdate"`
+	FMSPC                   string     `json:"fmspc"`
+	PCEID                   string     `json:"pceId"`
+	TCBType                 int        `json:"tcbType"`
+	TCBEvaluationDataNumber int        `json:"tcbEvaluationDataNumber"`
+	TCBLevels               []TCBLevel `json:"tcbLevels"`
+}
+
+// TCBLevel is a platform TCB level of a TCB info.
+type TCBLevel struct {
+	TCB         PlatformTCB `json:"tcb"`
+	TCBDate     time.Time   `json:"tcbDate"`
+	TCBStatus   string      `json:"tcbStatus"`
+	AdvisoryIDs []string    `json:"advisoryIDs"`
+}
+
+// PlatformTCB is the SVN of each platform TCB component and the PCE SVN.
+type PlatformTCB struct {
+	SGXTCBComponents []TCBComponent `json:"sgxtcbcomponents"`
+	PCESVN           int            `json:"pcesvn"`
+}
+
+// TCBComponent is a platform TCB component.
+type TCBComponent struct {
+	SVN int `json:"svn"`
+}
+
+// QEIdentity is the identity of the Quoting Enclave.
+type QEIdentity struct {
+	ID                      string            `json:"id"`
+	Version                 int               `json:"version"`
+	IssueDate               time.Time         `json:"issueDate"`
+	NextUpdate              time.Time         `json:"nextUpdate"`
+	TCBEvaluationDataNumber int               `json:"tcbEvaluationDataNumber"`
+	MiscSelect              string            `json:"miscselect"`
+	MiscSelectMask          string            `json:"miscselectMask"`
+	Attributes              string            `json:"attributes"`
+	AttributesMask          string            `json:"attributesMask"`
+	MRSigner                string            `json:"mrsigner"`
+	ISVProdID               uint16            `json:"isvprodid"`
+	TCBLevels               []QEIdentityLevel `json:"tcbLevels"`
+}
+
+// QEIdentityLevel is a TCB level of the Quoting Enclave identity.
+type QEIdentityLevel struct {
+	TCB         QETCB     `json:"tcb"`
+	TCBDate     time.Time `json:"tcbDate"`
+	TCBStatus   string    `json:"tcbStatus"`
+	AdvisoryIDs []string  `json:"advisoryIDs"`
+}
+
+// QETCB is the ISV SVN of the Quoting Enclave.
+type QETCB struct {
+	ISVSVN uint16 `json:"isvsvn"`
+}
+
+// signedTCBInfo is the TCB info document as served by the Intel PCS.
+type signedTCBInfo struct {
+	TCBInfo   json.RawMessage `json:"tcbInfo"`
+	Signature string          `json:"signature"`
+}
+
+// signedQEIdentity is the QE identity document as served by the Intel PCS.
+type signedQEIdentity struct {
+	EnclaveIdentity json.RawMessage `json:"enclaveIdentity"`
+	Signature       string          `json:"signature"`
+}
+
+// LoadCollateral loads the collateral files from a directory. The PCK CRL is optional when the
+// root CA CRL covers the PCK certificate, as with a two-level chain.
+func LoadCollateral(dir string) (*Collateral, *appErrors.AppError) {
+	read := func(name string) ([]byte, *appErrors.AppError) {
+		data, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			return nil, appErrors.ErrLoadingCollateral.WithDetails(err.Error())
+		}
+		return data, nil
+	}
+
+	files := map[string][]byte{}
+	for _, name := range []string{CollateralRootCAFile, CollateralRootCACRLFile, CollateralTCBInfoFile, CollateralQEIdentityFile, CollateralTCBSigningChainFile} {
+		data, err := read(name)
+		if err != nil {
+			return nil, err
+		}
+		files[name] = data
+	}
+
+	pckCRL, readErr := os.ReadFile(filepath.Join(dir, CollateralPCKCRLFile))
+	if readErr != nil && !os.IsNotExist(readErr) {
+		return nil, appErrors.ErrLoadingCollateral.WithDetails(readErr.Error())
+	}
+
+	crls := [][]byte{files[CollateralRootCACRLFile]}
+	if pckCRL != nil {
+		crls = append(crls, pckCRL)
+	}
+
+	return ParseCollateral(files[CollateralRootCAFile], crls, files[CollateralTCBInfoFile], files[CollateralQEIdentityFile], files[CollateralTCBSigningChainFile])
+}
+
+// ParseCollateral parses collateral from its encoded parts. CRLs may be PEM or DER encoded.
+func ParseCollateral(rootCAPEM []byte, crls [][]byte, tcbInfoJSON, qeIdentityJSON, tcbSigningChainPEM []byte) (*Collateral, *appErrors.AppError) {
+	collateral := &Collateral{}
+
+	rootCAs, err := parsePEMCertificates(rootCAPEM)
+	if err != nil {
+		return nil, appErrors.ErrLoadingCollateral.WithDetails(fmt.Sprintf("root CA: %v", err))
+	}
+	collateral.RootCA = rootCAs[0]
+
+	for _, der := range crls {
+		if block, _ := pem.Decode(der); block != nil {
+			der = block.Bytes
+		}
+		crl, err := x509.ParseRevocationList(der)
+		if err != nil {
+			return nil, appErrors.ErrLoadingCollateral.WithDetails(fmt.Sprintf("CRL: %v", err))
+		}
+		collateral.CRLs = append(collateral.CRLs, crl)
+	}
+
+	collateral.TCBSigningChain, err = parsePEMCertificates(tcbSigningChainPEM)
+	if err != nil {
+		return nil, appErrors.ErrLoadingCollateral.WithDetails(fmt.Sprintf("TCB signing chain: %v", err))
+	}
+
+	var tcbInfo signedTCBInfo
+	if err := json.Unmarshal(tcbInfoJSON, &tcbInfo); err != nil {
+		return nil, appErrors.ErrLoadingCollateral.WithDetails(fmt.Sprintf("TCB info: %v", err))
+	}
+	collateral.TCBInfo = &TCBInfo{}
+	if err := json.Unmarshal(tcbInfo.TCBInfo, collateral.TCBInfo); err != nil {
+		return nil, appErrors.ErrLoadingCollateral.WithDetails(fmt.Sprintf("TCB info: %v", err))
+	}
+	if collateral.TCBInfo.Version != TCBInfoVersion {
+		return nil, appErrors.ErrLoadingCollateral.WithDetails(fmt.Sprintf("unsupported TCB info version %d", collateral.TCBInfo.Version))
+	}
+	collateral.tcbInfoBody = tcbInfo.TCBInfo
+	if collateral.tcbInfoSignature, err = hex.DecodeString(tcbInfo.Signature); err != nil {
+		return nil, appErrors.ErrLoadingCollateral.WithDetails(fmt.Sprintf("TCB info signature: %v", err))
+	}
+
+	var qeIdentity signedQEIdentity
+	if err := json.Unmarshal(qeIdentityJSON, &qeIdentity); err != nil {
+		return nil, appErrors.ErrLoadingCollateral.WithDetails(fmt.Sprintf("QE identity: %v", err))
+	}
+	collateral.QEIdentity = &QEIdentity{}
+	if err := json.Unmarshal(qeIdentity.EnclaveIdentity, collateral.QEIdentity); err != nil {
+		return nil, appErrors.ErrLoadingCollateral.WithDetails(fmt.Sprintf("QE identity: %v", err))
+	}
+	collateral.qeIdentityBody = qeIdentity.EnclaveIdentity
+	if collateral.qeIdentitySignature, err = hex.DecodeString(qeIdentity.Signature); err != nil {
+		return nil, appErrors.ErrLoadingCollateral.WithDetails(fmt.Sprintf("QE identity signature: %v", err))
+	}
+
+	return collateral, nil
+}
+
+// verifyChain verifies a certificate chain, leaf first, up to the trusted root CA at the given time,
+// and checks every certificate below the root against the collateral CRLs.
+func (c *Collateral) verifyChain(chain []*x509.Certificate, at time.Time) error {
+	if len(chain) == 0 {
+		return fmt.Errorf("empty certificate chain")
+	}
+	if err := c.verifyRootCA(); err != nil {
+		return err
+	}
+
+	roots := x509.NewCertPool()
+	roots.AddCert(c.RootCA)
+	intermediates := x509.NewCertPool()
+	for _, cert := range chain[1:] {
+		if !cert.Equal(c.RootCA) {
+			intermediates.AddCert(cert)
+		}
+	}
+
+	verifiedChains, err := chain[0].Verify(x509.VerifyOptions{
+		Roots:         roots,
+		Intermediates: intermediates,
+		CurrentTime:   at,
+		KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageAny},
+	})
+	if err != nil {
+		return err
+	}
+
+	// Check the revocation status of every certificate below the root.
+	verifiedChain := verifiedChains[0]
+	for i := 0; i < len(verifiedChain)-1; i++ {
+		if err := c.checkRevocation(verifiedChain[i], verifiedChain[i+1], at); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// verifyRootCA checks that the root CA carries the pinned public key, so collateral cannot bring its own root.
+func (c *Collateral) verifyRootCA() error {
+	if c.rootCAPublicKey != nil {
+		if !bytes.Equal(c.RootCA.RawSubjectPublicKeyInfo, c.rootCAPublicKey) {
+			return fmt.Errorf("root CA %s does not carry the pinned public key", c.RootCA.Subject)
+		}
+		return nil
+	}
+	if hex.EncodeToString(c.RootCA.RawSubjectPublicKeyInfo) != intelSGXRootCAPublicKey {
+		return fmt.Errorf("root CA %s is not the Intel SGX Root CA", c.RootCA.Subject)
+	}
+	return nil
+}
+
+// checkRevocation checks a certificate against the CRL of its issuer.
+func (c *Collateral) checkRevocation(cert, issuer *x509.Certificate, at time.Time) error {
+	for _, crl := range c.CRLs {
+		if crl.CheckSignatureFrom(issuer) != nil {
+			continue
+		}
+		if !crl.NextUpdate.IsZero() && at.After(crl.NextUpdate) {
+			return fmt.Errorf("CRL of %s expired at %s", issuer.Subject, crl.NextUpdate.Format(time.RFC3339))
+		}
+		for _, revoked := range crl.RevokedCertificateEntries {
+			if revoked.SerialNumber.Cmp(cert.SerialNumber) == 0 {
+				return fmt.Errorf("certificate %s is revoked", cert.Subject)
+			}
+		}
+		return nil
+	}
+	return fmt.Errorf("no CRL issued by %s", issuer.Subject)
+}
+
+// verifySignedCollateral verifies the TCB signing chain and the signatures and validity of the TCB info and QE identity.
+func (c *Collateral) verifySignedCollateral(at time.Time) error {
+	if err := c.verifyChain(c.TCBSigningChain, at); err != nil {
+		return fmt.Errorf("TCB signing chain: %w", err)
+	}
+
+	signingKey, ok := c.TCBSigningChain[0].PublicKey.(*ecdsa.PublicKey)
+	if !ok {
+		return fmt.Errorf("TCB signing key is not an ECDSA key")
+	}
+
+	if !verifyRawECDSA(signingKey, c.tcbInfoBody, c.tcbInfoSignature) {
+		return fmt.Errorf("invalid TCB info signature")
+	}
+	if !verifyRawECDSA(signingKey, c.qeIdentityBody, c.qeIdentitySignature) {
+		return fmt.Errorf("invalid QE identity signature")
+	}
+
+	if c.TCBInfo.ID != TCBInfoIDSGX {
+		return fmt.Errorf("TCB info ID %q is not %q", c.TCBInfo.ID, TCBInfoIDSGX)
+	}
+	if c.QEIdentity.ID != QEIdentityIDQE {
+		return fmt.Errorf("QE identity ID %q is not %q", c.QEIdentity.ID, QEIdentityIDQE)
+	}
+
+	if at.Before(c.TCBInfo.IssueDate) {
+		return fmt.Errorf("TCB info is not valid before %s", c.TCBInfo.IssueDate.Format(time.RFC3339))
+	}
+	if at.After(c.TCBInfo.NextUpdate) {
+		return fmt.Errorf("TCB info expired at %s", c.TCBInfo.NextUpdate.Format(time.RFC3339))
+	}
+	if at.Before(c.QEIdentity.IssueDate) {
+		return fmt.Errorf("QE identity is not valid before %s", c.QEIdentity.IssueDate.Format(time.RFC3339))
+	}
+	if at.After(c.QEIdentity.NextUpdate) {
+		return fmt.Errorf("QE identity expired at %s", c.QEIdentity.NextUpdate.Format(time.RFC3339))
+	}
+	return nil
+}
+
+// verifyRawECDSA verifies a raw big-endian r||s ECDSA signature over the SHA-256 digest of data.
+func verifyRawECDSA(key *ecdsa.PublicKey, data, signature []byte) bool {
+	if len(signature) != ECDSASignatureSize {
+		return false
+	}
+	digest := sha256.Sum256(data)
+	r := new(big.Int).SetBytes(signature[:32])
+	s := new(big.Int).SetBytes(signature[32:])
+	return ecdsa.Verify(key, digest[:], r, s)
+}
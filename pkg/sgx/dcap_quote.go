@@ -0,0 +1,438 @@
+package sgx
+
+import (
+	"bytes"
+	"crypto/x509"
+	"encoding/asn1"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/pem"
+	"fmt"
+
+	appErrors "github.com/venture23-aleo/aleo-oracle-notarization-backend/pkg/errors"
+)
+
+// DCAP quote layout constants.
+// Refer to https://download.01.org/intel-sgx/latest/dcap-latest/linux/docs/Intel_SGX_ECDSA_QuoteLibReference_DCAP_API.pdf
+const (
+	// Quote header size
+	QuoteHeaderSize = 48
+
+	// Quote versions
+	QuoteVersion3 = 3
+	QuoteVersion4 = 4
+
+	// ECDSA-256-with-P-256 attestation key type
+	AttestationKeyTypeECDSAP256 = 2
+
+	// TEE types in v4 quotes
+	TEETypeSGX = 0x00000000
+	TEETypeTDX = 0x00000081
+
+	// Size of a raw r||s ECDSA P-256 signature
+	ECDSASignatureSize = 64
+
+	// Size of a raw x||y ECDSA P-256 public key
+	ECDSAPublicKeySize = 64
+
+	// Certification data type carrying the PEM encoded PCK certificate chain
+	CertificationDataTypePCKCertChain = 5
+
+	// Certification data type carrying the QE report certification data (v4 quotes)
+	CertificationDataTypeQEReport = 6
+)
+
+// DCAP quote is structured as follows:
+/*
+| Field                        | Size (bytes) |
+|------------------------------|--------------|
+| Header                       | 48           |
+| ISV enclave report body      | 384          |
+| Signature data length        | 4            |
+| Signature data               | variable     |
+|   ├─ ISV report signature    | 64           |
+|   ├─ Attestation key         | 64           |
+|   └─ v3: QE report, QE report signature, QE auth data, certification data
+|      v4: certification data of type 6 wrapping the same fields
+|------------------------------|--------------|
+
+Certification data is a 2-byte type, a 4-byte size and the data. The QE auth data is a
+2-byte size and the data.
+*/
+
+// QuoteHeader is the 48-byte DCAP quote header.
+type QuoteHeader struct {
+	Version            uint16
+	AttestationKeyType uint16
+	TEEType            uint32 // Reserved in v3 quotes.
+	QESVN              uint16 // Reserved in v4 quotes.
+	PCESVN             uint16 // Reserved in v4 quotes.
+	QEVendorID         [16]byte
+	UserData           [20]byte
+}
+
+// Quote is a parsed ECDSA DCAP quote.
+type Quote struct {
+	Header     QuoteHeader
+	ReportBody ReportBody
+
+	// Raw r||s ECDSA signature over the header and the report body by the attestation key.
+	Signature []byte
+
+	// Raw x||y ECDSA P-256 attestation key.
+	AttestationKey []byte
+
+	// Report of the Quoting Enclave. Its report data binds the attestation key.
+	QEReport ReportBody
+
+	// Raw r||s ECDSA signature over the QE report by the PCK key.
+	QEReportSignature []byte
+
+	// QE authentication data.
+	QEAuthData []byte
+
+	// Type and content of the innermost certification data.
+	CertificationDataType uint16
+	CertificationData     []byte
+
+	// PCK certificate chain, leaf first, parsed when the certification data type is 5.
+	PCKCertChain []*x509.Certificate
+
+	signedData  []byte // Header and report body, signed by the attestation key.
+	rawQEReport []byte // QE report, signed by the PCK key.
+}
+
+// quoteReader reads little-endian fields from a quote and records the first failure.
+type quoteReader struct {
+	data   []byte
+	offset int
+	err    error
+}
+
+func (r *quoteReader) next(n int, field string) []byte {
+	if r.err != nil {
+		return nil
+	}
+	if n < 0 || len(r.data)-r.offset < n {
+		r.err = fmt.Errorf("quote truncated reading %s at offset %d", field, r.offset)
+		return nil
+	}
+	b := r.data[r.offset : r.offset+n]
+	r.offset += n
+	return b
+}
+
+func (r *quoteReader) uint16(field string) uint16 {
+	b := r.next(2, field)
+	if b == nil {
+		return 0
+	}
+	return binary.LittleEndian.Uint16(b)
+}
+
+func (r *quoteReader) uint32(field string) uint32 {
+	b := r.next(4, field)
+	if b == nil {
+		return 0
+	}
+	return binary.LittleEndian.Uint32(b)
+}
+
+func (r *quoteReader) reportBody(field string) ReportBody {
+	var body ReportBody
+	b := r.next(SGXReportBodySize, field)
+	if b != nil {
+		binary.Read(bytes.NewReader(b), binary.LittleEndian, &body)
+	}
+	return body
+}
+
+func (r *quoteReader) certificationData(field string) (uint16, []byte) {
+	certType := r.uint16(field + " type")
+	size := r.uint32(field + " size")
+	return certType, r.next(int(size), field)
+}
+
+// ParseQuote parses a raw ECDSA DCAP quote of version 3 or 4 for an SGX enclave.
+//
+// It checks the structure only. The signatures and the PCK certificate chain are not verified.
+func ParseQuote(rawQuote []byte) (*Quote, *appErrors.AppError) {
+	r := &quoteReader{data: rawQuote}
+	quote := &Quote{}
+
+	// Step 1: Parse the header.
+	header := r.next(QuoteHeaderSize, "header")
+	if r.err != nil {
+		return nil, appErrors.ErrParsingQuote.WithDetails(r.err.Error())
+	}
+	binary.Read(bytes.NewReader(header), binary.LittleEndian, &quote.Header)
+
+	switch quote.Header.Version {
+	case QuoteVersion3:
+	case QuoteVersion4:
+		if quote.Header.TEEType != TEETypeSGX {
+			return nil, appErrors.ErrUnsupportedQuote.WithDetails(fmt.Sprintf("unsupported TEE type 0x%x", quote.Header.TEEType))
+		}
+	default:
+		return nil, appErrors.ErrUnsupportedQuote.WithDetails(fmt.Sprintf("unsupported quote version %d", quote.Header.Version))
+	}
+
+	if quote.Header.AttestationKeyType != AttestationKeyTypeECDSAP256 {
+		return nil, appErrors.ErrUnsupportedQuote.WithDetails(fmt.Sprintf("unsupported attestation key type %d", quote.Header.AttestationKeyType))
+	}
+
+	// Step 2: Parse the ISV enclave report body.
+	quote.ReportBody = r.reportBody("report body")
+	quote.signedData = rawQuote[:r.offset]
+
+	// Step 3: Parse the signature data.
+	signatureDataLen := r.uint32("signature data length")
+	signatureData := r.next(int(signatureDataLen), "signature data")
+	if r.err != nil {
+		return nil, appErrors.ErrParsingQuote.WithDetails(r.err.Error())
+	}
+	if r.offset != len(rawQuote) {
+		return nil, appErrors.ErrParsingQuote.WithDetails(fmt.Sprintf("%d trailing bytes after signature data", len(rawQuote)-r.offset))
+	}
+
+	s := &quoteReader{data: signatureData}
+	quote.Signature = s.next(ECDSASignatureSize, "quote signature")
+	quote.AttestationKey = s.next(ECDSAPublicKeySize, "attestation key")
+
+	// In v4 quotes the QE report fields are wrapped in certification data of type 6.
+	if quote.Header.Version == QuoteVersion4 {
+		certType, certData := s.certificationData("QE report certification data")
+		if s.err == nil && certType != CertificationDataTypeQEReport {
+			return nil, appErrors.ErrParsingQuote.WithDetails(fmt.Sprintf("unexpected certification data type %d, expected %d", certType, CertificationDataTypeQEReport))
+		}
+		if s.err == nil && s.offset != len(signatureData) {
+			return nil, appErrors.ErrParsingQuote.WithDetails("trailing bytes after QE report certification data")
+		}
+		s = &quoteReader{data: certData, err: s.err}
+	}
+
+	quote.QEReport = s.reportBody("QE report")
+	if s.err == nil {
+		quote.rawQEReport = s.data[s.offset-SGXReportBodySize : s.offset]
+	}
+	quote.QEReportSignature = s.next(ECDSASignatureSize, "QE report signature")
+	quote.QEAuthData = s.next(int(s.uint16("QE auth data size")), "QE auth data")
+	quote.CertificationDataType, quote.CertificationData = s.certificationData("certification data")
+	if s.err != nil {
+		return nil, appErrors.ErrParsingQuote.WithDetails(s.err.Error())
+	}
+
+	// Step 4: Parse the PCK certificate chain.
+	if quote.CertificationDataType == CertificationDataTypePCKCertChain {
+		chain, err := parsePEMCertificates(quote.CertificationData)
+		if err != nil {
+			return nil, appErrors.ErrParsingQuote.WithDetails(err.Error())
+		}
+		quote.PCKCertChain = chain
+	}
+
+	return quote, nil
+}
+
+// ParseOpenEnclaveEvidence parses a DCAP quote wrapped as Open Enclave evidence, as returned in attestation responses.
+func ParseOpenEnclaveEvidence(evidence []byte) (*Quote, *appErrors.AppError) {
+	rawQuote, err := UnwrapOpenEnclaveEvidence(evidence)
+	if err != nil {
+		return nil, err
+	}
+	return ParseQuote(rawQuote)
+}
+
+// parsePEMCertificates parses a concatenation of PEM certificates. Trailing NUL bytes are ignored.
+func parsePEMCertificates(data []byte) ([]*x509.Certificate, error) {
+	var certs []*x509.Certificate
+	rest := bytes.TrimRight(data, "\x00")
+	for {
+		var block *pem.Block
+		block, rest = pem.Decode(rest)
+		if block == nil {
+			break
+		}
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("parsing PCK certificate chain: %w", err)
+		}
+		certs = append(certs, cert)
+	}
+	if len(certs) == 0 {
+		return nil, fmt.Errorf("no certificates in PCK certificate chain")
+	}
+	return certs, nil
+}
+
+// PCKCertificate returns the PCK leaf certificate, or nil when the quote carries no certificate chain.
+func (q *Quote) PCKCertificate() *x509.Certificate {
+	if len(q.PCKCertChain) == 0 {
+		return nil
+	}
+	return q.PCKCertChain[0]
+}
+
+// IsDebug reports whether the quoted enclave runs in debug mode.
+func (q *Quote) IsDebug() bool {
+	return q.ReportBody.Attributes.Flags&DebugFlagMask != 0
+}
+
+// SGX extension OIDs in PCK certificates.
+// Refer to https://api.trustedservices.intel.com/documents/Intel_SGX_PCK_Certificate_CRL_Spec-1.5.pdf
+var (
+	OIDSGXExtensions = asn1.ObjectIdentifier{1, 2, 840, 113741, 1, 13, 1}
+	oidSGXTCB        = asn1.ObjectIdentifier{1, 2, 840, 113741, 1, 13, 1, 2}
+	oidSGXPCEID      = asn1.ObjectIdentifier{1, 2, 840, 113741, 1, 13, 1, 3}
+	oidSGXFMSPC      = asn1.ObjectIdentifier{1, 2, 840, 113741, 1, 13, 1, 4}
+)
+
+// Indexes of the PCE SVN and CPU SVN entries in the TCB extension.
+const (
+	pckTCBComponents = 16
+	pckTCBPCESVN     = 17
+	pckTCBCPUSVN     = 18
+)
+
+// PCKExtensions holds the SGX extensions of a PCK certificate.
+type PCKExtensions struct {
+	FMSPC            []byte
+	PCEID            []byte
+	CPUSVN           []byte
+	PCESVN           int
+	TCBComponentSVNs [pckTCBComponents]int
+}
+
+// pckExtensionEntry is one OID and value pair of the SGX extensions sequence.
+type pckExtensionEntry struct {
+	ID    asn1.ObjectIdentifier
+	Value asn1.RawValue
+}
+
+// ParsePCKExtensions parses the SGX extensions of a PCK certificate.
+func ParsePCKExtensions(cert *x509.Certificate) (*PCKExtensions, *appErrors.AppError) {
+	for _, ext := range cert.Extensions {
+		if !ext.Id.Equal(OIDSGXExtensions) {
+			continue
+		}
+
+		var entries []pckExtensionEntry
+		if _, err := asn1.Unmarshal(ext.Value, &entries); err != nil {
+			return nil, appErrors.ErrParsingPCKExtensions.WithDetails(err.Error())
+		}
+
+		extensions := &PCKExtensions{}
+		for _, entry := range entries {
+			switch {
+			case entry.ID.Equal(oidSGXFMSPC):
+				extensions.FMSPC = entry.Value.Bytes
+			case entry.ID.Equal(oidSGXPCEID):
+				extensions.PCEID = entry.Value.Bytes
+			case entry.ID.Equal(oidSGXTCB):
+				if err := parsePCKTCB(entry.Value.FullBytes, extensions); err != nil {
+					return nil, appErrors.ErrParsingPCKExtensions.WithDetails(err.Error())
+				}
+			}
+		}
+
+		if extensions.FMSPC == nil {
+			return nil, appErrors.ErrParsingPCKExtensions.WithDetails("FMSPC not found")
+		}
+		return extensions, nil
+	}
+
+	return nil, appErrors.ErrParsingPCKExtensions.WithDetails("SGX extensions not found")
+}
+
+// parsePCKTCB parses the TCB extension: 16 component SVNs, the PCE SVN and the CPU SVN.
+func parsePCKTCB(der []byte, extensions *PCKExtensions) error {
+	var entries []pckExtensionEntry
+	if _, err := asn1.Unmarshal(der, &entries); err != nil {
+		return fmt.Errorf("parsing TCB extension: %w", err)
+	}
+
+	for _, entry := range entries {
+		if len(entry.ID) != len(oidSGXTCB)+1 || !entry.ID[:len(oidSGXTCB)].Equal(oidSGXTCB) {
+			continue
+		}
+
+		index := entry.ID[len(oidSGXTCB)]
+		switch {
+		case index >= 1 && index <= pckTCBComponents:
+			var svn int
+			if _, err := asn1.Unmarshal(entry.Value.FullBytes, &svn); err != nil {
+				return fmt.Errorf("parsing TCB component %d: %w", index, err)
+			}
+			extensions.TCBComponentSVNs[index-1] = svn
+		case index == pckTCBPCESVN:
+			if _, err := asn1.Unmarshal(entry.Value.FullBytes, &extensions.PCESVN); err != nil {
+				return fmt.Errorf("parsing PCE SVN: %w", err)
+			}
+		case index == pckTCBCPUSVN:
+			extensions.CPUSVN = entry.Value.Bytes
+		}
+	}
+	return nil
+}
+
+// QuoteSummary is a JSON friendly view of the identity and TCB fields of a quote.
+type QuoteSummary struct {
+	Version               uint16 `json:"version"`
+	TEEType               string `json:"teeType"`
+	QEVendorID            string `json:"qeVendorId"`
+	MREnclave             string `json:"mrEnclave"`
+	MRSigner              string `json:"mrSigner"`
+	ISVProdID             uint16 `json:"isvProdId"`
+	ISVSVN                uint16 `json:"isvSvn"`
+	Debug                 bool   `json:"debug"`
+	ReportData            string `json:"reportData"`
+	AttestationKey        string `json:"attestationKey"`
+	CertificationDataType uint16 `json:"certificationDataType"`
+	PCKSubject            string `json:"pckSubject,omitempty"`
+	PCKIssuer             string `json:"pckIssuer,omitempty"`
+	FMSPC                 string `json:"fmspc,omitempty"`
+	PCEID                 string `json:"pceId,omitempty"`
+	CPUSVN                string `json:"cpuSvn,omitempty"`
+	PCESVN                int    `json:"pceSvn,omitempty"`
+	TCBComponentSVNs      []int  `json:"tcbComponentSvns,omitempty"`
+}
+
+// Summary returns the identity and TCB fields of the quote. The PCK fields are empty when the quote
+// carries no PCK certificate chain or the leaf certificate has no SGX extensions.
+func (q *Quote) Summary() *QuoteSummary {
+	summary := &QuoteSummary{
+		Version:               q.Header.Version,
+		TEEType:               "sgx",
+		QEVendorID:            hex.EncodeToString(q.Header.QEVendorID[:]),
+		MREnclave:             hex.EncodeToString(q.ReportBody.MREnclave[:]),
+		MRSigner:              hex.EncodeToString(q.ReportBody.MRSigner[:]),
+		ISVProdID:             binary.LittleEndian.Uint16(q.ReportBody.ISVProdID[:]),
+		ISVSVN:                binary.LittleEndian.Uint16(q.ReportBody.ISVSVN[:]),
+		Debug:                 q.IsDebug(),
+		ReportData:            hex.EncodeToString(q.ReportBody.ReportData[:]),
+		AttestationKey:        hex.EncodeToString(q.AttestationKey),
+		CertificationDataType: q.CertificationDataType,
+	}
+
+	pckCert := q.PCKCertificate()
+	if pckCert == nil {
+		return summary
+	}
+	summary.PCKSubject = pckCert.Subject.String()
+	summary.PCKIssuer = pckCert.Issuer.String()
+
+	extensions, err := ParsePCKExtensions(pckCert)
+	if err != nil {
+		return summary
+	}
+	summary.FMSPC = hex.EncodeToString(extensions.FMSPC)
+	summary.PCEID = hex.EncodeToString(extensions.PCEID)
+	summary.CPUSVN = hex.EncodeToString(extensions.CPUSVN)
+	summary.PCESVN = extensions.PCESVN
+	summary.TCBComponentSVNs = extensions.TCBComponentSVNs[:]
+
+	return summary
+}
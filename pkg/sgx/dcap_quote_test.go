@@ -0,0 +1,131 @@
+package sgx
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	appErrors "github.com/venture23-aleo/aleo-oracle-notarization-backend/pkg/errors"
+)
+
+func newSimulatedRawQuote(t *testing.T, reportData []byte) []byte {
+	provider, err := NewQuoteProvider(QuoteProviderSimulated, true)
+	require.NoError(t, err)
+
+	quote, appErr := provider.GenerateRawQuote(reportData)
+	require.Nil(t, appErr)
+	return quote
+}
+
+// toQuoteV4 repacks a v3 quote as an SGX v4 quote, moving the QE report fields into certification data of type 6.
+func toQuoteV4(t *testing.T, rawQuote []byte) []byte {
+	signatureData := rawQuote[QuoteHeaderSize+SGXReportBodySize+4:]
+	qeReportCertData := signatureData[ECDSASignatureSize+ECDSAPublicKeySize:]
+
+	header := append([]byte{}, rawQuote[:QuoteHeaderSize]...)
+	binary.LittleEndian.PutUint16(header[0:2], QuoteVersion4)
+	binary.LittleEndian.PutUint32(header[4:8], TEETypeSGX)
+
+	var v4SignatureData bytes.Buffer
+	v4SignatureData.Write(signatureData[:ECDSASignatureSize+ECDSAPublicKeySize])
+	binary.Write(&v4SignatureData, binary.LittleEndian, uint16(CertificationDataTypeQEReport))
+	binary.Write(&v4SignatureData, binary.LittleEndian, uint32(len(qeReportCertData)))
+	v4SignatureData.Write(qeReportCertData)
+
+	var quote bytes.Buffer
+	quote.Write(header)
+	quote.Write(rawQuote[QuoteHeaderSize : QuoteHeaderSize+SGXReportBodySize])
+	binary.Write(&quote, binary.LittleEndian, uint32(v4SignatureData.Len()))
+	quote.Write(v4SignatureData.Bytes())
+	return quote.Bytes()
+}
+
+func TestParseQuote(t *testing.T) {
+	reportData := make([]byte, SGXReportDataSize)
+	copy(reportData, "report data")
+	rawQuote := newSimulatedRawQuote(t, reportData)
+
+	for _, tc := range []struct {
+		name     string
+		rawQuote []byte
+		version  uint16
+	}{
+		{name: "v3", rawQuote: rawQuote, version: QuoteVersion3},
+		{name: "v4", rawQuote: toQuoteV4(t, rawQuote), version: QuoteVersion4},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			quote, err := ParseQuote(tc.rawQuote)
+			require.Nil(t, err)
+
+			assert.Equal(t, tc.version, quote.Header.Version)
+			assert.Equal(t, uint16(AttestationKeyTypeECDSAP256), quote.Header.AttestationKeyType)
+			assert.Equal(t, reportData, quote.ReportBody.ReportData[:])
+			assert.False(t, quote.IsDebug())
+			assert.Len(t, quote.Signature, ECDSASignatureSize)
+			assert.Len(t, quote.AttestationKey, ECDSAPublicKeySize)
+			assert.Len(t, quote.QEReportSignature, ECDSASignatureSize)
+			assert.Len(t, quote.QEAuthData, 32)
+			assert.Equal(t, uint16(CertificationDataTypePCKCertChain), quote.CertificationDataType)
+
+			require.Len(t, quote.PCKCertChain, 2)
+			assert.Equal(t, "Simulated SGX PCK Certificate", quote.PCKCertificate().Subject.CommonName)
+
+			extensions, err := ParsePCKExtensions(quote.PCKCertificate())
+			require.Nil(t, err)
+			assert.Equal(t, simulatedFMSPC, extensions.FMSPC)
+			assert.Equal(t, simulatedPCEID, extensions.PCEID)
+			assert.Equal(t, simulatedCPUSVN, extensions.CPUSVN)
+
+			summary := quote.Summary()
+			assert.Equal(t, tc.version, summary.Version)
+			assert.Equal(t, hex.EncodeToString(quote.ReportBody.MREnclave[:]), summary.MREnclave)
+			assert.Equal(t, hex.EncodeToString(simulatedFMSPC), summary.FMSPC)
+			assert.Len(t, summary.TCBComponentSVNs, 16)
+		})
+	}
+}
+
+func TestParseOpenEnclaveEvidence(t *testing.T) {
+	rawQuote := newSimulatedRawQuote(t, make([]byte, SGXReportDataSize))
+
+	quote, err := ParseOpenEnclaveEvidence(wrapRawQuoteAsOpenEnclaveEvidence(rawQuote))
+	require.Nil(t, err)
+	assert.Equal(t, uint16(QuoteVersion3), quote.Header.Version)
+}
+
+func TestParseQuote_Invalid(t *testing.T) {
+	rawQuote := newSimulatedRawQuote(t, make([]byte, SGXReportDataSize))
+
+	withHeader := func(offset int, value uint16) []byte {
+		quote := append([]byte{}, rawQuote...)
+		binary.LittleEndian.PutUint16(quote[offset:offset+2], value)
+		return quote
+	}
+
+	tdxQuote := toQuoteV4(t, rawQuote)
+	binary.LittleEndian.PutUint32(tdxQuote[4:8], TEETypeTDX)
+
+	testCases := []struct {
+		name          string
+		rawQuote      []byte
+		expectedError *appErrors.AppError
+	}{
+		{name: "truncated header", rawQuote: rawQuote[:QuoteHeaderSize-1], expectedError: appErrors.ErrParsingQuote},
+		{name: "truncated signature data", rawQuote: rawQuote[:len(rawQuote)-1], expectedError: appErrors.ErrParsingQuote},
+		{name: "trailing bytes", rawQuote: append(append([]byte{}, rawQuote...), 0), expectedError: appErrors.ErrParsingQuote},
+		{name: "unsupported version", rawQuote: withHeader(0, 5), expectedError: appErrors.ErrUnsupportedQuote},
+		{name: "unsupported attestation key type", rawQuote: withHeader(2, 3), expectedError: appErrors.ErrUnsupportedQuote},
+		{name: "TDX quote", rawQuote: tdxQuote, expectedError: appErrors.ErrUnsupportedQuote},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := ParseQuote(tc.rawQuote)
+			require.NotNil(t, err)
+			assert.Equal(t, tc.expectedError.Code, err.Code)
+		})
+	}
+}
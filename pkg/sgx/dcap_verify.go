@@ -0,0 +1,254 @@
+package sgx
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"slices"
+	"strings"
+	"time"
+
+	appErrors "github.com/venture23-aleo/aleo-oracle-notarization-backend/pkg/errors"
+)
+
+// QuoteVerificationResult is the outcome of verifying a quote against collateral.
+type QuoteVerificationResult struct {
+	// Overall TCB status: the platform TCB status converged with the QE identity status.
+	TCBStatus string `json:"tcbStatus"`
+
+	// Advisory IDs of the platform TCB level and the QE identity level.
+	AdvisoryIDs []string `json:"advisoryIds,omitempty"`
+
+	// TCB status and date of the matching platform TCB level.
+	PlatformTCBStatus string    `json:"platformTcbStatus"`
+	TCBDate           time.Time `json:"tcbDate"`
+
+	// TCB status of the matching QE identity level.
+	QEIdentityStatus string `json:"qeIdentityStatus"`
+
+	// TCB evaluation data number of the TCB info.
+	TCBEvaluationDataNumber int `json:"tcbEvaluationDataNumber"`
+}
+
+// VerifyQuoteSignatures verifies the signature chain of a quote up to the trusted root CA:
+//
+//  1. The PCK certificate chain verifies to the root CA at the given time and no certificate is revoked.
+//  2. The QE report is signed by the PCK key.
+//  3. The QE report data binds the attestation key and the QE auth data.
+//  4. The header and report body are signed by the attestation key.
+func VerifyQuoteSignatures(quote *Quote, collateral *Collateral, at time.Time) *appErrors.AppError {
+	// Step 1: Verify the PCK certificate chain.
+	if quote.CertificationDataType != CertificationDataTypePCKCertChain {
+		return appErrors.ErrVerifyingQuoteSignature.WithDetails(fmt.Sprintf("unsupported certification data type %d", quote.CertificationDataType))
+	}
+	if err := collateral.verifyChain(quote.PCKCertChain, at); err != nil {
+		return appErrors.ErrVerifyingQuoteSignature.WithDetails(fmt.Sprintf("PCK certificate chain: %v", err))
+	}
+
+	// Step 2: Verify the QE report signature.
+	pckKey, ok := quote.PCKCertificate().PublicKey.(*ecdsa.PublicKey)
+	if !ok {
+		return appErrors.ErrVerifyingQuoteSignature.WithDetails("PCK key is not an ECDSA key")
+	}
+	if !verifyRawECDSA(pckKey, quote.rawQEReport, quote.QEReportSignature) {
+		return appErrors.ErrVerifyingQuoteSignature.WithDetails("invalid QE report signature")
+	}
+
+	// Step 3: Verify that the QE report data binds the attestation key.
+	expectedQEReportData := make([]byte, SGXReportDataSize)
+	attestationKeyHash := sha256.Sum256(append(append([]byte{}, quote.AttestationKey...), quote.QEAuthData...))
+	copy(expectedQEReportData, attestationKeyHash[:])
+	if !bytes.Equal(quote.QEReport.ReportData[:], expectedQEReportData) {
+		return appErrors.ErrVerifyingQuoteSignature.WithDetails("QE report data does not bind the attestation key")
+	}
+
+	// Step 4: Verify the quote signature.
+	return VerifyReportSignature(quote)
+}
+
+// VerifyReportSignature verifies the signature of the header and ISV enclave report body with the attestation
+// key embedded in the quote.
+//
+// It needs no collateral, but only proves that the quote is intact: the attestation key is only trusted once
+// VerifyQuoteSignatures links it to the PCK certificate chain.
+func VerifyReportSignature(quote *Quote) *appErrors.AppError {
+	if len(quote.AttestationKey) != ECDSAPublicKeySize {
+		return appErrors.ErrVerifyingQuoteSignature.WithDetails("attestation key is not a raw P-256 key")
+	}
+	attestationKey := &ecdsa.PublicKey{
+		Curve: elliptic.P256(),
+		X:     new(big.Int).SetBytes(quote.AttestationKey[:32]),
+		Y:     new(big.Int).SetBytes(quote.AttestationKey[32:]),
+	}
+	if !attestationKey.Curve.IsOnCurve(attestationKey.X, attestationKey.Y) {
+		return appErrors.ErrVerifyingQuoteSignature.WithDetails("attestation key is not on the P-256 curve")
+	}
+	if !verifyRawECDSA(attestationKey, quote.signedData, quote.Signature) {
+		return appErrors.ErrVerifyingQuoteSignature.WithDetails("invalid quote signature")
+	}
+
+	return nil
+}
+
+// VerifyQuote verifies the signature chain of a quote and evaluates its TCB status against the collateral.
+//
+// All checks are evaluated at the given time, so an archived quote can be checked against the
+// collateral that was current when it was produced.
+func VerifyQuote(quote *Quote, collateral *Collateral, at time.Time) (*QuoteVerificationResult, *appErrors.AppError) {
+	if err := VerifyQuoteSignatures(quote, collateral, at); err != nil {
+		return nil, err
+	}
+	return EvaluateQuoteTCB(quote, collateral, at)
+}
+
+// EvaluateQuoteTCB verifies the signed collateral and evaluates the TCB status of a quote at the given time.
+//
+// It does not verify the quote signatures: it is meant for quotes already verified with VerifyQuoteSignatures.
+func EvaluateQuoteTCB(quote *Quote, collateral *Collateral, at time.Time) (*QuoteVerificationResult, *appErrors.AppError) {
+	if err := collateral.verifySignedCollateral(at); err != nil {
+		return nil, appErrors.ErrInvalidCollateral.WithDetails(err.Error())
+	}
+
+	// Evaluate the QE identity.
+	qeLevel, err := evaluateQEIdentity(quote, collateral.QEIdentity)
+	if err != nil {
+		return nil, err
+	}
+
+	// Evaluate the platform TCB level.
+	extensions, err := ParsePCKExtensions(quote.PCKCertificate())
+	if err != nil {
+		return nil, err
+	}
+	platformLevel, err := evaluateTCBInfo(extensions, collateral.TCBInfo)
+	if err != nil {
+		return nil, err
+	}
+
+	advisoryIDs := append([]string{}, platformLevel.AdvisoryIDs...)
+	for _, id := range qeLevel.AdvisoryIDs {
+		if !slices.Contains(advisoryIDs, id) {
+			advisoryIDs = append(advisoryIDs, id)
+		}
+	}
+
+	return &QuoteVerificationResult{
+		TCBStatus:               convergeTCBStatus(platformLevel.TCBStatus, qeLevel.TCBStatus),
+		AdvisoryIDs:             advisoryIDs,
+		PlatformTCBStatus:       platformLevel.TCBStatus,
+		TCBDate:                 platformLevel.TCBDate,
+		QEIdentityStatus:        qeLevel.TCBStatus,
+		TCBEvaluationDataNumber: collateral.TCBInfo.TCBEvaluationDataNumber,
+	}, nil
+}
+
+// evaluateQEIdentity matches the QE report against the QE identity and returns the QE TCB level.
+func evaluateQEIdentity(quote *Quote, identity *QEIdentity) (*QEIdentityLevel, *appErrors.AppError) {
+	mismatch := func(field string) *appErrors.AppError {
+		return appErrors.ErrTCBLevelNotFound.WithDetails(fmt.Sprintf("QE report %s does not match the QE identity", field))
+	}
+
+	qeReport := &quote.QEReport
+
+	mrSigner, err := hex.DecodeString(identity.MRSigner)
+	if err != nil || !bytes.Equal(mrSigner, qeReport.MRSigner[:]) {
+		return nil, mismatch("MRSIGNER")
+	}
+
+	if binary.LittleEndian.Uint16(qeReport.ISVProdID[:]) != identity.ISVProdID {
+		return nil, mismatch("ISVPRODID")
+	}
+
+	miscSelect, err1 := hexUint32(identity.MiscSelect)
+	miscSelectMask, err2 := hexUint32(identity.MiscSelectMask)
+	if err1 != nil || err2 != nil || binary.LittleEndian.Uint32(qeReport.MiscSelect[:])&miscSelectMask != miscSelect {
+		return nil, mismatch("MISCSELECT")
+	}
+
+	attributes, err1 := hex.DecodeString(identity.Attributes)
+	attributesMask, err2 := hex.DecodeString(identity.AttributesMask)
+	if err1 != nil || err2 != nil || len(attributes) != 16 || len(attributesMask) != 16 {
+		return nil, mismatch("ATTRIBUTES")
+	}
+	reportAttributes := make([]byte, 16)
+	binary.LittleEndian.PutUint64(reportAttributes[:8], qeReport.Attributes.Flags)
+	binary.LittleEndian.PutUint64(reportAttributes[8:], qeReport.Attributes.Xfrm)
+	for i := range reportAttributes {
+		if reportAttributes[i]&attributesMask[i] != attributes[i] {
+			return nil, mismatch("ATTRIBUTES")
+		}
+	}
+
+	// TCB levels are sorted from the highest to the lowest.
+	isvSVN := binary.LittleEndian.Uint16(qeReport.ISVSVN[:])
+	for i := range identity.TCBLevels {
+		if isvSVN >= identity.TCBLevels[i].TCB.ISVSVN {
+			return &identity.TCBLevels[i], nil
+		}
+	}
+	return nil, appErrors.ErrTCBLevelNotFound.WithDetails(fmt.Sprintf("no QE identity TCB level for ISVSVN %d", isvSVN))
+}
+
+// evaluateTCBInfo matches the PCK certificate TCB against the TCB info and returns the platform TCB level.
+func evaluateTCBInfo(extensions *PCKExtensions, tcbInfo *TCBInfo) (*TCBLevel, *appErrors.AppError) {
+	if !strings.EqualFold(tcbInfo.FMSPC, hex.EncodeToString(extensions.FMSPC)) {
+		return nil, appErrors.ErrTCBLevelNotFound.WithDetails(fmt.Sprintf("TCB info FMSPC %s does not match PCK FMSPC %x", tcbInfo.FMSPC, extensions.FMSPC))
+	}
+	if !strings.EqualFold(tcbInfo.PCEID, hex.EncodeToString(extensions.PCEID)) {
+		return nil, appErrors.ErrTCBLevelNotFound.WithDetails(fmt.Sprintf("TCB info PCEID %s does not match PCK PCEID %x", tcbInfo.PCEID, extensions.PCEID))
+	}
+
+	// TCB levels are sorted from the highest to the lowest. The first level not higher than the
+	// PCK TCB in any component is the platform TCB level.
+	for i := range tcbInfo.TCBLevels {
+		level := &tcbInfo.TCBLevels[i]
+		if len(level.TCB.SGXTCBComponents) != pckTCBComponents {
+			continue
+		}
+
+		matches := extensions.PCESVN >= level.TCB.PCESVN
+		for j, component := range level.TCB.SGXTCBComponents {
+			if extensions.TCBComponentSVNs[j] < component.SVN {
+				matches = false
+				break
+			}
+		}
+		if matches {
+			return level, nil
+		}
+	}
+	return nil, appErrors.ErrTCBLevelNotFound.WithDetails("no TCB info level matches the PCK certificate TCB")
+}
+
+// convergeTCBStatus combines the platform TCB status with the QE identity status.
+func convergeTCBStatus(platformStatus, qeStatus string) string {
+	switch qeStatus {
+	case TCBStatusRevoked:
+		return TCBStatusRevoked
+	case TCBStatusOutOfDate:
+		switch platformStatus {
+		case TCBStatusUpToDate, TCBStatusSWHardeningNeeded:
+			return TCBStatusOutOfDate
+		case TCBStatusConfigurationNeeded, TCBStatusConfigurationAndSWHardeningNeeded:
+			return TCBStatusOutOfDateConfigurationNeeded
+		}
+	}
+	return platformStatus
+}
+
+// hexUint32 parses a big-endian hex encoded uint32.
+func hexUint32(s string) (uint32, error) {
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		return 0, err
+	}
+	if len(b) != 4 {
+		return 0, fmt.Errorf("expected 4 bytes, got %d", len(b))
+	}
+	return binary.BigEndian.Uint32(b), nil
+}
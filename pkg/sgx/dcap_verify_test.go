@@ -0,0 +1,240 @@
+package sgx
+
+import (
+	"bytes"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	appErrors "github.com/venture23-aleo/aleo-oracle-notarization-backend/pkg/errors"
+)
+
+func newSimulatedProvider(t *testing.T) *simulatedQuoteProvider {
+	provider, err := newSimulatedQuoteProvider()
+	require.NoError(t, err)
+	return provider.(*simulatedQuoteProvider)
+}
+
+func newParsedSimulatedQuote(t *testing.T, provider *simulatedQuoteProvider) *Quote {
+	rawQuote, appErr := provider.GenerateRawQuote(make([]byte, SGXReportDataSize))
+	require.Nil(t, appErr)
+	quote, appErr := ParseQuote(rawQuote)
+	require.Nil(t, appErr)
+	return quote
+}
+
+// pemCertificates PEM encodes the given certificates.
+func pemCertificates(certs ...*x509.Certificate) []byte {
+	var buf bytes.Buffer
+	for _, cert := range certs {
+		pem.Encode(&buf, &pem.Block{Type: "CERTIFICATE", Bytes: cert.Raw})
+	}
+	return buf.Bytes()
+}
+
+func TestVerifyQuote(t *testing.T) {
+	provider := newSimulatedProvider(t)
+	quote := newParsedSimulatedQuote(t, provider)
+
+	testCases := []struct {
+		name                string
+		platformStatus      string
+		qeStatus            string
+		expectedStatus      string
+		expectedAdvisoryIDs []string
+	}{
+		{name: "up to date", platformStatus: TCBStatusUpToDate, qeStatus: TCBStatusUpToDate, expectedStatus: TCBStatusUpToDate},
+		{name: "SW hardening needed", platformStatus: TCBStatusSWHardeningNeeded, qeStatus: TCBStatusUpToDate, expectedStatus: TCBStatusSWHardeningNeeded, expectedAdvisoryIDs: []string{"INTEL-SA-00615"}},
+		{name: "QE out of date", platformStatus: TCBStatusUpToDate, qeStatus: TCBStatusOutOfDate, expectedStatus: TCBStatusOutOfDate, expectedAdvisoryIDs: []string{"INTEL-SA-00657"}},
+		{name: "QE out of date with configuration needed", platformStatus: TCBStatusConfigurationNeeded, qeStatus: TCBStatusOutOfDate, expectedStatus: TCBStatusOutOfDateConfigurationNeeded, expectedAdvisoryIDs: []string{"INTEL-SA-00615", "INTEL-SA-00657"}},
+		{name: "QE revoked", platformStatus: TCBStatusUpToDate, qeStatus: TCBStatusRevoked, expectedStatus: TCBStatusRevoked, expectedAdvisoryIDs: []string{"INTEL-SA-00657"}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var platformAdvisories, qeAdvisories []string
+			if tc.platformStatus != TCBStatusUpToDate {
+				platformAdvisories = []string{"INTEL-SA-00615"}
+			}
+			if tc.qeStatus != TCBStatusUpToDate {
+				qeAdvisories = []string{"INTEL-SA-00657"}
+			}
+
+			collateral, err := provider.collateral(simulatedTCBLevels(tc.platformStatus, platformAdvisories...), simulatedQEIdentityLevels(tc.qeStatus, qeAdvisories...), nil)
+			require.NoError(t, err)
+
+			result, appErr := VerifyQuote(quote, collateral, time.Now())
+			require.Nil(t, appErr)
+			assert.Equal(t, tc.expectedStatus, result.TCBStatus)
+			assert.Equal(t, tc.platformStatus, result.PlatformTCBStatus)
+			assert.Equal(t, tc.qeStatus, result.QEIdentityStatus)
+			assert.ElementsMatch(t, tc.expectedAdvisoryIDs, result.AdvisoryIDs)
+		})
+	}
+}
+
+func TestVerifyQuote_Invalid(t *testing.T) {
+	provider := newSimulatedProvider(t)
+	upToDate := func() (*Collateral, error) {
+		return provider.collateral(simulatedTCBLevels(TCBStatusUpToDate), simulatedQEIdentityLevels(TCBStatusUpToDate), nil)
+	}
+
+	t.Run("tampered report body", func(t *testing.T) {
+		quote := newParsedSimulatedQuote(t, provider)
+		quote.signedData = append([]byte{}, quote.signedData...)
+		quote.signedData[QuoteHeaderSize] ^= 0xff
+
+		collateral, err := upToDate()
+		require.NoError(t, err)
+		_, appErr := VerifyQuote(quote, collateral, time.Now())
+		require.NotNil(t, appErr)
+		assert.Equal(t, appErrors.ErrVerifyingQuoteSignature.Code, appErr.Code)
+	})
+
+	t.Run("tampered attestation key", func(t *testing.T) {
+		quote := newParsedSimulatedQuote(t, provider)
+		quote.QEAuthData = append([]byte{}, quote.QEAuthData...)
+		quote.QEAuthData[0] ^= 0xff
+
+		collateral, err := upToDate()
+		require.NoError(t, err)
+		_, appErr := VerifyQuote(quote, collateral, time.Now())
+		require.NotNil(t, appErr)
+		assert.Equal(t, appErrors.ErrVerifyingQuoteSignature.Code, appErr.Code)
+	})
+
+	t.Run("untrusted root", func(t *testing.T) {
+		quote := newParsedSimulatedQuote(t, provider)
+		collateral, err := newSimulatedProvider(t).collateral(simulatedTCBLevels(TCBStatusUpToDate), simulatedQEIdentityLevels(TCBStatusUpToDate), nil)
+		require.NoError(t, err)
+
+		_, appErr := VerifyQuote(quote, collateral, time.Now())
+		require.NotNil(t, appErr)
+		assert.Equal(t, appErrors.ErrVerifyingQuoteSignature.Code, appErr.Code)
+	})
+
+	t.Run("revoked PCK certificate", func(t *testing.T) {
+		quote := newParsedSimulatedQuote(t, provider)
+		collateral, err := provider.collateral(simulatedTCBLevels(TCBStatusUpToDate), simulatedQEIdentityLevels(TCBStatusUpToDate), []*big.Int{quote.PCKCertificate().SerialNumber})
+		require.NoError(t, err)
+
+		_, appErr := VerifyQuote(quote, collateral, time.Now())
+		require.NotNil(t, appErr)
+		assert.Equal(t, appErrors.ErrVerifyingQuoteSignature.Code, appErr.Code)
+		assert.Contains(t, appErr.Details, "revoked")
+	})
+
+	t.Run("expired collateral", func(t *testing.T) {
+		quote := newParsedSimulatedQuote(t, provider)
+		collateral, err := upToDate()
+		require.NoError(t, err)
+		collateral.TCBInfo.NextUpdate = time.Now().Add(-time.Minute)
+
+		_, appErr := VerifyQuote(quote, collateral, time.Now())
+		require.NotNil(t, appErr)
+		assert.Equal(t, appErrors.ErrInvalidCollateral.Code, appErr.Code)
+	})
+
+	t.Run("collateral not yet issued", func(t *testing.T) {
+		quote := newParsedSimulatedQuote(t, provider)
+		collateral, err := upToDate()
+		require.NoError(t, err)
+		collateral.QEIdentity.IssueDate = time.Now().Add(time.Minute)
+
+		_, appErr := VerifyQuote(quote, collateral, time.Now())
+		require.NotNil(t, appErr)
+		assert.Equal(t, appErrors.ErrInvalidCollateral.Code, appErr.Code)
+	})
+
+	t.Run("TDX QE identity", func(t *testing.T) {
+		quote := newParsedSimulatedQuote(t, provider)
+		collateral, err := upToDate()
+		require.NoError(t, err)
+		collateral.QEIdentity.ID = "TDQE"
+
+		_, appErr := VerifyQuote(quote, collateral, time.Now())
+		require.NotNil(t, appErr)
+		assert.Equal(t, appErrors.ErrInvalidCollateral.Code, appErr.Code)
+	})
+
+	t.Run("tampered TCB info", func(t *testing.T) {
+		quote := newParsedSimulatedQuote(t, provider)
+		collateral, err := upToDate()
+		require.NoError(t, err)
+		collateral.tcbInfoBody = append([]byte{}, collateral.tcbInfoBody...)
+		collateral.tcbInfoBody[len(collateral.tcbInfoBody)-2] ^= 0x01
+
+		_, appErr := VerifyQuote(quote, collateral, time.Now())
+		require.NotNil(t, appErr)
+		assert.Equal(t, appErrors.ErrInvalidCollateral.Code, appErr.Code)
+	})
+
+	t.Run("no matching TCB level", func(t *testing.T) {
+		quote := newParsedSimulatedQuote(t, provider)
+		levels := simulatedTCBLevels(TCBStatusUpToDate)
+		levels[0].TCB.PCESVN = 1
+		collateral, err := provider.collateral(levels, simulatedQEIdentityLevels(TCBStatusUpToDate), nil)
+		require.NoError(t, err)
+
+		_, appErr := VerifyQuote(quote, collateral, time.Now())
+		require.NotNil(t, appErr)
+		assert.Equal(t, appErrors.ErrTCBLevelNotFound.Code, appErr.Code)
+	})
+}
+
+func TestVerifyReportSignature(t *testing.T) {
+	provider := newSimulatedProvider(t)
+
+	quote := newParsedSimulatedQuote(t, provider)
+	assert.Nil(t, VerifyReportSignature(quote))
+
+	// The report signature does not depend on the collateral, so a tampered report data is caught without it.
+	quote.signedData = append([]byte{}, quote.signedData...)
+	quote.signedData[len(quote.signedData)-1] ^= 0xff
+	appErr := VerifyReportSignature(quote)
+	require.NotNil(t, appErr)
+	assert.Equal(t, appErrors.ErrVerifyingQuoteSignature.Code, appErr.Code)
+}
+
+func TestLoadCollateral(t *testing.T) {
+	provider := newSimulatedProvider(t)
+	collateral, err := provider.collateral(simulatedTCBLevels(TCBStatusUpToDate), simulatedQEIdentityLevels(TCBStatusUpToDate), nil)
+	require.NoError(t, err)
+
+	_, appErr := LoadCollateral(t.TempDir())
+	require.NotNil(t, appErr)
+	assert.Equal(t, appErrors.ErrLoadingCollateral.Code, appErr.Code)
+
+	// Write the collateral as it would be downloaded from the PCS and load it back.
+	dir := t.TempDir()
+	write := func(name string, data []byte) {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), data, 0600))
+	}
+	write(CollateralRootCAFile, pemCertificates(collateral.RootCA))
+	write(CollateralRootCACRLFile, collateral.CRLs[0].Raw)
+	write(CollateralTCBSigningChainFile, pemCertificates(collateral.TCBSigningChain...))
+	write(CollateralTCBInfoFile, []byte(`{"tcbInfo":`+string(collateral.tcbInfoBody)+`,"signature":"`+hex.EncodeToString(collateral.tcbInfoSignature)+`"}`))
+	write(CollateralQEIdentityFile, []byte(`{"enclaveIdentity":`+string(collateral.qeIdentityBody)+`,"signature":"`+hex.EncodeToString(collateral.qeIdentitySignature)+`"}`))
+
+	loaded, appErr := LoadCollateral(dir)
+	require.Nil(t, appErr)
+
+	// Collateral loaded from disk is only trusted when rooted at the Intel SGX Root CA.
+	quote := newParsedSimulatedQuote(t, provider)
+	_, appErr = VerifyQuote(quote, loaded, time.Now())
+	require.NotNil(t, appErr)
+	assert.Equal(t, appErrors.ErrVerifyingQuoteSignature.Code, appErr.Code)
+	assert.Contains(t, appErr.Details, "Intel SGX Root CA")
+
+	loaded.rootCAPublicKey = collateral.rootCAPublicKey
+	result, appErr := VerifyQuote(quote, loaded, time.Now())
+	require.Nil(t, appErr)
+	assert.Equal(t, TCBStatusUpToDate, result.TCBStatus)
+}
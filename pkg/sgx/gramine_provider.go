@@ -0,0 +1,104 @@
+package sgx
+
+import (
+	"fmt"
+	"syscall"
+
+	appErrors "github.com/venture23-aleo/aleo-oracle-notarization-backend/pkg/errors"
+	"github.com/venture23-aleo/aleo-oracle-notarization-backend/pkg/logger"
+)
+
+// gramineQuoteProvider generates reports and quotes through Gramine's low-level attestation interface.
+// Refer to https://gramine.readthedocs.io/en/stable/attestation.html#low-level-dev-attestation-interface
+type gramineQuoteProvider struct {
+	paths AttestationPaths
+}
+
+// newGramineQuoteProvider creates a Gramine quote provider using the given attestation paths.
+func newGramineQuoteProvider(paths AttestationPaths) *gramineQuoteProvider {
+	return &gramineQuoteProvider{paths: paths}
+}
+
+// Name returns the provider name.
+func (p *gramineQuoteProvider) Name() string {
+	return QuoteProviderGramine
+}
+
+// CheckEnvironment checks that all Gramine attestation pseudo-files are accessible and that
+// the attestation type is DCAP.
+func (p *gramineQuoteProvider) CheckEnvironment() error {
+	for _, path := range []string{
+		p.paths.MyTargetInfoPath,
+		p.paths.TargetInfoPath,
+		p.paths.UserReportDataPath,
+		p.paths.QuotePath,
+		p.paths.ReportPath,
+		p.paths.AttestationTypePath,
+	} {
+		fd, err := SecureOpenFile(GraminePseudoFilesRoot, path, ModeRead)
+		if err != nil {
+			return fmt.Errorf("reading %s: %w", path, err)
+		}
+		syscall.Close(fd)
+	}
+
+	attType, err := SecureReadFile(GraminePseudoFilesRoot, p.paths.AttestationTypePath)
+	if err != nil {
+		return fmt.Errorf("reading attestation_type: %w", err)
+	}
+
+	if string(attType) != AttestationType {
+		return fmt.Errorf("attestation type is not %s", AttestationType)
+	}
+
+	return nil
+}
+
+// GenerateReport generates a local SGX report targeted at the enclave itself.
+func (p *gramineQuoteProvider) GenerateReport(reportData []byte) ([]byte, *appErrors.AppError) {
+	// Read the target info from target info path
+	targetInfo, err := SecureReadFile(GraminePseudoFilesRoot, p.paths.MyTargetInfoPath)
+	if err != nil {
+		logger.Error("Error reading target info: ", "error", err)
+		return nil, appErrors.ErrReadingTargetInfo
+	}
+
+	// Write the target info to the target info path
+	if err := SecureWriteFile(GraminePseudoFilesRoot, p.paths.TargetInfoPath, targetInfo); err != nil {
+		logger.Error("Error writing target info: ", "error", err)
+		return nil, appErrors.ErrWritingTargetInfo
+	}
+
+	// Write report data
+	if err := SecureWriteFile(GraminePseudoFilesRoot, p.paths.UserReportDataPath, reportData); err != nil {
+		logger.Error("Error writing report data: ", "error", err)
+		return nil, appErrors.ErrWritingReportData
+	}
+
+	// Read the report from the report path
+	report, err := SecureReadFile(GraminePseudoFilesRoot, p.paths.ReportPath)
+	if err != nil {
+		logger.Error("Error reading report: ", "error", err)
+		return nil, appErrors.ErrReadingReport
+	}
+
+	return report, nil
+}
+
+// GenerateRawQuote writes the report data and reads back the raw DCAP quote.
+func (p *gramineQuoteProvider) GenerateRawQuote(reportData []byte) ([]byte, *appErrors.AppError) {
+	// Write the report data to the user report data path.
+	if err := SecureWriteFile(GraminePseudoFilesRoot, p.paths.UserReportDataPath, reportData); err != nil {
+		logger.Error("Error while writing report data:", "error", err)
+		return nil, appErrors.ErrWritingReportData
+	}
+
+	// Read the raw quote from the Gramine quote path.
+	quote, err := SecureReadFile(GraminePseudoFilesRoot, p.paths.QuotePath)
+	if err != nil {
+		logger.Error("Error while reading quote: ", "error", err)
+		return nil, appErrors.ErrReadingQuote
+	}
+
+	return quote, nil
+}
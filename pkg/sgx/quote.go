@@ -4,9 +4,22 @@ package sgx
 import (
 	"bytes"
 	"encoding/binary"
+	"fmt"
 
-	appErrors "github.com/venture23-aleo/aleo-oracle-notarization-backend/internal/errors"
-	"github.com/venture23-aleo/aleo-oracle-notarization-backend/internal/logger"
+	appErrors "github.com/venture23-aleo/aleo-oracle-notarization-backend/pkg/errors"
+	"github.com/venture23-aleo/aleo-oracle-notarization-backend/pkg/logger"
+)
+
+// Open Enclave evidence header constants.
+const (
+	oeVersionConst = 1 // Open Enclave evidence version
+	oeVersionLen   = 4 // Length of version field in bytes
+	oeTypeConst    = 2 // Open Enclave evidence type (2 = SGX ECDSA)
+	oeTypeLen      = 4 // Length of type field in bytes
+	oeQuoteLen     = 8 // Length of quote length field in bytes
+
+	// OpenEnclaveHeaderSize is the size of the Open Enclave evidence header in front of the raw quote.
+	OpenEnclaveHeaderSize = oeVersionLen + oeTypeLen + oeQuoteLen
 )
 
 // wrapRawQuoteAsOpenEnclaveEvidence wraps a raw SGX quote as Open Enclave evidence format.
@@ -26,14 +39,6 @@ import (
 // Returns:
 //   - []byte: The Open Enclave evidence buffer containing the headers and the raw quote.
 func wrapRawQuoteAsOpenEnclaveEvidence(rawQuoteBuffer []byte) []byte {
-	const (
-		oeVersionConst = 1 // Open Enclave evidence version
-		oeVersionLen   = 4 // Length of version field in bytes
-		oeTypeConst    = 2 // Open Enclave evidence type (2 = SGX ECDSA)
-		oeTypeLen      = 4 // Length of type field in bytes
-		oeQuoteLen     = 8 // Length of quote length field in bytes
-	)
-
 	// Create the Open Enclave version header (4 bytes, little-endian)
 	oeVersion := make([]byte, oeVersionLen)
 	binary.LittleEndian.PutUint32(oeVersion, oeVersionConst)
@@ -57,14 +62,41 @@ func wrapRawQuoteAsOpenEnclaveEvidence(rawQuoteBuffer []byte) []byte {
 	return buf.Bytes()
 }
 
+// UnwrapOpenEnclaveEvidence strips the Open Enclave evidence header and returns the raw SGX quote.
+//
+// It checks the evidence version and type, and that the quote length in the header matches the buffer.
+func UnwrapOpenEnclaveEvidence(evidence []byte) ([]byte, *appErrors.AppError) {
+	if len(evidence) < OpenEnclaveHeaderSize {
+		return nil, appErrors.ErrUnwrappingQuote.WithDetails("evidence shorter than the openenclave header")
+	}
+
+	version := binary.LittleEndian.Uint32(evidence[:oeVersionLen])
+	evidenceType := binary.LittleEndian.Uint32(evidence[oeVersionLen : oeVersionLen+oeTypeLen])
+	if version != oeVersionConst || evidenceType != oeTypeConst {
+		return nil, appErrors.ErrUnwrappingQuote.WithDetails(fmt.Sprintf("unexpected evidence version %d or type %d", version, evidenceType))
+	}
+
+	quoteLength := binary.LittleEndian.Uint64(evidence[oeVersionLen+oeTypeLen : OpenEnclaveHeaderSize])
+	quote := evidence[OpenEnclaveHeaderSize:]
+	if quoteLength != uint64(len(quote)) {
+		return nil, appErrors.ErrUnwrappingQuote.WithDetails(fmt.Sprintf("quote length %d does not match header length %d", len(quote), quoteLength))
+	}
+
+	if len(quote) < QuoteMinSize {
+		return nil, appErrors.ErrInvalidSGXQuoteSize
+	}
+
+	return quote, nil
+}
+
 // GenerateQuote generates a quote for the attestation service.
 // Refer to https://gramine.readthedocs.io/en/stable/attestation.html#low-level-dev-attestation-interface
 //
 // This function performs the following steps sequentially:
 // 1. Acquires a lock to ensure thread-safe quote generation (as quote generation is not thread-safe).
 // 2. Prepares a 64-byte report data buffer, copying the provided inputData into it (truncating or zero-padding as needed).
-// 3. Writes the report data to the user report data path specified in the Gramine configuration.
-// 4. Reads the raw SGX quote from the Gramine quote path.
+// 3. Writes the report data through the active quote provider (Gramine's user report data path by default).
+// 4. Reads the raw SGX quote from the active quote provider.
 // 5. Wraps the raw quote as Open Enclave evidence, as required by the contract and verifier backend.
 // 6. Returns the wrapped quote as a byte slice, or an error if any step fails.
 //
@@ -87,18 +119,10 @@ func GenerateQuote(inputData []byte) ([]byte, *appErrors.AppError) {
 	reportData := make([]byte, 64)
 	copy(reportData, inputData) // Copy inputData (truncates or zero-pads as needed)
 
-	// Step 3: Write the report data to the user report data path.
-	err := SecureWriteFile(GraminePseudoFilesRoot, gramineAttestationPaths.UserReportDataPath, reportData)
-	if err != nil {
-		logger.Error("Error while writing report data:", "error", err)
-		return nil, appErrors.ErrWritingReportData
-	}
-
-	// Step 4: Read the raw quote from the Gramine quote path.
-	quote, err := SecureReadFile(GraminePseudoFilesRoot, gramineAttestationPaths.QuotePath)
-	if err != nil {
-		logger.Error("Error while reading quote: ", "error", err)
-		return nil, appErrors.ErrReadingQuote
+	// Step 3 & 4: Write the report data and read the raw quote through the active quote provider.
+	quote, appErr := GetQuoteProvider().GenerateRawQuote(reportData)
+	if appErr != nil {
+		return nil, appErr
 	}
 
 	if len(quote) < QuoteMinSize {
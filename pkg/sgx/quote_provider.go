@@ -0,0 +1,96 @@
+package sgx
+
+import (
+	"fmt"
+	"sync"
+
+	appErrors "github.com/venture23-aleo/aleo-oracle-notarization-backend/pkg/errors"
+	"github.com/venture23-aleo/aleo-oracle-notarization-backend/pkg/logger"
+)
+
+// Quote provider names.
+const (
+	// QuoteProviderGramine is the production provider backed by Gramine's /dev/attestation pseudo-files.
+	QuoteProviderGramine = "gramine"
+
+	// QuoteProviderSimulated produces structurally valid but untrusted reports and quotes for non-enclave runs.
+	QuoteProviderSimulated = "simulated"
+
+	// AllowSimulatedSGXEnv is the environment variable that must be set to "true" to start with the simulated provider.
+	AllowSimulatedSGXEnv = "ALLOW_SIMULATED_SGX"
+)
+
+// QuoteProvider is the source of SGX reports and quotes.
+//
+// Implementations only deal with the raw enclave artifacts. Locking, size checks and the
+// Open Enclave wrapping are applied by the package-level GenerateQuote and GenerateSGXReport.
+type QuoteProvider interface {
+	// Name returns the provider name as shown in /info.
+	Name() string
+
+	// CheckEnvironment verifies that the provider can operate in the current environment.
+	CheckEnvironment() error
+
+	// GenerateReport returns a raw 432-byte SGX report committing to the 64-byte report data.
+	GenerateReport(reportData []byte) ([]byte, *appErrors.AppError)
+
+	// GenerateRawQuote returns a raw DCAP quote committing to the 64-byte report data.
+	GenerateRawQuote(reportData []byte) ([]byte, *appErrors.AppError)
+}
+
+var (
+	quoteProviderMu     sync.RWMutex
+	activeQuoteProvider QuoteProvider = newGramineQuoteProvider(gramineAttestationPaths)
+)
+
+// NewQuoteProvider creates the quote provider with the given name.
+//
+// An empty name selects the Gramine provider. The simulated provider is refused unless
+// allowSimulated is set, so that a misconfigured production build can never serve fake quotes.
+func NewQuoteProvider(name string, allowSimulated bool) (QuoteProvider, error) {
+	switch name {
+	case "", QuoteProviderGramine:
+		return newGramineQuoteProvider(gramineAttestationPaths), nil
+	case QuoteProviderSimulated:
+		if !allowSimulated {
+			return nil, fmt.Errorf("quote provider %q requires %s=true", QuoteProviderSimulated, AllowSimulatedSGXEnv)
+		}
+		return newSimulatedQuoteProvider()
+	default:
+		return nil, fmt.Errorf("unknown quote provider %q", name)
+	}
+}
+
+// InitQuoteProvider creates the named quote provider and makes it the active one.
+func InitQuoteProvider(name string, allowSimulated bool) error {
+	provider, err := NewQuoteProvider(name, allowSimulated)
+	if err != nil {
+		return err
+	}
+
+	if provider.Name() == QuoteProviderSimulated {
+		logger.Warn("USING SIMULATED SGX QUOTE PROVIDER - reports and quotes are NOT backed by an enclave and must not be trusted")
+	}
+
+	SetQuoteProvider(provider)
+	return nil
+}
+
+// SetQuoteProvider replaces the active quote provider.
+func SetQuoteProvider(provider QuoteProvider) {
+	quoteProviderMu.Lock()
+	defer quoteProviderMu.Unlock()
+	activeQuoteProvider = provider
+}
+
+// GetQuoteProvider returns the active quote provider.
+func GetQuoteProvider() QuoteProvider {
+	quoteProviderMu.RLock()
+	defer quoteProviderMu.RUnlock()
+	return activeQuoteProvider
+}
+
+// GetQuoteProviderName returns the name of the active quote provider.
+func GetQuoteProviderName() string {
+	return GetQuoteProvider().Name()
+}
@@ -0,0 +1,82 @@
+package sgx
+
+import (
+	"encoding/binary"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewQuoteProvider(t *testing.T) {
+	provider, err := NewQuoteProvider("", false)
+	require.NoError(t, err)
+	assert.Equal(t, QuoteProviderGramine, provider.Name())
+
+	provider, err = NewQuoteProvider(QuoteProviderGramine, false)
+	require.NoError(t, err)
+	assert.Equal(t, QuoteProviderGramine, provider.Name())
+
+	_, err = NewQuoteProvider(QuoteProviderSimulated, false)
+	assert.Error(t, err, "simulated provider must be refused without the dev flag")
+
+	provider, err = NewQuoteProvider(QuoteProviderSimulated, true)
+	require.NoError(t, err)
+	assert.Equal(t, QuoteProviderSimulated, provider.Name())
+
+	_, err = NewQuoteProvider("unknown", true)
+	assert.Error(t, err)
+}
+
+func TestSimulatedQuoteProvider_GenerateRawQuote(t *testing.T) {
+	provider, err := NewQuoteProvider(QuoteProviderSimulated, true)
+	require.NoError(t, err)
+	require.NoError(t, provider.CheckEnvironment())
+
+	reportData := make([]byte, SGXReportDataSize)
+	copy(reportData, "report data")
+
+	quote, appErr := provider.GenerateRawQuote(reportData)
+	require.Nil(t, appErr)
+
+	assert.GreaterOrEqual(t, len(quote), QuoteMinSize)
+	assert.Equal(t, uint16(QuoteVersion3), binary.LittleEndian.Uint16(quote[0:2]))
+	assert.Equal(t, uint16(AttestationKeyTypeECDSAP256), binary.LittleEndian.Uint16(quote[2:4]))
+
+	reportDataOffset := QuoteHeaderSize + SGXReportBodySize - SGXReportDataSize
+	assert.Equal(t, reportData, quote[reportDataOffset:reportDataOffset+SGXReportDataSize])
+
+	signatureDataLen := binary.LittleEndian.Uint32(quote[QuoteHeaderSize+SGXReportBodySize:])
+	assert.Equal(t, len(quote)-QuoteHeaderSize-SGXReportBodySize-4, int(signatureDataLen))
+}
+
+func TestSimulatedQuoteProvider_GenerateReport(t *testing.T) {
+	provider, err := NewQuoteProvider(QuoteProviderSimulated, true)
+	require.NoError(t, err)
+
+	report, appErr := provider.GenerateReport(make([]byte, SGXReportDataSize))
+	require.Nil(t, appErr)
+	require.Len(t, report, SGXReportSize)
+
+	parsed, appErr := ParseSGXReport(report)
+	require.Nil(t, appErr)
+	assert.Zero(t, parsed.Body.Attributes.Flags&DebugFlagMask, "simulated report must not be in debug mode")
+}
+
+func TestGenerateQuote_SimulatedProvider(t *testing.T) {
+	require.NoError(t, InitQuoteProvider(QuoteProviderSimulated, true))
+	t.Cleanup(func() {
+		SetQuoteProvider(newGramineQuoteProvider(gramineAttestationPaths))
+	})
+
+	assert.Equal(t, QuoteProviderSimulated, GetQuoteProviderName())
+	require.NoError(t, EnforceSGXStartup())
+
+	quote, appErr := GenerateQuote([]byte("test"))
+	require.Nil(t, appErr)
+
+	// Open Enclave evidence header: version 1, type 2, quote length.
+	assert.Equal(t, uint32(1), binary.LittleEndian.Uint32(quote[0:4]))
+	assert.Equal(t, uint32(2), binary.LittleEndian.Uint32(quote[4:8]))
+	assert.Equal(t, len(quote)-16, int(binary.LittleEndian.Uint32(quote[8:12])))
+}
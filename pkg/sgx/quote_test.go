@@ -0,0 +1,33 @@
+package sgx
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	appErrors "github.com/venture23-aleo/aleo-oracle-notarization-backend/pkg/errors"
+)
+
+func TestUnwrapOpenEnclaveEvidence(t *testing.T) {
+	rawQuote := make([]byte, QuoteMinSize)
+	rawQuote[0] = QuoteVersion3
+
+	evidence := wrapRawQuoteAsOpenEnclaveEvidence(rawQuote)
+	quote, err := UnwrapOpenEnclaveEvidence(evidence)
+	require.Nil(t, err)
+	assert.Equal(t, rawQuote, quote)
+
+	_, err = UnwrapOpenEnclaveEvidence(evidence[:OpenEnclaveHeaderSize-1])
+	assert.Equal(t, appErrors.ErrUnwrappingQuote.Code, err.Code)
+
+	_, err = UnwrapOpenEnclaveEvidence(evidence[:len(evidence)-1])
+	assert.Equal(t, appErrors.ErrUnwrappingQuote.Code, err.Code)
+
+	badType := append([]byte{}, evidence...)
+	badType[4] = 3
+	_, err = UnwrapOpenEnclaveEvidence(badType)
+	assert.Equal(t, appErrors.ErrUnwrappingQuote.Code, err.Code)
+
+	_, err = UnwrapOpenEnclaveEvidence(wrapRawQuoteAsOpenEnclaveEvidence(rawQuote[:QuoteMinSize-1]))
+	assert.Equal(t, appErrors.ErrInvalidSGXQuoteSize, err)
+}
@@ -4,8 +4,8 @@ import (
 	"bytes"
 	"encoding/binary"
 
-	appErrors "github.com/venture23-aleo/aleo-oracle-notarization-backend/internal/errors"
-	"github.com/venture23-aleo/aleo-oracle-notarization-backend/internal/logger"
+	appErrors "github.com/venture23-aleo/aleo-oracle-notarization-backend/pkg/errors"
+	"github.com/venture23-aleo/aleo-oracle-notarization-backend/pkg/logger"
 )
 
 // SGX report body is structured as follows:
@@ -64,31 +64,11 @@ func GenerateSGXReport() ([]byte, *appErrors.AppError) {
 	enclaveLock.Lock()
 	defer enclaveLock.Unlock()
 
-	// Read the target info from target info path
-	targetInfo, err := SecureReadFile(GraminePseudoFilesRoot, gramineAttestationPaths.MyTargetInfoPath)
-	if err != nil {
-		logger.Error("Error reading target info: ", "error", err)
-		return nil, appErrors.ErrReadingTargetInfo
-	}
-
-	// Write the target info to the target info path
-	if err := SecureWriteFile(GraminePseudoFilesRoot, gramineAttestationPaths.TargetInfoPath, targetInfo); err != nil {
-		logger.Error("Error writing target info: ", "error", err)
-		return nil, appErrors.ErrWritingTargetInfo
-	}
-
-	// Create and write report data
+	// Create the report data and generate the report through the active quote provider
 	reportData := make([]byte, SGXReportDataSize)
-	if err := SecureWriteFile(GraminePseudoFilesRoot, gramineAttestationPaths.UserReportDataPath, reportData); err != nil {
-		logger.Error("Error writing report data: ", "error", err)
-		return nil, appErrors.ErrWritingReportData
-	}
-
-	// Read the report from the report path
-	report, err := SecureReadFile(GraminePseudoFilesRoot, gramineAttestationPaths.ReportPath)
-	if err != nil {
-		logger.Error("Error reading report: ", "error", err)
-		return nil, appErrors.ErrReadingReport
+	report, appErr := GetQuoteProvider().GenerateReport(reportData)
+	if appErr != nil {
+		return nil, appErr
 	}
 
 	if len(report) != SGXReportSize {
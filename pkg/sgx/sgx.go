@@ -3,7 +3,6 @@ package sgx
 import (
 	"fmt"
 	"sync"
-	"syscall"
 )
 
 // SGX Constants
@@ -60,29 +59,10 @@ var gramineAttestationPaths = AttestationPaths{
 	QuotePath:           "/dev/attestation/quote",
 }
 
+// EnforceSGXStartup verifies that the active quote provider can produce a non-debug report and a quote.
 func EnforceSGXStartup() error {
-	for _, path := range []string{
-		gramineAttestationPaths.MyTargetInfoPath,
-		gramineAttestationPaths.TargetInfoPath,
-		gramineAttestationPaths.UserReportDataPath,
-		gramineAttestationPaths.QuotePath,
-		gramineAttestationPaths.ReportPath,
-		gramineAttestationPaths.AttestationTypePath,
-	} {
-		fd, err := SecureOpenFile(GraminePseudoFilesRoot, path, ModeRead)
-		if err != nil {
-			return fmt.Errorf("reading %s: %w", path, err)
-		}
-		syscall.Close(fd)
-	}
-
-	attType, err := SecureReadFile(GraminePseudoFilesRoot, gramineAttestationPaths.AttestationTypePath)
-	if err != nil {
-		return fmt.Errorf("reading attestation_type: %w", err)
-	}
-
-	if string(attType) != AttestationType {
-		return fmt.Errorf("attestation type is not %s", AttestationType)
+	if err := GetQuoteProvider().CheckEnvironment(); err != nil {
+		return err
 	}
 
 	sgxReport, reportErr := GenerateSGXReport()
@@ -0,0 +1,155 @@
+package sgx
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"time"
+
+	appErrors "github.com/venture23-aleo/aleo-oracle-notarization-backend/pkg/errors"
+)
+
+// simulatedQEMRSigner is the MRSIGNER of the simulated Quoting Enclave.
+var simulatedQEMRSigner = sha256.Sum256([]byte("simulated-qe-mrsigner"))
+
+// GetSimulatedCollateral returns collateral for the quotes of the active simulated quote provider.
+//
+// The collateral is rooted at the simulated root CA and reports every simulated quote as UpToDate.
+// It only exists so the verification path can be exercised outside SGX hardware.
+func GetSimulatedCollateral() (*Collateral, *appErrors.AppError) {
+	provider, ok := GetQuoteProvider().(*simulatedQuoteProvider)
+	if !ok {
+		return nil, appErrors.ErrLoadingCollateral.WithDetails("the active quote provider is not simulated")
+	}
+
+	collateral, err := provider.collateral(simulatedTCBLevels(TCBStatusUpToDate), simulatedQEIdentityLevels(TCBStatusUpToDate), nil)
+	if err != nil {
+		return nil, appErrors.ErrLoadingCollateral.WithDetails(err.Error())
+	}
+	return collateral, nil
+}
+
+// simulatedTCBLevels returns a single TCB level matching the simulated PCK certificate.
+func simulatedTCBLevels(status string, advisoryIDs ...string) []TCBLevel {
+	return []TCBLevel{{
+		TCB:         PlatformTCB{SGXTCBComponents: make([]TCBComponent, pckTCBComponents)},
+		TCBDate:     time.Now().Add(-24 * time.Hour).UTC().Truncate(time.Second),
+		TCBStatus:   status,
+		AdvisoryIDs: advisoryIDs,
+	}}
+}
+
+// simulatedQEIdentityLevels returns a single QE identity level matching the simulated QE report.
+func simulatedQEIdentityLevels(status string, advisoryIDs ...string) []QEIdentityLevel {
+	return []QEIdentityLevel{{
+		TCBDate:     time.Now().Add(-24 * time.Hour).UTC().Truncate(time.Second),
+		TCBStatus:   status,
+		AdvisoryIDs: advisoryIDs,
+	}}
+}
+
+// collateral builds signed collateral for the simulated certificate chain. The CRL issued by the
+// simulated root revokes the given serial numbers.
+func (p *simulatedQuoteProvider) collateral(tcbLevels []TCBLevel, qeLevels []QEIdentityLevel, revoked []*big.Int) (*Collateral, error) {
+	now := time.Now().UTC().Truncate(time.Second)
+
+	// TCB signing certificate issued by the simulated root.
+	signingKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, fmt.Errorf("generating simulated TCB signing key: %w", err)
+	}
+	signingTemplate := &x509.Certificate{
+		SerialNumber: big.NewInt(3),
+		Subject:      pkix.Name{CommonName: "Simulated SGX TCB Signing", Organization: []string{"Simulated"}},
+		NotBefore:    now.Add(-time.Hour),
+		NotAfter:     now.AddDate(1, 0, 0),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+	}
+	signingDER, err := x509.CreateCertificate(rand.Reader, signingTemplate, p.rootCert, &signingKey.PublicKey, p.rootKey)
+	if err != nil {
+		return nil, fmt.Errorf("creating simulated TCB signing certificate: %w", err)
+	}
+
+	var revokedEntries []x509.RevocationListEntry
+	for _, serial := range revoked {
+		revokedEntries = append(revokedEntries, x509.RevocationListEntry{SerialNumber: serial, RevocationTime: now})
+	}
+	crlDER, err := x509.CreateRevocationList(rand.Reader, &x509.RevocationList{
+		Number:                    big.NewInt(1),
+		ThisUpdate:                now.Add(-time.Hour),
+		NextUpdate:                now.AddDate(0, 1, 0),
+		RevokedCertificateEntries: revokedEntries,
+	}, p.rootCert, p.rootKey)
+	if err != nil {
+		return nil, fmt.Errorf("creating simulated CRL: %w", err)
+	}
+
+	tcbInfo, err := json.Marshal(&TCBInfo{
+		ID:                      TCBInfoIDSGX,
+		Version:                 TCBInfoVersion,
+		IssueDate:               now,
+		NextUpdate:              now.AddDate(0, 1, 0),
+		FMSPC:                   hex.EncodeToString(simulatedFMSPC),
+		PCEID:                   hex.EncodeToString(simulatedPCEID),
+		TCBEvaluationDataNumber: 1,
+		TCBLevels:               tcbLevels,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	qeIdentity, err := json.Marshal(&QEIdentity{
+		ID:                      QEIdentityIDQE,
+		Version:                 2,
+		IssueDate:               now,
+		NextUpdate:              now.AddDate(0, 1, 0),
+		TCBEvaluationDataNumber: 1,
+		MiscSelect:              "00000000",
+		MiscSelectMask:          "FFFFFFFF",
+		Attributes:              "01000000000000000000000000000000",
+		AttributesMask:          "FBFFFFFFFFFFFFFF0000000000000000",
+		MRSigner:                hex.EncodeToString(simulatedQEMRSigner[:]),
+		TCBLevels:               qeLevels,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	signDocument := func(field string, body []byte) ([]byte, error) {
+		signature, err := signRawECDSA(signingKey, body)
+		if err != nil {
+			return nil, err
+		}
+		return json.Marshal(map[string]any{field: json.RawMessage(body), "signature": hex.EncodeToString(signature)})
+	}
+	tcbInfoJSON, err := signDocument("tcbInfo", tcbInfo)
+	if err != nil {
+		return nil, fmt.Errorf("signing simulated TCB info: %w", err)
+	}
+	qeIdentityJSON, err := signDocument("enclaveIdentity", qeIdentity)
+	if err != nil {
+		return nil, fmt.Errorf("signing simulated QE identity: %w", err)
+	}
+
+	var rootPEM, signingChainPEM bytes.Buffer
+	pem.Encode(&rootPEM, &pem.Block{Type: "CERTIFICATE", Bytes: p.rootCert.Raw})
+	pem.Encode(&signingChainPEM, &pem.Block{Type: "CERTIFICATE", Bytes: signingDER})
+	pem.Encode(&signingChainPEM, &pem.Block{Type: "CERTIFICATE", Bytes: p.rootCert.Raw})
+
+	collateral, appErr := ParseCollateral(rootPEM.Bytes(), [][]byte{crlDER}, tcbInfoJSON, qeIdentityJSON, signingChainPEM.Bytes())
+	if appErr != nil {
+		return nil, fmt.Errorf("%s: %s", appErr.Message, appErr.Details)
+	}
+	// Simulated collateral is rooted at the simulated root CA instead of the Intel SGX Root CA.
+	collateral.rootCAPublicKey = p.rootCert.RawSubjectPublicKeyInfo
+	return collateral, nil
+}
@@ -0,0 +1,285 @@
+package sgx
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/binary"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"time"
+
+	appErrors "github.com/venture23-aleo/aleo-oracle-notarization-backend/pkg/errors"
+	"github.com/venture23-aleo/aleo-oracle-notarization-backend/pkg/logger"
+)
+
+// simulatedQEUserData marks the quote header so that a simulated quote can never be mistaken for a real one.
+const simulatedQEUserData = "SIMULATED-SGX-QUOTE"
+
+// simulatedQuoteProvider produces structurally valid DCAP v3 reports and quotes without an enclave.
+//
+// The quotes are signed by an attestation key and a PCK certificate chain generated at startup,
+// rooted at a self-signed certificate that is NOT the Intel SGX root. They only exist so the
+// service can be exercised outside SGX hardware.
+type simulatedQuoteProvider struct {
+	attestationKey *ecdsa.PrivateKey
+	pckKey         *ecdsa.PrivateKey
+	rootKey        *ecdsa.PrivateKey
+	rootCert       *x509.Certificate
+	certChainPEM   []byte
+	mrEnclave      [32]byte
+	mrSigner       [32]byte
+}
+
+// newSimulatedQuoteProvider creates a simulated quote provider with freshly generated keys.
+func newSimulatedQuoteProvider() (QuoteProvider, error) {
+	attestationKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, fmt.Errorf("generating simulated attestation key: %w", err)
+	}
+
+	rootKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, fmt.Errorf("generating simulated root key: %w", err)
+	}
+
+	pckKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, fmt.Errorf("generating simulated PCK key: %w", err)
+	}
+
+	now := time.Now()
+
+	rootTemplate := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "Simulated SGX Root CA", Organization: []string{"Simulated"}},
+		NotBefore:             now.Add(-time.Hour),
+		NotAfter:              now.AddDate(1, 0, 0),
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageCRLSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	rootDER, err := x509.CreateCertificate(rand.Reader, rootTemplate, rootTemplate, &rootKey.PublicKey, rootKey)
+	if err != nil {
+		return nil, fmt.Errorf("creating simulated root certificate: %w", err)
+	}
+	rootCert, err := x509.ParseCertificate(rootDER)
+	if err != nil {
+		return nil, fmt.Errorf("parsing simulated root certificate: %w", err)
+	}
+
+	pckExtension, err := simulatedPCKExtension()
+	if err != nil {
+		return nil, fmt.Errorf("creating simulated PCK extensions: %w", err)
+	}
+
+	pckTemplate := &x509.Certificate{
+		SerialNumber: big.NewInt(2),
+		Subject:      pkix.Name{CommonName: "Simulated SGX PCK Certificate", Organization: []string{"Simulated"}},
+		NotBefore:    now.Add(-time.Hour),
+		NotAfter:     now.AddDate(1, 0, 0),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+
+		ExtraExtensions: []pkix.Extension{pckExtension},
+	}
+	pckDER, err := x509.CreateCertificate(rand.Reader, pckTemplate, rootCert, &pckKey.PublicKey, rootKey)
+	if err != nil {
+		return nil, fmt.Errorf("creating simulated PCK certificate: %w", err)
+	}
+
+	var chain bytes.Buffer
+	pem.Encode(&chain, &pem.Block{Type: "CERTIFICATE", Bytes: pckDER})
+	pem.Encode(&chain, &pem.Block{Type: "CERTIFICATE", Bytes: rootDER})
+
+	return &simulatedQuoteProvider{
+		attestationKey: attestationKey,
+		pckKey:         pckKey,
+		rootKey:        rootKey,
+		rootCert:       rootCert,
+		certChainPEM:   chain.Bytes(),
+		mrEnclave:      sha256.Sum256([]byte("simulated-mrenclave")),
+		mrSigner:       sha256.Sum256([]byte("simulated-mrsigner")),
+	}, nil
+}
+
+// Name returns the provider name.
+func (p *simulatedQuoteProvider) Name() string {
+	return QuoteProviderSimulated
+}
+
+// CheckEnvironment always succeeds, the simulated provider has no environment requirements.
+func (p *simulatedQuoteProvider) CheckEnvironment() error {
+	return nil
+}
+
+// GenerateReport returns a 432-byte report with a zero key ID and MAC.
+func (p *simulatedQuoteProvider) GenerateReport(reportData []byte) ([]byte, *appErrors.AppError) {
+	body, appErr := p.reportBody(p.mrEnclave, p.mrSigner, reportData)
+	if appErr != nil {
+		return nil, appErr
+	}
+
+	report := make([]byte, SGXReportSize)
+	copy(report, body)
+	return report, nil
+}
+
+// GenerateRawQuote returns a DCAP v3 quote signed by the simulated attestation key.
+func (p *simulatedQuoteProvider) GenerateRawQuote(reportData []byte) ([]byte, *appErrors.AppError) {
+	body, appErr := p.reportBody(p.mrEnclave, p.mrSigner, reportData)
+	if appErr != nil {
+		return nil, appErr
+	}
+
+	header := make([]byte, QuoteHeaderSize)
+	binary.LittleEndian.PutUint16(header[0:2], QuoteVersion3)
+	binary.LittleEndian.PutUint16(header[2:4], AttestationKeyTypeECDSAP256)
+	// header[4:8] is reserved in v3, header[8:10] QE SVN, header[10:12] PCE SVN, header[12:28] QE vendor ID
+	copy(header[28:48], simulatedQEUserData)
+
+	// The ISV enclave report signature covers the header and the report body.
+	quoteSignature, err := signRawECDSA(p.attestationKey, append(append([]byte{}, header...), body...))
+	if err != nil {
+		logger.Error("Error signing simulated quote: ", "error", err)
+		return nil, appErrors.ErrReadingQuote
+	}
+
+	attestationPublicKey := rawECDSAPublicKey(&p.attestationKey.PublicKey)
+
+	authData := make([]byte, 32)
+	if _, err := rand.Read(authData); err != nil {
+		logger.Error("Error generating simulated QE auth data: ", "error", err)
+		return nil, appErrors.ErrReadingQuote
+	}
+
+	// The QE report data binds the attestation key: SHA256(attestation key || auth data) || 32 zero bytes.
+	qeReportData := sha256.Sum256(append(append([]byte{}, attestationPublicKey...), authData...))
+	qeReport, appErr := p.reportBody(sha256.Sum256([]byte("simulated-qe-mrenclave")), simulatedQEMRSigner, qeReportData[:])
+	if appErr != nil {
+		return nil, appErr
+	}
+
+	qeReportSignature, err := signRawECDSA(p.pckKey, qeReport)
+	if err != nil {
+		logger.Error("Error signing simulated QE report: ", "error", err)
+		return nil, appErrors.ErrReadingQuote
+	}
+
+	var signatureData bytes.Buffer
+	signatureData.Write(quoteSignature)
+	signatureData.Write(attestationPublicKey)
+	signatureData.Write(qeReport)
+	signatureData.Write(qeReportSignature)
+	binary.Write(&signatureData, binary.LittleEndian, uint16(len(authData)))
+	signatureData.Write(authData)
+	binary.Write(&signatureData, binary.LittleEndian, uint16(CertificationDataTypePCKCertChain))
+	binary.Write(&signatureData, binary.LittleEndian, uint32(len(p.certChainPEM)))
+	signatureData.Write(p.certChainPEM)
+
+	var quote bytes.Buffer
+	quote.Write(header)
+	quote.Write(body)
+	binary.Write(&quote, binary.LittleEndian, uint32(signatureData.Len()))
+	quote.Write(signatureData.Bytes())
+
+	return quote.Bytes(), nil
+}
+
+// Simulated PCK certificate SGX extension values.
+var (
+	simulatedFMSPC  = []byte{0x00, 0x00, 0x00, 0x00, 0x00, 0x00}
+	simulatedPCEID  = []byte{0x00, 0x00}
+	simulatedCPUSVN = make([]byte, 16)
+)
+
+// simulatedPCKExtension builds the SGX extensions of the simulated PCK certificate, with all SVNs set to zero.
+func simulatedPCKExtension() (pkix.Extension, error) {
+	entry := func(id asn1.ObjectIdentifier, value any) (pckExtensionEntry, error) {
+		der, err := asn1.Marshal(value)
+		if err != nil {
+			return pckExtensionEntry{}, err
+		}
+		return pckExtensionEntry{ID: id, Value: asn1.RawValue{FullBytes: der}}, nil
+	}
+
+	var tcbEntries []pckExtensionEntry
+	for i := 1; i <= pckTCBCPUSVN; i++ {
+		var value any = 0
+		if i == pckTCBCPUSVN {
+			value = simulatedCPUSVN
+		}
+		tcbEntry, err := entry(append(append(asn1.ObjectIdentifier{}, oidSGXTCB...), i), value)
+		if err != nil {
+			return pkix.Extension{}, err
+		}
+		tcbEntries = append(tcbEntries, tcbEntry)
+	}
+
+	tcb, err := entry(oidSGXTCB, tcbEntries)
+	if err != nil {
+		return pkix.Extension{}, err
+	}
+	pceID, err := entry(oidSGXPCEID, simulatedPCEID)
+	if err != nil {
+		return pkix.Extension{}, err
+	}
+	fmspc, err := entry(oidSGXFMSPC, simulatedFMSPC)
+	if err != nil {
+		return pkix.Extension{}, err
+	}
+
+	value, err := asn1.Marshal([]pckExtensionEntry{tcb, pceID, fmspc})
+	if err != nil {
+		return pkix.Extension{}, err
+	}
+	return pkix.Extension{Id: OIDSGXExtensions, Value: value}, nil
+}
+
+// reportBody builds a 384-byte non-debug report body with the given measurements and report data.
+func (p *simulatedQuoteProvider) reportBody(mrEnclave, mrSigner [32]byte, reportData []byte) ([]byte, *appErrors.AppError) {
+	if len(reportData) > SGXReportDataSize {
+		return nil, appErrors.ErrInvalidSGXReportSize
+	}
+
+	body := ReportBody{
+		// INIT | MODE64BIT, debug bit cleared
+		Attributes: Attributes{Flags: 0x05, Xfrm: 0x03},
+		MREnclave:  mrEnclave,
+		MRSigner:   mrSigner,
+	}
+	copy(body.ReportData[:], reportData)
+
+	var buf bytes.Buffer
+	if err := binary.Write(&buf, binary.LittleEndian, &body); err != nil {
+		return nil, appErrors.ErrParsingSGXReport
+	}
+	return buf.Bytes(), nil
+}
+
+// signRawECDSA signs the SHA-256 digest of data and returns the raw big-endian r||s signature.
+func signRawECDSA(key *ecdsa.PrivateKey, data []byte) ([]byte, error) {
+	digest := sha256.Sum256(data)
+	r, s, err := ecdsa.Sign(rand.Reader, key, digest[:])
+	if err != nil {
+		return nil, err
+	}
+
+	signature := make([]byte, ECDSASignatureSize)
+	r.FillBytes(signature[:32])
+	s.FillBytes(signature[32:])
+	return signature, nil
+}
+
+// rawECDSAPublicKey returns the raw big-endian x||y encoding of a P-256 public key.
+func rawECDSAPublicKey(key *ecdsa.PublicKey) []byte {
+	raw := make([]byte, ECDSAPublicKeySize)
+	key.X.FillBytes(raw[:32])
+	key.Y.FillBytes(raw[32:])
+	return raw
+}
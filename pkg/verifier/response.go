@@ -0,0 +1,88 @@
+package verifier
+
+import (
+	encoding "github.com/venture23-aleo/aleo-oracle-encoding"
+	"github.com/venture23-aleo/aleo-oracle-encoding/positionRecorder"
+)
+
+// The types below mirror the JSON responses of the notarization endpoints, so that consumers can decode and
+// verify a response without depending on the backend.
+
+// AttestationRequest is the attestation request echoed in a response, with unaccepted header values masked.
+//
+// The field order matches the backend: the roughtime request digest is the SHA-512 hash of the JSON encoded requests.
+type AttestationRequest struct {
+	Url string `json:"url"` // The URL to fetch data from.
+
+	RequestMethod  string  `json:"requestMethod"`            // The request method.
+	Selector       string  `json:"selector,omitempty"`       // The selector.
+	ResponseFormat string  `json:"responseFormat"`           // The response format.
+	HTMLResultType *string `json:"htmlResultType,omitempty"` // The HTML result type.
+
+	RequestBody        *string `json:"requestBody,omitempty"`        // The request body.
+	RequestContentType *string `json:"requestContentType,omitempty"` // The request content type.
+
+	RequestHeaders map[string]string `json:"requestHeaders,omitempty"` // The request headers.
+
+	EncodingOptions encoding.EncodingOptions `json:"encodingOptions"` // The encoding options.
+}
+
+// ProofPositionalInfo contains the positions of the fields encoded in the user data.
+type ProofPositionalInfo struct {
+	encoding.ProofPositionalInfo
+
+	// Position of the Aleo block height. Only set when the block height is encoded, right after the timestamp.
+	BlockHeight *positionRecorder.PositionInfo `json:"blockHeight,omitempty"`
+}
+
+// OracleData is the Aleo-encoded data of an attestation and its signatures.
+type OracleData struct {
+	Signature         string               `json:"signature"`                   // Schnorr signature of the hash of Report.
+	UserData          string               `json:"userData"`                    // Aleo-encoded data whose hash is the quote report data.
+	Report            string               `json:"report"`                      // Aleo-encoded attestation report.
+	Address           string               `json:"address"`                     // Address the signature was created against.
+	PreviousSignature string               `json:"previousSignature,omitempty"` // Signature by the previous key during a key rotation overlap window.
+	PreviousAddress   string               `json:"previousAddress,omitempty"`   // Address the previous signature was created against.
+	EncodedPositions  *ProofPositionalInfo `json:"encodedPositions,omitempty"`  // Positions of the fields encoded in UserData.
+
+	EncodedRequest         string `json:"encodedRequest,omitempty"`         // UserData with zeroed data, timestamp and block height.
+	RequestHash            string `json:"requestHash,omitempty"`            // Poseidon8 hash of EncodedRequest.
+	TimestampedRequestHash string `json:"timestampedRequestHash,omitempty"` // Poseidon8 hash of RequestHash and the timestamp.
+}
+
+// AttestationResultForEachToken is the attestation of a single token in a multi-token response.
+type AttestationResultForEachToken struct {
+	AttestationData      string             `json:"attestationData"`           // The attestation data.
+	AttestationRequest   AttestationRequest `json:"attestationRequest"`        // The attestation request.
+	ResponseBody         string             `json:"responseBody"`              // The response body.
+	ResponseStatusCode   int                `json:"responseStatusCode"`        // The response status code.
+	AttestationTimestamp int64              `json:"timestamp"`                 // The attestation timestamp.
+	AleoBlockHeight      int64              `json:"aleoBlockHeight,omitempty"` // The Aleo block height, omitted when not encoded.
+	RequestHash          string             `json:"requestHash"`               // The request hash of the token's user data chunk.
+}
+
+// AttestationResponse is the response of the notarization endpoint for a single attestation request.
+type AttestationResponse struct {
+	ReportType           string                          `json:"reportType"`                // The report type.
+	AttestationRequest   AttestationRequest              `json:"attestationRequest"`        // The attestation request.
+	AttestationReport    string                          `json:"attestationReport"`         // The base64 encoded attestation report.
+	AttestationTimestamp int64                           `json:"timestamp"`                 // The attestation timestamp.
+	AleoBlockHeight      int64                           `json:"aleoBlockHeight,omitempty"` // The Aleo block height, omitted when not encoded.
+	ResponseBody         string                          `json:"responseBody"`              // The response body.
+	ResponseStatusCode   int                             `json:"responseStatusCode"`        // The response status code.
+	AttestationData      string                          `json:"attestationData"`           // The attestation data.
+	OracleData           OracleData                      `json:"oracleData"`                // The oracle data.
+	AttestationResults   []AttestationResultForEachToken `json:"attestationResults"`        // The per-token results of a multi-token response.
+	RoughtimeProof       *RoughtimeProof                 `json:"roughtimeProof,omitempty"`  // The roughtime proof of the attestation timestamp.
+}
+
+// AttestationResponseForMultipleTokens is the response of the notarization endpoint for multiple price feed requests.
+type AttestationResponseForMultipleTokens struct {
+	ReportType           string                          `json:"reportType"`                // The report type.
+	AttestationTimestamp int64                           `json:"timestamp"`                 // The attestation timestamp.
+	AleoBlockHeight      int64                           `json:"aleoBlockHeight,omitempty"` // The Aleo block height, omitted when not encoded.
+	AttestationReport    string                          `json:"attestationReport"`         // The base64 encoded attestation report.
+	OracleData           OracleData                      `json:"oracleData"`                // The oracle data.
+	AttestationResults   []AttestationResultForEachToken `json:"attestationResults"`        // The per-token results.
+	RoughtimeProof       *RoughtimeProof                 `json:"roughtimeProof,omitempty"`  // The roughtime proof of the attestation timestamp.
+}
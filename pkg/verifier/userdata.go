@@ -0,0 +1,283 @@
+package verifier
+
+import (
+	"bytes"
+	"crypto/sha512"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/big"
+	"strings"
+	"sync"
+
+	encoding "github.com/venture23-aleo/aleo-oracle-encoding"
+	"github.com/venture23-aleo/aleo-oracle-encoding/positionRecorder"
+	appErrors "github.com/venture23-aleo/aleo-oracle-notarization-backend/pkg/errors"
+	aleoUtils "github.com/venture23-aleo/aleo-utils-go"
+)
+
+// Layout of the Aleo-encoded oracle data.
+const (
+	chunkSizeInBytes         = 512      // Size of a single user data chunk.
+	userDataChunks           = 10       // UserData is formatted into C0 - C9 chunks.
+	reportChunks             = 10       // Report is formatted into C0 - C9 chunks.
+	encodedRequestChunks     = 1        // EncodedRequest is formatted from a single chunk.
+	attestationDataSizeLimit = 1024 * 3 // Size the string attestation data is padded to.
+	tokenIDOffset            = 21       // Offset of the price feed token ID in the meta header.
+	blockHeightLenOffset     = 22       // Offset of the Aleo block height length in the meta header.
+	priceFeedURLPrefix       = "price_feed: "
+)
+
+var (
+	aleoWrapperOnce sync.Once
+	aleoWrapper     aleoUtils.Wrapper
+	aleoWrapperErr  error
+)
+
+// newAleoSession creates a session of the shared Aleo wrapper. The wrapper only formats and hashes
+// messages, it never creates or loads a signing key. Sessions are not goroutine safe, so every
+// verification uses its own.
+func newAleoSession() (aleoUtils.Session, *appErrors.AppError) {
+	// The wrapper compiles the WASM module once and lives as long as the process.
+	aleoWrapperOnce.Do(func() {
+		aleoWrapper, _, aleoWrapperErr = aleoUtils.NewWrapper()
+	})
+	if aleoWrapperErr != nil {
+		return nil, appErrors.ErrAleoContext.WithDetails(aleoWrapperErr.Error())
+	}
+
+	session, err := aleoWrapper.NewSession()
+	if err != nil {
+		return nil, appErrors.ErrAleoContext.WithDetails(err.Error())
+	}
+	return session, nil
+}
+
+// priceFeedTokenID returns the token ID of a price feed URL like "price_feed: btc twap=15m", and false
+// when the URL is not a price feed URL.
+func priceFeedTokenID(url string, tokenIDs map[string]int) (int, bool, error) {
+	if !strings.HasPrefix(url, priceFeedURLPrefix) {
+		return 0, false, nil
+	}
+	token, _, _ := strings.Cut(strings.TrimPrefix(url, priceFeedURLPrefix), " ")
+	tokenID, ok := tokenIDs[strings.ToUpper(token)]
+	if !ok {
+		return 0, false, fmt.Errorf("no token ID for price feed token %s", strings.ToUpper(token))
+	}
+	return tokenID, true, nil
+}
+
+// padAttestationData pads the attestation data of a non price feed request the way the backend does before encoding it.
+func padAttestationData(attestationData string, encodingOptions encoding.EncodingOptions) (string, error) {
+	pad := func(str string, paddingChar byte, targetLength int) (string, error) {
+		if len(str) > targetLength {
+			return "", fmt.Errorf("attestation data is longer than %d bytes", targetLength)
+		}
+		return str + strings.Repeat(string(paddingChar), targetLength-len(str)), nil
+	}
+
+	switch encodingOptions.Value {
+	case encoding.ENCODING_OPTION_STRING:
+		return pad(attestationData, 0x00, attestationDataSizeLimit)
+	case encoding.ENCODING_OPTION_FLOAT:
+		if !strings.Contains(attestationData, ".") {
+			attestationData += "."
+		}
+		return pad(attestationData, '0', math.MaxUint8)
+	case encoding.ENCODING_OPTION_INT:
+		// Integers are prepended with zeroes.
+		zeroes, err := pad("", '0', math.MaxUint8-len(attestationData))
+		if err != nil {
+			return "", err
+		}
+		return zeroes + attestationData, nil
+	default:
+		return "", fmt.Errorf("invalid encoding option %q", encodingOptions.Value)
+	}
+}
+
+// userDataChunk encodes an attestation into its user data chunk and returns the positions of the encoded fields.
+//
+// The Aleo block height is only encoded when it is positive, and the token ID of a price feed is written
+// into the meta header.
+func userDataChunk(statusCode int, attestationData string, timestamp, aleoBlockHeight int64, req AttestationRequest, tokenIDs map[string]int) ([]byte, *ProofPositionalInfo, error) {
+	tokenID, isPriceFeed, err := priceFeedTokenID(req.Url, tokenIDs)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	preppedAttestationData := attestationData
+	if !isPriceFeed {
+		if preppedAttestationData, err = padAttestationData(attestationData, req.EncodingOptions); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	var buf bytes.Buffer
+	recorder, err := positionRecorder.NewPositionRecorder(&buf, encoding.TARGET_ALIGNMENT)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Write an empty meta header, filled in once the field lengths are known.
+	if _, err := encoding.WriteWithPadding(recorder, make([]byte, encoding.TARGET_ALIGNMENT*2)); err != nil {
+		return nil, nil, fmt.Errorf("writing meta header: %w", err)
+	}
+
+	write := func(field string, data []byte) *positionRecorder.PositionInfo {
+		if err != nil {
+			return nil
+		}
+		var position *positionRecorder.PositionInfo
+		if position, err = encoding.WriteWithPadding(recorder, data); err != nil {
+			err = fmt.Errorf("writing %s: %w", field, err)
+		}
+		return position
+	}
+
+	encodedData, encodeErr := encoding.EncodeAttestationData(preppedAttestationData, &req.EncodingOptions)
+	if encodeErr != nil {
+		return nil, nil, fmt.Errorf("encoding attestation data: %w", encodeErr)
+	}
+	responseFormat, encodeErr := encoding.EncodeResponseFormat(req.ResponseFormat)
+	if encodeErr != nil {
+		return nil, nil, fmt.Errorf("encoding response format: %w", encodeErr)
+	}
+	encodingOptions, encodeErr := encoding.EncodeEncodingOptions(&req.EncodingOptions)
+	if encodeErr != nil {
+		return nil, nil, fmt.Errorf("encoding encoding options: %w", encodeErr)
+	}
+	encodedHeaders := encoding.EncodeHeaders(req.RequestHeaders)
+	optionalFields, encodeErr := encoding.EncodeOptionalFields(req.HTMLResultType, req.RequestContentType, req.RequestBody)
+	if encodeErr != nil {
+		return nil, nil, fmt.Errorf("encoding optional fields: %w", encodeErr)
+	}
+
+	positions := &ProofPositionalInfo{}
+	dataPosition := write("attestation data", encodedData)
+	timestampPosition := write("timestamp", encoding.NumberToBytes(uint64(timestamp)))
+	if aleoBlockHeight > 0 {
+		positions.BlockHeight = write("Aleo block height", encoding.NumberToBytes(uint64(aleoBlockHeight)))
+	}
+	statusCodePosition := write("status code", encoding.NumberToBytes(uint64(statusCode)))
+	urlPosition := write("URL", []byte(req.Url))
+	selectorPosition := write("selector", []byte(req.Selector))
+	responseFormatPosition := write("response format", responseFormat)
+	methodPosition := write("request method", []byte(req.RequestMethod))
+	encodingOptionsPosition := write("encoding options", encodingOptions)
+	headersPosition := write("request headers", encodedHeaders)
+	optionalFieldsPosition := write("optional fields", optionalFields)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	for field, length := range map[string]int{
+		"attestation data": len(preppedAttestationData),
+		"request method":   len(req.RequestMethod),
+		"URL":              len(req.Url),
+		"selector":         len(req.Selector),
+		"request headers":  len(encodedHeaders),
+		"optional fields":  len(optionalFields),
+	} {
+		if length > math.MaxUint16 {
+			return nil, nil, fmt.Errorf("%s is too long for the meta header", field)
+		}
+	}
+
+	result := buf.Bytes()
+	encoding.CreateMetaHeader(
+		result[:encoding.TARGET_ALIGNMENT*2],
+		uint16(len(preppedAttestationData)),
+		uint16(len(req.RequestMethod)),
+		uint16(len(req.Url)),
+		uint16(len(req.Selector)),
+		uint16(len(encodedHeaders)),
+		uint16(len(optionalFields)),
+	)
+	if positions.BlockHeight != nil {
+		// The block height is encoded as an uint64.
+		binary.LittleEndian.PutUint16(result[blockHeightLenOffset:blockHeightLenOffset+2], 8)
+	}
+	if isPriceFeed {
+		result[tokenIDOffset] = byte(tokenID)
+	}
+
+	positions.ProofPositionalInfo = encoding.ProofPositionalInfo{
+		Data:            *dataPosition,
+		Timestamp:       *timestampPosition,
+		StatusCode:      *statusCodePosition,
+		Method:          *methodPosition,
+		ResponseFormat:  *responseFormatPosition,
+		Url:             *urlPosition,
+		Selector:        *selectorPosition,
+		EncodingOptions: *encodingOptionsPosition,
+		RequestHeaders:  *headersPosition,
+		OptionalFields:  *optionalFieldsPosition,
+	}
+
+	chunk := make([]byte, chunkSizeInBytes)
+	copy(chunk, result)
+	return chunk, positions, nil
+}
+
+// formatUserData formats the concatenated user data chunks into C0 - C9 chunks.
+func formatUserData(session aleoUtils.Session, chunks []byte) ([]byte, error) {
+	userDataProof := make([]byte, userDataChunks*chunkSizeInBytes)
+	copy(userDataProof, chunks)
+	return session.FormatMessage(userDataProof, userDataChunks)
+}
+
+// encodedRequest formats a user data chunk with zeroed attestation data, timestamp and Aleo block height.
+func encodedRequest(session aleoUtils.Session, chunk []byte, positions *ProofPositionalInfo) ([]byte, error) {
+	blockHeightLen := 0
+	if positions.BlockHeight != nil {
+		blockHeightLen = positions.BlockHeight.Len
+	}
+
+	metaHeaderLen := 2 * encoding.TARGET_ALIGNMENT
+	endOffset := metaHeaderLen + (positions.Data.Len+positions.Timestamp.Len+blockHeightLen)*encoding.TARGET_ALIGNMENT
+	if endOffset > len(chunk) {
+		return nil, fmt.Errorf("user data chunk is too short")
+	}
+
+	requestProof := make([]byte, chunkSizeInBytes)
+	copy(requestProof, chunk)
+	clear(requestProof[metaHeaderLen:endOffset])
+
+	return session.FormatMessage(requestProof, encodedRequestChunks)
+}
+
+// timestampedRequestHash hashes the request hash together with the attestation timestamp as
+// "{ request_hash: <hash>u128, attestation_timestamp: <timestamp>u128 }".
+func timestampedRequestHash(session aleoUtils.Session, requestHash []byte, timestamp uint64) (string, error) {
+	if len(requestHash) != encoding.TARGET_ALIGNMENT {
+		return "", fmt.Errorf("request hash is %d bytes, expected %d", len(requestHash), encoding.TARGET_ALIGNMENT)
+	}
+
+	timestampBytes := make([]byte, encoding.TARGET_ALIGNMENT)
+	binary.LittleEndian.PutUint64(timestampBytes, timestamp)
+
+	message := fmt.Sprintf("{ request_hash: %su128, attestation_timestamp: %su128 }", littleEndianU128(requestHash), littleEndianU128(timestampBytes))
+	return session.HashMessageToString([]byte(message))
+}
+
+// littleEndianU128 decodes a 16-byte little-endian unsigned integer.
+func littleEndianU128(buf []byte) *big.Int {
+	reversed := make([]byte, len(buf))
+	for i, b := range buf {
+		reversed[len(buf)-1-i] = b
+	}
+	return new(big.Int).SetBytes(reversed)
+}
+
+// roughtimeRequestDigest returns the digest the roughtime nonces are derived from: the SHA-512 hash of the
+// JSON encoded attestation requests. The requests in a response already have unaccepted header values masked.
+func roughtimeRequestDigest(requests ...AttestationRequest) ([]byte, error) {
+	encodedRequests, err := json.Marshal(requests)
+	if err != nil {
+		return nil, err
+	}
+	digest := sha512.Sum512(encodedRequests)
+	return digest[:], nil
+}
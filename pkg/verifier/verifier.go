@@ -0,0 +1,587 @@
+// Package verifier checks the cryptographic links of attestation responses produced by the notarization backend.
+//
+// Each link is recomputed from the response with the same aleo-utils-go primitives the backend uses to build it:
+//
+//	attestation request -> UserData -> attestation hash -> quote report data
+//	quote -> Report -> Signature
+//	UserData -> EncodedRequest -> RequestHash -> TimestampedRequestHash
+//	attestation requests -> roughtime nonces -> signed roughtime responses -> timestamp
+//
+// The package never creates or loads a signing key and does not depend on the backend configuration:
+// everything that is trusted comes from Options.
+//
+// A response is only Valid when every security-critical check passed. When a check cannot run because the
+// response or Options lacks what it needs, the check is skipped and the report is unverified rather than valid.
+package verifier
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+	"time"
+
+	appErrors "github.com/venture23-aleo/aleo-oracle-notarization-backend/pkg/errors"
+	"github.com/venture23-aleo/aleo-oracle-notarization-backend/pkg/roughtime"
+	"github.com/venture23-aleo/aleo-oracle-notarization-backend/pkg/sgx"
+	aleoUtils "github.com/venture23-aleo/aleo-utils-go"
+)
+
+// Quote is a parsed SGX DCAP quote.
+type Quote = sgx.Quote
+
+// QuoteSummary is a JSON friendly view of the identity and TCB fields of a quote.
+type QuoteSummary = sgx.QuoteSummary
+
+// RoughtimeProof is the signed roughtime responses backing the attestation timestamp.
+type RoughtimeProof = roughtime.Proof
+
+// Collateral is the trusted root CA, CRLs, TCB info and QE identity used to verify a quote offline.
+type Collateral = sgx.Collateral
+
+// QuoteVerificationResult is the TCB status of a quote verified against collateral.
+type QuoteVerificationResult = sgx.QuoteVerificationResult
+
+// TCB statuses.
+const (
+	TCBStatusUpToDate                          = sgx.TCBStatusUpToDate
+	TCBStatusSWHardeningNeeded                 = sgx.TCBStatusSWHardeningNeeded
+	TCBStatusConfigurationNeeded               = sgx.TCBStatusConfigurationNeeded
+	TCBStatusConfigurationAndSWHardeningNeeded = sgx.TCBStatusConfigurationAndSWHardeningNeeded
+	TCBStatusOutOfDate                         = sgx.TCBStatusOutOfDate
+	TCBStatusOutOfDateConfigurationNeeded      = sgx.TCBStatusOutOfDateConfigurationNeeded
+	TCBStatusRevoked                           = sgx.TCBStatusRevoked
+)
+
+// DefaultAcceptedTCBStatuses are the TCB statuses accepted when Options.AcceptedTCBStatuses is empty.
+var DefaultAcceptedTCBStatuses = []string{TCBStatusUpToDate, TCBStatusSWHardeningNeeded}
+
+// LoadCollateral loads collateral from the files in a directory. See the sgx.Collateral*File constants
+// for the expected file names.
+func LoadCollateral(dir string) (*Collateral, *appErrors.AppError) {
+	return sgx.LoadCollateral(dir)
+}
+
+// Check statuses.
+const (
+	CheckStatusPassed  = "passed"
+	CheckStatusFailed  = "failed"
+	CheckStatusSkipped = "skipped"
+)
+
+// Report statuses.
+const (
+	ReportStatusValid      = "valid"      // Every security-critical check passed.
+	ReportStatusInvalid    = "invalid"    // At least one check failed.
+	ReportStatusUnverified = "unverified" // No check failed, but a security-critical check was skipped.
+)
+
+// Check names.
+const (
+	CheckQuote                  = "quote"                  // The attestation report parses as an SGX DCAP quote.
+	CheckReportSignature        = "reportSignature"        // The quote is signed by the attestation key it embeds.
+	CheckQuoteSignature         = "quoteSignature"         // The quote signature chain verifies up to the trusted root CA.
+	CheckTCB                    = "tcb"                    // The TCB status of the platform is one of the accepted statuses.
+	CheckReport                 = "report"                 // Report is the Aleo-encoded quote.
+	CheckAttestationHash        = "attestationHash"        // The quote report data holds the hash of UserData.
+	CheckUserData               = "userData"               // UserData is recomputed from the attestation request and data.
+	CheckEncodedRequest         = "encodedRequest"         // EncodedRequest is UserData with zeroed data, timestamp and block height.
+	CheckRequestHash            = "requestHash"            // RequestHash is the hash of EncodedRequest.
+	CheckTimestampedRequestHash = "timestampedRequestHash" // TimestampedRequestHash is the hash of RequestHash and the timestamp.
+	CheckSigner                 = "signer"                 // The signing address is one of the trusted addresses.
+	CheckSignature              = "signature"              // Signature is a Schnorr signature over the hash of Report.
+	CheckPreviousSignature      = "previousSignature"      // PreviousSignature is a Schnorr signature over the hash of Report by a trusted address.
+	CheckTimestamp              = "timestamp"              // The roughtime proof was produced for the requests and backs the timestamp.
+)
+
+// Aleo bech32 prefixes.
+const (
+	aleoAddressPrefix   = "aleo1"
+	aleoSignaturePrefix = "sign1"
+)
+
+// Check is the result of verifying a single link of an attestation response.
+type Check struct {
+	Name    string `json:"name"`              // The check name.
+	Status  string `json:"status"`            // The check status: passed, failed or skipped.
+	Details string `json:"details,omitempty"` // Why the check failed or was skipped.
+}
+
+// Report is the per-check result of verifying an attestation response.
+type Report struct {
+	Valid  bool    `json:"valid"`  // Whether every security-critical check passed.
+	Status string  `json:"status"` // The report status: valid, invalid or unverified.
+	Checks []Check `json:"checks"` // The individual checks, in the order they were run.
+
+	// Identity and TCB fields of the quote, set when the attestation report parses.
+	Quote *QuoteSummary `json:"quote,omitempty"`
+
+	// TCB status and advisory IDs of the platform, set when the quote verifies against the collateral.
+	TCB *QuoteVerificationResult `json:"tcb,omitempty"`
+}
+
+// SignatureVerifier verifies Aleo Schnorr signatures.
+//
+// aleo-utils-go signs but cannot verify signatures, so the verification is supplied by the consumer, for
+// example backed by snarkVM or the Aleo SDK.
+type SignatureVerifier interface {
+	// VerifySignature reports whether signature is a valid signature of message by address.
+	VerifySignature(address string, message []byte, signature string) (bool, error)
+}
+
+// Options configures the verification. Everything the verification trusts comes from the options.
+type Options struct {
+	// Addresses trusted to sign attestations. The signer check is skipped when empty.
+	TrustedAddresses []string
+
+	// Verifier of the Schnorr signatures. The signature checks are skipped when nil.
+	SignatureVerifier SignatureVerifier
+
+	// Collateral to verify the quote signature chain and TCB status against. The quoteSignature and
+	// tcb checks are skipped when nil.
+	Collateral *Collateral
+
+	// Time at which certificates, CRLs and collateral are checked for validity. Defaults to the
+	// attestation timestamp of the response, so archived responses verify against archived collateral.
+	VerificationTime time.Time
+
+	// TCB statuses accepted by the tcb check. Defaults to DefaultAcceptedTCBStatuses.
+	AcceptedTCBStatuses []string
+
+	// Roughtime servers trusted to sign timestamps, by server name to Ed25519 public key. The timestamp check
+	// is skipped when empty.
+	RoughtimeServers map[string][]byte
+
+	// Minimum number of distinct trusted roughtime servers in the proof. Defaults to 1.
+	RoughtimeQuorum int
+
+	// Token IDs of the price feed tokens, by upper case token symbol like "BTC". The token ID is encoded in
+	// the user data of price feed attestations, so price feed responses fail the userData check without it.
+	PriceFeedTokenIDs map[string]int
+}
+
+func (r *Report) add(name, status, details string) {
+	r.Checks = append(r.Checks, Check{Name: name, Status: status, Details: details})
+	if status == CheckStatusFailed {
+		r.Valid = false
+		r.Status = ReportStatusInvalid
+	}
+}
+
+func (r *Report) pass(name string) {
+	r.add(name, CheckStatusPassed, "")
+}
+
+func (r *Report) fail(name string, format string, args ...any) {
+	r.add(name, CheckStatusFailed, fmt.Sprintf(format, args...))
+}
+
+func (r *Report) skip(name string, format string, args ...any) {
+	r.add(name, CheckStatusSkipped, fmt.Sprintf(format, args...))
+}
+
+// unverified skips a security-critical check, so the report can no longer be valid.
+func (r *Report) unverified(name string, format string, args ...any) {
+	r.skip(name, format, args...)
+	r.Valid = false
+	if r.Status == ReportStatusValid {
+		r.Status = ReportStatusUnverified
+	}
+}
+
+// GetCheck returns the check with the given name.
+func (r *Report) GetCheck(name string) (Check, bool) {
+	for _, check := range r.Checks {
+		if check.Name == name {
+			return check, true
+		}
+	}
+	return Check{}, false
+}
+
+// ParseAttestationReport parses the attestationReport field of an attestation response: a base64 encoded
+// DCAP quote wrapped as Open Enclave evidence.
+func ParseAttestationReport(attestationReport string) (*Quote, *appErrors.AppError) {
+	evidence, err := base64.StdEncoding.DecodeString(attestationReport)
+	if err != nil {
+		return nil, appErrors.ErrUnwrappingQuote.WithDetails("attestation report is not valid base64")
+	}
+	return sgx.ParseOpenEnclaveEvidence(evidence)
+}
+
+// VerifyAttestationResponse verifies a single-request attestation response.
+//
+// The returned error is only set when the verifier itself cannot run, for example when the Aleo wrapper
+// cannot be initialized. A response that fails verification is reported through the checks.
+func VerifyAttestationResponse(response *AttestationResponse, opts Options) (*Report, *appErrors.AppError) {
+	session, err := newAleoSession()
+	if err != nil {
+		return nil, err
+	}
+	defer session.Close()
+
+	report := &Report{Valid: true, Status: ReportStatusValid}
+	oracleData := &response.OracleData
+
+	quote := verifyQuoteChain(report, session, response.AttestationReport, oracleData)
+	verifyQuoteCollateral(report, quote, response.AttestationTimestamp, opts)
+
+	// Recompute UserData from the attestation request and the attested data.
+	chunk, encodedPositions, chunkErr := userDataChunk(
+		response.ResponseStatusCode,
+		response.AttestationData,
+		response.AttestationTimestamp,
+		response.AleoBlockHeight,
+		response.AttestationRequest,
+		opts.PriceFeedTokenIDs,
+	)
+	if chunkErr != nil {
+		report.fail(CheckUserData, "failed to recompute user data: %v", chunkErr)
+	} else if userData, formatErr := formatUserData(session, chunk); formatErr != nil {
+		chunkErr = formatErr
+		report.fail(CheckUserData, "failed to format user data: %v", formatErr)
+	} else if string(userData) != oracleData.UserData {
+		report.fail(CheckUserData, "user data does not match the attestation request and data")
+	} else {
+		report.pass(CheckUserData)
+	}
+
+	if chunkErr == nil {
+		verifyRequestHashes(report, session, chunk, encodedPositions, uint64(response.AttestationTimestamp), oracleData)
+	} else {
+		report.skip(CheckEncodedRequest, "user data could not be recomputed")
+		report.skip(CheckRequestHash, "user data could not be recomputed")
+		report.skip(CheckTimestampedRequestHash, "user data could not be recomputed")
+	}
+
+	verifySigner(report, session, quote, oracleData, opts)
+	verifyTimestamp(report, response.RoughtimeProof, response.AttestationTimestamp, opts, response.AttestationRequest)
+
+	return report, nil
+}
+
+// VerifyAttestationResponseForMultipleTokens verifies a multi-token attestation response.
+//
+// The merged UserData is recomputed from the per-token results, and the request hash of each result
+// is checked against its own chunk. The merged response carries no EncodedRequest or TimestampedRequestHash.
+func VerifyAttestationResponseForMultipleTokens(response *AttestationResponseForMultipleTokens, opts Options) (*Report, *appErrors.AppError) {
+	session, err := newAleoSession()
+	if err != nil {
+		return nil, err
+	}
+	defer session.Close()
+
+	report := &Report{Valid: true, Status: ReportStatusValid}
+	oracleData := &response.OracleData
+
+	quote := verifyQuoteChain(report, session, response.AttestationReport, oracleData)
+	verifyQuoteCollateral(report, quote, response.AttestationTimestamp, opts)
+
+	if len(response.AttestationResults) == 0 {
+		report.fail(CheckUserData, "response has no attestation results")
+		report.skip(CheckRequestHash, "response has no attestation results")
+	} else {
+		// Recompute the chunk and request hash of each result, then the merged UserData.
+		mergedUserDataChunks := []byte{}
+		requestHashMismatches := []string{}
+		var chunkErr error
+
+		for i, result := range response.AttestationResults {
+			chunk, encodedPositions, err := userDataChunk(
+				result.ResponseStatusCode,
+				result.AttestationData,
+				result.AttestationTimestamp,
+				result.AleoBlockHeight,
+				result.AttestationRequest,
+				opts.PriceFeedTokenIDs,
+			)
+			if err != nil {
+				chunkErr = err
+				break
+			}
+			mergedUserDataChunks = append(mergedUserDataChunks, chunk...)
+
+			request, err := encodedRequest(session, chunk, encodedPositions)
+			if err != nil {
+				chunkErr = err
+				break
+			}
+			requestHash, err := session.HashMessageToString(request)
+			if err != nil {
+				chunkErr = err
+				break
+			}
+			if requestHash != result.RequestHash {
+				requestHashMismatches = append(requestHashMismatches, fmt.Sprintf("%d", i))
+			}
+		}
+
+		if chunkErr != nil {
+			report.fail(CheckUserData, "failed to recompute user data: %v", chunkErr)
+			report.skip(CheckRequestHash, "user data could not be recomputed")
+		} else {
+			mergedUserData, formatErr := formatUserData(session, mergedUserDataChunks)
+			if formatErr != nil {
+				report.fail(CheckUserData, "failed to format user data: %v", formatErr)
+			} else if string(mergedUserData) != oracleData.UserData {
+				report.fail(CheckUserData, "user data does not match the attestation results")
+			} else {
+				report.pass(CheckUserData)
+			}
+
+			if len(requestHashMismatches) > 0 {
+				report.fail(CheckRequestHash, "request hash mismatch for attestation results %s", strings.Join(requestHashMismatches, ", "))
+			} else {
+				report.pass(CheckRequestHash)
+			}
+		}
+	}
+
+	verifySigner(report, session, quote, oracleData, opts)
+
+	requests := make([]AttestationRequest, len(response.AttestationResults))
+	for i, result := range response.AttestationResults {
+		requests[i] = result.AttestationRequest
+	}
+	verifyTimestamp(report, response.RoughtimeProof, response.AttestationTimestamp, opts, requests...)
+
+	return report, nil
+}
+
+// verifyQuoteChain verifies the quote, its report signature, the report and the attestation hash, returning
+// the parsed quote if it decodes.
+func verifyQuoteChain(report *Report, session aleoUtils.Session, attestationReport string, oracleData *OracleData) *Quote {
+	// Decode and parse the Open Enclave evidence.
+	evidence, decodeErr := base64.StdEncoding.DecodeString(attestationReport)
+	if decodeErr != nil {
+		report.fail(CheckQuote, "attestation report is not valid base64: %v", decodeErr)
+		report.skip(CheckReportSignature, "quote could not be decoded")
+		report.skip(CheckReport, "quote could not be decoded")
+		report.skip(CheckAttestationHash, "quote could not be decoded")
+		return nil
+	}
+
+	quote, err := sgx.ParseOpenEnclaveEvidence(evidence)
+	if err != nil {
+		report.fail(CheckQuote, "%s: %s", err.Message, err.Details)
+		report.skip(CheckReportSignature, "quote could not be decoded")
+		report.skip(CheckReport, "quote could not be decoded")
+		report.skip(CheckAttestationHash, "quote could not be decoded")
+		return nil
+	}
+	report.pass(CheckQuote)
+	report.Quote = quote.Summary()
+
+	// The report signature needs no collateral, so a quote with tampered report data always fails.
+	if err := sgx.VerifyReportSignature(quote); err != nil {
+		report.fail(CheckReportSignature, "%s: %s", err.Message, err.Details)
+	} else {
+		report.pass(CheckReportSignature)
+	}
+
+	// The report is the Aleo-encoded Open Enclave evidence.
+	formattedReport, formatErr := session.FormatMessage(evidence, reportChunks)
+	if formatErr != nil {
+		report.fail(CheckReport, "failed to format quote: %v", formatErr)
+	} else if string(formattedReport) != oracleData.Report {
+		report.fail(CheckReport, "report does not match the attestation report")
+	} else {
+		report.pass(CheckReport)
+	}
+
+	// The quote report data is the attestation hash of the user data, zero-padded to 64 bytes.
+	attestationHash, hashErr := session.HashMessage([]byte(oracleData.UserData))
+	if hashErr != nil {
+		report.fail(CheckAttestationHash, "failed to hash user data: %v", hashErr)
+		return quote
+	}
+
+	expectedReportData := make([]byte, sgx.SGXReportDataSize)
+	copy(expectedReportData, attestationHash)
+
+	if !bytes.Equal(quote.ReportBody.ReportData[:], expectedReportData) {
+		report.fail(CheckAttestationHash, "quote report data does not match the hash of user data")
+	} else {
+		report.pass(CheckAttestationHash)
+	}
+
+	return quote
+}
+
+// verifyQuoteCollateral verifies the quote signature chain and evaluates the TCB status against the collateral.
+func verifyQuoteCollateral(report *Report, quote *Quote, attestationTimestamp int64, opts Options) {
+	if quote == nil {
+		report.skip(CheckQuoteSignature, "quote could not be decoded")
+		report.skip(CheckTCB, "quote could not be decoded")
+		return
+	}
+	if opts.Collateral == nil {
+		report.unverified(CheckQuoteSignature, "no collateral configured")
+		report.unverified(CheckTCB, "no collateral configured")
+		return
+	}
+
+	at := opts.VerificationTime
+	if at.IsZero() {
+		at = time.Unix(attestationTimestamp, 0)
+	}
+
+	if err := sgx.VerifyQuoteSignatures(quote, opts.Collateral, at); err != nil {
+		report.fail(CheckQuoteSignature, "%s: %s", err.Message, err.Details)
+		report.skip(CheckTCB, "quote signature could not be verified")
+		return
+	}
+	report.pass(CheckQuoteSignature)
+
+	result, err := sgx.EvaluateQuoteTCB(quote, opts.Collateral, at)
+	if err != nil {
+		report.fail(CheckTCB, "%s: %s", err.Message, err.Details)
+		return
+	}
+	report.TCB = result
+
+	acceptedStatuses := opts.AcceptedTCBStatuses
+	if len(acceptedStatuses) == 0 {
+		acceptedStatuses = DefaultAcceptedTCBStatuses
+	}
+	if !slices.Contains(acceptedStatuses, result.TCBStatus) {
+		report.fail(CheckTCB, "TCB status %s is not accepted (advisories: %s)", result.TCBStatus, strings.Join(result.AdvisoryIDs, ", "))
+	} else {
+		report.pass(CheckTCB)
+	}
+}
+
+// verifyRequestHashes verifies the encoded request, the request hash and the timestamped request hash.
+func verifyRequestHashes(report *Report, session aleoUtils.Session, chunk []byte, encodedPositions *ProofPositionalInfo, timestamp uint64, oracleData *OracleData) {
+	if oracleData.EncodedPositions != nil && !reflect.DeepEqual(*oracleData.EncodedPositions, *encodedPositions) {
+		report.fail(CheckEncodedRequest, "encoded positions do not match the attestation request and data")
+		report.skip(CheckRequestHash, "encoded request does not match")
+		report.skip(CheckTimestampedRequestHash, "encoded request does not match")
+		return
+	}
+
+	request, err := encodedRequest(session, chunk, encodedPositions)
+	if err != nil {
+		report.fail(CheckEncodedRequest, "failed to recompute encoded request: %v", err)
+		report.skip(CheckRequestHash, "encoded request could not be recomputed")
+		report.skip(CheckTimestampedRequestHash, "encoded request could not be recomputed")
+		return
+	}
+
+	if string(request) != oracleData.EncodedRequest {
+		report.fail(CheckEncodedRequest, "encoded request does not match the user data")
+	} else {
+		report.pass(CheckEncodedRequest)
+	}
+
+	requestHash, err := session.HashMessage(request)
+	if err != nil {
+		report.fail(CheckRequestHash, "failed to recompute request hash: %v", err)
+		report.skip(CheckTimestampedRequestHash, "request hash could not be recomputed")
+		return
+	}
+	requestHashString, err := session.HashMessageToString(request)
+	if err != nil {
+		report.fail(CheckRequestHash, "failed to recompute request hash: %v", err)
+		report.skip(CheckTimestampedRequestHash, "request hash could not be recomputed")
+		return
+	}
+
+	if requestHashString != oracleData.RequestHash {
+		report.fail(CheckRequestHash, "request hash does not match the encoded request")
+	} else {
+		report.pass(CheckRequestHash)
+	}
+
+	recomputed, err := timestampedRequestHash(session, requestHash, timestamp)
+	if err != nil {
+		report.fail(CheckTimestampedRequestHash, "failed to recompute timestamped request hash: %v", err)
+		return
+	}
+
+	if recomputed != oracleData.TimestampedRequestHash {
+		report.fail(CheckTimestampedRequestHash, "timestamped request hash does not match the request hash and timestamp")
+	} else {
+		report.pass(CheckTimestampedRequestHash)
+	}
+}
+
+// verifySigner verifies that the signing addresses are trusted and that the signatures are Schnorr signatures
+// of the hash of Report by those addresses.
+//
+// The previous signature is only checked when the response carries one, during a key rotation overlap window.
+func verifySigner(report *Report, session aleoUtils.Session, quote *Quote, oracleData *OracleData, opts Options) {
+	if !strings.HasPrefix(oracleData.Address, aleoAddressPrefix) {
+		report.fail(CheckSigner, "address %q is not an Aleo address", oracleData.Address)
+	} else if len(opts.TrustedAddresses) == 0 {
+		report.unverified(CheckSigner, "no trusted addresses configured")
+	} else if !slices.Contains(opts.TrustedAddresses, oracleData.Address) {
+		report.fail(CheckSigner, "address %s is not a trusted signer", oracleData.Address)
+	} else {
+		report.pass(CheckSigner)
+	}
+
+	var reportHash []byte
+	var hashErr error
+	if quote != nil {
+		reportHash, hashErr = session.HashMessage([]byte(oracleData.Report))
+	}
+
+	verify := func(name, address, signature string) {
+		if !strings.HasPrefix(signature, aleoSignaturePrefix) {
+			report.fail(name, "signature is not an Aleo signature")
+		} else if quote == nil {
+			report.skip(name, "quote could not be decoded")
+		} else if hashErr != nil {
+			report.fail(name, "failed to hash report: %v", hashErr)
+		} else if opts.SignatureVerifier == nil {
+			report.unverified(name, "no signature verifier configured")
+		} else if ok, err := opts.SignatureVerifier.VerifySignature(address, reportHash, signature); err != nil {
+			report.fail(name, "failed to verify signature: %v", err)
+		} else if !ok {
+			report.fail(name, "signature is not a signature of the report hash by %s", address)
+		} else {
+			report.pass(name)
+		}
+	}
+
+	verify(CheckSignature, oracleData.Address, oracleData.Signature)
+
+	if oracleData.PreviousSignature == "" && oracleData.PreviousAddress == "" {
+		return
+	}
+	if !strings.HasPrefix(oracleData.PreviousAddress, aleoAddressPrefix) {
+		report.fail(CheckPreviousSignature, "previous address %q is not an Aleo address", oracleData.PreviousAddress)
+	} else if len(opts.TrustedAddresses) > 0 && !slices.Contains(opts.TrustedAddresses, oracleData.PreviousAddress) {
+		report.fail(CheckPreviousSignature, "previous address %s is not a trusted signer", oracleData.PreviousAddress)
+	} else {
+		verify(CheckPreviousSignature, oracleData.PreviousAddress, oracleData.PreviousSignature)
+	}
+}
+
+// verifyTimestamp verifies that the roughtime proof was produced for the attestation requests and backs
+// the attestation timestamp.
+func verifyTimestamp(report *Report, proof *RoughtimeProof, timestamp int64, opts Options, requests ...AttestationRequest) {
+	if proof == nil {
+		report.unverified(CheckTimestamp, "response has no roughtime proof")
+		return
+	}
+	if len(opts.RoughtimeServers) == 0 {
+		report.unverified(CheckTimestamp, "no trusted roughtime servers configured")
+		return
+	}
+
+	requestDigest, err := roughtimeRequestDigest(requests...)
+	if err != nil {
+		report.fail(CheckTimestamp, "failed to compute request digest: %v", err)
+		return
+	}
+
+	if err := roughtime.VerifyProof(proof, requestDigest, timestamp, opts.RoughtimeServers, opts.RoughtimeQuorum); err != nil {
+		report.fail(CheckTimestamp, "%s", err.Details)
+		return
+	}
+	report.pass(CheckTimestamp)
+}
@@ -0,0 +1,518 @@
+package verifier
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/cloudflare/roughtime/protocol"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	encoding "github.com/venture23-aleo/aleo-oracle-encoding"
+	aleoUtil "github.com/venture23-aleo/aleo-oracle-notarization-backend/internal/aleoutil"
+	configs "github.com/venture23-aleo/aleo-oracle-notarization-backend/internal/config"
+	"github.com/venture23-aleo/aleo-oracle-notarization-backend/internal/constants"
+	"github.com/venture23-aleo/aleo-oracle-notarization-backend/internal/services/attestation"
+	"github.com/venture23-aleo/aleo-oracle-notarization-backend/pkg/logger"
+	"github.com/venture23-aleo/aleo-oracle-notarization-backend/pkg/roughtime"
+	"github.com/venture23-aleo/aleo-oracle-notarization-backend/pkg/sgx"
+)
+
+// TestMain initializes the logger for all tests in this package
+func TestMain(m *testing.M) {
+	logger.InitLogger("DEBUG")
+	m.Run()
+}
+
+func useSimulatedQuoteProvider(t *testing.T) {
+	require.NoError(t, sgx.InitQuoteProvider(sgx.QuoteProviderSimulated, true))
+	t.Cleanup(func() {
+		provider, _ := sgx.NewQuoteProvider(sgx.QuoteProviderGramine, false)
+		sgx.SetQuoteProvider(provider)
+	})
+}
+
+// fakeSignatureVerifier accepts the signatures the backend produced for a report, standing in for a
+// Schnorr verifier.
+type fakeSignatureVerifier struct {
+	message    []byte
+	signatures map[string]string
+}
+
+func (v *fakeSignatureVerifier) VerifySignature(address string, message []byte, signature string) (bool, error) {
+	return bytes.Equal(v.message, message) && v.signatures[address] == signature, nil
+}
+
+func trustedSigner(t *testing.T) Options {
+	aleoContext, err := aleoUtil.GetAleoContext()
+	require.Nil(t, err)
+
+	tokenIDs := map[string]int{}
+	for symbol, tokenConfig := range configs.GetTokenRegistry() {
+		tokenIDs[symbol] = tokenConfig.TokenID
+	}
+	return Options{TrustedAddresses: []string{aleoContext.GetPublicKey()}, PriceFeedTokenIDs: tokenIDs}
+}
+
+// signatureVerifier returns a verifier accepting the signatures of the oracle data.
+func signatureVerifier(t *testing.T, oracleData OracleData) SignatureVerifier {
+	session, err := newAleoSession()
+	require.Nil(t, err)
+	defer session.Close()
+
+	message, hashErr := session.HashMessage([]byte(oracleData.Report))
+	require.NoError(t, hashErr)
+	return &fakeSignatureVerifier{message: message, signatures: map[string]string{oracleData.Address: oracleData.Signature}}
+}
+
+// signRoughtimeProof signs a roughtime proof for the attestation request of the response with a test server
+// and trusts that server in the options.
+func signRoughtimeProof(t *testing.T, response *AttestationResponse, opts *Options) {
+	requestDigest, err := roughtimeRequestDigest(response.AttestationRequest)
+	require.NoError(t, err)
+
+	rootPublicKey, rootKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	_, onlineKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	midpoint := time.Unix(response.AttestationTimestamp, 0)
+	cert, err := protocol.NewCertificate(midpoint.Add(-time.Hour), midpoint.Add(time.Hour), onlineKey, rootKey)
+	require.NoError(t, err)
+
+	_, blind, request, err := protocol.CreateRequest([]protocol.Version{}, rand.Reader, requestDigest, rootPublicKey)
+	require.NoError(t, err)
+	parsedRequest, err := protocol.ParseRequest(request)
+	require.NoError(t, err)
+	version, err := protocol.ResponseVersionFromSupported(parsedRequest.Versions)
+	require.NoError(t, err)
+	replies, err := protocol.CreateReplies(version, []protocol.Request{*parsedRequest}, midpoint, time.Second, cert)
+	require.NoError(t, err)
+
+	response.RoughtimeProof = &RoughtimeProof{
+		RequestDigest: requestDigest,
+		Responses: []roughtime.Response{{
+			Server:    "roughtime.example.com",
+			Version:   roughtime.VersionIETF,
+			PublicKey: rootPublicKey,
+			Blind:     blind,
+			Midpoint:  midpoint.UnixMicro(),
+			Radius:    time.Second.Microseconds(),
+			Response:  replies[0],
+		}},
+	}
+	opts.RoughtimeServers = map[string][]byte{"roughtime.example.com": rootPublicKey}
+}
+
+// toVerifierType converts a backend response into its verifier type through JSON, the way a consumer
+// receives it.
+func toVerifierType[T any](t *testing.T, response any) *T {
+	encoded, err := json.Marshal(response)
+	require.NoError(t, err)
+	var decoded T
+	require.NoError(t, json.Unmarshal(encoded, &decoded))
+	return &decoded
+}
+
+// newAttestationResponse builds a single-request attestation response the way the random handler does.
+func newAttestationResponse(t *testing.T) *AttestationResponse {
+	attestationRequest := attestation.AttestationRequest{
+		Url:            "crypto/rand:100",
+		RequestMethod:  "GET",
+		ResponseFormat: "json",
+		EncodingOptions: encoding.EncodingOptions{
+			Value: "int",
+		},
+	}
+	timestamp := int64(1754278324)
+	aleoBlockHeight := int64(9876543)
+
+	quotePrepData, err := attestation.PrepareDataForQuoteGeneration(200, "42", uint64(timestamp), uint64(aleoBlockHeight), attestationRequest)
+	require.Nil(t, err)
+
+	quote, err := sgx.GenerateQuote(quotePrepData.AttestationHash)
+	require.Nil(t, err)
+
+	oracleData, err := attestation.BuildCompleteOracleData(quotePrepData, quote)
+	require.Nil(t, err)
+
+	return toVerifierType[AttestationResponse](t, &attestation.AttestationResponse{
+		ReportType:           "sgx",
+		AttestationRequest:   attestationRequest,
+		AttestationTimestamp: timestamp,
+		AleoBlockHeight:      aleoBlockHeight,
+		AttestationReport:    base64.StdEncoding.EncodeToString(quote),
+		OracleData:           *oracleData,
+		ResponseBody:         "42",
+		AttestationData:      "42",
+		ResponseStatusCode:   200,
+	})
+}
+
+// newMultipleTokensResponse builds a multi-token attestation response the way the notarization handler does.
+func newMultipleTokensResponse(t *testing.T) *AttestationResponseForMultipleTokens {
+	timestamp := int64(1754278324)
+	priceFeeds := map[string]string{
+		"price_feed: btc": "118000.5",
+		"price_feed: eth": "3800.25",
+	}
+
+	mergedUserDataChunks := []byte{}
+	results := []attestation.AttestationResultForEachToken{}
+	for _, url := range []string{"price_feed: btc", "price_feed: eth"} {
+		req := attestation.AttestationRequest{
+			Url:            url,
+			RequestMethod:  "GET",
+			ResponseFormat: "json",
+			Selector:       "weightedAvgPrice",
+			EncodingOptions: encoding.EncodingOptions{
+				Value:     "float",
+				Precision: 6,
+			},
+		}
+
+		userDataChunk, encodedPositions, err := attestation.PrepareOracleUserDataChunk(200, priceFeeds[url], uint64(timestamp), 0, req)
+		require.Nil(t, err)
+		requestHash, err := attestation.GetRequestHashFromSingleChunk(userDataChunk, encodedPositions)
+		require.Nil(t, err)
+
+		mergedUserDataChunks = append(mergedUserDataChunks, userDataChunk...)
+		results = append(results, attestation.AttestationResultForEachToken{
+			AttestationData:      priceFeeds[url],
+			AtttestationRequest:  req,
+			ResponseStatusCode:   200,
+			AttestationTimestamp: timestamp,
+			RequestHash:          requestHash,
+		})
+	}
+
+	finalMergedUserDataChunks := make([]byte, constants.OracleUserDataChunkSize*constants.ChunkSizeInBytes)
+	copy(finalMergedUserDataChunks, mergedUserDataChunks)
+	mergedUserData, err := attestation.FormatMessage(finalMergedUserDataChunks, constants.OracleUserDataChunkSize)
+	require.Nil(t, err)
+
+	attestationHash, err := attestation.GenerateAttestationHash(mergedUserData)
+	require.Nil(t, err)
+	quote, err := sgx.GenerateQuote(attestationHash)
+	require.Nil(t, err)
+	oracleReport, err := attestation.PrepareOracleReport(quote)
+	require.Nil(t, err)
+	signatures, err := attestation.PrepareOracleSignature(oracleReport)
+	require.Nil(t, err)
+
+	return toVerifierType[AttestationResponseForMultipleTokens](t, &attestation.AttestationResponseForMultipleTokens{
+		ReportType:           "sgx",
+		AttestationTimestamp: timestamp,
+		AttestationReport:    base64.StdEncoding.EncodeToString(quote),
+		OracleData: attestation.OracleData{
+			Signature: signatures.Signature,
+			Report:    string(oracleReport),
+			Address:   signatures.Address,
+			UserData:  string(mergedUserData),
+		},
+		AttestationResults: results,
+	})
+}
+
+func checkStatuses(report *Report) map[string]string {
+	statuses := map[string]string{}
+	for _, check := range report.Checks {
+		statuses[check.Name] = check.Status
+	}
+	return statuses
+}
+
+func TestVerifyAttestationResponse(t *testing.T) {
+	useSimulatedQuoteProvider(t)
+	response := newAttestationResponse(t)
+
+	report, err := VerifyAttestationResponse(response, trustedSigner(t))
+	require.Nil(t, err)
+
+	// Without collateral and a signature verifier nothing failed, but the response is not verified.
+	assert.False(t, report.Valid)
+	assert.Equal(t, ReportStatusUnverified, report.Status, "checks: %+v", report.Checks)
+	assert.Equal(t, map[string]string{
+		CheckQuote:                  CheckStatusPassed,
+		CheckReportSignature:        CheckStatusPassed,
+		CheckQuoteSignature:         CheckStatusSkipped,
+		CheckTCB:                    CheckStatusSkipped,
+		CheckReport:                 CheckStatusPassed,
+		CheckAttestationHash:        CheckStatusPassed,
+		CheckUserData:               CheckStatusPassed,
+		CheckEncodedRequest:         CheckStatusPassed,
+		CheckRequestHash:            CheckStatusPassed,
+		CheckTimestampedRequestHash: CheckStatusPassed,
+		CheckSigner:                 CheckStatusPassed,
+		CheckSignature:              CheckStatusSkipped,
+		CheckTimestamp:              CheckStatusSkipped,
+	}, checkStatuses(report))
+
+	quote, err := ParseAttestationReport(response.AttestationReport)
+	require.Nil(t, err)
+	require.NotNil(t, report.Quote)
+	assert.Equal(t, quote.Summary(), report.Quote)
+	assert.False(t, report.Quote.Debug)
+	assert.NotEmpty(t, report.Quote.FMSPC)
+}
+
+func TestVerifyAttestationResponse_Tampered(t *testing.T) {
+	useSimulatedQuoteProvider(t)
+
+	testCases := []struct {
+		name         string
+		tamper       func(response *AttestationResponse)
+		opts         Options
+		failedChecks []string
+	}{
+		{
+			name:         "attestation data",
+			tamper:       func(response *AttestationResponse) { response.AttestationData = "43" },
+			failedChecks: []string{CheckUserData},
+		},
+		{
+			name:         "timestamp",
+			tamper:       func(response *AttestationResponse) { response.AttestationTimestamp++ },
+			failedChecks: []string{CheckUserData, CheckTimestampedRequestHash},
+		},
+		{
+			name:         "aleo block height",
+			tamper:       func(response *AttestationResponse) { response.AleoBlockHeight++ },
+			failedChecks: []string{CheckUserData},
+		},
+		{
+			name:         "aleo block height removed",
+			tamper:       func(response *AttestationResponse) { response.AleoBlockHeight = 0 },
+			failedChecks: []string{CheckUserData, CheckEncodedRequest},
+		},
+		{
+			name:         "user data",
+			tamper:       func(response *AttestationResponse) { response.OracleData.UserData += " " },
+			failedChecks: []string{CheckAttestationHash, CheckUserData},
+		},
+		{
+			name:         "report",
+			tamper:       func(response *AttestationResponse) { response.OracleData.Report = "{ c0: 0u8 }" },
+			failedChecks: []string{CheckReport},
+		},
+		{
+			name:         "request hash",
+			tamper:       func(response *AttestationResponse) { response.OracleData.RequestHash = "1field" },
+			failedChecks: []string{CheckRequestHash},
+		},
+		{
+			name:         "attestation report",
+			tamper:       func(response *AttestationResponse) { response.AttestationReport = "not base64" },
+			failedChecks: []string{CheckQuote},
+		},
+		{
+			name: "roughtime proof for another request",
+			tamper: func(response *AttestationResponse) {
+				response.RoughtimeProof = &RoughtimeProof{RequestDigest: []byte("other request")}
+			},
+			opts:         Options{RoughtimeServers: map[string][]byte{"roughtime.example.com": make([]byte, 32)}},
+			failedChecks: []string{CheckTimestamp},
+		},
+		{
+			name:         "untrusted signer",
+			tamper:       func(response *AttestationResponse) {},
+			opts:         Options{TrustedAddresses: []string{"aleo1untrusted"}},
+			failedChecks: []string{CheckSigner},
+		},
+		{
+			name: "quote report data",
+			tamper: func(response *AttestationResponse) {
+				evidence, err := base64.StdEncoding.DecodeString(response.AttestationReport)
+				require.NoError(t, err)
+				quote, appErr := ParseAttestationReport(response.AttestationReport)
+				require.Nil(t, appErr)
+
+				// Forge the report data without access to the attestation key.
+				offset := bytes.Index(evidence, quote.ReportBody.ReportData[:16])
+				require.GreaterOrEqual(t, offset, 0)
+				evidence[offset] ^= 0xff
+				response.AttestationReport = base64.StdEncoding.EncodeToString(evidence)
+			},
+			failedChecks: []string{CheckReportSignature, CheckReport, CheckAttestationHash},
+		},
+		{
+			name:         "previous signature without address",
+			tamper:       func(response *AttestationResponse) { response.OracleData.PreviousSignature = "sign1forged" },
+			failedChecks: []string{CheckPreviousSignature},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			response := newAttestationResponse(t)
+			tc.tamper(response)
+
+			report, err := VerifyAttestationResponse(response, tc.opts)
+			require.Nil(t, err)
+			assert.False(t, report.Valid)
+			assert.Equal(t, ReportStatusInvalid, report.Status)
+
+			failed := []string{}
+			for _, check := range report.Checks {
+				if check.Status == CheckStatusFailed {
+					failed = append(failed, check.Name)
+				}
+			}
+			assert.ElementsMatch(t, tc.failedChecks, failed, "checks: %+v", report.Checks)
+		})
+	}
+}
+
+func TestVerifyAttestationResponseForMultipleTokens(t *testing.T) {
+	useSimulatedQuoteProvider(t)
+	response := newMultipleTokensResponse(t)
+
+	report, err := VerifyAttestationResponseForMultipleTokens(response, trustedSigner(t))
+	require.Nil(t, err)
+	assert.False(t, report.Valid)
+	assert.Equal(t, ReportStatusUnverified, report.Status, "checks: %+v", report.Checks)
+	assert.Equal(t, map[string]string{
+		CheckQuote:           CheckStatusPassed,
+		CheckReportSignature: CheckStatusPassed,
+		CheckQuoteSignature:  CheckStatusSkipped,
+		CheckTCB:             CheckStatusSkipped,
+		CheckReport:          CheckStatusPassed,
+		CheckAttestationHash: CheckStatusPassed,
+		CheckUserData:        CheckStatusPassed,
+		CheckRequestHash:     CheckStatusPassed,
+		CheckSigner:          CheckStatusPassed,
+		CheckSignature:       CheckStatusSkipped,
+		CheckTimestamp:       CheckStatusSkipped,
+	}, checkStatuses(report))
+
+	// A tampered price changes both the merged user data and that token's request hash.
+	response.AttestationResults[1].AttestationData = "3900.25"
+	response.AttestationResults[1].RequestHash = "1field"
+
+	report, err = VerifyAttestationResponseForMultipleTokens(response, trustedSigner(t))
+	require.Nil(t, err)
+	assert.False(t, report.Valid)
+	assert.Equal(t, ReportStatusInvalid, report.Status)
+
+	check, ok := report.GetCheck(CheckUserData)
+	require.True(t, ok)
+	assert.Equal(t, CheckStatusFailed, check.Status)
+
+	check, ok = report.GetCheck(CheckRequestHash)
+	require.True(t, ok)
+	assert.Equal(t, CheckStatusFailed, check.Status)
+	assert.Contains(t, check.Details, "1")
+}
+
+func TestVerifyAttestationResponse_Collateral(t *testing.T) {
+	useSimulatedQuoteProvider(t)
+	response := newAttestationResponse(t)
+
+	collateral, err := sgx.GetSimulatedCollateral()
+	require.Nil(t, err)
+
+	opts := trustedSigner(t)
+	opts.Collateral = collateral
+	opts.VerificationTime = time.Now()
+	opts.SignatureVerifier = signatureVerifier(t, response.OracleData)
+	signRoughtimeProof(t, response, &opts)
+
+	report, err := VerifyAttestationResponse(response, opts)
+	require.Nil(t, err)
+	assert.True(t, report.Valid, "checks: %+v", report.Checks)
+	assert.Equal(t, ReportStatusValid, report.Status)
+
+	statuses := checkStatuses(report)
+	assert.Equal(t, CheckStatusPassed, statuses[CheckTimestamp])
+	assert.Equal(t, CheckStatusPassed, statuses[CheckQuoteSignature])
+	assert.Equal(t, CheckStatusPassed, statuses[CheckTCB])
+	assert.Equal(t, CheckStatusPassed, statuses[CheckSignature])
+	require.NotNil(t, report.TCB)
+	assert.Equal(t, TCBStatusUpToDate, report.TCB.TCBStatus)
+
+	// An UpToDate platform fails when only a status it does not have is accepted.
+	opts.AcceptedTCBStatuses = []string{TCBStatusSWHardeningNeeded}
+	report, err = VerifyAttestationResponse(response, opts)
+	require.Nil(t, err)
+	assert.False(t, report.Valid)
+	assert.Equal(t, CheckStatusFailed, checkStatuses(report)[CheckTCB])
+
+	// Without a verification time the collateral is checked at the attestation timestamp, before it was issued.
+	opts.AcceptedTCBStatuses = nil
+	opts.VerificationTime = time.Time{}
+	report, err = VerifyAttestationResponse(response, opts)
+	require.Nil(t, err)
+	assert.False(t, report.Valid)
+	assert.Equal(t, CheckStatusFailed, checkStatuses(report)[CheckQuoteSignature])
+	assert.Equal(t, CheckStatusSkipped, checkStatuses(report)[CheckTCB])
+}
+
+func TestVerifyAttestationResponse_RoughtimeProofRemoved(t *testing.T) {
+	useSimulatedQuoteProvider(t)
+	response := newAttestationResponse(t)
+
+	collateral, err := sgx.GetSimulatedCollateral()
+	require.Nil(t, err)
+
+	opts := trustedSigner(t)
+	opts.Collateral = collateral
+	opts.VerificationTime = time.Now()
+	opts.SignatureVerifier = signatureVerifier(t, response.OracleData)
+	signRoughtimeProof(t, response, &opts)
+
+	report, err := VerifyAttestationResponse(response, opts)
+	require.Nil(t, err)
+	require.Equal(t, ReportStatusValid, report.Status, "checks: %+v", report.Checks)
+
+	// Stripping the proof does not downgrade the timestamp check to an optional one.
+	response.RoughtimeProof = nil
+	report, err = VerifyAttestationResponse(response, opts)
+	require.Nil(t, err)
+	assert.False(t, report.Valid)
+	assert.Equal(t, ReportStatusUnverified, report.Status)
+
+	check, ok := report.GetCheck(CheckTimestamp)
+	require.True(t, ok)
+	assert.Equal(t, CheckStatusSkipped, check.Status)
+	assert.Equal(t, "response has no roughtime proof", check.Details)
+}
+
+func TestVerifyAttestationResponse_Signature(t *testing.T) {
+	useSimulatedQuoteProvider(t)
+	response := newAttestationResponse(t)
+
+	opts := trustedSigner(t)
+	opts.SignatureVerifier = signatureVerifier(t, response.OracleData)
+
+	report, err := VerifyAttestationResponse(response, opts)
+	require.Nil(t, err)
+	assert.Equal(t, CheckStatusPassed, checkStatuses(report)[CheckSignature])
+	_, ok := report.GetCheck(CheckPreviousSignature)
+	assert.False(t, ok, "no previous signature outside a key rotation overlap window")
+
+	// A roughtime proof is not verified without trusted roughtime servers.
+	response.RoughtimeProof = &RoughtimeProof{}
+	report, err = VerifyAttestationResponse(response, opts)
+	require.Nil(t, err)
+	assert.Equal(t, ReportStatusUnverified, report.Status)
+	assert.Equal(t, CheckStatusSkipped, checkStatuses(report)[CheckTimestamp])
+
+	// A signature by a trusted address over another report fails.
+	response.OracleData.Signature = "sign1forged"
+	report, err = VerifyAttestationResponse(response, opts)
+	require.Nil(t, err)
+	assert.False(t, report.Valid)
+	assert.Equal(t, CheckStatusFailed, checkStatuses(report)[CheckSignature])
+
+	// A previous signature must be by a trusted address.
+	response = newAttestationResponse(t)
+	response.OracleData.PreviousAddress = "aleo1untrusted"
+	response.OracleData.PreviousSignature = response.OracleData.Signature
+	report, err = VerifyAttestationResponse(response, opts)
+	require.Nil(t, err)
+	assert.False(t, report.Valid)
+	assert.Equal(t, CheckStatusFailed, checkStatuses(report)[CheckPreviousSignature])
+}